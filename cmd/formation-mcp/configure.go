@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cyverse-de/formation-mcp/internal/config"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigPath is where `configure` writes the config file when -o is
+// not given, matching the default lookup path in config.Load.
+const defaultConfigPath = "~/.config/formation-mcp/config.yaml"
+
+// runConfigure implements the `formation-mcp configure` subcommand. It
+// builds a Config from flags (and, with -interactive, from stdin prompts
+// for anything still missing), validates it, and writes it out as YAML.
+func runConfigure(args []string) int {
+	fs := flag.NewFlagSet("configure", flag.ExitOnError)
+	var (
+		output      = fs.String("o", "", "Output path for the config file (default: "+defaultConfigPath+")")
+		baseURL     = fs.String("base-url", "", "Formation base URL")
+		token       = fs.String("token", "", "Formation JWT token")
+		username    = fs.String("username", "", "Formation username")
+		password    = fs.String("password", "", "Formation password")
+		logLevel    = fs.String("log-level", "info", "Log level: debug, info, warn, error")
+		metricsAddr = fs.String("metrics-addr", "", "Address for the metrics endpoint")
+		force       = fs.Bool("force", false, "Overwrite the config file if it already exists")
+		interactive = fs.Bool("interactive", false, "Prompt for any required fields not given as flags")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	cfg := &config.Config{
+		BaseURL:     *baseURL,
+		Token:       *token,
+		Username:    *username,
+		Password:    *password,
+		LogLevel:    *logLevel,
+		MetricsAddr: *metricsAddr,
+	}
+
+	if *interactive {
+		promptMissingFields(cfg)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	path, err := resolveConfigPath(*output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	if _, err := os.Stat(path); err == nil && !*force {
+		fmt.Fprintf(os.Stderr, "config file already exists at %s (use -force to overwrite)\n", path)
+		return 1
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create config directory: %v\n", err)
+		return 1
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal config: %v\n", err)
+		return 1
+	}
+
+	// Secrets end up in this file, so lock it down when it has any.
+	mode := os.FileMode(0o644)
+	if cfg.Token != "" || cfg.Password != "" {
+		mode = 0o600
+	}
+
+	if err := os.WriteFile(path, data, mode); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write config file: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Wrote configuration to %s\n", path)
+	printEnvEquivalents(cfg)
+	return 0
+}
+
+// resolveConfigPath expands a "~/"-prefixed output path or falls back to
+// defaultConfigPath when none is given.
+func resolveConfigPath(output string) (string, error) {
+	path := output
+	if path == "" {
+		path = defaultConfigPath
+	}
+
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	return path, nil
+}
+
+// promptMissingFields fills in any still-empty required fields on cfg by
+// prompting on stdin, masking password input via x/term.
+func promptMissingFields(cfg *config.Config) {
+	reader := bufio.NewReader(os.Stdin)
+
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = promptLine(reader, "Formation base URL: ")
+	}
+
+	if cfg.Token == "" && (cfg.Username == "" || cfg.Password == "") {
+		cfg.Token = promptLine(reader, "Token (leave blank to authenticate with username/password instead): ")
+		if cfg.Token == "" {
+			if cfg.Username == "" {
+				cfg.Username = promptLine(reader, "Username: ")
+			}
+			if cfg.Password == "" {
+				cfg.Password = promptPassword("Password: ")
+			}
+		}
+	}
+}
+
+// promptLine prints prompt and reads a single trimmed line from reader.
+func promptLine(reader *bufio.Reader, prompt string) string {
+	fmt.Print(prompt)
+	line, _ := reader.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// promptPassword prints prompt and reads a password from the terminal
+// without echoing it.
+func promptPassword(prompt string) string {
+	fmt.Print(prompt)
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// printEnvEquivalents prints the environment variables that would produce
+// an equivalent configuration, so users can compare config-file and
+// env-var based deployments. Secret values are redacted.
+func printEnvEquivalents(cfg *config.Config) {
+	fmt.Println("\nEquivalent environment variables:")
+	fmt.Printf("  FORMATION_BASE_URL=%s\n", cfg.BaseURL)
+	if cfg.Token != "" {
+		fmt.Println("  FORMATION_TOKEN=<redacted>")
+	}
+	if cfg.Username != "" {
+		fmt.Printf("  FORMATION_USERNAME=%s\n", cfg.Username)
+	}
+	if cfg.Password != "" {
+		fmt.Println("  FORMATION_PASSWORD=<redacted>")
+	}
+	fmt.Printf("  LOG_LEVEL=%s\n", cfg.LogLevel)
+	if cfg.MetricsAddr != "" {
+		fmt.Printf("  METRICS_ADDR=%s\n", cfg.MetricsAddr)
+	}
+}
@@ -5,9 +5,14 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/cyverse-de/formation-mcp/internal/audit"
 	"github.com/cyverse-de/formation-mcp/internal/client"
 	"github.com/cyverse-de/formation-mcp/internal/config"
 	"github.com/cyverse-de/formation-mcp/internal/logging"
@@ -19,6 +24,12 @@ import (
 const version = "1.0.0"
 
 func main() {
+	// The configure subcommand writes a config file and exits; it doesn't
+	// share the top-level flag set since it has its own flags.
+	if len(os.Args) > 1 && os.Args[1] == "configure" {
+		os.Exit(runConfigure(os.Args[2:]))
+	}
+
 	// Define CLI flags
 	var (
 		configFile   = flag.String("config", "", "Path to configuration file")
@@ -28,8 +39,16 @@ func main() {
 		password     = flag.String("password", "", "Formation password (overrides config file and env var)")
 		logLevel     = flag.String("log-level", "", "Log level: debug, info, warn, error (default: info)")
 		logJSON      = flag.Bool("log-json", false, "Output logs in JSON format")
+		logOTLP      = flag.Bool("log-otlp", false, "Output logs in OpenTelemetry Logs Data Model JSON format (overrides -log-json)")
+		logBackend   = flag.String("log-backend", "", "Logger backend internal/client and internal/workflows log through (default: slog; only slog is built in)")
+		logLevels    = flag.String("log-levels", "", "Per-subsystem log level overrides, e.g. client=debug,workflows=info,server=warn (overrides -log-level for those subsystems on the stderr sink)")
+		logSampling  = flag.Bool("log-sampling", false, "Sample debug logs (first 5 then 1-in-100 per second, per message) so a hot polling loop can't flood a sink; Warn/Error are never sampled")
 		showVersion  = flag.Bool("version", false, "Show version and exit")
 		pollInterval = flag.Int("poll-interval", 0, "Analysis status poll interval in seconds (default: 5)")
+		logSyslog    = flag.Bool("log-syslog", false, "Also send logs to the local syslog daemon")
+		logFile      = flag.String("log-file", "", "Also write logs to this file, with rotation")
+		auditFile    = flag.String("audit-file", "", "Record every tool call as a JSON line appended to this file")
+		auditSyslog  = flag.Bool("audit-syslog", false, "Also record every tool call to the local syslog daemon")
 	)
 
 	flag.Parse()
@@ -49,7 +68,15 @@ func main() {
 		Password:     *password,
 		LogLevel:     *logLevel,
 		LogJSON:      *logJSON,
+		LogOTLP:      *logOTLP,
+		LogBackend:   *logBackend,
+		LogLevels:    *logLevels,
+		LogSampling:  *logSampling,
 		PollInterval: *pollInterval,
+		LogSyslog:    *logSyslog,
+		LogFile:      *logFile,
+		AuditFile:    *auditFile,
+		AuditSyslog:  *auditSyslog,
 	}
 
 	// Load configuration with proper precedence
@@ -59,16 +86,63 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Setup logging
-	logger := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogJSON)
+	// Setup logging. baseLevel and subsystemLevels are shared, mutable
+	// state: the set_log_level/get_log_level MCP tools (and a SIGHUP
+	// reload) change them live, without rebuilding the handler chain - see
+	// logging.SubsystemLevelHandler.
+	baseLevel := &slog.LevelVar{}
+	baseLevel.Set(logging.ParseLevel(cfg.LogLevel))
+	subsystemLevels, err := logging.ParseSubsystemLevels(cfg.LogLevels)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Logging setup error: %v\n", err)
+		os.Exit(1)
+	}
+
+	sinks, err := buildLogSinks(cfg, baseLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Logging setup error: %v\n", err)
+		os.Exit(1)
+	}
+	logHandler := logging.NewReloadableHandler(buildLogHandler(sinks, baseLevel, subsystemLevels, cfg.LogSampling))
+	logger := slog.New(logHandler)
+	slog.SetDefault(logger)
 	logger.Info("formation-mcp starting", "version", version)
 
-	// Create Formation API client
+	// Reload configuration on SIGHUP, swapping in safe changes (log level,
+	// log format, log sinks, metrics addr, poll interval) without a restart.
+	reloader := config.NewReloader(cliConfig, cfg)
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for range sighup {
+			newCfg, err := reloader.Reload()
+			if err != nil {
+				logger.Error("config reload failed", "error", err)
+				continue
+			}
+			newSinks, err := buildLogSinks(newCfg, baseLevel)
+			if err != nil {
+				logger.Error("failed to rebuild log sinks on reload", "error", err)
+				continue
+			}
+			logHandler.Swap(buildLogHandler(newSinks, baseLevel, subsystemLevels, newCfg.LogSampling))
+			closeSinks(sinks)
+			sinks = newSinks
+			baseLevel.Set(logging.ParseLevel(newCfg.LogLevel))
+			replaceSubsystemLevels(subsystemLevels, newCfg.LogLevels)
+			logger.Info("configuration reloaded", "log_level", newCfg.LogLevel, "log_json", newCfg.LogJSON, "poll_interval", newCfg.PollInterval)
+		}
+	}()
+
+	// Create Formation API client, using the client package's default retry
+	// and rate-limit policies.
 	formationClient := client.NewFormationClient(
 		cfg.BaseURL,
 		cfg.Token,
 		cfg.Username,
 		cfg.Password,
+		nil,
+		nil,
 	)
 
 	// Create workflows
@@ -78,6 +152,26 @@ func main() {
 
 	// Create MCP server
 	formationMCPServer := formationServer.NewFormationMCPServer(formationWorkflows, formationClient)
+	formationMCPServer.SetCurrentUser(cfg.Username)
+	formationMCPServer.SetDefaultDeleteGracePeriodSeconds(cfg.DeleteGracePeriodSeconds)
+	formationMCPServer.SetLogBackend(logging.LogBackend(cfg.LogBackend))
+	formationMCPServer.SetLogLevelControls(baseLevel, subsystemLevels)
+
+	auditSinks, err := buildAuditSinks(cfg, formationClient)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Audit sink setup error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, sink := range auditSinks {
+		formationMCPServer.AuditLog().AddSink(sink)
+	}
+
+	if cfg.DeleteJobStateFile != "" {
+		if err := formationMCPServer.LoadDeleteJobs(cfg.DeleteJobStateFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Delete job store setup error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Start stdio server
 	logger.Info("starting MCP stdio server")
@@ -88,3 +182,115 @@ func main() {
 
 	logger.Info("formation-mcp shutting down")
 }
+
+// buildLogSinks builds the slog.Handlers requested by cfg: stderr is
+// always included, with a rotating file and/or syslog added on top when
+// configured. The stderr sink is built against baseLevel (a *slog.LevelVar)
+// rather than a level baked in at construction time, so set_log_level and
+// SIGHUP reloads can raise or lower its verbosity live - see
+// logging.SubsystemLevelHandler. The file and syslog sinks keep a level
+// fixed at construction, like LogFileMaxSizeMB and other deploy-time
+// settings; bumping their verbosity still requires a reload.
+func buildLogSinks(cfg *config.Config, baseLevel *slog.LevelVar) ([]slog.Handler, error) {
+	stderrFormat := logging.LogFormatHuman
+	switch {
+	case cfg.LogOTLP:
+		stderrFormat = logging.LogFormatOTLP
+	case cfg.LogJSON:
+		stderrFormat = logging.LogFormatJSON
+	}
+	sinks := []slog.Handler{logging.BuildHandlerWithLeveler(os.Stderr, baseLevel, stderrFormat)}
+
+	if cfg.LogFile != "" {
+		fileHandler, err := logging.NewRotatingFileHandler(cfg.LogFile, cfg.LogFileMaxSizeMB, cfg.LogFileMaxAgeDays, cfg.LogFileMaxBackups, cfg.LogLevel, cfg.LogJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		sinks = append(sinks, fileHandler)
+	}
+
+	if cfg.LogSyslog {
+		syslogHandler, err := logging.NewSyslogHandler(cfg.LogSyslogFacility, "formation-mcp", cfg.LogLevel, cfg.LogJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		sinks = append(sinks, syslogHandler)
+	}
+
+	return sinks, nil
+}
+
+// buildLogHandler assembles sinks into the handler chain the server logs
+// through: a SubsystemLevelHandler for set_log_level/get_log_level, and,
+// when sampling is set (--log-sampling/LOG_SAMPLING), an outer
+// SamplingHandler so a hot polling loop's debug logs can't flood a sink -
+// see logging.NewSamplingHandler.
+func buildLogHandler(sinks []slog.Handler, baseLevel *slog.LevelVar, subsystemLevels *logging.SubsystemLevels, sampling bool) slog.Handler {
+	var handler slog.Handler = logging.NewSubsystemLevelHandler(logging.NewMultiHandler(sinks...), baseLevel, subsystemLevels)
+	if sampling {
+		handler = logging.NewSamplingHandler(handler, logging.DefaultSamplingOptions())
+	}
+	return handler
+}
+
+// closeSinks closes every sink in sinks that implements io.Closer (a
+// rotating file handler's background pruning goroutine and open file,
+// notably - see logging.NewRotatingFileHandler), ignoring errors since
+// this only runs to release resources superseded by a reload, never to
+// report failures back to a caller. Sinks with nothing to release (stderr,
+// syslog) are skipped silently.
+func closeSinks(sinks []slog.Handler) {
+	for _, sink := range sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+}
+
+// replaceSubsystemLevels updates levels in place to match spec, so the
+// *logging.SubsystemLevels pointer already handed to the set_log_level
+// tool and the SubsystemLevelHandler stays live across a reload instead of
+// needing to be swapped out too. A malformed spec (already rejected by
+// config.Config.Validate at load time) is treated as no overrides rather
+// than failing the reload outright.
+func replaceSubsystemLevels(levels *logging.SubsystemLevels, spec string) {
+	next, err := logging.ParseSubsystemLevels(spec)
+	if err != nil {
+		next = logging.NewSubsystemLevels()
+	}
+	for subsystem := range levels.All() {
+		levels.Clear(subsystem)
+	}
+	for subsystem, level := range next.All() {
+		levels.Set(subsystem, level)
+	}
+}
+
+// buildAuditSinks builds the audit.Sinks requested by cfg, on top of the
+// server's always-on in-memory history. formationClient is reused for the
+// iRODS sink rather than minting a separate connection.
+func buildAuditSinks(cfg *config.Config, formationClient client.FormationAPIClient) ([]audit.Sink, error) {
+	var sinks []audit.Sink
+
+	if cfg.AuditFile != "" {
+		fileSink, err := audit.NewJSONLFileSink(cfg.AuditFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit file: %w", err)
+		}
+		sinks = append(sinks, fileSink)
+	}
+
+	if cfg.AuditSyslog {
+		syslogSink, err := audit.NewSyslogSink(cfg.AuditSyslogFacility, "formation-mcp-audit")
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect audit sink to syslog: %w", err)
+		}
+		sinks = append(sinks, syslogSink)
+	}
+
+	if cfg.AuditIRODSCollection != "" {
+		sinks = append(sinks, audit.NewIRODSSink(formationClient, cfg.AuditIRODSCollection))
+	}
+
+	return sinks, nil
+}
@@ -0,0 +1,158 @@
+// Package audit records every MCP tool invocation a FormationMCPServer
+// handles - timestamp, tool name, parameters, a summary of the result, any
+// error, and how long it took - so operators can answer "what did this
+// session actually do" after the fact, and so a prior call can be looked
+// up and replayed.
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Record is one tool invocation as recorded by a Log.
+type Record struct {
+	// ID identifies this call. It's normally the same request id the
+	// server generated for the call (see client.WithRequestID), so a
+	// single id ties an audit Record back to the log lines and outgoing
+	// Formation requests it produced.
+	ID string `json:"id"`
+
+	Timestamp     time.Time              `json:"timestamp"`
+	Tool          string                 `json:"tool"`
+	Params        map[string]interface{} `json:"params,omitempty"`
+	ResultSummary string                 `json:"result_summary,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+	Duration      time.Duration          `json:"duration"`
+	User          string                 `json:"user,omitempty"`
+}
+
+// Sink receives a copy of every Record a Log produces, for durable storage
+// outside the process (a file, syslog, an iRODS collection). A sink error
+// is surfaced to the caller of Log.Record so it can be logged, but never
+// prevents the record from being kept in memory - a broken audit sink
+// shouldn't take list_tool_history or replay_tool_call down with it.
+type Sink interface {
+	Write(ctx context.Context, record Record) error
+}
+
+// Filter narrows Log.Query to a subset of recorded calls. A zero Filter
+// matches everything.
+type Filter struct {
+	// Tool restricts results to this tool name, if non-empty.
+	Tool string
+
+	// Since and Until, if non-zero, bound Timestamp to [Since, Until].
+	Since time.Time
+	Until time.Time
+
+	// FailedOnly restricts results to calls that returned an error.
+	FailedOnly bool
+}
+
+// matches reports whether record satisfies f.
+func (f Filter) matches(record Record) bool {
+	if f.Tool != "" && record.Tool != f.Tool {
+		return false
+	}
+	if !f.Since.IsZero() && record.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && record.Timestamp.After(f.Until) {
+		return false
+	}
+	if f.FailedOnly && record.Error == "" {
+		return false
+	}
+	return true
+}
+
+// maxRecords bounds the in-memory history so a long-running server
+// doesn't grow without limit. Like FormationWorkflows' launches map, this
+// is a best-effort window, not a durable record - configure a Sink for
+// that.
+const maxRecords = 1000
+
+// Log is an in-memory, thread-safe history of tool invocations, fanned out
+// to zero or more Sinks as each call is recorded.
+type Log struct {
+	mu      sync.Mutex
+	sinks   []Sink
+	records []Record
+	nextID  uint64
+}
+
+// NewLog creates a Log that keeps the last maxRecords calls in memory and
+// fans each one out to sinks as well.
+func NewLog(sinks ...Sink) *Log {
+	return &Log{sinks: append([]Sink(nil), sinks...)}
+}
+
+// AddSink appends sink to l, so a sink can be wired up after startup (e.g.
+// once configuration has been reloaded), the way ReloadableHandler.Swap
+// lets the logging package's sinks change without a restart.
+func (l *Log) AddSink(sink Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, sink)
+}
+
+// Record appends entry to the log - assigning it an ID and timestamp if
+// entry doesn't already carry them - and fans it out to every configured
+// Sink. The record is kept in memory even if a sink write fails; sink
+// errors are joined and returned so the caller can log them.
+func (l *Log) Record(ctx context.Context, entry Record) (Record, error) {
+	l.mu.Lock()
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if entry.ID == "" {
+		l.nextID++
+		entry.ID = fmt.Sprintf("call-%d", l.nextID)
+	}
+	l.records = append(l.records, entry)
+	if len(l.records) > maxRecords {
+		l.records = l.records[len(l.records)-maxRecords:]
+	}
+	sinks := append([]Sink(nil), l.sinks...)
+	l.mu.Unlock()
+
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Write(ctx, entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return entry, errors.Join(errs...)
+}
+
+// Query returns every recorded call matching filter, oldest first.
+func (l *Log) Query(filter Filter) []Record {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var matches []Record
+	for _, record := range l.records {
+		if filter.matches(record) {
+			matches = append(matches, record)
+		}
+	}
+	return matches
+}
+
+// Get returns the recorded call with the given ID, if it's still in the
+// in-memory window.
+func (l *Log) Get(id string) (Record, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := len(l.records) - 1; i >= 0; i-- {
+		if l.records[i].ID == id {
+			return l.records[i], true
+		}
+	}
+	return Record{}, false
+}
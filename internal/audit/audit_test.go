@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// recordingSink is a Sink that just remembers every Record it was given,
+// for asserting fan-out behavior without touching a file or syslog.
+type recordingSink struct {
+	records  []Record
+	failNext bool
+}
+
+func (s *recordingSink) Write(ctx context.Context, record Record) error {
+	if s.failNext {
+		s.failNext = false
+		return errors.New("sink write failed")
+	}
+	s.records = append(s.records, record)
+	return nil
+}
+
+func TestLogRecordAssignsIDAndTimestamp(t *testing.T) {
+	l := NewLog()
+
+	recorded, err := l.Record(context.Background(), Record{Tool: "list_apps"})
+	if err != nil {
+		t.Fatalf("Record() unexpected error = %v", err)
+	}
+	if recorded.ID == "" {
+		t.Error("Record() left ID empty")
+	}
+	if recorded.Timestamp.IsZero() {
+		t.Error("Record() left Timestamp zero")
+	}
+
+	// A caller-supplied ID (e.g. the request id a tool call already has)
+	// is preserved rather than overwritten.
+	recorded, err = l.Record(context.Background(), Record{ID: "req-123", Tool: "list_apps"})
+	if err != nil {
+		t.Fatalf("Record() unexpected error = %v", err)
+	}
+	if recorded.ID != "req-123" {
+		t.Errorf("Record() ID = %q, want req-123", recorded.ID)
+	}
+}
+
+func TestLogFansOutToSinks(t *testing.T) {
+	sinkA := &recordingSink{}
+	sinkB := &recordingSink{}
+	l := NewLog(sinkA, sinkB)
+
+	if _, err := l.Record(context.Background(), Record{Tool: "launch_app_and_wait"}); err != nil {
+		t.Fatalf("Record() unexpected error = %v", err)
+	}
+
+	if len(sinkA.records) != 1 || len(sinkB.records) != 1 {
+		t.Fatalf("sinkA has %d records, sinkB has %d, want 1 each", len(sinkA.records), len(sinkB.records))
+	}
+}
+
+func TestLogRecordKeepsEntryDespiteSinkError(t *testing.T) {
+	sink := &recordingSink{failNext: true}
+	l := NewLog(sink)
+
+	if _, err := l.Record(context.Background(), Record{ID: "req-1", Tool: "upload_file"}); err == nil {
+		t.Fatal("Record() expected a sink error to be returned")
+	}
+
+	if _, ok := l.Get("req-1"); !ok {
+		t.Error("Get() could not find the record despite the sink failing")
+	}
+}
+
+func TestLogQueryFilters(t *testing.T) {
+	l := NewLog()
+	ctx := context.Background()
+
+	l.Record(ctx, Record{ID: "a", Tool: "list_apps"})
+	l.Record(ctx, Record{ID: "b", Tool: "upload_file", Error: "boom"})
+	l.Record(ctx, Record{ID: "c", Tool: "upload_file"})
+
+	byTool := l.Query(Filter{Tool: "upload_file"})
+	if len(byTool) != 2 {
+		t.Fatalf("Query(Tool=upload_file) returned %d records, want 2", len(byTool))
+	}
+
+	failed := l.Query(Filter{FailedOnly: true})
+	if len(failed) != 1 || failed[0].ID != "b" {
+		t.Fatalf("Query(FailedOnly=true) = %+v, want only record b", failed)
+	}
+}
+
+func TestLogGetReturnsNotFoundForUnknownID(t *testing.T) {
+	l := NewLog()
+	if _, ok := l.Get("does-not-exist"); ok {
+		t.Error("Get() unexpectedly found a record for an unknown ID")
+	}
+}
+
+func TestLogTrimsToMaxRecords(t *testing.T) {
+	l := NewLog()
+	ctx := context.Background()
+
+	for i := 0; i < maxRecords+10; i++ {
+		l.Record(ctx, Record{Tool: "list_apps"})
+	}
+
+	all := l.Query(Filter{})
+	if len(all) != maxRecords {
+		t.Fatalf("Query() returned %d records, want capped at %d", len(all), maxRecords)
+	}
+}
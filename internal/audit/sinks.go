@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// JSONLFileSink appends each Record to a file as one JSON object per line,
+// for structured, append-friendly output.
+type JSONLFileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewJSONLFileSink opens (creating if necessary) path for appending and
+// returns a Sink that writes one JSON line per Record.
+func NewJSONLFileSink(path string) (*JSONLFileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	return &JSONLFileSink{file: file}, nil
+}
+
+// Write implements Sink.
+func (s *JSONLFileSink) Write(_ context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(line)
+	return err
+}
+
+// Close closes the underlying file.
+func (s *JSONLFileSink) Close() error {
+	return s.file.Close()
+}
+
+// IRODSSink writes each Record as its own JSON data object under a
+// collection in iRODS, via the same client.FormationAPIClient the rest of
+// the server talks to Formation through - there's no separate iRODS
+// credential to manage, and every audit entry ends up browsable with
+// browse_data like any other file.
+type IRODSSink struct {
+	client     client.FormationAPIClient
+	collection string
+}
+
+// NewIRODSSink returns a Sink that uploads each Record as
+// "<collection>/<id>.json".
+func NewIRODSSink(c client.FormationAPIClient, collection string) *IRODSSink {
+	return &IRODSSink{client: c, collection: strings.TrimSuffix(collection, "/")}
+}
+
+// Write implements Sink.
+func (s *IRODSSink) Write(ctx context.Context, record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	path := fmt.Sprintf("%s/%s.json", s.collection, record.ID)
+	return s.client.UploadFile(ctx, path, string(body), nil)
+}
@@ -0,0 +1,64 @@
+//go:build !windows
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"sync"
+)
+
+// SyslogSink writes each Record as a single JSON-encoded LOG_INFO message
+// to the local syslog daemon, under the given facility and tag - the same
+// facility vocabulary logging.NewSyslogHandler uses for LOG_SYSLOG_FACILITY.
+type SyslogSink struct {
+	mu sync.Mutex
+	w  *syslog.Writer
+}
+
+// syslogFacilities mirrors logging.syslogFacilities; kept separate so the
+// audit package doesn't depend on the logging package for a one-line map.
+var syslogFacilities = map[string]syslog.Priority{
+	"daemon": syslog.LOG_DAEMON,
+	"user":   syslog.LOG_USER,
+	"local0": syslog.LOG_LOCAL0,
+	"local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4,
+	"local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6,
+	"local7": syslog.LOG_LOCAL7,
+}
+
+// NewSyslogSink connects to the local syslog daemon under facility (empty
+// defaults to "daemon") and tag.
+func NewSyslogSink(facility, tag string) (*SyslogSink, error) {
+	if facility == "" {
+		facility = "daemon"
+	}
+	priority, ok := syslogFacilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility: %q", facility)
+	}
+
+	w, err := syslog.New(priority|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &SyslogSink{w: w}, nil
+}
+
+// Write implements Sink.
+func (s *SyslogSink) Write(_ context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Info(string(line))
+}
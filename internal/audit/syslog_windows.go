@@ -0,0 +1,22 @@
+//go:build windows
+
+package audit
+
+import (
+	"context"
+	"errors"
+)
+
+// NewSyslogSink is unsupported on Windows, which has no syslog daemon.
+func NewSyslogSink(facility, tag string) (*SyslogSink, error) {
+	return nil, errors.New("syslog audit sink is not supported on windows")
+}
+
+// SyslogSink is an unused placeholder on Windows; see syslog_unix.go for
+// the real implementation.
+type SyslogSink struct{}
+
+// Write implements Sink.
+func (s *SyslogSink) Write(ctx context.Context, record Record) error {
+	return errors.New("syslog audit sink is not supported on windows")
+}
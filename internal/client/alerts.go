@@ -0,0 +1,444 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// alertPollInterval is how often AlertManager checks a subscribed alert's
+// analysis for a status or url_ready transition. A var, not a const, so
+// tests can shorten it rather than waiting out a real 5 seconds per poll.
+var alertPollInterval = 5 * time.Second
+
+// AlertEvent identifies a single analysis lifecycle transition an
+// AnalysisAlert can fire on.
+type AlertEvent string
+
+const (
+	AlertEventStatusChanged AlertEvent = "status_changed"
+	AlertEventURLReady      AlertEvent = "url_ready"
+	AlertEventCompleted     AlertEvent = "completed"
+	AlertEventFailed        AlertEvent = "failed"
+)
+
+// AlertPhase is the lifecycle phase of an AnalysisAlert subscription
+// itself, not the analysis it watches: PENDING until the first poll
+// succeeds, ACTIVE while polling is healthy, ERROR when the most recent
+// poll failed (LastError holds why).
+type AlertPhase string
+
+const (
+	AlertPhasePending AlertPhase = "PENDING"
+	AlertPhaseActive  AlertPhase = "ACTIVE"
+	AlertPhaseError   AlertPhase = "ERROR"
+)
+
+// SlackWebhook is a Slack incoming-webhook destination for an
+// AnalysisAlert.
+type SlackWebhook struct {
+	URL     string `json:"url"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// HTTPWebhook is a generic HTTP destination for an AnalysisAlert, for
+// anything that doesn't speak Slack's incoming-webhook format (CI systems,
+// custom chat bots).
+type HTTPWebhook struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// AnalysisAlert subscribes to lifecycle Events on AnalysisID and dispatches
+// them to Emails/SlackWebhooks/HTTPWebhooks, so downstream systems can
+// react to a VICE app's launch without polling GetAnalysisStatus
+// themselves. Secret, if set, signs every HTTPWebhooks delivery (see
+// AlertManager.postHTTPWebhook).
+type AnalysisAlert struct {
+	ID            string         `json:"id"`
+	AnalysisID    string         `json:"analysis_id"`
+	Events        []AlertEvent   `json:"events"`
+	Emails        []string       `json:"emails,omitempty"`
+	SlackWebhooks []SlackWebhook `json:"slack_webhooks,omitempty"`
+	HTTPWebhooks  []HTTPWebhook  `json:"http_webhooks,omitempty"`
+	Secret        string         `json:"secret,omitempty"`
+
+	// Phase, LastError, and DeliveriesSent report the subscription's own
+	// health, separate from the Status of the analysis it watches.
+	Phase          AlertPhase `json:"phase"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveriesSent int        `json:"deliveries_sent"`
+}
+
+// AlertPayload is the JSON body delivered to every SlackWebhooks/
+// HTTPWebhooks destination a fired AnalysisAlert has configured.
+type AlertPayload struct {
+	AlertID    string     `json:"alert_id"`
+	AnalysisID string     `json:"analysis_id"`
+	Event      AlertEvent `json:"event"`
+	Status     string     `json:"status"`
+	URLReady   bool       `json:"url_ready"`
+	URL        string     `json:"url,omitempty"`
+	Timestamp  time.Time  `json:"timestamp"`
+}
+
+// alertSubscription is the live, mutable state AlertManager tracks for one
+// AnalysisAlert - its own mutex guards the fields run updates concurrently
+// with create/list/delete reading them.
+type alertSubscription struct {
+	mu     sync.Mutex
+	alert  AnalysisAlert
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (s *alertSubscription) snapshot() AnalysisAlert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.alert
+}
+
+func (s *alertSubscription) update(fn func(*AnalysisAlert)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.alert)
+}
+
+// AlertManager runs the background poll-and-dispatch loop behind
+// FormationClient's CreateAnalysisAlert/ListAnalysisAlerts/
+// DeleteAnalysisAlert: one goroutine per subscribed alert, each polling
+// GetAnalysisStatus on its own alertPollInterval ticker and firing a
+// webhook delivery for every subscribed AlertEvent its poll newly
+// satisfies, modeled on AnalysisWatcher's one-goroutine-per-subject shape
+// but without the cross-subscriber coalescing AnalysisWatcher needs, since
+// alerts don't share subscribers the way LaunchAndWaitStream callers do.
+type AlertManager struct {
+	client FormationAPIClient
+
+	mu     sync.Mutex
+	alerts map[string]*alertSubscription
+
+	webhookClient *http.Client
+}
+
+// newAlertManager returns an AlertManager with no alerts registered yet.
+func newAlertManager(c FormationAPIClient) *AlertManager {
+	return &AlertManager{
+		client:        c,
+		alerts:        make(map[string]*alertSubscription),
+		webhookClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// create validates alert, assigns it an ID, and starts its poll loop.
+func (m *AlertManager) create(alert AnalysisAlert) (*AnalysisAlert, error) {
+	if alert.AnalysisID == "" {
+		return nil, fmt.Errorf("alert: analysis_id is required")
+	}
+	if len(alert.Events) == 0 {
+		return nil, fmt.Errorf("alert: at least one event is required")
+	}
+	if len(alert.Emails) == 0 && len(alert.SlackWebhooks) == 0 && len(alert.HTTPWebhooks) == 0 {
+		return nil, fmt.Errorf("alert: at least one destination (emails, slack_webhooks, or http_webhooks) is required")
+	}
+
+	id, err := newUploadSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate alert id: %w", err)
+	}
+	alert.ID = id
+	alert.Phase = AlertPhasePending
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &alertSubscription{alert: alert, cancel: cancel, done: make(chan struct{})}
+
+	m.mu.Lock()
+	m.alerts[id] = sub
+	m.mu.Unlock()
+
+	result := sub.snapshot()
+	go m.run(ctx, sub)
+
+	return &result, nil
+}
+
+// list returns every registered alert, optionally filtered to one
+// analysisID ("" returns all of them).
+func (m *AlertManager) list(analysisID string) []AnalysisAlert {
+	m.mu.Lock()
+	subs := make([]*alertSubscription, 0, len(m.alerts))
+	for _, sub := range m.alerts {
+		subs = append(subs, sub)
+	}
+	m.mu.Unlock()
+
+	out := make([]AnalysisAlert, 0, len(subs))
+	for _, sub := range subs {
+		alert := sub.snapshot()
+		if analysisID == "" || alert.AnalysisID == analysisID {
+			out = append(out, alert)
+		}
+	}
+	return out
+}
+
+// delete cancels alertID's poll loop, waits for it to exit, and removes
+// it.
+func (m *AlertManager) delete(alertID string) error {
+	m.mu.Lock()
+	sub, ok := m.alerts[alertID]
+	if ok {
+		delete(m.alerts, alertID)
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no such alert %q", alertID)
+	}
+
+	sub.cancel()
+	<-sub.done
+	return nil
+}
+
+// Stop cancels every in-flight alert subscription and waits for their poll
+// loops to exit, mirroring AnalysisWatcher.Stop so FormationClient.Shutdown
+// can drain background alert polling the same way workflows drains
+// AnalysisWatcher.
+func (m *AlertManager) Stop() {
+	m.mu.Lock()
+	subs := make([]*alertSubscription, 0, len(m.alerts))
+	for _, sub := range m.alerts {
+		subs = append(subs, sub)
+	}
+	m.alerts = make(map[string]*alertSubscription)
+	m.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.cancel()
+	}
+	for _, sub := range subs {
+		<-sub.done
+	}
+}
+
+// run polls sub's analysis every alertPollInterval until ctx is cancelled
+// or the analysis reaches a terminal status, dispatching a delivery for
+// every subscribed AlertEvent the poll's result newly satisfies.
+func (m *AlertManager) run(ctx context.Context, sub *alertSubscription) {
+	defer close(sub.done)
+
+	ticker := time.NewTicker(alertPollInterval)
+	defer ticker.Stop()
+
+	var lastStatus string
+	var lastURLReady bool
+	first := true
+
+	for {
+		analysisID := sub.snapshot().AnalysisID
+		status, err := m.client.GetAnalysisStatus(ctx, analysisID)
+		if err != nil {
+			sub.update(func(a *AnalysisAlert) {
+				a.Phase = AlertPhaseError
+				a.LastError = err.Error()
+			})
+		} else {
+			for _, event := range m.triggeredEvents(sub, first, lastStatus, lastURLReady, status) {
+				m.dispatch(ctx, sub, event, status)
+			}
+
+			first = false
+			lastStatus = status.Status
+			lastURLReady = status.URLReady
+
+			sub.update(func(a *AnalysisAlert) {
+				a.Phase = AlertPhaseActive
+				a.LastError = ""
+			})
+
+			if terminalAnalysisStatuses[status.Status] {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// triggeredEvents reports which of sub's subscribed Events this poll's
+// status newly satisfies: status_changed on any change after the first
+// poll, url_ready the first time URLReady flips true, and completed/failed
+// on entering those terminal statuses.
+func (m *AlertManager) triggeredEvents(sub *alertSubscription, first bool, lastStatus string, lastURLReady bool, status *AnalysisStatus) []AlertEvent {
+	alert := sub.snapshot()
+	subscribed := make(map[AlertEvent]bool, len(alert.Events))
+	for _, event := range alert.Events {
+		subscribed[event] = true
+	}
+
+	var fired []AlertEvent
+	if !first && status.Status != lastStatus && subscribed[AlertEventStatusChanged] {
+		fired = append(fired, AlertEventStatusChanged)
+	}
+	if status.URLReady && !lastURLReady && subscribed[AlertEventURLReady] {
+		fired = append(fired, AlertEventURLReady)
+	}
+	if first || status.Status != lastStatus {
+		switch status.Status {
+		case "Completed":
+			if subscribed[AlertEventCompleted] {
+				fired = append(fired, AlertEventCompleted)
+			}
+		case "Failed":
+			if subscribed[AlertEventFailed] {
+				fired = append(fired, AlertEventFailed)
+			}
+		}
+	}
+	return fired
+}
+
+// dispatch delivers event to every destination sub's alert configures,
+// recording the last delivery error (if any) and bumping DeliveriesSent
+// regardless of whether every destination succeeded - a single bad
+// webhook URL shouldn't stop the others from being notified.
+func (m *AlertManager) dispatch(ctx context.Context, sub *alertSubscription, event AlertEvent, status *AnalysisStatus) {
+	alert := sub.snapshot()
+	payload := AlertPayload{
+		AlertID:    alert.ID,
+		AnalysisID: alert.AnalysisID,
+		Event:      event,
+		Status:     status.Status,
+		URLReady:   status.URLReady,
+		URL:        status.URL,
+		Timestamp:  time.Now(),
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		sub.update(func(a *AnalysisAlert) { a.LastError = fmt.Sprintf("failed to encode alert payload: %v", err) })
+		return
+	}
+
+	// Email delivery has no transport in this module yet - emails is
+	// accepted and reported back so a caller can see who's subscribed, but
+	// isn't actually sent anywhere.
+	var lastErr error
+	for _, wh := range alert.SlackWebhooks {
+		if err := m.postSlack(ctx, wh, payload); err != nil {
+			lastErr = err
+		}
+	}
+	for _, wh := range alert.HTTPWebhooks {
+		if err := m.postHTTPWebhook(ctx, wh, alert.Secret, body); err != nil {
+			lastErr = err
+		}
+	}
+
+	sub.update(func(a *AnalysisAlert) {
+		a.DeliveriesSent++
+		if lastErr != nil {
+			a.LastError = lastErr.Error()
+		}
+	})
+}
+
+// postSlack posts a plain-text summary of payload to wh, in the format
+// Slack's incoming webhooks expect.
+func (m *AlertManager) postSlack(ctx context.Context, wh SlackWebhook, payload AlertPayload) error {
+	slackBody := map[string]interface{}{
+		"text": fmt.Sprintf("analysis %s: %s (status=%s)", payload.AnalysisID, payload.Event, payload.Status),
+	}
+	if wh.Channel != "" {
+		slackBody["channel"] = wh.Channel
+	}
+
+	body, err := json.Marshal(slackBody)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack webhook delivery to %s failed: %w", wh.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("slack webhook delivery to %s returned status %d", wh.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// postHTTPWebhook delivers body to wh, signed with an HMAC-SHA256 of body
+// keyed by secret (the same scheme GitHub and Stripe use for webhook
+// signatures) whenever secret is non-empty, so the receiver can verify the
+// payload actually came from this alert and wasn't tampered with in
+// transit.
+func (m *AlertManager) postHTTPWebhook(ctx context.Context, wh HTTPWebhook, secret string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range wh.Headers {
+		req.Header.Set(k, v)
+	}
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-Formation-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := m.webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook delivery to %s failed: %w", wh.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("webhook delivery to %s returned status %d", wh.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// CreateAnalysisAlert registers alert and starts a background poll loop
+// that watches alert.AnalysisID for the subscribed Events, dispatching
+// signed webhook deliveries as they occur. The returned AnalysisAlert has
+// its ID and initial Phase (PENDING, until the first poll completes)
+// filled in. This is complementary to WatchAnalysis/WatchAnalyses: those
+// stream updates to a caller still connected and watching, while an
+// AnalysisAlert keeps dispatching to its configured destinations for as
+// long as it's registered, whether or not anyone is watching.
+func (c *FormationClient) CreateAnalysisAlert(ctx context.Context, alert AnalysisAlert) (*AnalysisAlert, error) {
+	return c.alerts.create(alert)
+}
+
+// ListAnalysisAlerts returns every alert currently registered, optionally
+// filtered to one analysisID ("" returns all of them).
+func (c *FormationClient) ListAnalysisAlerts(ctx context.Context, analysisID string) ([]AnalysisAlert, error) {
+	return c.alerts.list(analysisID), nil
+}
+
+// DeleteAnalysisAlert cancels alertID's poll loop and removes it.
+func (c *FormationClient) DeleteAnalysisAlert(ctx context.Context, alertID string) error {
+	return c.alerts.delete(alertID)
+}
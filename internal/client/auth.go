@@ -0,0 +1,690 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuthProvider supplies FormationClient with a bearer token for the
+// Authorization header, obtaining or refreshing it as needed.
+// BasicAuthProvider and OIDCAuthProvider both implement it, so
+// NewFormationClientWithAuth accepts either interchangeably.
+type AuthProvider interface {
+	// Token returns a valid bearer token, logging in or refreshing as
+	// necessary.
+	Token(ctx context.Context) (string, error)
+}
+
+// BasicAuthProvider authenticates against the Formation API's own
+// username/password login endpoint - the same flow a FormationClient
+// built with NewFormationClient uses internally, wrapped as an
+// AuthProvider so it can be swapped for OIDCAuthProvider.
+type BasicAuthProvider struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewBasicAuthProvider creates a BasicAuthProvider for baseURL.
+func NewBasicAuthProvider(baseURL, username, password string) *BasicAuthProvider {
+	return &BasicAuthProvider{
+		baseURL:    baseURL,
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+// Token implements AuthProvider.
+func (p *BasicAuthProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Now().Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/login", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create login request: %w", err)
+	}
+	req.SetBasicAuth(p.username, p.password)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("login request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("login failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var loginResp LoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&loginResp); err != nil {
+		return "", fmt.Errorf("failed to decode login response: %w", err)
+	}
+
+	p.token = loginResp.AccessToken
+	p.tokenExpiry = time.Now().Add(time.Duration(loginResp.ExpiresIn) * time.Second).Add(-tokenExpiryMargin)
+	return p.token, nil
+}
+
+// OAuthToken is the token material OIDCAuthProvider persists to a
+// TokenStore between calls, so a refresh token survives a process
+// restart without a new interactive authorization.
+type OAuthToken struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+// TokenStore persists OAuthToken values for OIDCAuthProvider, keyed by a
+// caller-chosen identifier (e.g. the configured username), so one store
+// can back several providers. Implementations must be safe for
+// concurrent use.
+type TokenStore interface {
+	Get(key string) (OAuthToken, bool, error)
+	Save(key string, token OAuthToken) error
+}
+
+// MemoryTokenStore is an in-memory TokenStore. It's the default used when
+// OIDCAuthProvider.TokenStore is nil, and can't resume a refresh token
+// across process restarts.
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]OAuthToken
+}
+
+// NewMemoryTokenStore creates an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]OAuthToken)}
+}
+
+// Get implements TokenStore.
+func (s *MemoryTokenStore) Get(key string) (OAuthToken, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[key]
+	return token, ok, nil
+}
+
+// Save implements TokenStore.
+func (s *MemoryTokenStore) Save(key string, token OAuthToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = token
+	return nil
+}
+
+// URLOpener opens url in the user's default browser. It has the same
+// shape as workflows.BrowserOpener; OIDCAuthProvider declares its own
+// copy rather than importing the workflows package, which already
+// imports client.
+type URLOpener interface {
+	Open(url string) error
+}
+
+// oidcDiscovery is the subset of an OIDC issuer's discovery document
+// (<issuer>/.well-known/openid-configuration) OIDCAuthProvider needs.
+type oidcDiscovery struct {
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// oidcTokenResponse is a token endpoint response, for both the
+// authorization_code and refresh_token grants.
+type oidcTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// OIDCAuthProvider authenticates via the OAuth2 Authorization Code flow
+// with PKCE, for identity providers (e.g. Keycloak) where the Resource
+// Owner Password grant BasicAuthProvider relies on isn't available.
+// Authorize must be called once, interactively, before Token can
+// succeed; after that, Token silently refreshes the access token using
+// the stored refresh token as it nears expiry.
+type OIDCAuthProvider struct {
+	// IssuerURL is the OIDC issuer, e.g. https://idp.example.org/realms/cyverse.
+	IssuerURL string
+
+	// ClientID is the OAuth2 client id registered with the issuer.
+	ClientID string
+
+	// RedirectURI is the loopback URI the issuer redirects back to, e.g.
+	// http://127.0.0.1:8484/callback. Authorize listens on its host:port.
+	RedirectURI string
+
+	// Scopes are the OAuth2 scopes requested, e.g. {"openid", "profile"}.
+	Scopes []string
+
+	// StoreKey identifies this provider's token within TokenStore.
+	StoreKey string
+
+	// TokenStore persists the refresh token. A nil TokenStore uses a
+	// throwaway in-memory store.
+	TokenStore TokenStore
+
+	// Opener, if set, is used to open the authorization URL in a browser.
+	// The URL is always logged as well, since many deployments run this
+	// headlessly and expect the operator to open it themselves.
+	Opener URLOpener
+
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+}
+
+// NewOIDCAuthProvider creates an OIDCAuthProvider. A nil tokenStore uses
+// an in-memory MemoryTokenStore.
+func NewOIDCAuthProvider(issuerURL, clientID, redirectURI string, scopes []string, tokenStore TokenStore) *OIDCAuthProvider {
+	if tokenStore == nil {
+		tokenStore = NewMemoryTokenStore()
+	}
+	return &OIDCAuthProvider{
+		IssuerURL:   issuerURL,
+		ClientID:    clientID,
+		RedirectURI: redirectURI,
+		Scopes:      scopes,
+		StoreKey:    clientID,
+		TokenStore:  tokenStore,
+		httpClient:  &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+// Authorize runs the interactive Authorization Code + PKCE flow: it
+// discovers the issuer's endpoints, generates a PKCE code_verifier/
+// code_challenge pair and a state nonce, opens a loopback HTTP server on
+// RedirectURI to capture the redirect, and exchanges the returned code
+// for an access and refresh token. It blocks until the redirect arrives
+// or ctx is cancelled.
+func (p *OIDCAuthProvider) Authorize(ctx context.Context) error {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := randomURLSafeString(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate PKCE verifier: %w", err)
+	}
+	challenge := codeChallengeS256(verifier)
+
+	state, err := randomURLSafeString(16)
+	if err != nil {
+		return fmt.Errorf("failed to generate state nonce: %w", err)
+	}
+
+	// The redirect listener must be live before the authorization request
+	// is sent, since the issuer may redirect back before this goroutine
+	// reaches the code below.
+	awaitCode, err := p.listenForRedirect(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	authURL, err := p.authorizationURL(discovery.AuthorizationEndpoint, state, challenge)
+	if err != nil {
+		return err
+	}
+	slog.Info("open this URL to authorize formation-mcp", "url", authURL)
+	if p.Opener != nil {
+		if err := p.Opener.Open(authURL); err != nil {
+			slog.Warn("failed to open authorization URL automatically", "error", err)
+		}
+	}
+
+	code, err := awaitCode()
+	if err != nil {
+		return err
+	}
+
+	token, err := p.exchangeCode(ctx, discovery.TokenEndpoint, code, verifier)
+	if err != nil {
+		return err
+	}
+
+	return p.storeToken(token)
+}
+
+// LoginWithClientCredentials authenticates via the OAuth2 Client
+// Credentials grant, for headless/service callers that authenticate as
+// themselves rather than on behalf of a user - no browser or paired
+// device is ever involved. clientSecret is p.ClientID's confidential
+// client secret.
+//
+// The token endpoint doesn't issue a refresh token for this grant, so
+// once the cached access token expires, Token can't silently renew it;
+// callers using this grant should call LoginWithClientCredentials again
+// instead of relying on Token to refresh.
+func (p *OIDCAuthProvider) LoginWithClientCredentials(ctx context.Context, clientSecret string) error {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {p.ClientID},
+		"client_secret": {clientSecret},
+	}
+	if len(p.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.Scopes, " "))
+	}
+
+	token, err := p.requestToken(ctx, discovery.TokenEndpoint, form)
+	if err != nil {
+		return err
+	}
+	return p.storeToken(token)
+}
+
+// errAuthorizationPending and errSlowDown are the two token-endpoint error
+// codes RFC 8628 defines as "keep polling" rather than "the device flow
+// failed"; pollDeviceToken translates them to these sentinels so
+// LoginWithDeviceCode's loop can tell them apart from a real failure.
+var (
+	errAuthorizationPending = errors.New("authorization_pending")
+	errSlowDown             = errors.New("slow_down")
+)
+
+// deviceAuthorizationResponse is a device-authorization-endpoint response,
+// per RFC 8628 section 3.2.
+type deviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// LoginWithDeviceCode runs the OAuth2 Device Authorization Grant (RFC
+// 8628): it requests a device code from the issuer, logs (and, via
+// Opener, opens) the verification URL and user code, then polls the
+// token endpoint until the user completes authorization, the device code
+// expires, or ctx is cancelled. Unlike Authorize, this needs no loopback
+// listener, which is the point - it's for environments where
+// formation-mcp has no browser of its own but a paired chat UI can
+// display a code for the user to enter elsewhere.
+func (p *OIDCAuthProvider) LoginWithDeviceCode(ctx context.Context) error {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return err
+	}
+	if discovery.DeviceAuthorizationEndpoint == "" {
+		return fmt.Errorf("issuer %q does not advertise a device_authorization_endpoint", p.IssuerURL)
+	}
+
+	form := url.Values{"client_id": {p.ClientID}}
+	if len(p.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.DeviceAuthorizationEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create device authorization request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("device authorization request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("device authorization failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var auth deviceAuthorizationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return fmt.Errorf("failed to decode device authorization response: %w", err)
+	}
+
+	verificationURL := auth.VerificationURIComplete
+	if verificationURL == "" {
+		verificationURL = auth.VerificationURI
+	}
+	slog.Info("enter this code to authorize formation-mcp", "code", auth.UserCode, "url", verificationURL)
+	if p.Opener != nil {
+		if err := p.Opener.Open(verificationURL); err != nil {
+			slog.Warn("failed to open verification URL automatically", "error", err)
+		}
+	}
+
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("device code expired before authorization completed")
+		}
+
+		token, err := p.pollDeviceToken(ctx, discovery.TokenEndpoint, auth.DeviceCode)
+		switch {
+		case errors.Is(err, errAuthorizationPending):
+			continue
+		case errors.Is(err, errSlowDown):
+			interval += 5 * time.Second
+			continue
+		case err != nil:
+			return err
+		}
+
+		return p.storeToken(token)
+	}
+}
+
+// pollDeviceToken makes one device_code grant attempt, translating the
+// authorization_pending and slow_down error codes RFC 8628 defines into
+// errAuthorizationPending/errSlowDown so the caller's poll loop can
+// distinguish "keep waiting" from a real failure.
+func (p *OIDCAuthProvider) pollDeviceToken(ctx context.Context, tokenEndpoint, deviceCode string) (OAuthToken, error) {
+	form := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {deviceCode},
+		"client_id":   {p.ClientID},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return OAuthToken{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return OAuthToken{}, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(bodyBytes, &errResp)
+		switch errResp.Error {
+		case "authorization_pending":
+			return OAuthToken{}, errAuthorizationPending
+		case "slow_down":
+			return OAuthToken{}, errSlowDown
+		default:
+			return OAuthToken{}, fmt.Errorf("device token request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		}
+	}
+
+	var tokenResp oidcTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return OAuthToken{}, fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	return OAuthToken{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: tokenResp.RefreshToken,
+		Expiry:       time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second).Add(-tokenExpiryMargin),
+	}, nil
+}
+
+// Token implements AuthProvider. It returns the cached access token if
+// still valid, otherwise refreshes it using the stored refresh token.
+// Authorize must have completed successfully at least once before Token
+// can succeed.
+func (p *OIDCAuthProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.tokenExpiry) {
+		return p.accessToken, nil
+	}
+
+	stored, ok, err := p.TokenStore.Get(p.StoreKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to load stored token: %w", err)
+	}
+	if !ok || stored.RefreshToken == "" {
+		return "", fmt.Errorf("no authorization on file for %q; call Authorize first", p.StoreKey)
+	}
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := p.refresh(ctx, discovery.TokenEndpoint, stored.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = stored.RefreshToken
+	}
+
+	if err := p.storeTokenLocked(token); err != nil {
+		return "", err
+	}
+	return p.accessToken, nil
+}
+
+func (p *OIDCAuthProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.IssuerURL, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("oidc discovery failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, fmt.Errorf("failed to decode oidc discovery document: %w", err)
+	}
+	return &discovery, nil
+}
+
+func (p *OIDCAuthProvider) authorizationURL(endpoint, state, challenge string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint %q: %w", endpoint, err)
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.ClientID)
+	q.Set("redirect_uri", p.RedirectURI)
+	q.Set("scope", strings.Join(p.Scopes, " "))
+	q.Set("state", state)
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// listenForRedirect starts a loopback HTTP server on RedirectURI's
+// host:port and path, and returns a function that blocks until the
+// redirect arrives, ctx is cancelled, or the server fails to start. It's
+// split from the rest of Authorize so the listener is live before the
+// authorization request is ever sent.
+func (p *OIDCAuthProvider) listenForRedirect(ctx context.Context, state string) (func() (string, error), error) {
+	redirect, err := url.Parse(p.RedirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect URI %q: %w", p.RedirectURI, err)
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	resultCh := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if errParam := query.Get("error"); errParam != "" {
+			resultCh <- result{err: fmt.Errorf("authorization denied: %s", errParam)}
+		} else if query.Get("state") != state {
+			resultCh <- result{err: fmt.Errorf("authorization response had mismatched state")}
+		} else {
+			resultCh <- result{code: query.Get("code")}
+		}
+
+		fmt.Fprint(w, "Authorization complete; you may close this window.")
+	})
+
+	// Bind the listener here, synchronously, rather than inside
+	// ListenAndServe on the goroutine below: the caller opens the
+	// authorization URL right after this returns, and the port must
+	// already be accepting connections before that happens.
+	ln, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on redirect URI %q: %w", p.RedirectURI, err)
+	}
+
+	srv := &http.Server{Handler: mux}
+	listenErrCh := make(chan error, 1)
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			listenErrCh <- err
+		}
+	}()
+
+	return func() (string, error) {
+		defer srv.Shutdown(context.Background()) //nolint:errcheck
+
+		select {
+		case err := <-listenErrCh:
+			return "", fmt.Errorf("redirect listener failed: %w", err)
+		case res := <-resultCh:
+			return res.code, res.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}, nil
+}
+
+func (p *OIDCAuthProvider) exchangeCode(ctx context.Context, tokenEndpoint, code, verifier string) (OAuthToken, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURI},
+		"client_id":     {p.ClientID},
+		"code_verifier": {verifier},
+	}
+	return p.requestToken(ctx, tokenEndpoint, form)
+}
+
+func (p *OIDCAuthProvider) refresh(ctx context.Context, tokenEndpoint, refreshToken string) (OAuthToken, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {p.ClientID},
+	}
+	return p.requestToken(ctx, tokenEndpoint, form)
+}
+
+func (p *OIDCAuthProvider) requestToken(ctx context.Context, tokenEndpoint string, form url.Values) (OAuthToken, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return OAuthToken{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return OAuthToken{}, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return OAuthToken{}, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var tokenResp oidcTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return OAuthToken{}, fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	expiresAt := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	return OAuthToken{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: tokenResp.RefreshToken,
+		Expiry:       expiresAt.Add(-tokenExpiryMargin),
+	}, nil
+}
+
+func (p *OIDCAuthProvider) storeToken(token OAuthToken) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.storeTokenLocked(token)
+}
+
+// storeTokenLocked updates the in-memory token and persists it to
+// TokenStore. Callers must hold p.mu.
+func (p *OIDCAuthProvider) storeTokenLocked(token OAuthToken) error {
+	p.accessToken = token.AccessToken
+	p.tokenExpiry = token.Expiry
+
+	if err := p.TokenStore.Save(p.StoreKey, token); err != nil {
+		return fmt.Errorf("failed to persist oauth token: %w", err)
+	}
+	return nil
+}
+
+// randomURLSafeString returns a base64url (no padding) encoding of n
+// random bytes, suitable for a PKCE code_verifier or a state nonce.
+func randomURLSafeString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallengeS256 computes the PKCE S256 code_challenge for verifier.
+func codeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
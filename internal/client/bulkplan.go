@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// BulkOpType identifies which mutating operation a BulkOp performs.
+type BulkOpType string
+
+const (
+	BulkOpCreateDirectory BulkOpType = "create_directory"
+	BulkOpSetMetadata     BulkOpType = "set_metadata"
+	BulkOpDelete          BulkOpType = "delete"
+)
+
+// BulkOp is one step of a BulkDataPlan. Path may be a glob pattern (matched
+// with path.Match against the entries of its parent directory) for
+// SetMetadata and Delete ops, so a single op can target many paths at once;
+// CreateDirectory paths are always taken literally, since there's nothing
+// to match against before the directory exists.
+type BulkOp struct {
+	Type     BulkOpType             `json:"type"`
+	Path     string                 `json:"path"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Replace  bool                   `json:"replace,omitempty"`
+	Recurse  bool                   `json:"recurse,omitempty"`
+}
+
+// BulkDataPlan is an ordered list of data operations to apply, or preview,
+// as a unit.
+type BulkDataPlan struct {
+	Ops []BulkOp `json:"ops"`
+
+	// StopOnError halts the plan at the first op that fails and best-effort
+	// rolls back ops already applied earlier in this plan (deletes can't be
+	// rolled back). Without it, every op is attempted regardless of earlier
+	// failures.
+	StopOnError bool `json:"stop_on_error"`
+
+	// DryRun resolves every op against the current tree - expanding globs,
+	// computing which paths a recursive delete would remove - without
+	// applying any of them.
+	DryRun bool `json:"dry_run"`
+}
+
+// BulkOpResult reports one op's outcome, or in dry-run mode, the paths it
+// resolved to and would have applied to.
+type BulkOpResult struct {
+	Op            BulkOp   `json:"op"`
+	OK            bool     `json:"ok"`
+	Error         string   `json:"error,omitempty"`
+	RolledBack    bool     `json:"rolled_back,omitempty"`
+	ResolvedPaths []string `json:"resolved_paths,omitempty"`
+}
+
+// BulkPlanResult is the outcome of ApplyBulkPlan: one BulkOpResult per op,
+// in plan order.
+type BulkPlanResult struct {
+	Results []BulkOpResult `json:"results"`
+	DryRun  bool           `json:"dry_run"`
+}
+
+// appliedBulkOp records one already-applied path, and enough state to
+// best-effort undo it, for ApplyBulkPlan's StopOnError rollback.
+// resultIndex ties it back to the BulkOpResult it contributed to, since one
+// op can resolve to several paths (e.g. a glob).
+type appliedBulkOp struct {
+	resultIndex  int
+	kind         BulkOpType
+	path         string
+	priorMeta    map[string]interface{}
+	priorExisted bool
+}
+
+// ApplyBulkPlan resolves and applies plan's ops, in order, against c.
+// ApplyBulkPlan is a standalone function rather than a FormationAPIClient
+// method - like ValidateLaunchConfig, it only needs the interface's
+// existing data methods, so both FormationClient and the mock client get it
+// for free instead of reimplementing it twice.
+func ApplyBulkPlan(ctx context.Context, c FormationAPIClient, plan BulkDataPlan) (*BulkPlanResult, error) {
+	result := &BulkPlanResult{DryRun: plan.DryRun}
+	var applied []appliedBulkOp
+
+	rollback := func() {
+		for i := len(applied) - 1; i >= 0; i-- {
+			a := applied[i]
+			switch a.kind {
+			case BulkOpCreateDirectory:
+				if err := c.DeleteData(ctx, a.path, true, false); err != nil {
+					slog.Warn("bulk plan rollback: failed to remove created directory", "path", a.path, "error", err)
+					continue
+				}
+				result.Results[a.resultIndex].RolledBack = true
+			case BulkOpSetMetadata:
+				if a.priorExisted {
+					if err := c.SetMetadata(ctx, a.path, a.priorMeta, true); err != nil {
+						slog.Warn("bulk plan rollback: failed to restore metadata", "path", a.path, "error", err)
+						continue
+					}
+				}
+				result.Results[a.resultIndex].RolledBack = true
+			case BulkOpDelete:
+				// Deletes can't be rolled back.
+			}
+		}
+	}
+
+	for _, op := range plan.Ops {
+		opResult := BulkOpResult{Op: op}
+
+		paths, err := resolveBulkOpPaths(ctx, c, op)
+		if err != nil {
+			opResult.Error = err.Error()
+			result.Results = append(result.Results, opResult)
+			if plan.StopOnError {
+				rollback()
+				return result, nil
+			}
+			continue
+		}
+		opResult.ResolvedPaths = paths
+
+		if plan.DryRun {
+			opResult.OK = true
+			result.Results = append(result.Results, opResult)
+			continue
+		}
+
+		resultIndex := len(result.Results)
+		var failErr error
+		var newlyApplied []appliedBulkOp
+		for _, p := range paths {
+			switch op.Type {
+			case BulkOpCreateDirectory:
+				if _, err := c.CreateDirectory(ctx, p, op.Metadata); err != nil {
+					failErr = err
+				} else {
+					newlyApplied = append(newlyApplied, appliedBulkOp{resultIndex: resultIndex, kind: op.Type, path: p})
+				}
+			case BulkOpSetMetadata:
+				priorMeta, priorErr := c.SnapshotMetadata(ctx, p)
+				if err := c.SetMetadata(ctx, p, op.Metadata, op.Replace); err != nil {
+					failErr = err
+				} else {
+					newlyApplied = append(newlyApplied, appliedBulkOp{resultIndex: resultIndex, kind: op.Type, path: p, priorMeta: priorMeta, priorExisted: priorErr == nil})
+				}
+			case BulkOpDelete:
+				if err := c.DeleteData(ctx, p, op.Recurse, false); err != nil {
+					failErr = err
+				} else {
+					newlyApplied = append(newlyApplied, appliedBulkOp{resultIndex: resultIndex, kind: op.Type, path: p})
+				}
+			default:
+				failErr = fmt.Errorf("unknown bulk op type %q", op.Type)
+			}
+			if failErr != nil {
+				break
+			}
+		}
+
+		applied = append(applied, newlyApplied...)
+		opResult.OK = failErr == nil
+		if failErr != nil {
+			opResult.Error = failErr.Error()
+		}
+		result.Results = append(result.Results, opResult)
+
+		if failErr != nil && plan.StopOnError {
+			rollback()
+			return result, nil
+		}
+	}
+
+	return result, nil
+}
+
+// resolveBulkOpPaths expands op.Path against the current tree when it
+// contains glob metacharacters, by listing its parent directory and
+// matching entries with path.Match - one level only, since the Data Store
+// browse API lists one directory at a time. A literal path is returned
+// as-is without checking existence; CreateDirectory, SetMetadata, and
+// DeleteData each report a missing or conflicting path on their own.
+func resolveBulkOpPaths(ctx context.Context, c FormationAPIClient, op BulkOp) ([]string, error) {
+	if op.Type == BulkOpCreateDirectory || !strings.ContainsAny(op.Path, "*?[") {
+		return []string{op.Path}, nil
+	}
+
+	dir := path.Dir(op.Path)
+	pattern := path.Base(op.Path)
+
+	listing, err := c.BrowseData(ctx, dir, 0, 0, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve glob %q: %w", op.Path, err)
+	}
+	contents, ok := listing.(*DirectoryContents)
+	if !ok {
+		return nil, fmt.Errorf("failed to resolve glob %q: %s is not a directory", op.Path, dir)
+	}
+
+	var matches []string
+	for _, entry := range contents.Contents {
+		matched, err := path.Match(pattern, entry.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", op.Path, err)
+		}
+		if matched {
+			matches = append(matches, path.Join(dir, entry.Name))
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("glob %q matched no paths under %s", op.Path, dir)
+	}
+	return matches, nil
+}
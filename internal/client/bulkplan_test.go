@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApplyBulkPlanExpandsGlobAndApplies(t *testing.T) {
+	var metadataCalls []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/data/cyverse/home/test/runs":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(DirectoryContents{
+				Path: "/cyverse/home/test/runs",
+				Type: "collection",
+				Contents: []DirectoryEntry{
+					{Name: "run1.log", Type: "data_object"},
+					{Name: "run2.log", Type: "data_object"},
+					{Name: "notes.txt", Type: "data_object"},
+				},
+			})
+		case r.Method == "PUT":
+			metadataCalls = append(metadataCalls, r.URL.Path)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+
+	plan := BulkDataPlan{
+		Ops: []BulkOp{
+			{Type: BulkOpSetMetadata, Path: "/cyverse/home/test/runs/*.log", Metadata: map[string]interface{}{"status": "archived"}},
+		},
+	}
+
+	result, err := ApplyBulkPlan(context.Background(), c, plan)
+	if err != nil {
+		t.Fatalf("ApplyBulkPlan() unexpected error = %v", err)
+	}
+	if len(result.Results) != 1 || !result.Results[0].OK {
+		t.Fatalf("ApplyBulkPlan() = %+v, want the op to succeed", result.Results)
+	}
+	if len(result.Results[0].ResolvedPaths) != 2 {
+		t.Errorf("ApplyBulkPlan() resolved paths = %v, want run1.log and run2.log only", result.Results[0].ResolvedPaths)
+	}
+	if len(metadataCalls) != 2 {
+		t.Errorf("ApplyBulkPlan() applied SetMetadata %d times, want 2", len(metadataCalls))
+	}
+}
+
+func TestApplyBulkPlanDryRunDoesNotMutate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(DirectoryContents{
+				Path:     "/cyverse/home/test",
+				Type:     "collection",
+				Contents: []DirectoryEntry{{Name: "a.txt", Type: "data_object"}},
+			})
+		default:
+			t.Errorf("unexpected mutating request during dry run: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	c := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+
+	plan := BulkDataPlan{
+		DryRun: true,
+		Ops: []BulkOp{
+			{Type: BulkOpDelete, Path: "/cyverse/home/test/*.txt", Recurse: true},
+		},
+	}
+
+	result, err := ApplyBulkPlan(context.Background(), c, plan)
+	if err != nil {
+		t.Fatalf("ApplyBulkPlan() unexpected error = %v", err)
+	}
+	if !result.DryRun {
+		t.Error("ApplyBulkPlan() result.DryRun = false, want true")
+	}
+	if len(result.Results) != 1 || result.Results[0].ResolvedPaths[0] != "/cyverse/home/test/a.txt" {
+		t.Errorf("ApplyBulkPlan() = %+v, want a.txt resolved without deleting it", result.Results)
+	}
+}
+
+func TestApplyBulkPlanStopOnErrorRollsBackCreatedDirectory(t *testing.T) {
+	var created, deleted []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT" && r.URL.Query().Get("resource_type") == "directory":
+			created = append(created, r.URL.Path)
+			json.NewEncoder(w).Encode(CreateDirectoryResponse{Path: r.URL.Path, Type: "collection"})
+		case r.Method == "DELETE" && r.URL.Path == "/data/cyverse/home/test/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path)
+		case r.Method == "GET":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+
+	plan := BulkDataPlan{
+		StopOnError: true,
+		Ops: []BulkOp{
+			{Type: BulkOpCreateDirectory, Path: "/cyverse/home/test/newdir"},
+			{Type: BulkOpDelete, Path: "/cyverse/home/test/missing"},
+		},
+	}
+
+	result, err := ApplyBulkPlan(context.Background(), c, plan)
+	if err != nil {
+		t.Fatalf("ApplyBulkPlan() unexpected error = %v", err)
+	}
+	if len(created) != 1 {
+		t.Fatalf("ApplyBulkPlan() created = %v, want exactly one directory created", created)
+	}
+	if !result.Results[0].RolledBack {
+		t.Errorf("ApplyBulkPlan() first op = %+v, want RolledBack after the second op failed", result.Results[0])
+	}
+	if len(deleted) != 1 {
+		t.Errorf("ApplyBulkPlan() deleted = %v, want the created directory removed during rollback", deleted)
+	}
+}
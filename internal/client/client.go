@@ -4,14 +4,19 @@ package client
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/logging"
 )
 
 const (
@@ -40,12 +45,29 @@ type FormationAPIClient interface {
 	// LaunchApp launches an application with the given configuration.
 	LaunchApp(ctx context.Context, systemID, appID string, submission LaunchSubmission) (*LaunchResponse, error)
 
+	// EstimateCost estimates the resource envelope and cost of launching
+	// an app with config, without submitting it.
+	EstimateCost(ctx context.Context, systemID, appID string, config LaunchConfig) (*CostEstimate, error)
+
 	// GetAnalysisStatus retrieves the status of an analysis.
 	GetAnalysisStatus(ctx context.Context, analysisID string) (*AnalysisStatus, error)
 
 	// ListAnalyses lists analyses filtered by status.
 	ListAnalyses(ctx context.Context, status string) ([]Analysis, error)
 
+	// WatchAnalysis streams status updates for a single analysis until it
+	// reaches a terminal status or ctx is cancelled.
+	WatchAnalysis(ctx context.Context, analysisID string) (<-chan AnalysisEvent, error)
+
+	// WatchAnalyses streams status updates for every analysis matching
+	// filter, for driving a live dashboard view.
+	WatchAnalyses(ctx context.Context, filter string) (<-chan AnalysisEvent, error)
+
+	// StreamAnalysisLogs streams log lines for analysisID per opts, so a
+	// caller can tail recent stderr/stdout or follow new output without
+	// polling GetAnalysisStatus in a busy loop.
+	StreamAnalysisLogs(ctx context.Context, analysisID string, opts LogStreamOptions) (<-chan LogLine, error)
+
 	// ControlAnalysis controls an analysis (e.g., stop, pause).
 	ControlAnalysis(ctx context.Context, analysisID, operation string, saveOutputs bool) error
 
@@ -58,42 +80,241 @@ type FormationAPIClient interface {
 	// UploadFile uploads a file to iRODS.
 	UploadFile(ctx context.Context, path, content string, metadata map[string]interface{}) error
 
+	// UploadFileStream uploads size bytes read from r to path in fixed-size
+	// chunks, resuming from opts.StateStore if a matching prior attempt was
+	// interrupted, for files too large to buffer in memory via UploadFile.
+	UploadFileStream(ctx context.Context, path string, r io.Reader, size int64, opts UploadOptions) error
+
+	// DownloadFileStream reads the file at path from iRODS and writes its
+	// content to w without buffering the whole file in memory, for Data
+	// Store objects too large to read via BrowseData. onProgress, if
+	// non-nil, is invoked at a throttled cadence with bytes written so far
+	// and the total size (0 if the server didn't report a Content-Length).
+	DownloadFileStream(ctx context.Context, path string, w io.Writer, onProgress func(n, total int64)) error
+
+	// UploadFileChunk accepts one chunk of a chunked upload to path, for
+	// MCP tool callers that can only send one bounded chunk per call
+	// rather than a whole io.Reader (see UploadFileStream for that case).
+	// Passing uploadID = "" starts a new session at offset 0; passing
+	// back a prior call's UploadID continues it, so long as offset
+	// matches the session's current offset. isFinal commits the upload
+	// once the chunk is accepted.
+	UploadFileChunk(ctx context.Context, uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*UploadChunkResult, error)
+
+	// DownloadFileRange reads length bytes of the file at path starting at
+	// offset, via an HTTP Range request, for a caller that wants one bounded
+	// slice of a large object rather than the whole thing (see
+	// DownloadFileStream for that case). The caller must Close the returned
+	// io.ReadCloser. length <= 0 reads through the end of the file.
+	DownloadFileRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, *FileMetadata, error)
+
 	// SetMetadata sets metadata on a path in iRODS.
 	SetMetadata(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error
 
+	// SnapshotMetadata retrieves the metadata currently set on path, so a
+	// caller that's about to overwrite it (e.g. a bulk operation) can
+	// restore the prior values if a later step fails.
+	SnapshotMetadata(ctx context.Context, path string) (map[string]interface{}, error)
+
+	// SearchMetadata finds paths whose AVUs satisfy every predicate in
+	// query.Predicates, optionally narrowed by PathPrefix and Type, so a
+	// caller can discover paths by what they're tagged with instead of
+	// walking directories with BrowseData.
+	SearchMetadata(ctx context.Context, query MetadataQuery) (*MetadataSearchResult, error)
+
 	// DeleteData deletes a file or directory from iRODS.
 	DeleteData(ctx context.Context, path string, recurse, dryRun bool) error
+
+	// CreateAnalysisAlert registers a webhook/email subscription on an
+	// analysis's lifecycle events and starts watching for them.
+	CreateAnalysisAlert(ctx context.Context, alert AnalysisAlert) (*AnalysisAlert, error)
+
+	// ListAnalysisAlerts returns every registered alert, optionally
+	// filtered to one analysisID ("" for all of them).
+	ListAnalysisAlerts(ctx context.Context, analysisID string) ([]AnalysisAlert, error)
+
+	// DeleteAnalysisAlert cancels a registered alert's watch loop and
+	// removes it.
+	DeleteAnalysisAlert(ctx context.Context, alertID string) error
+
+	// Shutdown stops accepting new requests - calls made after it's called
+	// return ErrClientClosed - and waits for requests already in flight to
+	// finish, or for ctx to expire, before closing idle connections.
+	Shutdown(ctx context.Context) error
+
+	// LastActivity returns the time of the most recent request start or
+	// completion, for an outer idle-timeout loop to compare against.
+	LastActivity() time.Time
 }
 
 // FormationClient is the HTTP client for the Formation API.
 type FormationClient struct {
-	baseURL    string
-	httpClient *http.Client
-	token      string
+	baseURL     string
+	httpClient  *http.Client
+	token       string
 	tokenExpiry time.Time
-	username   string
-	password   string
+	// tokenMu guards token/tokenExpiry and serializes ensureToken, so
+	// several concurrent requests that all notice an expired token (e.g.
+	// a BulkExecutor worker pool) trigger one Login instead of a
+	// stampede of simultaneous ones.
+	tokenMu      sync.Mutex
+	username     string
+	password     string
+	retryPolicy  *RetryPolicy
+	limiter      *rateLimiter
+	authProvider AuthProvider
+	observer     Observer
+	uploadChunks *uploadChunkSessionStore
+	idle         *idleTracker
+
+	// logger is this client's default logger, used whenever a call's
+	// context doesn't carry its own (see WithLogger) - so a host embedding
+	// this module can route its logs into its own structured pipeline via
+	// SetLogger instead of slog's process-global default. It's tagged with
+	// logging.SubsystemKey="client" so a SubsystemLevelHandler (see
+	// logging.NewSubsystemLevelHandler) can tune this package's verbosity
+	// independently of the rest of the process.
+	logger logging.Logger
+
+	// alerts backs CreateAnalysisAlert/ListAnalysisAlerts/
+	// DeleteAnalysisAlert.
+	alerts *AlertManager
+}
+
+// ErrClientClosed is returned by any request made after Shutdown has been
+// called.
+var ErrClientClosed = errors.New("formation client is shutting down")
+
+// NewFormationClient creates a new Formation API client. A nil retryPolicy
+// or rateLimit falls back to DefaultRetryPolicy/DefaultRateLimitPolicy.
+func NewFormationClient(baseURL, token, username, password string, retryPolicy *RetryPolicy, rateLimit *RateLimitPolicy) *FormationClient {
+	if retryPolicy == nil {
+		retryPolicy = DefaultRetryPolicy()
+	}
+	if rateLimit == nil {
+		rateLimit = DefaultRateLimitPolicy()
+	}
+
+	c := &FormationClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout:   defaultHTTPTimeout,
+			Transport: &trackingRoundTripper{},
+		},
+		token:        token,
+		username:     username,
+		password:     password,
+		retryPolicy:  retryPolicy,
+		limiter:      newRateLimiter(rateLimit),
+		observer:     noopObserver{},
+		uploadChunks: newUploadChunkSessionStore(),
+		idle:         newIdleTracker(),
+		logger:       defaultClientLogger(),
+	}
+	c.alerts = newAlertManager(c)
+	return c
 }
 
-// NewFormationClient creates a new Formation API client.
-func NewFormationClient(baseURL, token, username, password string) *FormationClient {
-	return &FormationClient{
+// NewFormationClientWithAuth creates a FormationClient whose bearer token
+// comes from auth rather than the baseURL's own username/password login
+// endpoint, so BasicAuthProvider and OIDCAuthProvider can be used
+// interchangeably. A nil retryPolicy or rateLimit falls back to
+// DefaultRetryPolicy/DefaultRateLimitPolicy.
+func NewFormationClientWithAuth(baseURL string, auth AuthProvider, retryPolicy *RetryPolicy, rateLimit *RateLimitPolicy) *FormationClient {
+	if retryPolicy == nil {
+		retryPolicy = DefaultRetryPolicy()
+	}
+	if rateLimit == nil {
+		rateLimit = DefaultRateLimitPolicy()
+	}
+
+	c := &FormationClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: defaultHTTPTimeout,
+			Timeout:   defaultHTTPTimeout,
+			Transport: &trackingRoundTripper{},
 		},
-		token:    token,
-		username: username,
-		password: password,
+		authProvider: auth,
+		retryPolicy:  retryPolicy,
+		limiter:      newRateLimiter(rateLimit),
+		observer:     noopObserver{},
+		uploadChunks: newUploadChunkSessionStore(),
+		idle:         newIdleTracker(),
+		logger:       defaultClientLogger(),
 	}
+	c.alerts = newAlertManager(c)
+	return c
+}
+
+// defaultClientLogger returns a logging.Logger wrapping slog.Default(),
+// tagged with logging.SubsystemKey="client" so a SubsystemLevelHandler can
+// tune this package's verbosity independently - see the logger field.
+func defaultClientLogger() logging.Logger {
+	return logging.NewSlogLogger(slog.Default()).With(logging.SubsystemKey, "client")
 }
 
 // Compile-time check to ensure FormationClient implements FormationAPIClient.
 var _ FormationAPIClient = (*FormationClient)(nil)
 
+// SetObserver installs o to receive lifecycle hooks for every request this
+// client makes (see Observer), replacing any previously set Observer. A
+// nil o disables observation, same as never calling SetObserver.
+func (c *FormationClient) SetObserver(o Observer) {
+	if o == nil {
+		o = noopObserver{}
+	}
+	c.observer = o
+}
+
+// SetLogger installs logger as this client's default logger, used for any
+// call whose context doesn't carry its own via WithLogger, replacing any
+// previously set one. A nil logger restores the slog.Default()-backed
+// logger NewFormationClient starts with. Hosts embedding this module can
+// use this to route its logs into their own logging.Logger implementation
+// instead of slog's process-global default.
+func (c *FormationClient) SetLogger(logger logging.Logger) {
+	if logger == nil {
+		logger = defaultClientLogger()
+	}
+	c.logger = logger
+}
+
+// loggerFor returns the logger doRequest/doRequestOnce/Login should log
+// through for ctx: the context's own logger (see WithLogger) if one was
+// stashed there, so a per-call trace (e.g. request_id + MCP tool name)
+// stays attached, otherwise this client's configured default (see
+// SetLogger).
+func (c *FormationClient) loggerFor(ctx context.Context) logging.Logger {
+	if logger, ok := loggerFromContext(ctx); ok {
+		return logger
+	}
+	if c.logger != nil {
+		return c.logger
+	}
+	return defaultClientLogger()
+}
+
 // ensureToken ensures that the client has a valid token.
 // If the token is expired or missing and credentials are provided, it will login.
+// Concurrent calls serialize on tokenMu, so several requests that all
+// notice an expired token at once (as a BulkExecutor worker pool's
+// workers would) cause one Login rather than a stampede of them - the
+// second and later callers re-check the now-refreshed token after
+// acquiring the lock instead of logging in again.
 func (c *FormationClient) ensureToken(ctx context.Context) error {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	// An AuthProvider, when set, owns the whole login/refresh decision.
+	if c.authProvider != nil {
+		token, err := c.authProvider.Token(ctx)
+		if err != nil {
+			return err
+		}
+		c.token = token
+		return nil
+	}
+
 	// If we have a valid token, use it
 	if c.token != "" && time.Now().Before(c.tokenExpiry) {
 		return nil
@@ -109,12 +330,17 @@ func (c *FormationClient) ensureToken(ctx context.Context) error {
 	}
 
 	// Login to get a new token
-	slog.Debug("token expired or missing, logging in", "username", c.username)
+	logger := c.loggerFor(ctx)
+	logger.Debug("token expired or missing, logging in", "username", c.username)
 	return c.Login(ctx)
 }
 
 // Login authenticates with the Formation API and stores the token.
 func (c *FormationClient) Login(ctx context.Context) error {
+	ctx = ensureRequestID(ctx)
+	requestID, _ := RequestIDFromContext(ctx)
+	logger := c.loggerFor(ctx)
+
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/login", nil)
 	if err != nil {
 		return fmt.Errorf("failed to create login request: %w", err)
@@ -122,24 +348,34 @@ func (c *FormationClient) Login(ctx context.Context) error {
 
 	// Use HTTP Basic Authentication
 	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("X-Request-ID", requestID)
 
+	c.observer.OnRequestStart(ctx, "Login", req)
 	startTime := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		c.observer.OnRequestEnd(ctx, "Login", nil, err, time.Since(startTime))
 		return fmt.Errorf("login request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if meta := responseMetaFromContext(ctx); meta != nil {
+		meta.RequestID = resp.Header.Get("X-Request-ID")
+	}
+
 	duration := time.Since(startTime)
-	slog.Info("api_call", "method", "POST", "endpoint", "/login", "status", resp.StatusCode, "duration", duration)
+	logger.Info("api_call", "request_id", requestID, "method", "POST", "endpoint", "/login", "status", resp.StatusCode, "duration", duration)
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("login failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		err := fmt.Errorf("login failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		c.observer.OnRequestEnd(ctx, "Login", resp, err, duration)
+		return err
 	}
 
 	var loginResp LoginResponse
 	if err := json.NewDecoder(resp.Body).Decode(&loginResp); err != nil {
+		c.observer.OnRequestEnd(ctx, "Login", resp, err, duration)
 		return fmt.Errorf("failed to decode login response: %w", err)
 	}
 
@@ -148,20 +384,163 @@ func (c *FormationClient) Login(ctx context.Context) error {
 	expiresAt := time.Now().Add(time.Duration(loginResp.ExpiresIn) * time.Second)
 	c.tokenExpiry = expiresAt.Add(-tokenExpiryMargin)
 
-	slog.Info("login successful", "expires_in", loginResp.ExpiresIn, "expires_at", expiresAt, "effective_expiry", c.tokenExpiry)
+	logger.Info("login successful", "request_id", requestID, "expires_in", loginResp.ExpiresIn, "expires_at", expiresAt, "effective_expiry", c.tokenExpiry)
+	c.observer.OnRequestEnd(ctx, "Login", resp, nil, duration)
 	return nil
 }
 
-// doRequest performs an HTTP request with authentication and error handling.
-func (c *FormationClient) doRequest(ctx context.Context, method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+// doRequest performs an HTTP request with authentication, client-side rate
+// limiting, and retries. GET/HEAD requests are retried automatically per
+// c.retryPolicy; POST/PUT/DELETE are only retried if ctx was created with
+// WithRetry(ctx, true), since replaying an already-applied write could
+// double it - except that a POST is also retried, opt-in or not, when the
+// failure is a pre-flight network error (dial failure, or EOF before any
+// part of the body was transmitted): the server never saw the request, so
+// replaying it can't double an effect. A request body is only replayed
+// across attempts when it's an io.ReadSeeker (e.g. bytes.Reader,
+// strings.Reader); otherwise retries are disabled for that call, even if
+// otherwise eligible, except for the pre-flight case above, where the body
+// (if any) was never read in the first place. op names the logical
+// operation (e.g. "ListApps") for c.observer, which sees exactly one
+// OnRequestStart/OnRequestEnd pair per call here, however many attempts it
+// takes. Once Shutdown has been called, doRequest returns ErrClientClosed
+// immediately instead of starting a new request; c.idle.tryStart checking
+// and registering the request as in-flight under a single lock is what
+// guarantees Shutdown can't observe "no requests in flight" while one is
+// still on its way in. A request that did register is tracked as in-flight
+// until its response body is closed (or, on error, until it returns), so
+// Shutdown can wait for it to finish.
+func (c *FormationClient) doRequest(ctx context.Context, op, method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	if !c.idle.tryStart() {
+		return nil, ErrClientClosed
+	}
+
+	ctx = ensureRequestID(ctx)
+
+	tracked := false
+	defer func() {
+		if !tracked {
+			c.idle.done()
+		}
+	}()
+
+	displayReq, reqErr := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if reqErr == nil {
+		c.observer.OnRequestStart(ctx, op, displayReq)
+		// Headers the observer set (e.g. OTelObserver's traceparent) go
+		// out on every attempt; the caller's own headers take precedence.
+		if len(displayReq.Header) > 0 {
+			merged := make(map[string]string, len(displayReq.Header)+len(headers))
+			for k := range displayReq.Header {
+				merged[k] = displayReq.Header.Get(k)
+			}
+			for k, v := range headers {
+				merged[k] = v
+			}
+			headers = merged
+		}
+	}
+
+	start := time.Now()
+	resp, err := c.doRequestAttempts(ctx, op, method, path, body, headers)
+	c.observer.OnRequestEnd(ctx, op, resp, err, time.Since(start))
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Body = &idleTrackingBody{ReadCloser: resp.Body, tracker: c.idle}
+	tracked = true
+	return resp, nil
+}
+
+// Shutdown stops FormationClient from starting new requests - calls made
+// after this returns (or even while it's still waiting) get
+// ErrClientClosed - and waits for requests already in flight to finish, or
+// for ctx to expire, before closing idle connections. It's safe to call
+// more than once.
+func (c *FormationClient) Shutdown(ctx context.Context) error {
+	idle := c.idle.drain()
+	c.alerts.Stop()
+	err := c.idle.wait(ctx, idle)
+	c.httpClient.CloseIdleConnections()
+	return err
+}
+
+// LastActivity returns the time of the most recent request start or
+// completion, for an outer idle-timeout loop to compare against.
+func (c *FormationClient) LastActivity() time.Time {
+	return c.idle.lastActive()
+}
+
+// doRequestAttempts runs doRequest's retry loop; see doRequest's comment
+// for the retry rules. It reports to c.observer.OnRetry before each retry.
+func (c *FormationClient) doRequestAttempts(ctx context.Context, op, method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter: %w", err)
+	}
+
+	canRetry := retryAllowed(ctx, method)
+	seekableBody, _ := body.(io.ReadSeeker)
+	if body != nil && seekableBody == nil {
+		canRetry = false
+	}
+	preflightEligible := method == http.MethodPost
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && seekableBody != nil {
+			if _, err := seekableBody.Seek(0, io.SeekStart); err != nil {
+				return nil, fmt.Errorf("failed to rewind request body for retry: %w", err)
+			}
+		}
+
+		tracker := &preflightTracker{}
+		attemptCtx := context.WithValue(ctx, preflightTrackerKey{}, tracker)
+		resp, retryable, retryAfter, err := c.doRequestOnce(attemptCtx, method, path, body, headers)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		attemptCanRetry := canRetry
+		if !attemptCanRetry && preflightEligible {
+			attemptCanRetry = isPreflightNetworkError(err) && !tracker.started.Load()
+		}
+
+		if !attemptCanRetry || !retryable || attempt >= c.retryPolicy.MaxAttempts-1 {
+			return nil, lastErr
+		}
+
+		delay := retryAfter
+		if delay == 0 {
+			delay = c.retryPolicy.backoff(attempt)
+		}
+
+		c.observer.OnRetry(ctx, op, attempt+1, delay, lastErr)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// doRequestOnce performs a single HTTP attempt. retryable reports whether
+// the failure is one doRequest's retry loop should consider retrying
+// (network errors, or a status code in c.retryPolicy.RetryableStatusCodes);
+// retryAfter carries any Retry-After delay the server requested.
+func (c *FormationClient) doRequestOnce(ctx context.Context, method, path string, body io.Reader, headers map[string]string) (*http.Response, bool, time.Duration, error) {
 	// Ensure we have a valid token
 	if err := c.ensureToken(ctx); err != nil {
-		return nil, err
+		return nil, false, 0, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, false, 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set authorization header
@@ -169,6 +548,9 @@ func (c *FormationClient) doRequest(ctx context.Context, method, path string, bo
 		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
 
+	requestID, _ := RequestIDFromContext(ctx)
+	req.Header.Set("X-Request-ID", requestID)
+
 	// Set additional headers
 	for k, v := range headers {
 		req.Header.Set(k, v)
@@ -177,20 +559,30 @@ func (c *FormationClient) doRequest(ctx context.Context, method, path string, bo
 	startTime := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, true, 0, fmt.Errorf("request failed: %w", err)
+	}
+
+	if meta := responseMetaFromContext(ctx); meta != nil {
+		meta.RequestID = resp.Header.Get("X-Request-ID")
 	}
 
 	duration := time.Since(startTime)
-	slog.Debug("api_call", "method", method, "path", path, "status", resp.StatusCode, "duration", duration)
+	c.loggerFor(ctx).Debug("api_call", "request_id", requestID, "method", method, "path", path, "status", resp.StatusCode, "duration", duration)
 
 	// Check for error status codes
 	if resp.StatusCode >= 400 {
 		defer resp.Body.Close()
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+
+		retryable := c.retryPolicy.retryableStatus(resp.StatusCode)
+		var retryAfter time.Duration
+		if retryable {
+			retryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+		return nil, retryable, retryAfter, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
-	return resp, nil
+	return resp, false, 0, nil
 }
 
 // buildDataPath constructs the full API path for data store operations.
@@ -199,17 +591,22 @@ func (c *FormationClient) buildDataPath(path string) string {
 	return "/data/" + strings.TrimPrefix(path, "/")
 }
 
-// addMetadataHeaders adds metadata as X-Datastore-* headers to the headers map.
+// addMetadataHeaders adds metadata as X-Datastore-* headers to the headers
+// map, hex-encoding each key so its case survives HTTP header-name
+// canonicalization intact (net/http - on both our side and whatever sets
+// the response header - uppercases a header name's first letter and
+// lowercases the rest; hex digits are unaffected by that, and hex.DecodeString
+// accepts either case, so extractMetadataFromHeaders recovers the exact key).
 func (c *FormationClient) addMetadataHeaders(headers map[string]string, metadata map[string]interface{}) {
 	for k, v := range metadata {
-		headers[metadataHeaderPrefix+k] = fmt.Sprint(v)
+		headers[metadataHeaderPrefix+hex.EncodeToString([]byte(k))] = fmt.Sprint(v)
 	}
 }
 
 // doRequestAndDecode performs an HTTP request and decodes the JSON response.
 // This helper reduces boilerplate for API calls that return JSON responses.
-func (c *FormationClient) doRequestAndDecode(ctx context.Context, method, path string, body io.Reader, headers map[string]string, result interface{}) error {
-	resp, err := c.doRequest(ctx, method, path, body, headers)
+func (c *FormationClient) doRequestAndDecode(ctx context.Context, op, method, path string, body io.Reader, headers map[string]string, result interface{}) error {
+	resp, err := c.doRequest(ctx, op, method, path, body, headers)
 	if err != nil {
 		return err
 	}
@@ -241,7 +638,7 @@ func (c *FormationClient) ListApps(ctx context.Context, name, integrator, descri
 
 	path := "/apps?" + query.Encode()
 	var appResp AppListResponse
-	if err := c.doRequestAndDecode(ctx, "GET", path, nil, nil, &appResp); err != nil {
+	if err := c.doRequestAndDecode(ctx, "ListApps", "GET", path, nil, nil, &appResp); err != nil {
 		return nil, err
 	}
 
@@ -252,7 +649,7 @@ func (c *FormationClient) ListApps(ctx context.Context, name, integrator, descri
 func (c *FormationClient) GetAppParameters(ctx context.Context, systemID, appID string) (*AppParameters, error) {
 	path := fmt.Sprintf("/apps/%s/%s/parameters", systemID, appID)
 	var params AppParameters
-	if err := c.doRequestAndDecode(ctx, "GET", path, nil, nil, &params); err != nil {
+	if err := c.doRequestAndDecode(ctx, "GetAppParameters", "GET", path, nil, nil, &params); err != nil {
 		return nil, err
 	}
 
@@ -263,7 +660,26 @@ func (c *FormationClient) GetAppParameters(ctx context.Context, systemID, appID
 // Submits a complete analysis submission to the Formation API.
 // The Formation API will auto-generate name and output_dir if not provided.
 // Email will be resolved from the JWT token if not provided.
+//
+// Before submitting, LaunchApp fetches the app's parameters and runs
+// ValidateLaunchConfig against submission.Config, refusing to submit (and
+// returning a *LaunchConfigValidationError) if it fails. If submission.DryRun
+// is set, LaunchApp returns after validation - with no errors - without ever
+// submitting the analysis.
 func (c *FormationClient) LaunchApp(ctx context.Context, systemID, appID string, submission LaunchSubmission) (*LaunchResponse, error) {
+	appParams, err := c.GetAppParameters(ctx, systemID, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app parameters for validation: %w", err)
+	}
+
+	if validationErrs := ValidateLaunchConfig(*appParams, submission.Config); len(validationErrs) > 0 {
+		return &LaunchResponse{ValidationErrors: validationErrs}, &LaunchConfigValidationError{Errors: validationErrs}
+	}
+
+	if submission.DryRun {
+		return &LaunchResponse{Status: "valid"}, nil
+	}
+
 	body, err := json.Marshal(submission)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal launch request: %w", err)
@@ -275,18 +691,42 @@ func (c *FormationClient) LaunchApp(ctx context.Context, systemID, appID string,
 	}
 
 	var launchResp LaunchResponse
-	if err := c.doRequestAndDecode(ctx, "POST", path, bytes.NewReader(body), headers, &launchResp); err != nil {
+	if err := c.doRequestAndDecode(ctx, "LaunchApp", "POST", path, bytes.NewReader(body), headers, &launchResp); err != nil {
 		return nil, err
 	}
 
 	return &launchResp, nil
 }
 
+// EstimateCost estimates the resource envelope and dollar-or-token cost of
+// launching an app with config, against the tenant's configured price
+// table, without submitting it.
+func (c *FormationClient) EstimateCost(ctx context.Context, systemID, appID string, config LaunchConfig) (*CostEstimate, error) {
+	body, err := json.Marshal(struct {
+		Config LaunchConfig `json:"config"`
+	}{Config: config})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cost estimate request: %w", err)
+	}
+
+	path := fmt.Sprintf("/app/launch/%s/%s/estimate", systemID, appID)
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var estimate CostEstimate
+	if err := c.doRequestAndDecode(ctx, "EstimateCost", "POST", path, bytes.NewReader(body), headers, &estimate); err != nil {
+		return nil, err
+	}
+
+	return &estimate, nil
+}
+
 // GetAnalysisStatus retrieves the status of an analysis.
 func (c *FormationClient) GetAnalysisStatus(ctx context.Context, analysisID string) (*AnalysisStatus, error) {
 	path := fmt.Sprintf("/apps/analyses/%s/status", analysisID)
 	var status AnalysisStatus
-	if err := c.doRequestAndDecode(ctx, "GET", path, nil, nil, &status); err != nil {
+	if err := c.doRequestAndDecode(ctx, "GetAnalysisStatus", "GET", path, nil, nil, &status); err != nil {
 		return nil, err
 	}
 
@@ -306,7 +746,7 @@ func (c *FormationClient) ListAnalyses(ctx context.Context, status string) ([]An
 	}
 
 	var analysisResp AnalysisListResponse
-	if err := c.doRequestAndDecode(ctx, "GET", path, nil, nil, &analysisResp); err != nil {
+	if err := c.doRequestAndDecode(ctx, "ListAnalyses", "GET", path, nil, nil, &analysisResp); err != nil {
 		return nil, err
 	}
 
@@ -321,7 +761,7 @@ func (c *FormationClient) ControlAnalysis(ctx context.Context, analysisID, opera
 
 	path := fmt.Sprintf("/apps/analyses/%s/control?%s", analysisID, query.Encode())
 
-	resp, err := c.doRequest(ctx, "POST", path, nil, nil)
+	resp, err := c.doRequest(ctx, "ControlAnalysis", "POST", path, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -348,7 +788,7 @@ func (c *FormationClient) BrowseData(ctx context.Context, path string, offset, l
 		fullPath += "?" + query.Encode()
 	}
 
-	resp, err := c.doRequest(ctx, "GET", fullPath, nil, nil)
+	resp, err := c.doRequest(ctx, "BrowseData", "GET", fullPath, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -387,6 +827,75 @@ func (c *FormationClient) BrowseData(ctx context.Context, path string, offset, l
 	return fileContent, nil
 }
 
+// DownloadFileStream reads the file at path from iRODS and streams it to w,
+// for files too large to read via BrowseData's single []byte result.
+// onProgress, if non-nil, is invoked at a throttled cadence - see
+// newProgressThrottler - with bytes written so far and the total from the
+// response's Content-Length header.
+func (c *FormationClient) DownloadFileStream(ctx context.Context, path string, w io.Writer, onProgress func(n, total int64)) error {
+	resp, err := c.doRequest(ctx, "DownloadFileStream", "GET", c.buildDataPath(path), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	total := resp.ContentLength
+	if total < 0 {
+		total = 0
+	}
+
+	dst := w
+	throttler := newProgressThrottler(onProgress, total)
+	if onProgress != nil {
+		dst = &progressWriter{w: w, throttler: throttler}
+	}
+
+	written, err := io.Copy(dst, resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", path, err)
+	}
+	throttler.report(written, true)
+
+	return nil
+}
+
+// DownloadFileRange reads length bytes of the file at path starting at
+// offset, via an HTTP Range request, so a caller that only needs a slice of
+// a large object (e.g. an agent paging through a file bigger than its
+// context window) doesn't have to download the whole thing first. length <=
+// 0 requests through the end of the file. The caller must Close the
+// returned io.ReadCloser.
+func (c *FormationClient) DownloadFileRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, *FileMetadata, error) {
+	rangeHeader := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		rangeHeader = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+
+	resp, err := c.doRequest(ctx, "DownloadFileRange", "GET", c.buildDataPath(path), nil, map[string]string{"Range": rangeHeader})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata := &FileMetadata{
+		Path:        path,
+		Offset:      offset,
+		Length:      resp.ContentLength,
+		TotalSize:   resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+
+	if contentRange := resp.Header.Get("Content-Range"); contentRange != "" {
+		var start, end, total int64
+		if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total); err == nil {
+			metadata.Offset = start
+			metadata.Length = end - start + 1
+			metadata.TotalSize = total
+		}
+	}
+
+	return resp.Body, metadata, nil
+}
+
 // CreateDirectory creates a directory in iRODS.
 // Uses resource_type=directory query parameter with no body, per Formation API.
 func (c *FormationClient) CreateDirectory(ctx context.Context, path string, metadata map[string]interface{}) (*CreateDirectoryResponse, error) {
@@ -403,7 +912,7 @@ func (c *FormationClient) CreateDirectory(ctx context.Context, path string, meta
 	c.addMetadataHeaders(headers, metadata)
 
 	// No body for directory creation
-	resp, err := c.doRequest(ctx, "PUT", fullPath, nil, headers)
+	resp, err := c.doRequest(ctx, "CreateDirectory", "PUT", fullPath, nil, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -427,7 +936,7 @@ func (c *FormationClient) UploadFile(ctx context.Context, path, content string,
 	// Add metadata headers
 	c.addMetadataHeaders(headers, metadata)
 
-	resp, err := c.doRequest(ctx, "PUT", fullPath, strings.NewReader(content), headers)
+	resp, err := c.doRequest(ctx, "UploadFile", "PUT", fullPath, strings.NewReader(content), headers)
 	if err != nil {
 		return err
 	}
@@ -454,7 +963,7 @@ func (c *FormationClient) SetMetadata(ctx context.Context, path string, metadata
 	// Add metadata headers
 	c.addMetadataHeaders(headers, metadata)
 
-	resp, err := c.doRequest(ctx, "PUT", fullPath, nil, headers)
+	resp, err := c.doRequest(ctx, "SetMetadata", "PUT", fullPath, nil, headers)
 	if err != nil {
 		return err
 	}
@@ -463,6 +972,41 @@ func (c *FormationClient) SetMetadata(ctx context.Context, path string, metadata
 	return nil
 }
 
+// SnapshotMetadata retrieves the metadata currently set on path via a HEAD
+// request, so a caller can restore it later if an overwrite needs undoing.
+func (c *FormationClient) SnapshotMetadata(ctx context.Context, path string) (map[string]interface{}, error) {
+	fullPath := c.buildDataPath(path)
+
+	resp, err := c.doRequest(ctx, "SnapshotMetadata", "HEAD", fullPath, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return extractMetadataFromHeaders(resp.Header), nil
+}
+
+// SearchMetadata finds paths whose AVUs satisfy query, via the Data
+// Store's metadata search endpoint, for a caller that wants to discover
+// paths by tag instead of walking directories with BrowseData.
+func (c *FormationClient) SearchMetadata(ctx context.Context, query MetadataQuery) (*MetadataSearchResult, error) {
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata search query: %w", err)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var result MetadataSearchResult
+	if err := c.doRequestAndDecode(ctx, "SearchMetadata", "POST", "/metadata/search", bytes.NewReader(body), headers, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 // DeleteData deletes a file or directory from iRODS.
 func (c *FormationClient) DeleteData(ctx context.Context, path string, recurse, dryRun bool) error {
 	fullPath := c.buildDataPath(path)
@@ -477,7 +1021,7 @@ func (c *FormationClient) DeleteData(ctx context.Context, path string, recurse,
 		fullPath += "?" + query.Encode()
 	}
 
-	resp, err := c.doRequest(ctx, "DELETE", fullPath, nil, nil)
+	resp, err := c.doRequest(ctx, "DeleteData", "DELETE", fullPath, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -486,14 +1030,21 @@ func (c *FormationClient) DeleteData(ctx context.Context, path string, recurse,
 	return nil
 }
 
-// extractMetadataFromHeaders extracts metadata from HTTP headers with the metadata prefix.
+// extractMetadataFromHeaders extracts metadata from HTTP headers with the
+// metadata prefix, hex-decoding each key - see addMetadataHeaders - so a key
+// whose case was mangled by header-name canonicalization in transit comes
+// back exactly as it was set.
 func extractMetadataFromHeaders(headers http.Header) map[string]interface{} {
 	metadata := make(map[string]interface{})
 	for k, v := range headers {
 		if strings.HasPrefix(k, metadataHeaderPrefix) {
-			key := strings.TrimPrefix(k, metadataHeaderPrefix)
+			encoded := strings.TrimPrefix(k, metadataHeaderPrefix)
+			keyBytes, err := hex.DecodeString(encoded)
+			if err != nil {
+				continue
+			}
 			if len(v) > 0 {
-				metadata[key] = v[0]
+				metadata[string(keyBytes)] = v[0]
 			}
 		}
 	}
@@ -1,13 +1,28 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/logging"
 )
 
 // TestLogin tests the login functionality with a mock server
@@ -68,7 +83,7 @@ func TestLogin(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewFormationClient(server.URL, "", tt.username, tt.password)
+			client := NewFormationClient(server.URL, "", tt.username, tt.password, nil, nil)
 			err := client.Login(context.Background())
 
 			if tt.wantErr {
@@ -163,7 +178,7 @@ func TestListApps(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewFormationClient(server.URL, "test-token", "", "")
+			client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 			apps, err := client.ListApps(context.Background(), tt.nameFilter, "", "", "", tt.limit, tt.offset)
 
 			if tt.wantErr {
@@ -216,7 +231,7 @@ func TestGetAppParameters(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewFormationClient(server.URL, "test-token", "", "")
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 	params, err := client.GetAppParameters(context.Background(), "de", "test-app-id")
 
 	if err != nil {
@@ -245,6 +260,15 @@ func TestLaunchApp(t *testing.T) {
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// LaunchApp fetches the app's parameters for validation before
+		// submitting the launch itself - answer that GET with no parameter
+		// groups so validation passes regardless of submission.Config.
+		if r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/parameters") {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(AppParameters{})
+			return
+		}
+
 		// Verify method and path
 		if r.Method != "POST" {
 			t.Errorf("Expected POST, got %v", r.Method)
@@ -263,7 +287,7 @@ func TestLaunchApp(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewFormationClient(server.URL, "test-token", "", "")
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 	response, err := client.LaunchApp(context.Background(), "de", "test-app-id", submission)
 
 	if err != nil {
@@ -318,7 +342,7 @@ func TestGetAnalysisStatus(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewFormationClient(server.URL, "test-token", "", "")
+			client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 			status, err := client.GetAnalysisStatus(context.Background(), tt.analysisID)
 
 			if tt.wantErr {
@@ -369,7 +393,7 @@ func TestListAnalyses(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewFormationClient(server.URL, "test-token", "", "")
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 	analyses, err := client.ListAnalyses(context.Background(), "Running")
 
 	if err != nil {
@@ -422,7 +446,7 @@ func TestControlAnalysis(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewFormationClient(server.URL, "test-token", "", "")
+			client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 			err := client.ControlAnalysis(context.Background(), tt.analysisID, tt.operation, tt.saveOutputs)
 
 			if tt.wantErr && err == nil {
@@ -490,7 +514,7 @@ func TestBrowseData(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewFormationClient(server.URL, "test-token", "", "")
+			client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 			result, err := client.BrowseData(context.Background(), tt.path, 0, 0, false)
 
 			if tt.wantErr {
@@ -547,7 +571,7 @@ func TestCreateDirectory(t *testing.T) {
 		}
 
 		// Verify metadata headers
-		if r.Header.Get("X-Datastore-project") != "test-project" {
+		if r.Header.Get("X-Datastore-"+hex.EncodeToString([]byte("project"))) != "test-project" {
 			t.Errorf("Expected X-Datastore-project header")
 		}
 
@@ -559,7 +583,7 @@ func TestCreateDirectory(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewFormationClient(server.URL, "test-token", "", "")
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 	resp, err := client.CreateDirectory(context.Background(), "/cyverse/home/testuser/newdir", metadata)
 
 	if err != nil {
@@ -589,7 +613,7 @@ func TestUploadFile(t *testing.T) {
 		}
 
 		// Verify metadata header
-		if r.Header.Get("X-Datastore-description") != "test file" {
+		if r.Header.Get("X-Datastore-"+hex.EncodeToString([]byte("description"))) != "test file" {
 			t.Errorf("Expected X-Datastore-description header")
 		}
 
@@ -597,7 +621,7 @@ func TestUploadFile(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewFormationClient(server.URL, "test-token", "", "")
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 	err := client.UploadFile(context.Background(), path, content, metadata)
 
 	if err != nil {
@@ -624,7 +648,7 @@ func TestSetMetadata(t *testing.T) {
 		}
 
 		// Verify metadata headers
-		if r.Header.Get("X-Datastore-key1") != "value1" {
+		if r.Header.Get("X-Datastore-"+hex.EncodeToString([]byte("key1"))) != "value1" {
 			t.Errorf("Expected X-Datastore-key1 header")
 		}
 
@@ -632,7 +656,7 @@ func TestSetMetadata(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewFormationClient(server.URL, "test-token", "", "")
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 	err := client.SetMetadata(context.Background(), "/cyverse/home/testuser/file.txt", metadata, true)
 
 	if err != nil {
@@ -640,6 +664,33 @@ func TestSetMetadata(t *testing.T) {
 	}
 }
 
+// TestSnapshotMetadata tests retrieving a path's current metadata via HEAD.
+// The key is mixed-case to confirm it round-trips exactly: net/http
+// canonicalizes header names on both the server's Header.Set here and the
+// client's parsing of the response, which would mangle an un-encoded
+// "myKey1" into "Mykey1" if extractMetadataFromHeaders didn't hex-decode it.
+func TestSnapshotMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "HEAD" {
+			t.Errorf("Expected HEAD, got %v", r.Method)
+		}
+
+		w.Header().Set("X-Datastore-"+hex.EncodeToString([]byte("myKey1")), "value1")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+	metadata, err := client.SnapshotMetadata(context.Background(), "/cyverse/home/testuser/file.txt")
+
+	if err != nil {
+		t.Fatalf("SnapshotMetadata() unexpected error = %v", err)
+	}
+	if metadata["myKey1"] != "value1" {
+		t.Errorf("SnapshotMetadata() = %v, want myKey1=value1", metadata)
+	}
+}
+
 // TestDeleteData tests data deletion
 func TestDeleteData(t *testing.T) {
 	tests := []struct {
@@ -692,7 +743,7 @@ func TestDeleteData(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewFormationClient(server.URL, "test-token", "", "")
+			client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
 			err := client.DeleteData(context.Background(), tt.path, tt.recurse, tt.dryRun)
 
 			if tt.wantErr && err == nil {
@@ -726,7 +777,7 @@ func TestTokenRefresh(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewFormationClient(server.URL, "", "testuser", "testpass")
+	client := NewFormationClient(server.URL, "", "testuser", "testpass", nil, nil)
 
 	// First call should login
 	_, err := client.ListApps(context.Background(), "", "", "", "", 10, 0)
@@ -747,3 +798,1487 @@ func TestTokenRefresh(t *testing.T) {
 		t.Errorf("Expected at least 2 login calls, got %d", loginCount)
 	}
 }
+
+// TestEnsureTokenDoesNotStampede verifies that several concurrent requests
+// which all notice a missing token at once trigger a single Login, not one
+// per goroutine - the scenario a BulkExecutor worker pool produces when
+// every worker's first request happens to land before any token exists.
+func TestEnsureTokenDoesNotStampede(t *testing.T) {
+	var loginCount int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			atomic.AddInt32(&loginCount, 1)
+			// Give other goroutines' ensureToken calls a chance to reach
+			// the lock while this login is still in flight, so a missing
+			// mutex would reliably produce more than one login here.
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(LoginResponse{AccessToken: "shared-token", ExpiresIn: 3600})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(AppListResponse{Apps: []App{}})
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "", "testuser", "testpass", nil, nil)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			if _, err := client.ListApps(context.Background(), "", "", "", "", 10, 0); err != nil {
+				t.Errorf("ListApps() unexpected error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&loginCount); got != 1 {
+		t.Errorf("expected exactly 1 login across %d concurrent requests, got %d", workers, got)
+	}
+}
+
+// TestDoRequestRetriesAfterRetryAfterHeader verifies a 429 with a
+// Retry-After header is retried and eventually succeeds.
+func TestDoRequestRetriesAfterRetryAfterHeader(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(AppListResponse{Apps: []App{{ID: "app-1"}}})
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+	apps, err := client.ListApps(context.Background(), "", "", "", "", 10, 0)
+	if err != nil {
+		t.Fatalf("ListApps() unexpected error = %v", err)
+	}
+	if len(apps) != 1 {
+		t.Errorf("ListApps() got %d apps, want 1", len(apps))
+	}
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+// TestDoRequestExhaustsRetries verifies a persistently failing retryable
+// status eventually surfaces an error once MaxAttempts is used up.
+func TestDoRequestExhaustsRetries(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := &RetryPolicy{
+		MaxAttempts:          3,
+		BaseDelay:            time.Millisecond,
+		MaxDelay:             5 * time.Millisecond,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	}
+	client := NewFormationClient(server.URL, "test-token", "", "", policy, &RateLimitPolicy{QPS: 1000, Burst: 1000})
+	_, err := client.ListApps(context.Background(), "", "", "", "", 10, 0)
+	if err == nil {
+		t.Fatal("ListApps() expected error after exhausting retries, got none")
+	}
+	if atomic.LoadInt32(&attempts) != int32(policy.MaxAttempts) {
+		t.Errorf("expected %d attempts, got %d", policy.MaxAttempts, attempts)
+	}
+}
+
+// TestDoRequestDoesNotRetryNonIdempotentByDefault verifies POST/PUT/DELETE
+// requests aren't retried unless the caller opts in via WithRetry.
+func TestDoRequestDoesNotRetryNonIdempotentByDefault(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := &RetryPolicy{
+		MaxAttempts:          3,
+		BaseDelay:            time.Millisecond,
+		MaxDelay:             5 * time.Millisecond,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	}
+	client := NewFormationClient(server.URL, "test-token", "", "", policy, &RateLimitPolicy{QPS: 1000, Burst: 1000})
+
+	if err := client.ControlAnalysis(context.Background(), "analysis-1", "exit", false); err == nil {
+		t.Fatal("ControlAnalysis() expected error, got none")
+	}
+	if atomic.LoadInt32(&attempts) != 1 {
+		t.Errorf("expected exactly 1 attempt for a non-idempotent request without WithRetry, got %d", attempts)
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	if err := client.ControlAnalysis(WithRetry(context.Background(), true), "analysis-1", "exit", false); err == nil {
+		t.Fatal("ControlAnalysis() expected error, got none")
+	}
+	if atomic.LoadInt32(&attempts) != int32(policy.MaxAttempts) {
+		t.Errorf("expected %d attempts once WithRetry(true) opted in, got %d", policy.MaxAttempts, attempts)
+	}
+}
+
+// dialFailOnceTransport fails its first RoundTrip with a dial-style
+// net.OpError, then forwards every later attempt to next.
+type dialFailOnceTransport struct {
+	next     http.RoundTripper
+	attempts int32
+	failed   int32
+}
+
+func (t *dialFailOnceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.attempts, 1)
+	if atomic.CompareAndSwapInt32(&t.failed, 0, 1) {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// TestDoRequestRetriesNonIdempotentOnPreflightNetworkError verifies a POST
+// is retried even without WithRetry when the failure happened before any
+// part of the request reached the server, since replaying it can't double
+// an effect the server never saw in the first place.
+func TestDoRequestRetriesNonIdempotentOnPreflightNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport := &dialFailOnceTransport{next: http.DefaultTransport}
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+	client.httpClient.Transport = transport
+
+	if err := client.ControlAnalysis(context.Background(), "analysis-1", "exit", false); err != nil {
+		t.Fatalf("ControlAnalysis() unexpected error = %v", err)
+	}
+	if got := atomic.LoadInt32(&transport.attempts); got != 2 {
+		t.Errorf("expected 2 attempts (one pre-flight dial failure, one success), got %d", got)
+	}
+}
+
+// TestShutdownWaitsForInFlightRequest verifies Shutdown blocks until a
+// request already in flight finishes reading its response body, then
+// rejects further requests.
+func TestShutdownWaitsForInFlightRequest(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(AppListResponse{Apps: []App{}})
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := client.ListApps(context.Background(), "", "", "", "", 0, 0); err != nil {
+			t.Errorf("ListApps() unexpected error = %v", err)
+		}
+	}()
+
+	shutdownDone := make(chan error, 1)
+	go func() {
+		shutdownDone <- client.Shutdown(context.Background())
+	}()
+
+	select {
+	case <-shutdownDone:
+		t.Fatal("Shutdown() returned before the in-flight request finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(unblock)
+	<-done
+
+	select {
+	case err := <-shutdownDone:
+		if err != nil {
+			t.Errorf("Shutdown() unexpected error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown() did not return after the in-flight request finished")
+	}
+
+	if _, err := client.ListApps(context.Background(), "", "", "", "", 0, 0); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed after Shutdown(), got %v", err)
+	}
+}
+
+// TestShutdownReturnsCtxErrIfRequestsStillInFlight verifies Shutdown
+// respects ctx's deadline rather than waiting forever.
+func TestShutdownReturnsCtxErrIfRequestsStillInFlight(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+
+	go client.ListApps(context.Background(), "", "", "", "", 0, 0)
+	time.Sleep(20 * time.Millisecond) // let the request start
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := client.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Shutdown() to return context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// TestRateLimiterThrottlesConcurrentRequests verifies the token-bucket
+// limiter caps how many requests can go out in a burst window.
+func TestRateLimiterThrottlesConcurrentRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(AppListResponse{Apps: []App{}})
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 10, Burst: 2})
+
+	const concurrency = 5
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	start := time.Now()
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			client.ListApps(context.Background(), "", "", "", "", 10, 0)
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	// The burst of 2 is spent immediately; the remaining 3 requests must
+	// each wait roughly 1/QPS for a fresh token, so 5 requests at QPS=10,
+	// burst=2 can't all complete in under ~200ms.
+	if elapsed < 200*time.Millisecond {
+		t.Errorf("expected rate limiting to slow concurrent requests, took only %v", elapsed)
+	}
+}
+
+// TestWatchAnalysisOrdersEventsAndClosesOnTerminal verifies events arrive
+// in order and the channel closes once a terminal status is seen.
+func TestWatchAnalysisOrdersEventsAndClosesOnTerminal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher := w.(http.Flusher)
+
+		frames := []string{
+			`{"status":"Running","url_ready":false}`,
+			`{"status":"Running","url_ready":true,"url":"https://test.cyverse.run"}`,
+			`{"status":"Completed","url_ready":true,"url":"https://test.cyverse.run"}`,
+		}
+		for _, frame := range frames {
+			fmt.Fprintf(w, "data: %s\n\n", frame)
+			flusher.Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+	events, err := client.WatchAnalysis(context.Background(), "analysis-1")
+	if err != nil {
+		t.Fatalf("WatchAnalysis() unexpected error = %v", err)
+	}
+
+	var statuses []string
+	for event := range events {
+		if event.Err != nil {
+			t.Fatalf("unexpected event error: %v", event.Err)
+		}
+		statuses = append(statuses, event.Status)
+	}
+
+	want := []string{"Running", "Running", "Completed"}
+	if len(statuses) != len(want) {
+		t.Fatalf("got %d events %v, want %d", len(statuses), statuses, len(want))
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Errorf("event[%d] = %q, want %q", i, statuses[i], want[i])
+		}
+	}
+}
+
+// TestWatchAnalysisReconnectsOnDisconnect verifies that an abrupt
+// mid-stream disconnect is followed by a reconnect carrying Last-Event-ID,
+// and that the watch continues seamlessly afterward.
+func TestWatchAnalysisReconnectsOnDisconnect(t *testing.T) {
+	var connectCount int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&connectCount, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher := w.(http.Flusher)
+
+		if n == 1 {
+			fmt.Fprint(w, "id: evt-1\ndata: {\"status\":\"Running\",\"url_ready\":false}\n\n")
+			flusher.Flush()
+			panic(http.ErrAbortHandler)
+		}
+
+		if got := r.Header.Get("Last-Event-ID"); got != "evt-1" {
+			t.Errorf("expected reconnect to carry Last-Event-ID evt-1, got %q", got)
+		}
+		fmt.Fprint(w, "data: {\"status\":\"Completed\",\"url_ready\":true,\"url\":\"https://test.cyverse.run\"}\n\n")
+		flusher.Flush()
+	}))
+	defer server.Close()
+
+	policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, JitterFraction: 0}
+	client := NewFormationClient(server.URL, "test-token", "", "", policy, &RateLimitPolicy{QPS: 1000, Burst: 1000})
+
+	events, err := client.WatchAnalysis(context.Background(), "analysis-1")
+	if err != nil {
+		t.Fatalf("WatchAnalysis() unexpected error = %v", err)
+	}
+
+	var got []AnalysisEvent
+	for event := range events {
+		got = append(got, event)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %d: %+v", len(got), got)
+	}
+	if got[0].Status != "Running" || got[1].Status != "Completed" {
+		t.Errorf("unexpected event sequence: %+v", got)
+	}
+	if atomic.LoadInt32(&connectCount) != 2 {
+		t.Errorf("expected 2 connections (initial + 1 reconnect), got %d", connectCount)
+	}
+}
+
+// TestWatchAnalysisGivesUpAfterExhaustingReconnects verifies a
+// persistently failing connection eventually surfaces an error on the
+// channel and closes it, rather than reconnecting forever.
+func TestWatchAnalysisGivesUpAfterExhaustingReconnects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "data: incomplete")
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	defer server.Close()
+
+	policy := &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond, JitterFraction: 0}
+	client := NewFormationClient(server.URL, "test-token", "", "", policy, &RateLimitPolicy{QPS: 1000, Burst: 1000})
+
+	events, err := client.WatchAnalysis(context.Background(), "analysis-1")
+	if err != nil {
+		t.Fatalf("WatchAnalysis() unexpected error = %v", err)
+	}
+
+	var lastEvent AnalysisEvent
+	for event := range events {
+		lastEvent = event
+	}
+
+	if lastEvent.Err == nil {
+		t.Error("expected final event to carry the exhausted-retries error")
+	}
+}
+
+// TestWatchAnalysesAppliesStatusFilter verifies WatchAnalyses hits the
+// list-watch endpoint with the requested status filter.
+func TestWatchAnalysesAppliesStatusFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apps/analyses/watch" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("status"); got != "Running" {
+			t.Errorf("expected status=Running filter, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "data: {\"analysis_id\":\"analysis-1\",\"status\":\"Completed\"}\n\n")
+		w.(http.Flusher).Flush()
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+	events, err := client.WatchAnalyses(context.Background(), "Running")
+	if err != nil {
+		t.Fatalf("WatchAnalyses() unexpected error = %v", err)
+	}
+
+	event, ok := <-events
+	if !ok {
+		t.Fatal("expected one event before channel closed")
+	}
+	if event.AnalysisID != "analysis-1" || event.Status != "Completed" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	if _, ok := <-events; ok {
+		t.Error("expected channel to close after terminal status")
+	}
+}
+
+func TestUploadFileStreamSendsChunksAndCommits(t *testing.T) {
+	content := "0123456789abcdefghij" // 20 bytes
+	wantSHA := sha256.Sum256([]byte(content))
+	wantSHAHex := hex.EncodeToString(wantSHA[:])
+
+	var puts int32
+	var sessionID string
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			n := atomic.AddInt32(&puts, 1)
+
+			wantRange := fmt.Sprintf("bytes %d-%d/20", (n-1)*10, n*10-1)
+			if got := r.Header.Get("Content-Range"); got != wantRange {
+				t.Errorf("chunk %d: expected Content-Range %q, got %q", n, wantRange, got)
+			}
+			if got := string(body); got != content[(n-1)*10:n*10] {
+				t.Errorf("chunk %d: unexpected body %q", n, got)
+			}
+
+			mu.Lock()
+			if sessionID == "" {
+				sessionID = r.Header.Get("X-Upload-Session")
+			} else if got := r.Header.Get("X-Upload-Session"); got != sessionID {
+				t.Errorf("expected consistent session id %q, got %q", sessionID, got)
+			}
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusOK)
+		case http.MethodPost:
+			if got := atomic.LoadInt32(&puts); got != 2 {
+				t.Errorf("expected commit after 2 chunks, got %d", got)
+			}
+			if got := r.Header.Get("X-Content-SHA256"); got != wantSHAHex {
+				t.Errorf("expected X-Content-SHA256 %q, got %q", wantSHAHex, got)
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected method %v", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+	err := client.UploadFileStream(context.Background(), "/cyverse/home/testuser/big.bin", strings.NewReader(content), int64(len(content)), UploadOptions{ChunkSize: 10})
+	if err != nil {
+		t.Fatalf("UploadFileStream() unexpected error = %v", err)
+	}
+	if got := atomic.LoadInt32(&puts); got != 2 {
+		t.Errorf("expected 2 chunk PUTs, got %d", got)
+	}
+}
+
+func TestUploadFileStreamResumesAfterFailure(t *testing.T) {
+	content := "0123456789abcdefghij" // 20 bytes
+	path := "/cyverse/home/testuser/big.bin"
+	store := NewMemoryUploadStateStore()
+
+	var puts int32
+	var commits int32
+	failSecondChunk := true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			n := atomic.AddInt32(&puts, 1)
+			// Chunk 2 and its retries all fail, so the upload gives up.
+			if n >= 2 && failSecondChunk {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case http.MethodHead:
+			w.WriteHeader(http.StatusOK)
+		case http.MethodPost:
+			atomic.AddInt32(&commits, 1)
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected method %v", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+
+	err := client.UploadFileStream(context.Background(), path, strings.NewReader(content), int64(len(content)), UploadOptions{ChunkSize: 10, StateStore: store})
+	if err == nil {
+		t.Fatal("expected first attempt to fail on chunk 2")
+	}
+	if got := atomic.LoadInt32(&commits); got != 0 {
+		t.Errorf("expected no commit before all chunks succeed, got %d", got)
+	}
+
+	state, ok, err := store.Get(path)
+	if err != nil || !ok {
+		t.Fatalf("expected progress saved after chunk 1, ok=%v err=%v", ok, err)
+	}
+	if state.Offset != 10 {
+		t.Errorf("expected saved offset 10, got %d", state.Offset)
+	}
+
+	failSecondChunk = false
+	atomic.StoreInt32(&puts, 1) // a fresh attempt should only need to send the remaining chunk
+
+	err = client.UploadFileStream(context.Background(), path, strings.NewReader(content), int64(len(content)), UploadOptions{ChunkSize: 10, StateStore: store})
+	if err != nil {
+		t.Fatalf("UploadFileStream() resume unexpected error = %v", err)
+	}
+	if got := atomic.LoadInt32(&puts); got != 2 {
+		t.Errorf("expected resume to send only the missing chunk, total puts = %d", got)
+	}
+	if got := atomic.LoadInt32(&commits); got != 1 {
+		t.Errorf("expected exactly one commit, got %d", got)
+	}
+
+	if _, ok, _ := store.Get(path); ok {
+		t.Error("expected upload state to be cleared after a successful commit")
+	}
+}
+
+func TestUploadFileStreamReportsProgress(t *testing.T) {
+	content := "0123456789abcdefghij" // 20 bytes
+	path := "/cyverse/home/testuser/big.bin"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+
+	var reported []int64
+	opts := UploadOptions{
+		ChunkSize: 10,
+		OnProgress: func(written, total int64) {
+			if total != int64(len(content)) {
+				t.Errorf("OnProgress() total = %d, want %d", total, len(content))
+			}
+			reported = append(reported, written)
+		},
+	}
+
+	if err := client.UploadFileStream(context.Background(), path, strings.NewReader(content), int64(len(content)), opts); err != nil {
+		t.Fatalf("UploadFileStream() unexpected error = %v", err)
+	}
+
+	if len(reported) == 0 {
+		t.Fatal("expected at least one OnProgress call")
+	}
+	if last := reported[len(reported)-1]; last != int64(len(content)) {
+		t.Errorf("expected final OnProgress call to report all %d bytes, got %d", len(content), last)
+	}
+}
+
+func TestDownloadFileStream(t *testing.T) {
+	content := "This is file content"
+	path := "/cyverse/home/testuser/file.txt"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expectedPath := "/data" + path
+		if r.URL.Path != expectedPath {
+			t.Errorf("Expected path %v, got %v", expectedPath, r.URL.Path)
+		}
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+
+	var lastN, lastTotal int64
+	var buf bytes.Buffer
+	err := client.DownloadFileStream(context.Background(), path, &buf, func(n, total int64) {
+		lastN, lastTotal = n, total
+	})
+	if err != nil {
+		t.Fatalf("DownloadFileStream() unexpected error = %v", err)
+	}
+	if buf.String() != content {
+		t.Errorf("DownloadFileStream() wrote %q, want %q", buf.String(), content)
+	}
+	if lastN != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Errorf("expected final progress call (%d, %d), got (%d, %d)", len(content), len(content), lastN, lastTotal)
+	}
+}
+
+func TestUploadLocalFileResumesFromManifestAfterFailure(t *testing.T) {
+	content := "0123456789abcdefghij" // 20 bytes
+	remotePath := "/cyverse/home/testuser/big.bin"
+
+	localPath := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(localPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write local file: %v", err)
+	}
+	stateDir := t.TempDir()
+
+	var puts int32
+	failSecondChunk := true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			n := atomic.AddInt32(&puts, 1)
+			// Chunk 2 and its retries all fail, so the upload gives up.
+			if n >= 2 && failSecondChunk {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case http.MethodHead, http.MethodPost:
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected method %v", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	c := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+	opts := ResumableUploadOptions{ChunkSize: 10, StateDir: stateDir}
+
+	err := UploadLocalFile(context.Background(), c, localPath, remotePath, opts)
+	if err == nil {
+		t.Fatal("expected first attempt to fail on chunk 2")
+	}
+
+	mp := localManifestPath(stateDir, remotePath)
+	if _, statErr := os.Stat(mp); statErr != nil {
+		t.Fatalf("expected manifest to remain after a failed upload: %v", statErr)
+	}
+
+	failSecondChunk = false
+	if err := ResumeUpload(context.Background(), c, mp, opts); err != nil {
+		t.Fatalf("ResumeUpload() unexpected error = %v", err)
+	}
+
+	if _, statErr := os.Stat(mp); !os.IsNotExist(statErr) {
+		t.Errorf("expected manifest to be removed after a successful resume, stat err = %v", statErr)
+	}
+}
+
+func TestBasicAuthProviderTokenLogsInAndCaches(t *testing.T) {
+	var logins int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&logins, 1)
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "testuser" || password != "testpass" {
+			t.Errorf("unexpected basic auth credentials: %q/%q ok=%v", username, password, ok)
+		}
+		json.NewEncoder(w).Encode(LoginResponse{AccessToken: "token-1", ExpiresIn: 3600})
+	}))
+	defer server.Close()
+
+	provider := NewBasicAuthProvider(server.URL, "testuser", "testpass")
+
+	token, err := provider.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() unexpected error = %v", err)
+	}
+	if token != "token-1" {
+		t.Errorf("expected token-1, got %q", token)
+	}
+
+	if _, err := provider.Token(context.Background()); err != nil {
+		t.Fatalf("Token() unexpected error on second call = %v", err)
+	}
+	if got := atomic.LoadInt32(&logins); got != 1 {
+		t.Errorf("expected a single login while the token is still valid, got %d", got)
+	}
+}
+
+func TestFormationClientUsesAuthProviderForBearerToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer provider-token" {
+			t.Errorf("expected Authorization from the AuthProvider, got %q", got)
+		}
+		json.NewEncoder(w).Encode(AppListResponse{Apps: []App{}})
+	}))
+	defer server.Close()
+
+	client := NewFormationClientWithAuth(server.URL, stubAuthProvider{token: "provider-token"}, nil, &RateLimitPolicy{QPS: 1000, Burst: 1000})
+
+	if _, err := client.ListApps(context.Background(), "", "", "", "", 10, 0); err != nil {
+		t.Fatalf("ListApps() unexpected error = %v", err)
+	}
+}
+
+type stubAuthProvider struct {
+	token string
+	err   error
+}
+
+func (s stubAuthProvider) Token(ctx context.Context) (string, error) {
+	return s.token, s.err
+}
+
+func TestOIDCAuthProviderAuthorizePKCEFlow(t *testing.T) {
+	var server *httptest.Server
+	var gotGrantType, gotVerifier, gotRedirectURI string
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"authorization_endpoint": server.URL + "/authorize",
+				"token_endpoint":         server.URL + "/token",
+			})
+		case "/token":
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse token request form: %v", err)
+			}
+			gotGrantType = r.FormValue("grant_type")
+			gotVerifier = r.FormValue("code_verifier")
+			gotRedirectURI = r.FormValue("redirect_uri")
+
+			json.NewEncoder(w).Encode(oidcTokenResponse{
+				AccessToken:  "access-1",
+				RefreshToken: "refresh-1",
+				ExpiresIn:    3600,
+			})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	redirectURI := "http://" + freeLoopbackAddr(t) + "/callback"
+
+	var capturedChallenge string
+	opener := openerFunc(func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			t.Fatalf("invalid authorization URL: %v", err)
+		}
+		capturedChallenge = u.Query().Get("code_challenge")
+		state := u.Query().Get("state")
+
+		// Simulate the browser completing the redirect back to our
+		// loopback listener, the way a real IdP would after the user
+		// approves the request.
+		go func() {
+			resp, err := http.Get(redirectURI + "?code=test-code&state=" + state)
+			if err != nil {
+				t.Errorf("failed to simulate oauth redirect: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+		return nil
+	})
+
+	provider := NewOIDCAuthProvider(server.URL, "test-client", redirectURI, []string{"openid"}, nil)
+	provider.Opener = opener
+
+	if err := provider.Authorize(context.Background()); err != nil {
+		t.Fatalf("Authorize() unexpected error = %v", err)
+	}
+
+	if gotGrantType != "authorization_code" {
+		t.Errorf("expected grant_type=authorization_code, got %q", gotGrantType)
+	}
+	if gotRedirectURI != redirectURI {
+		t.Errorf("expected redirect_uri %q sent to token endpoint, got %q", redirectURI, gotRedirectURI)
+	}
+	if want := codeChallengeS256(gotVerifier); capturedChallenge != want {
+		t.Errorf("code_challenge %q doesn't match S256(code_verifier) %q", capturedChallenge, want)
+	}
+
+	token, err := provider.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() unexpected error = %v", err)
+	}
+	if token != "access-1" {
+		t.Errorf("expected access-1, got %q", token)
+	}
+}
+
+func TestOIDCAuthProviderTokenRefreshesExpiredAccessToken(t *testing.T) {
+	var server *httptest.Server
+	var gotGrantType, gotRefreshToken string
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"authorization_endpoint": server.URL + "/authorize",
+				"token_endpoint":         server.URL + "/token",
+			})
+		case "/token":
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse token request form: %v", err)
+			}
+			gotGrantType = r.FormValue("grant_type")
+			gotRefreshToken = r.FormValue("refresh_token")
+
+			json.NewEncoder(w).Encode(oidcTokenResponse{
+				AccessToken:  "access-2",
+				RefreshToken: "refresh-1",
+				ExpiresIn:    3600,
+			})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	provider := NewOIDCAuthProvider(server.URL, "test-client", "http://127.0.0.1/callback", []string{"openid"}, store)
+	if err := store.Save(provider.StoreKey, OAuthToken{RefreshToken: "refresh-1"}); err != nil {
+		t.Fatalf("failed to seed token store: %v", err)
+	}
+
+	token, err := provider.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() unexpected error = %v", err)
+	}
+	if token != "access-2" {
+		t.Errorf("expected access-2, got %q", token)
+	}
+	if gotGrantType != "refresh_token" {
+		t.Errorf("expected grant_type=refresh_token, got %q", gotGrantType)
+	}
+	if gotRefreshToken != "refresh-1" {
+		t.Errorf("expected refresh_token=refresh-1, got %q", gotRefreshToken)
+	}
+}
+
+func TestOIDCAuthProviderLoginWithClientCredentials(t *testing.T) {
+	var server *httptest.Server
+	var gotGrantType, gotClientSecret string
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"token_endpoint": server.URL + "/token",
+			})
+		case "/token":
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse token request form: %v", err)
+			}
+			gotGrantType = r.FormValue("grant_type")
+			gotClientSecret = r.FormValue("client_secret")
+
+			json.NewEncoder(w).Encode(oidcTokenResponse{AccessToken: "service-access", ExpiresIn: 3600})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOIDCAuthProvider(server.URL, "service-client", "", []string{"openid"}, nil)
+
+	if err := provider.LoginWithClientCredentials(context.Background(), "shh"); err != nil {
+		t.Fatalf("LoginWithClientCredentials() unexpected error = %v", err)
+	}
+	if gotGrantType != "client_credentials" {
+		t.Errorf("expected grant_type=client_credentials, got %q", gotGrantType)
+	}
+	if gotClientSecret != "shh" {
+		t.Errorf("expected client_secret=shh, got %q", gotClientSecret)
+	}
+
+	token, err := provider.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() unexpected error = %v", err)
+	}
+	if token != "service-access" {
+		t.Errorf("expected service-access, got %q", token)
+	}
+}
+
+// TestOIDCAuthProviderLoginWithDeviceCode exercises one authorization_pending
+// poll before the device completes, verifying the loop keeps polling on that
+// error code instead of treating it as a failure.
+func TestOIDCAuthProviderLoginWithDeviceCode(t *testing.T) {
+	var server *httptest.Server
+	var tokenAttempts int
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"token_endpoint":               server.URL + "/token",
+				"device_authorization_endpoint": server.URL + "/device",
+			})
+		case "/device":
+			json.NewEncoder(w).Encode(deviceAuthorizationResponse{
+				DeviceCode: "device-123",
+				UserCode:   "ABCD-EFGH",
+				ExpiresIn:  60,
+				Interval:   0,
+			})
+		case "/token":
+			tokenAttempts++
+			if tokenAttempts == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+			json.NewEncoder(w).Encode(oidcTokenResponse{AccessToken: "device-access", RefreshToken: "device-refresh", ExpiresIn: 3600})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var loggedCode string
+	provider := NewOIDCAuthProvider(server.URL, "test-client", "", nil, nil)
+	provider.Opener = openerFunc(func(verificationURL string) error {
+		loggedCode = verificationURL
+		return nil
+	})
+
+	// Device poll intervals default to 5s when the server omits one; this
+	// test's device response sets Interval to 0 for that reason, so the
+	// retry happens almost immediately instead of making the test slow.
+	if err := provider.LoginWithDeviceCode(context.Background()); err != nil {
+		t.Fatalf("LoginWithDeviceCode() unexpected error = %v", err)
+	}
+	if tokenAttempts != 2 {
+		t.Errorf("expected 2 token polls (one pending, one success), got %d", tokenAttempts)
+	}
+	if loggedCode == "" {
+		t.Error("expected the verification URL to be passed to Opener")
+	}
+
+	token, err := provider.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() unexpected error = %v", err)
+	}
+	if token != "device-access" {
+		t.Errorf("expected device-access, got %q", token)
+	}
+}
+
+func TestFileTokenStoreSaveAndGetRoundTrip(t *testing.T) {
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "tokens"))
+
+	if _, ok, err := store.Get("missing"); ok || err != nil {
+		t.Fatalf("Get() on an empty store = (ok=%v, err=%v), want (false, nil)", ok, err)
+	}
+
+	want := OAuthToken{AccessToken: "a", RefreshToken: "r", Expiry: time.Now().Add(time.Hour).Truncate(time.Second)}
+	if err := store.Save("user-1", want); err != nil {
+		t.Fatalf("Save() unexpected error = %v", err)
+	}
+
+	got, ok, err := store.Get("user-1")
+	if err != nil || !ok {
+		t.Fatalf("Get() = (ok=%v, err=%v), want (true, nil)", ok, err)
+	}
+	if got.AccessToken != want.AccessToken || got.RefreshToken != want.RefreshToken || !got.Expiry.Equal(want.Expiry) {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+}
+
+type openerFunc func(url string) error
+
+func (f openerFunc) Open(url string) error { return f(url) }
+
+// freeLoopbackAddr returns an address on the loopback interface with a
+// free port, for tests that need to know a listen address before
+// starting the server that will use it.
+func freeLoopbackAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// spyObserver records the sequence of Observer hook calls it receives, for
+// asserting ordering rather than just final counts.
+type spyObserver struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *spyObserver) OnRequestStart(ctx context.Context, op string, req *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, "start:"+op)
+}
+
+func (o *spyObserver) OnRequestEnd(ctx context.Context, op string, resp *http.Response, err error, dur time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err != nil {
+		o.events = append(o.events, "end:"+op+":error")
+		return
+	}
+	o.events = append(o.events, fmt.Sprintf("end:%s:%d", op, resp.StatusCode))
+}
+
+func (o *spyObserver) OnRetry(ctx context.Context, op string, attempt int, delay time.Duration, reason error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, fmt.Sprintf("retry:%s:%d", op, attempt))
+}
+
+// TestObserverSeesStartRetryEndOnceEach verifies OnRequestStart/OnRequestEnd
+// fire exactly once around a whole doRequest call, with OnRetry firing once
+// in between, for a request that fails once with a retryable status and
+// then succeeds.
+func TestObserverSeesStartRetryEndOnceEach(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(AppListResponse{Apps: []App{{ID: "app-1"}}})
+	}))
+	defer server.Close()
+
+	policy := &RetryPolicy{
+		MaxAttempts:          3,
+		BaseDelay:            time.Millisecond,
+		MaxDelay:             5 * time.Millisecond,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	}
+	client := NewFormationClient(server.URL, "test-token", "", "", policy, &RateLimitPolicy{QPS: 1000, Burst: 1000})
+
+	observer := &spyObserver{}
+	client.SetObserver(observer)
+
+	if _, err := client.ListApps(context.Background(), "", "", "", "", 10, 0); err != nil {
+		t.Fatalf("ListApps() unexpected error = %v", err)
+	}
+
+	want := []string{"start:ListApps", "retry:ListApps:1", "end:ListApps:200"}
+	observer.mu.Lock()
+	got := append([]string(nil), observer.events...)
+	observer.mu.Unlock()
+
+	if len(got) != len(want) {
+		t.Fatalf("observer events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("observer event %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+// TestMultiObserverFansOutToEach verifies MultiObserver calls every
+// Observer it wraps for each hook.
+func TestMultiObserverFansOutToEach(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(AppListResponse{})
+	}))
+	defer server.Close()
+
+	a, b := &spyObserver{}, &spyObserver{}
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+	client.SetObserver(MultiObserver{a, b})
+
+	if _, err := client.ListApps(context.Background(), "", "", "", "", 10, 0); err != nil {
+		t.Fatalf("ListApps() unexpected error = %v", err)
+	}
+
+	for _, o := range []*spyObserver{a, b} {
+		o.mu.Lock()
+		n := len(o.events)
+		o.mu.Unlock()
+		if n != 2 {
+			t.Errorf("observer saw %d events, want 2 (start, end)", n)
+		}
+	}
+}
+
+// TestPrometheusObserverWritesExpositionFormat verifies PrometheusObserver
+// records a request and renders it in Prometheus text exposition format.
+func TestPrometheusObserverWritesExpositionFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(AppListResponse{})
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+	prom := NewPrometheusObserver(nil)
+	client.SetObserver(prom)
+
+	if _, err := client.ListApps(context.Background(), "", "", "", "", 10, 0); err != nil {
+		t.Fatalf("ListApps() unexpected error = %v", err)
+	}
+
+	var buf strings.Builder
+	if err := prom.WriteMetricsTo(&buf); err != nil {
+		t.Fatalf("WriteMetricsTo() unexpected error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `formation_client_requests_total{op="ListApps",code="200"} 1`) {
+		t.Errorf("exposition output missing requests_total line, got:\n%s", out)
+	}
+	if !strings.Contains(out, `formation_client_request_duration_seconds_count{op="ListApps"} 1`) {
+		t.Errorf("exposition output missing duration_seconds_count line, got:\n%s", out)
+	}
+}
+
+// TestOTelObserverPropagatesTraceparentAndExportsSpan verifies OTelObserver
+// sets a traceparent header on the outgoing request and exports a matching
+// span once the request completes.
+func TestOTelObserverPropagatesTraceparentAndExportsSpan(t *testing.T) {
+	var gotTraceparent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(AppListResponse{})
+	}))
+	defer server.Close()
+
+	var exported []Span
+	exporter := spanExporterFunc(func(s Span) { exported = append(exported, s) })
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+	client.SetObserver(NewOTelObserver(exporter))
+
+	if _, err := client.ListApps(context.Background(), "", "", "", "", 10, 0); err != nil {
+		t.Fatalf("ListApps() unexpected error = %v", err)
+	}
+
+	if !traceParentPattern.MatchString(gotTraceparent) {
+		t.Errorf("server saw traceparent %q, want it to match the W3C format", gotTraceparent)
+	}
+	if len(exported) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(exported))
+	}
+	if exported[0].Name != "ListApps" {
+		t.Errorf("exported span Name = %q, want ListApps", exported[0].Name)
+	}
+	if exported[0].Attributes["http.status_code"] != "200" {
+		t.Errorf("exported span http.status_code = %q, want 200", exported[0].Attributes["http.status_code"])
+	}
+}
+
+type spanExporterFunc func(Span)
+
+func (f spanExporterFunc) ExportSpan(s Span) { f(s) }
+
+// TestUploadFileChunkStartsResumesAndFinalizes drives a full chunked upload
+// across three separate calls (start, middle chunk, final chunk) and
+// verifies each PUT carries the right range and session id, and that the
+// final chunk triggers a commit with the accumulated hash.
+func TestUploadFileChunkStartsResumesAndFinalizes(t *testing.T) {
+	path := "/cyverse/home/testuser/big.bin"
+	chunks := []string{"hello", "world", "!!"}
+	wantSHA := sha256.Sum256([]byte("helloworld!!"))
+	wantSHAHex := hex.EncodeToString(wantSHA[:])
+
+	var puts, commits int32
+	var sessionID string
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			atomic.AddInt32(&puts, 1)
+			mu.Lock()
+			got := r.Header.Get("X-Upload-Session")
+			if sessionID == "" {
+				sessionID = got
+			} else if got != sessionID {
+				t.Errorf("expected consistent session id %q, got %q", sessionID, got)
+			}
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		case http.MethodPost:
+			atomic.AddInt32(&commits, 1)
+			if got := r.Header.Get("X-Content-SHA256"); got != wantSHAHex {
+				t.Errorf("expected X-Content-SHA256 %q, got %q", wantSHAHex, got)
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected method %v", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+
+	result, err := client.UploadFileChunk(context.Background(), "", path, 0, []byte(chunks[0]), false, nil)
+	if err != nil {
+		t.Fatalf("first chunk unexpected error = %v", err)
+	}
+	if result.UploadID == "" {
+		t.Fatal("expected a non-empty upload id from the first chunk")
+	}
+	if result.Offset != int64(len(chunks[0])) {
+		t.Errorf("offset after first chunk = %d, want %d", result.Offset, len(chunks[0]))
+	}
+
+	result, err = client.UploadFileChunk(context.Background(), result.UploadID, path, result.Offset, []byte(chunks[1]), false, nil)
+	if err != nil {
+		t.Fatalf("second chunk unexpected error = %v", err)
+	}
+	if want := int64(len(chunks[0]) + len(chunks[1])); result.Offset != want {
+		t.Errorf("offset after second chunk = %d, want %d", result.Offset, want)
+	}
+
+	result, err = client.UploadFileChunk(context.Background(), result.UploadID, path, result.Offset, []byte(chunks[2]), true, nil)
+	if err != nil {
+		t.Fatalf("final chunk unexpected error = %v", err)
+	}
+	if !result.Complete {
+		t.Error("expected Complete = true after the final chunk")
+	}
+	if result.ContentSHA256 != wantSHAHex {
+		t.Errorf("ContentSHA256 = %q, want %q", result.ContentSHA256, wantSHAHex)
+	}
+	if got := atomic.LoadInt32(&puts); got != 3 {
+		t.Errorf("expected 3 chunk PUTs, got %d", got)
+	}
+	if got := atomic.LoadInt32(&commits); got != 1 {
+		t.Errorf("expected exactly 1 commit, got %d", got)
+	}
+}
+
+// TestUploadFileChunkRejectsOffsetMismatch verifies a chunk resumed at the
+// wrong offset is rejected by the session store before any request is sent.
+func TestUploadFileChunkRejectsOffsetMismatch(t *testing.T) {
+	path := "/cyverse/home/testuser/big.bin"
+	var puts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			atomic.AddInt32(&puts, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+
+	result, err := client.UploadFileChunk(context.Background(), "", path, 0, []byte("hello"), false, nil)
+	if err != nil {
+		t.Fatalf("first chunk unexpected error = %v", err)
+	}
+
+	before := atomic.LoadInt32(&puts)
+	if _, err := client.UploadFileChunk(context.Background(), result.UploadID, path, 3, []byte("world"), false, nil); err == nil {
+		t.Fatal("expected an error for a chunk resumed at the wrong offset")
+	}
+	if got := atomic.LoadInt32(&puts); got != before {
+		t.Errorf("expected no additional PUTs after a rejected offset, got %d more", got-before)
+	}
+}
+
+// TestUploadFileChunkRejectsUnknownSession verifies an upload id the store
+// doesn't recognize (e.g. after a restart) is rejected with a clear error.
+func TestUploadFileChunkRejectsUnknownSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", DefaultRetryPolicy(), &RateLimitPolicy{QPS: 1000, Burst: 1000})
+
+	_, err := client.UploadFileChunk(context.Background(), "not-a-real-session", "/cyverse/home/testuser/big.bin", 0, []byte("hello"), false, nil)
+	if err == nil {
+		t.Fatal("expected an error for an unknown upload session")
+	}
+}
+
+// TestNewULIDShape verifies newULID produces a 26-character Crockford
+// base32 id using only characters from that alphabet.
+func TestNewULIDShape(t *testing.T) {
+	id := newULID()
+	if len(id) != 26 {
+		t.Fatalf("expected a 26-character ULID, got %q (%d chars)", id, len(id))
+	}
+	for _, r := range id {
+		if !strings.ContainsRune(crockfordAlphabet, r) {
+			t.Errorf("ULID %q contains non-Crockford-base32 character %q", id, r)
+		}
+	}
+}
+
+// TestWithRequestIDGeneratesWhenEmpty verifies WithRequestID mints a ULID
+// when passed an empty id, and preserves a caller-supplied one untouched.
+func TestWithRequestIDGeneratesWhenEmpty(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "")
+	id, ok := RequestIDFromContext(ctx)
+	if !ok || id == "" {
+		t.Fatal("expected WithRequestID(\"\") to generate a non-empty id")
+	}
+
+	ctx = WithRequestID(context.Background(), "caller-supplied-id")
+	id, ok = RequestIDFromContext(ctx)
+	if !ok || id != "caller-supplied-id" {
+		t.Errorf("expected the caller-supplied id to be preserved, got %q", id)
+	}
+}
+
+// TestDoRequestSendsAndCapturesRequestID verifies every outgoing request
+// carries an X-Request-ID (minted automatically if the caller didn't
+// supply one) and that WithResponseMeta captures the server's echoed
+// value back.
+func TestDoRequestSendsAndCapturesRequestID(t *testing.T) {
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Request-ID")
+		w.Header().Set("X-Request-ID", gotHeader)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(AppListResponse{Apps: []App{}})
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+
+	ctx, meta := WithResponseMeta(context.Background())
+	if _, err := client.ListApps(ctx, "", "", "", "", 10, 0); err != nil {
+		t.Fatalf("ListApps() unexpected error = %v", err)
+	}
+
+	if gotHeader == "" {
+		t.Fatal("expected an X-Request-ID header to be sent even without WithRequestID")
+	}
+	if meta.RequestID != gotHeader {
+		t.Errorf("ResponseMeta.RequestID = %q, want the echoed %q", meta.RequestID, gotHeader)
+	}
+}
+
+// TestLoggerFromContextFallsBackToDefault verifies LoggerFromContext
+// returns a logging.SlogLogger wrapping slog.Default() when no logger was
+// stashed via WithLogger.
+func TestLoggerFromContextFallsBackToDefault(t *testing.T) {
+	got := LoggerFromContext(context.Background())
+	if _, ok := got.(*logging.SlogLogger); !ok {
+		t.Errorf("expected LoggerFromContext to fall back to a *logging.SlogLogger, got %T", got)
+	}
+}
+
+// TestCreateAnalysisAlertDispatchesSignedWebhook verifies CreateAnalysisAlert
+// starts a poll loop that, once the analysis's status changes, POSTs a
+// signed webhook delivery to every configured HTTPWebhooks destination.
+func TestCreateAnalysisAlertDispatchesSignedWebhook(t *testing.T) {
+	var pollCount atomic.Int32
+	formationServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := pollCount.Add(1)
+		status := "Submitted"
+		if n > 1 {
+			status = "Running"
+		}
+		json.NewEncoder(w).Encode(AnalysisStatus{AnalysisID: "analysis-1", Status: status})
+	}))
+	defer formationServer.Close()
+
+	var deliveredBody []byte
+	var deliveredSig string
+	delivered := make(chan struct{}, 1)
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveredBody, _ = io.ReadAll(r.Body)
+		deliveredSig = r.Header.Get("X-Formation-Signature-256")
+		w.WriteHeader(http.StatusOK)
+		select {
+		case delivered <- struct{}{}:
+		default:
+		}
+	}))
+	defer webhookServer.Close()
+
+	origInterval := alertPollInterval
+	alertPollInterval = 10 * time.Millisecond
+	defer func() { alertPollInterval = origInterval }()
+
+	client := NewFormationClient(formationServer.URL, "test-token", "", "", nil, nil)
+	defer client.alerts.Stop()
+
+	alert, err := client.CreateAnalysisAlert(context.Background(), AnalysisAlert{
+		AnalysisID:   "analysis-1",
+		Events:       []AlertEvent{AlertEventStatusChanged},
+		HTTPWebhooks: []HTTPWebhook{{URL: webhookServer.URL}},
+		Secret:       "shh",
+	})
+	if err != nil {
+		t.Fatalf("CreateAnalysisAlert() unexpected error = %v", err)
+	}
+	if alert.ID == "" {
+		t.Error("CreateAnalysisAlert() did not assign an alert id")
+	}
+
+	select {
+	case <-delivered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a webhook delivery")
+	}
+
+	mac := hmac.New(sha256.New, []byte("shh"))
+	mac.Write(deliveredBody)
+	wantSig := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if deliveredSig != wantSig {
+		t.Errorf("webhook signature = %q, want %q", deliveredSig, wantSig)
+	}
+
+	if err := client.DeleteAnalysisAlert(context.Background(), alert.ID); err != nil {
+		t.Errorf("DeleteAnalysisAlert() unexpected error = %v", err)
+	}
+
+	alerts, err := client.ListAnalysisAlerts(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListAnalysisAlerts() unexpected error = %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("ListAnalysisAlerts() after delete = %v, want none", alerts)
+	}
+}
+
+// TestCreateAnalysisAlertRejectsMissingDestination verifies CreateAnalysisAlert
+// refuses an alert with no way to deliver it.
+func TestCreateAnalysisAlertRejectsMissingDestination(t *testing.T) {
+	client := NewFormationClient("http://example.invalid", "test-token", "", "", nil, nil)
+	defer client.alerts.Stop()
+
+	_, err := client.CreateAnalysisAlert(context.Background(), AnalysisAlert{
+		AnalysisID: "analysis-1",
+		Events:     []AlertEvent{AlertEventCompleted},
+	})
+	if err == nil {
+		t.Fatal("CreateAnalysisAlert() expected an error for an alert with no destinations")
+	}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// idleTracker counts requests currently in flight, so FormationClient.Shutdown
+// can wait for them to finish (or a deadline to expire) before closing idle
+// connections, modeled on the kind of idle/in-flight tracker a long-running
+// server uses to drain before exiting. draining lives behind the same mutex
+// as count so tryStart and drain can't race: a request either registers
+// itself before drain is seen, or drain sees it's already draining and
+// tryStart refuses it - there's no window where Shutdown can observe "no
+// requests in flight" while one is still about to start.
+type idleTracker struct {
+	mu           sync.Mutex
+	count        int
+	draining     bool
+	idle         chan struct{} // closed whenever count is 0; replaced when count becomes nonzero
+	lastActivity time.Time
+}
+
+// newIdleTracker returns an idleTracker that starts out idle.
+func newIdleTracker() *idleTracker {
+	idle := make(chan struct{})
+	close(idle)
+	return &idleTracker{idle: idle, lastActivity: time.Now()}
+}
+
+// tryStart records the beginning of a request and reports true, unless
+// drain has already been called, in which case it reports false without
+// counting the request.
+func (t *idleTracker) tryStart() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.count++
+	t.lastActivity = time.Now()
+	if t.count == 1 {
+		t.idle = make(chan struct{})
+	}
+	return true
+}
+
+// done records the completion of a request started with tryStart.
+func (t *idleTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.count--
+	t.lastActivity = time.Now()
+	if t.count == 0 {
+		close(t.idle)
+	}
+}
+
+// drain marks the tracker as draining, so every subsequent tryStart call
+// fails, and returns the idle channel to wait on - guaranteed to reflect
+// every request that had already passed tryStart at the moment drain runs.
+func (t *idleTracker) drain() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.draining = true
+	return t.idle
+}
+
+// wait blocks until idle (as returned by drain) is closed or ctx is done,
+// whichever comes first.
+func (t *idleTracker) wait(ctx context.Context, idle <-chan struct{}) error {
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *idleTracker) lastActive() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastActivity
+}
+
+// idleTrackingBody wraps a response body so idleTracker.done runs exactly
+// once, when the body is closed, rather than when the response headers
+// arrive - a caller streaming a large download is still "in flight" as far
+// as Shutdown is concerned until it finishes reading and closes the body.
+type idleTrackingBody struct {
+	io.ReadCloser
+	tracker *idleTracker
+	once    sync.Once
+}
+
+func (b *idleTrackingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.tracker.done)
+	return err
+}
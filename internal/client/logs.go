@@ -0,0 +1,227 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LogLine is a single log line received from StreamAnalysisLogs. Err is
+// set on a frame that can't be decoded (the stream goes on), and, with
+// the channel closed immediately after, when the stream ends abnormally:
+// a non-following stream's disconnect, or a following stream's
+// disconnect after exhausting reconnect attempts.
+type LogLine struct {
+	Container string
+	Timestamp time.Time
+	Line      string
+	Err       error
+}
+
+// LogStreamOptions controls what StreamAnalysisLogs streams.
+type LogStreamOptions struct {
+	// Follow keeps the stream open for new log lines as they're produced,
+	// like `tail -f`, instead of closing once the currently buffered
+	// output has been delivered.
+	Follow bool
+
+	// Since, if non-zero, restricts the stream to lines logged at or
+	// after this time.
+	Since time.Time
+
+	// Container selects which container's logs to stream, for
+	// multi-container VICE apps; empty selects the app's primary
+	// container.
+	Container string
+
+	// TailLines, if non-zero, limits the initial backlog to the last N
+	// lines before following (or before the stream ends, if Follow is
+	// false).
+	TailLines int
+}
+
+// StreamAnalysisLogs streams log lines for analysisID from
+// /apps/analyses/{id}/logs until ctx is cancelled, or, if opts.Follow is
+// false, until the buffered backlog has been delivered. See watch for the
+// connection negotiation and reconnect semantics this shares; unlike a
+// status watch there's no terminal condition to detect, so a non-following
+// stream simply ends (with no error) once the server closes the
+// connection, and a disconnected non-following stream is reported as an
+// error rather than retried.
+func (c *FormationClient) StreamAnalysisLogs(ctx context.Context, analysisID string, opts LogStreamOptions) (<-chan LogLine, error) {
+	path := fmt.Sprintf("/apps/analyses/%s/logs", analysisID)
+
+	q := url.Values{}
+	if opts.Follow {
+		q.Set("follow", "true")
+	}
+	if !opts.Since.IsZero() {
+		q.Set("since", opts.Since.Format(time.RFC3339))
+	}
+	if opts.Container != "" {
+		q.Set("container", opts.Container)
+	}
+	if opts.TailLines > 0 {
+		q.Set("tail", fmt.Sprintf("%d", opts.TailLines))
+	}
+	if encoded := q.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+
+	resp, isSSE, err := c.connectWatch(ctx, path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(chan LogLine)
+
+	go func() {
+		defer close(lines)
+
+		var lastEventID string
+		failures := 0
+
+		for {
+			streamErr := c.streamLogFrames(ctx, resp, isSSE, &lastEventID, lines)
+			if ctx.Err() != nil {
+				return
+			}
+			if streamErr == nil {
+				// The server closed the stream cleanly: the backlog has
+				// been delivered, and a non-following stream is done.
+				return
+			}
+			if !opts.Follow {
+				select {
+				case lines <- LogLine{Err: fmt.Errorf("log stream disconnected: %w", streamErr)}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			for {
+				failures++
+				if failures > c.retryPolicy.MaxAttempts {
+					select {
+					case lines <- LogLine{Err: fmt.Errorf("log stream disconnected after %d attempts: %w", failures-1, streamErr)}:
+					case <-ctx.Done():
+					}
+					return
+				}
+
+				timer := time.NewTimer(c.retryPolicy.backoff(failures - 1))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+
+				var connectErr error
+				resp, isSSE, connectErr = c.connectWatch(ctx, path, lastEventID)
+				if connectErr != nil {
+					streamErr = connectErr
+					continue
+				}
+				failures = 0
+				break
+			}
+		}
+	}()
+
+	return lines, nil
+}
+
+// streamLogFrames reads log lines from resp's body, sending each parsed
+// LogLine on lines, until the body ends or ctx is done. It returns the
+// error that ended the stream, if any (nil on a clean EOF).
+func (c *FormationClient) streamLogFrames(ctx context.Context, resp *http.Response, isSSE bool, lastEventID *string, lines chan<- LogLine) error {
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var dataLines []string
+	flush := func() error {
+		if len(dataLines) == 0 {
+			return nil
+		}
+		payload := strings.Join(dataLines, "\n")
+		dataLines = nil
+
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(payload), &raw); err != nil {
+			select {
+			case lines <- LogLine{Err: fmt.Errorf("failed to decode log line: %w", err)}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case lines <- logLineFromFrame(raw):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		line := scanner.Text()
+
+		if !isSSE {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			dataLines = []string{line}
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch {
+		case line == "":
+			if err := flush(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "id:"):
+			*lastEventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			// Other SSE fields (event:, retry:, comments) carry nothing
+			// the logs endpoint needs and are ignored.
+		}
+	}
+
+	return scanner.Err()
+}
+
+// logLineFromFrame builds a LogLine from a decoded log-stream frame.
+func logLineFromFrame(raw map[string]interface{}) LogLine {
+	line := LogLine{Timestamp: time.Now()}
+
+	if v, ok := raw["container"].(string); ok {
+		line.Container = v
+	}
+	if v, ok := raw["line"].(string); ok {
+		line.Line = v
+	}
+	if v, ok := raw["timestamp"].(string); ok {
+		if ts, err := time.Parse(time.RFC3339, v); err == nil {
+			line.Timestamp = ts
+		}
+	}
+
+	return line
+}
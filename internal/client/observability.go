@@ -0,0 +1,407 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Observer receives lifecycle hooks around every FormationClient request,
+// for telemetry that this package's own tests don't otherwise exercise:
+// latency, error rates, and retry counts. op identifies the logical
+// operation (e.g. "Login", "ListApps", "UploadFileStream.Chunk"), the
+// same string across all three hooks for a given call. OnRequestStart and
+// OnRequestEnd each fire exactly once per logical call, regardless of how
+// many attempts doRequest makes internally; OnRetry fires once per retry
+// in between. Implementations must be safe for concurrent use and should
+// not block, since they run inline on the calling goroutine.
+type Observer interface {
+	// OnRequestStart fires just before the first attempt is sent. req is
+	// a representative request (method and URL only) for logging/tracing
+	// purposes, not necessarily the exact request object placed on the
+	// wire for every retry; headers set on it are copied onto every
+	// attempt doRequest sends.
+	OnRequestStart(ctx context.Context, op string, req *http.Request)
+
+	// OnRequestEnd fires once the call finishes, successfully or not.
+	// resp is nil whenever err is non-nil, except for Login, which passes
+	// its non-200 or undecodable response along with the error.
+	OnRequestEnd(ctx context.Context, op string, resp *http.Response, err error, dur time.Duration)
+
+	// OnRetry fires before each retry (not the first attempt), once
+	// doRequest has decided to retry following reason, after delay.
+	OnRetry(ctx context.Context, op string, attempt int, delay time.Duration, reason error)
+}
+
+// noopObserver is the Observer FormationClient uses until SetObserver is
+// called.
+type noopObserver struct{}
+
+func (noopObserver) OnRequestStart(context.Context, string, *http.Request)                      {}
+func (noopObserver) OnRequestEnd(context.Context, string, *http.Response, error, time.Duration) {}
+func (noopObserver) OnRetry(context.Context, string, int, time.Duration, error)                 {}
+
+// MultiObserver fans every hook out to each Observer in order, so e.g. a
+// SlogObserver, a PrometheusObserver, and an OTelObserver can all be
+// installed at once via SetObserver(MultiObserver{...}).
+type MultiObserver []Observer
+
+func (m MultiObserver) OnRequestStart(ctx context.Context, op string, req *http.Request) {
+	for _, o := range m {
+		o.OnRequestStart(ctx, op, req)
+	}
+}
+
+func (m MultiObserver) OnRequestEnd(ctx context.Context, op string, resp *http.Response, err error, dur time.Duration) {
+	for _, o := range m {
+		o.OnRequestEnd(ctx, op, resp, err, dur)
+	}
+}
+
+func (m MultiObserver) OnRetry(ctx context.Context, op string, attempt int, delay time.Duration, reason error) {
+	for _, o := range m {
+		o.OnRetry(ctx, op, attempt, delay, reason)
+	}
+}
+
+// SlogObserver is an Observer that logs each call's lifecycle via
+// log/slog. It never logs the Authorization header, and redacts any
+// basic-auth credentials embedded in a request URL via url.Redacted.
+type SlogObserver struct {
+	logger *slog.Logger
+}
+
+// NewSlogObserver creates a SlogObserver. A nil logger uses slog.Default().
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogObserver{logger: logger}
+}
+
+func (o *SlogObserver) OnRequestStart(ctx context.Context, op string, req *http.Request) {
+	o.logger.Debug("formation_client request started", requestAttrs(op, req)...)
+}
+
+func (o *SlogObserver) OnRequestEnd(ctx context.Context, op string, resp *http.Response, err error, dur time.Duration) {
+	attrs := []any{"op", op, "duration", dur}
+	if resp != nil {
+		attrs = append(attrs, "status", resp.StatusCode)
+	}
+	if err != nil {
+		o.logger.Error("formation_client request failed", append(attrs, "error", err)...)
+		return
+	}
+	o.logger.Info("formation_client request completed", attrs...)
+}
+
+func (o *SlogObserver) OnRetry(ctx context.Context, op string, attempt int, delay time.Duration, reason error) {
+	o.logger.Warn("formation_client retrying request", "op", op, "attempt", attempt, "delay", delay, "reason", reason)
+}
+
+// requestAttrs builds the slog attributes for req, redacting anything
+// that could leak a credential: the request URL's userinfo (via
+// url.Redacted) and the presence (never the value) of an Authorization
+// header.
+func requestAttrs(op string, req *http.Request) []any {
+	attrs := []any{"op", op, "method", req.Method, "url", req.URL.Redacted()}
+	if req.Header.Get("Authorization") != "" {
+		attrs = append(attrs, "authorization", "[redacted]")
+	}
+	return attrs
+}
+
+// defaultDurationBuckets are the histogram buckets PrometheusObserver
+// uses when none are supplied, matching Traefik's default latency
+// buckets.
+var defaultDurationBuckets = []float64{0.1, 0.3, 1.2, 5}
+
+type promCounterKey struct {
+	op   string
+	code string
+}
+
+type promRetryKey struct {
+	op     string
+	reason string
+}
+
+// PrometheusObserver is an Observer that records formation_client_requests_total,
+// formation_client_request_duration_seconds, and formation_client_retries_total.
+// It hand-rolls the small subset of the Prometheus text exposition format
+// formation-mcp needs rather than depending on client_golang, since this
+// module tree has no go.mod to vendor one.
+type PrometheusObserver struct {
+	buckets []float64
+
+	mu            sync.Mutex
+	requestsTotal map[promCounterKey]int64
+	retriesTotal  map[promRetryKey]int64
+	durationSum   map[string]float64
+	durationCount map[string]int64
+	bucketCounts  map[string][]int64 // op -> cumulative count per buckets[i], "<=" semantics
+}
+
+// NewPrometheusObserver creates a PrometheusObserver. A nil or empty
+// buckets slice uses defaultDurationBuckets.
+func NewPrometheusObserver(buckets []float64) *PrometheusObserver {
+	if len(buckets) == 0 {
+		buckets = defaultDurationBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &PrometheusObserver{
+		buckets:       sorted,
+		requestsTotal: make(map[promCounterKey]int64),
+		retriesTotal:  make(map[promRetryKey]int64),
+		durationSum:   make(map[string]float64),
+		durationCount: make(map[string]int64),
+		bucketCounts:  make(map[string][]int64),
+	}
+}
+
+func (o *PrometheusObserver) OnRequestStart(context.Context, string, *http.Request) {}
+
+// OnRequestEnd implements Observer.
+func (o *PrometheusObserver) OnRequestEnd(ctx context.Context, op string, resp *http.Response, err error, dur time.Duration) {
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	seconds := dur.Seconds()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.requestsTotal[promCounterKey{op: op, code: code}]++
+	o.durationSum[op] += seconds
+	o.durationCount[op]++
+
+	counts, ok := o.bucketCounts[op]
+	if !ok {
+		counts = make([]int64, len(o.buckets))
+		o.bucketCounts[op] = counts
+	}
+	for i, le := range o.buckets {
+		if seconds <= le {
+			counts[i]++
+		}
+	}
+}
+
+// OnRetry implements Observer.
+func (o *PrometheusObserver) OnRetry(ctx context.Context, op string, attempt int, delay time.Duration, reason error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.retriesTotal[promRetryKey{op: op, reason: retryReasonLabel(reason)}]++
+}
+
+// retryStatusPattern extracts the status code doRequestOnce's error
+// messages embed (see its "request failed with status %d" format).
+var retryStatusPattern = regexp.MustCompile(`status (\d+)`)
+
+// retryReasonLabel collapses reason into a low-cardinality label value;
+// the raw error string would give one series per distinct error message.
+func retryReasonLabel(reason error) string {
+	if reason == nil {
+		return "unknown"
+	}
+	if m := retryStatusPattern.FindStringSubmatch(reason.Error()); m != nil {
+		return "status_" + m[1]
+	}
+	return "network_error"
+}
+
+// WriteMetricsTo writes o's metrics in Prometheus text exposition format,
+// for a handler to serve on a /metrics endpoint. Named WriteMetricsTo
+// rather than WriteTo since its (io.Writer) error signature doesn't
+// satisfy io.WriterTo's (io.Writer) (int64, error), which go vet's
+// stdmethods check flags on a method actually named WriteTo.
+func (o *PrometheusObserver) WriteMetricsTo(w io.Writer) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "# HELP formation_client_requests_total Total Formation API requests by operation and status code.")
+	fmt.Fprintln(bw, "# TYPE formation_client_requests_total counter")
+	for k, v := range o.requestsTotal {
+		fmt.Fprintf(bw, "formation_client_requests_total{op=%q,code=%q} %d\n", k.op, k.code, v)
+	}
+
+	fmt.Fprintln(bw, "# HELP formation_client_request_duration_seconds Formation API request duration in seconds.")
+	fmt.Fprintln(bw, "# TYPE formation_client_request_duration_seconds histogram")
+	for op, counts := range o.bucketCounts {
+		for i, le := range o.buckets {
+			fmt.Fprintf(bw, "formation_client_request_duration_seconds_bucket{op=%q,le=%q} %d\n", op, strconv.FormatFloat(le, 'g', -1, 64), counts[i])
+		}
+		fmt.Fprintf(bw, "formation_client_request_duration_seconds_bucket{op=%q,le=\"+Inf\"} %d\n", op, o.durationCount[op])
+		fmt.Fprintf(bw, "formation_client_request_duration_seconds_sum{op=%q} %g\n", op, o.durationSum[op])
+		fmt.Fprintf(bw, "formation_client_request_duration_seconds_count{op=%q} %d\n", op, o.durationCount[op])
+	}
+
+	fmt.Fprintln(bw, "# HELP formation_client_retries_total Total Formation API request retries by operation and reason.")
+	fmt.Fprintln(bw, "# TYPE formation_client_retries_total counter")
+	for k, v := range o.retriesTotal {
+		fmt.Fprintf(bw, "formation_client_retries_total{op=%q,reason=%q} %d\n", k.op, k.reason, v)
+	}
+
+	return bw.Flush()
+}
+
+// Span is a single finished span recorded by OTelObserver.
+type Span struct {
+	Name       string
+	TraceID    string
+	SpanID     string
+	ParentID   string
+	StartTime  time.Time
+	Duration   time.Duration
+	Attributes map[string]string
+	Err        error
+}
+
+// SpanExporter receives each span OTelObserver finishes. It mirrors the
+// shape of an OpenTelemetry SpanExporter closely enough to adapt to one
+// later; OTelObserver doesn't depend on go.opentelemetry.io itself, since
+// this module tree has no go.mod to vendor it.
+type SpanExporter interface {
+	ExportSpan(span Span)
+}
+
+// OTelObserver is an Observer that opens a span per call with http.* and
+// formation.op attributes, and propagates a W3C Trace Context
+// (traceparent) header outward on the request it instruments.
+type OTelObserver struct {
+	Exporter SpanExporter
+
+	mu     sync.Mutex
+	active map[context.Context]*spanState
+}
+
+type spanState struct {
+	name      string
+	traceID   string
+	spanID    string
+	parentID  string
+	startTime time.Time
+	attrs     map[string]string
+}
+
+// NewOTelObserver creates an OTelObserver. A nil exporter still
+// propagates traceparent headers; it just drops the finished spans.
+func NewOTelObserver(exporter SpanExporter) *OTelObserver {
+	return &OTelObserver{Exporter: exporter, active: make(map[context.Context]*spanState)}
+}
+
+// OnRequestStart implements Observer: it starts the span, continuing an
+// existing trace if ctx carries one (see ContextWithTraceParent), and
+// sets the traceparent header on req so it propagates to the server.
+func (o *OTelObserver) OnRequestStart(ctx context.Context, op string, req *http.Request) {
+	traceID, parentID := traceParentFromContext(ctx)
+	if traceID == "" {
+		traceID = randomHexID(16)
+	}
+	spanID := randomHexID(8)
+
+	state := &spanState{
+		name:      op,
+		traceID:   traceID,
+		spanID:    spanID,
+		parentID:  parentID,
+		startTime: time.Now(),
+		attrs: map[string]string{
+			"formation.op": op,
+			"http.method":  req.Method,
+			"http.url":     req.URL.Redacted(),
+		},
+	}
+	req.Header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", traceID, spanID))
+
+	o.mu.Lock()
+	o.active[ctx] = state
+	o.mu.Unlock()
+}
+
+// OnRequestEnd implements Observer: it finishes the span started by the
+// matching OnRequestStart call (same ctx) and exports it.
+func (o *OTelObserver) OnRequestEnd(ctx context.Context, op string, resp *http.Response, err error, dur time.Duration) {
+	o.mu.Lock()
+	state, ok := o.active[ctx]
+	delete(o.active, ctx)
+	o.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if resp != nil {
+		state.attrs["http.status_code"] = strconv.Itoa(resp.StatusCode)
+	}
+
+	if o.Exporter != nil {
+		o.Exporter.ExportSpan(Span{
+			Name:       state.name,
+			TraceID:    state.traceID,
+			SpanID:     state.spanID,
+			ParentID:   state.parentID,
+			StartTime:  state.startTime,
+			Duration:   dur,
+			Attributes: state.attrs,
+			Err:        err,
+		})
+	}
+}
+
+// OnRetry implements Observer; retries don't get their own span, since
+// the span OnRequestStart opened covers the whole logical call including
+// its retries.
+func (o *OTelObserver) OnRetry(context.Context, string, int, time.Duration, error) {}
+
+// traceParentContextKey is the context key ContextWithTraceParent uses.
+type traceParentContextKey struct{}
+
+// ContextWithTraceParent returns a context carrying an existing W3C
+// traceparent value (e.g. one received from an upstream MCP caller), so
+// OTelObserver continues that trace instead of starting a new one.
+func ContextWithTraceParent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceParentContextKey{}, traceparent)
+}
+
+// traceParentPattern parses a W3C traceparent header:
+// version-traceid-spanid-flags.
+var traceParentPattern = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// traceParentFromContext extracts the trace id and parent span id from a
+// traceparent value stashed via ContextWithTraceParent, if any.
+func traceParentFromContext(ctx context.Context) (traceID, parentSpanID string) {
+	raw, _ := ctx.Value(traceParentContextKey{}).(string)
+	m := traceParentPattern.FindStringSubmatch(raw)
+	if m == nil {
+		return "", ""
+	}
+	return m[1], m[2]
+}
+
+// randomHexID returns n random bytes hex-encoded, for a trace or span id.
+// It falls back to a fixed all-zero id (same shape OpenTelemetry uses for
+// an invalid id) if the system's CSPRNG is unavailable, rather than
+// failing a request purely because tracing couldn't mint an id.
+func randomHexID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString(make([]byte, n))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"io"
+	"time"
+)
+
+// progressByteInterval and progressTimeInterval bound how often
+// progressThrottler.report invokes its callback: at most once per this many
+// bytes transferred, or this much time elapsed, whichever comes first.
+const (
+	progressByteInterval = 256 * 1024
+	progressTimeInterval = 250 * time.Millisecond
+)
+
+// progressThrottler decides whether enough bytes or time has passed since
+// the last report to justify calling onProgress again, so a caller copying
+// in small increments (e.g. upload chunks, or a download's io.Copy buffer)
+// doesn't flood onProgress with a call per increment.
+type progressThrottler struct {
+	onProgress func(n, total int64)
+	total      int64
+
+	lastReported int64
+	lastTime     time.Time
+}
+
+// newProgressThrottler creates a progressThrottler that reports progress
+// toward total via onProgress. onProgress may be nil, in which case report
+// is a no-op.
+func newProgressThrottler(onProgress func(n, total int64), total int64) *progressThrottler {
+	return &progressThrottler{onProgress: onProgress, total: total}
+}
+
+// report invokes onProgress with n (cumulative bytes transferred so far) if
+// final is true, or if at least progressByteInterval bytes or
+// progressTimeInterval has passed since the last call that fired.
+func (p *progressThrottler) report(n int64, final bool) {
+	if p.onProgress == nil {
+		return
+	}
+	if !final && n-p.lastReported < progressByteInterval && time.Since(p.lastTime) < progressTimeInterval {
+		return
+	}
+	p.lastReported = n
+	p.lastTime = time.Now()
+	p.onProgress(n, p.total)
+}
+
+// progressWriter wraps w, reporting cumulative bytes written to throttler
+// after each Write.
+type progressWriter struct {
+	w         io.Writer
+	throttler *progressThrottler
+	written   int64
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	if n > 0 {
+		pw.written += int64(n)
+		pw.throttler.report(pw.written, false)
+	}
+	return n, err
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/logging"
+)
+
+// requestIDContextKey is the context key WithRequestID uses.
+type requestIDContextKey struct{}
+
+// loggerContextKey is the context key WithLogger uses.
+type loggerContextKey struct{}
+
+// crockfordAlphabet is the Crockford base32 alphabet ULIDs are encoded
+// with - it excludes I, L, O, and U to avoid confusion with 1, 1, 0, and V
+// when an id is read aloud or copied by hand.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// WithRequestID returns a context carrying id as the correlation id every
+// outgoing FormationClient request made with it sends as X-Request-ID, so
+// the HTTP hop can be tied back to the MCP tool invocation or workflow
+// step that triggered it. If id is empty, a ULID is generated; read it
+// back out afterward with RequestIDFromContext.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		id = newULID()
+	}
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext returns the request id stashed by WithRequestID, if
+// any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDContextKey{}).(string)
+	return id, ok
+}
+
+// ensureRequestID returns ctx unchanged if it already carries a request
+// id, otherwise a context with a freshly generated one - so every outgoing
+// request carries an X-Request-ID even when the caller never called
+// WithRequestID itself.
+func ensureRequestID(ctx context.Context) context.Context {
+	if _, ok := RequestIDFromContext(ctx); ok {
+		return ctx
+	}
+	return WithRequestID(ctx, "")
+}
+
+// WithLogger returns a context carrying logger, for doRequest and the
+// workflows package to log through instead of slog's process-global
+// default - typically a logger already annotated with request_id and a
+// tool/operation name, so every log line produced while handling one call
+// is connected. logger can be any logging.Logger backend, not just the
+// built-in slog one - see logging.Logger.
+func WithLogger(ctx context.Context, logger logging.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// loggerFromContext returns the logger stashed by WithLogger, if any.
+func loggerFromContext(ctx context.Context) (logging.Logger, bool) {
+	logger, ok := ctx.Value(loggerContextKey{}).(logging.Logger)
+	if !ok || logger == nil {
+		return nil, false
+	}
+	return logger, true
+}
+
+// LoggerFromContext returns the logger stashed by WithLogger, or a
+// logging.SlogLogger wrapping slog.Default() if none was set.
+func LoggerFromContext(ctx context.Context) logging.Logger {
+	if logger, ok := loggerFromContext(ctx); ok {
+		return logger
+	}
+	return logging.NewSlogLogger(slog.Default())
+}
+
+// responseMetaContextKey is the context key WithResponseMeta uses.
+type responseMetaContextKey struct{}
+
+// ResponseMeta captures metadata about the HTTP response of a single
+// FormationClient call.
+type ResponseMeta struct {
+	// RequestID is the server's X-Request-ID response header - normally an
+	// echo of the id FormationClient sent, but left as whatever the server
+	// actually returned in case it mints its own.
+	RequestID string
+}
+
+// WithResponseMeta returns a context paired with a *ResponseMeta that
+// doRequest and Login populate from the response headers of whichever
+// client call ctx is used for, mirroring how WithRetry/preflightTracker
+// thread an out-param through context: use the returned ctx for exactly
+// one client call, then read meta back afterward.
+func WithResponseMeta(ctx context.Context) (context.Context, *ResponseMeta) {
+	meta := &ResponseMeta{}
+	return context.WithValue(ctx, responseMetaContextKey{}, meta), meta
+}
+
+// responseMetaFromContext returns the *ResponseMeta stashed by
+// WithResponseMeta, or nil if the caller isn't collecting one.
+func responseMetaFromContext(ctx context.Context) *ResponseMeta {
+	meta, _ := ctx.Value(responseMetaContextKey{}).(*ResponseMeta)
+	return meta
+}
+
+// newULID generates a ULID: a 48-bit millisecond timestamp followed by 80
+// bits of randomness, both Crockford base32 encoded into a 26-character id
+// that sorts lexicographically by creation time. Falls back to all-zero
+// randomness (still fine for log correlation, just not collision-proof) if
+// the system's CSPRNG is unavailable, rather than failing a request purely
+// because it couldn't mint a trace id.
+func newULID() string {
+	var b [16]byte
+
+	ms := uint64(time.Now().UnixMilli())
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+
+	_, _ = rand.Read(b[6:])
+
+	return encodeCrockford32(b[:])
+}
+
+// encodeCrockford32 encodes b (the 16 bytes of a ULID) as 26 Crockford
+// base32 characters, 5 bits at a time.
+func encodeCrockford32(b []byte) string {
+	var out strings.Builder
+	out.Grow(26)
+
+	var bits uint64
+	var bitCount uint
+	for _, by := range b {
+		bits = bits<<8 | uint64(by)
+		bitCount += 8
+		for bitCount >= 5 {
+			bitCount -= 5
+			out.WriteByte(crockfordAlphabet[(bits>>bitCount)&0x1F])
+		}
+	}
+	if bitCount > 0 {
+		out.WriteByte(crockfordAlphabet[(bits<<(5-bitCount))&0x1F])
+	}
+
+	return out.String()
+}
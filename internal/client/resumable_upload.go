@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResumableUploadOptions configures UploadLocalFile and ResumeUpload.
+type ResumableUploadOptions struct {
+	// ChunkSize is passed through to UploadFileStream; see
+	// UploadOptions.ChunkSize.
+	ChunkSize int64
+
+	// StateDir is where UploadLocalFile persists the manifest and chunk
+	// progress an interrupted upload needs to resume. Required: unlike
+	// UploadFileStream's in-memory default, a resumable upload with
+	// nowhere to persist state can't survive the process restart it
+	// exists to handle.
+	StateDir string
+
+	// OnProgress, if non-nil, is passed through to UploadFileStream; see
+	// UploadOptions.OnProgress.
+	OnProgress func(written, total int64)
+}
+
+// localUploadManifest is the sidecar UploadLocalFile writes under
+// ResumableUploadOptions.StateDir, recording the one thing UploadFileStream's
+// own UploadStateStore entry doesn't: which local file on disk a given
+// remote path's in-progress upload is reading from, so a later process can
+// find its way back to it via ResumeUpload.
+type localUploadManifest struct {
+	LocalPath  string
+	RemotePath string
+}
+
+// UploadLocalFile uploads the local file at localPath to remotePath using
+// UploadFileStream's chunked, resumable transfer (Content-Range PUTs under
+// a shared upload session, verified on completion by a SHA-256 digest over
+// the whole content - see UploadFileStream's comment for the full wire
+// protocol). It persists a small manifest alongside UploadFileStream's own
+// progress tracking under opts.StateDir, so that if the process crashes
+// mid-upload, a later call to ResumeUpload with the manifest's path picks
+// the upload back up without the caller needing to remember which local
+// file it came from.
+//
+// This intentionally reuses UploadFileStream's existing resume mechanism
+// rather than a separate chunk-numbered wire protocol with its own
+// finalize step: the server already only understands the Content-Range/
+// X-Upload-Session scheme UploadFileStream speaks, and maintaining a
+// second, parallel chunked-upload protocol alongside it would fork how
+// this client uploads large files for no functional benefit.
+func UploadLocalFile(ctx context.Context, c FormationAPIClient, localPath, remotePath string, opts ResumableUploadOptions) error {
+	if opts.StateDir == "" {
+		return fmt.Errorf("UploadLocalFile: StateDir is required so the upload can resume after a crash")
+	}
+	if err := os.MkdirAll(opts.StateDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create resumable upload state dir: %w", err)
+	}
+
+	manifest := localUploadManifest{LocalPath: localPath, RemotePath: remotePath}
+	mp := localManifestPath(opts.StateDir, remotePath)
+	if err := writeLocalUploadManifest(mp, manifest); err != nil {
+		return err
+	}
+
+	if err := uploadLocalFile(ctx, c, manifest, opts); err != nil {
+		return err
+	}
+	os.Remove(mp)
+	return nil
+}
+
+// ResumeUpload continues an upload UploadLocalFile started but didn't
+// finish, using the manifest it left at manifestPath to recover the local
+// and remote paths involved, then uploading exactly the way UploadLocalFile
+// does - UploadFileStream's own UploadStateStore already recognizes the
+// interrupted attempt's progress and resumes from there.
+func ResumeUpload(ctx context.Context, c FormationAPIClient, manifestPath string, opts ResumableUploadOptions) error {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read upload manifest: %w", err)
+	}
+	var manifest localUploadManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse upload manifest: %w", err)
+	}
+
+	if err := uploadLocalFile(ctx, c, manifest, opts); err != nil {
+		return err
+	}
+	os.Remove(manifestPath)
+	return nil
+}
+
+func uploadLocalFile(ctx context.Context, c FormationAPIClient, manifest localUploadManifest, opts ResumableUploadOptions) error {
+	f, err := os.Open(manifest.LocalPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", manifest.LocalPath, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", manifest.LocalPath, err)
+	}
+
+	store := NewFileUploadStateStore(filepath.Join(opts.StateDir, "progress.json"))
+
+	return c.UploadFileStream(ctx, manifest.RemotePath, f, info.Size(), UploadOptions{
+		ChunkSize:  opts.ChunkSize,
+		StateStore: store,
+		OnProgress: opts.OnProgress,
+	})
+}
+
+func writeLocalUploadManifest(path string, manifest localUploadManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal upload manifest: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write upload manifest: %w", err)
+	}
+	return nil
+}
+
+// localManifestPath returns the manifest path UploadLocalFile writes for an
+// upload rooted at stateDir, named after remotePath so concurrent uploads
+// to different remote destinations don't collide.
+func localManifestPath(stateDir, remotePath string) string {
+	name := strings.ReplaceAll(strings.TrimPrefix(remotePath, "/"), "/", "_")
+	return filepath.Join(stateDir, name+".upload.json")
+}
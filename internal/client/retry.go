@@ -0,0 +1,260 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy configures how FormationClient retries a request that fails
+// with a status code in RetryableStatusCodes or a network error. Attempts
+// are spaced by exponential backoff with jitter:
+//
+//	delay = min(BaseDelay * 2^attempt, MaxDelay) * (1 + rand()*JitterFraction)
+//
+// Modeled on client-go/rest's retry behavior.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// A value <= 1 disables retries.
+	MaxAttempts int
+
+	// BaseDelay is the backoff delay before the first retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the backoff delay, before jitter is applied.
+	MaxDelay time.Duration
+
+	// JitterFraction randomizes each delay by up to this fraction (e.g. 0.2
+	// spreads delays over [delay, delay*1.2]) so concurrent callers don't
+	// retry in lockstep.
+	JitterFraction float64
+
+	// RetryableStatusCodes lists the HTTP status codes worth retrying.
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryPolicy returns the retry policy FormationClient uses when
+// none is supplied: 3 attempts, 200ms-5s exponential backoff with 20%
+// jitter, retrying the request-timeout, too-early and rate-limit statuses
+// (408/425/429) plus the 500/502/503/504 server errors.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		BaseDelay:      200 * time.Millisecond,
+		MaxDelay:       5 * time.Second,
+		JitterFraction: 0.2,
+		RetryableStatusCodes: []int{
+			http.StatusRequestTimeout,
+			http.StatusTooEarly,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func (p *RetryPolicy) retryableStatus(status int) bool {
+	for _, s := range p.RetryableStatusCodes {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff computes the delay before the retry following the given 0-indexed
+// attempt number.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(attempt)))
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return time.Duration(float64(delay) * (1 + rand.Float64()*p.JitterFraction))
+}
+
+// parseRetryAfter parses a Retry-After header value, which per RFC 7231 is
+// either a number of seconds or an HTTP-date. It reports false if header is
+// empty or unparseable.
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(header); err == nil {
+		if delay := time.Until(t); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// preflightTracker records whether a request's body ever started being
+// read by the transport, so doRequestAttempts can tell a failure before any
+// bytes went out (safe to retry, even for a non-idempotent method - the
+// server never saw the request) from a failure partway through sending the
+// body (unsafe - it may already have been applied).
+type preflightTracker struct {
+	started atomic.Bool
+}
+
+// preflightTrackerKey is the context key a request's preflightTracker is
+// attached under, for trackingRoundTripper to find.
+type preflightTrackerKey struct{}
+
+// trackingRoundTripper wraps next, flipping the preflightTracker attached to
+// a request's context (if any) as soon as that request's body starts being
+// read, i.e. just before its bytes go out on the wire.
+type trackingRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (t *trackingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if tracker, ok := req.Context().Value(preflightTrackerKey{}).(*preflightTracker); ok && req.Body != nil {
+		req.Body = &trackingBody{ReadCloser: req.Body, tracker: tracker}
+	}
+
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(req)
+}
+
+// trackingBody marks its tracker started on the first successful Read, the
+// same moment net/http's transport begins writing the body to the
+// connection.
+type trackingBody struct {
+	io.ReadCloser
+	tracker *preflightTracker
+}
+
+func (b *trackingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.tracker.started.Store(true)
+	}
+	return n, err
+}
+
+// isPreflightNetworkError reports whether err looks like a failure that
+// happened before any request data could have reached the server: a dial
+// failure, or an EOF encountered while the connection was still being
+// established. Combined with a preflightTracker that never started, this
+// identifies requests safe to retry even when the method isn't normally
+// idempotent.
+func isPreflightNetworkError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, io.EOF)
+}
+
+// retryContextKey is the context key for WithRetry's opt-in flag.
+type retryContextKey struct{}
+
+// WithRetry returns a context that opts non-idempotent requests (POST, PUT,
+// DELETE) made with it into FormationClient's retry policy. GET and HEAD
+// requests are always eligible for retry; other methods are retried only
+// when the caller confirms it's safe to replay them.
+func WithRetry(ctx context.Context, retry bool) context.Context {
+	return context.WithValue(ctx, retryContextKey{}, retry)
+}
+
+// retryAllowed reports whether requests using method may be retried: always
+// for idempotent methods, otherwise only if the context opted in via
+// WithRetry.
+func retryAllowed(ctx context.Context, method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	}
+	retry, _ := ctx.Value(retryContextKey{}).(bool)
+	return retry
+}
+
+// RateLimitPolicy configures FormationClient's client-side token-bucket
+// rate limiter.
+type RateLimitPolicy struct {
+	// QPS is the steady-state rate at which tokens refill, in requests per
+	// second.
+	QPS float64
+
+	// Burst is the bucket size, i.e. the largest burst of requests allowed
+	// before the limiter starts throttling to QPS.
+	Burst int
+}
+
+// DefaultRateLimitPolicy returns the rate limit policy FormationClient uses
+// when none is supplied: 10 requests/second with bursts up to 20, enough
+// headroom for interactive use while keeping bulk operations (e.g.
+// ListAnalyses polling loops) from hammering the Formation server.
+func DefaultRateLimitPolicy() *RateLimitPolicy {
+	return &RateLimitPolicy{QPS: 10, Burst: 20}
+}
+
+// rateLimiter is a token-bucket limiter: tokens accumulate at qps per
+// second up to burst capacity, and wait spends one token per request,
+// blocking the caller until one is available.
+type rateLimiter struct {
+	mu         sync.Mutex
+	qps        float64
+	burst      float64
+	tokens     float64
+	lastRefill time.Time
+}
+
+func newRateLimiter(policy *RateLimitPolicy) *rateLimiter {
+	return &rateLimiter{
+		qps:        policy.QPS,
+		burst:      float64(policy.Burst),
+		tokens:     float64(policy.Burst),
+		lastRefill: time.Now(),
+	}
+}
+
+// wait blocks until a token is available, or ctx is done.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		now := time.Now()
+		r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.lastRefill).Seconds()*r.qps)
+		r.lastRefill = now
+
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			return nil
+		}
+
+		wait := time.Duration((1 - r.tokens) / r.qps * float64(time.Second))
+		r.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileTokenRecord is the on-disk (or in-keyring) JSON shape for an
+// OAuthToken, shared by FileTokenStore and KeyringTokenStore.
+type fileTokenRecord struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// FileTokenStore persists OAuthToken values as one JSON file per key under
+// Dir, so a refresh token survives a process restart without the caller
+// needing a keyring. Files are written 0600 and Dir is created 0700, since
+// the contents are a live refresh token.
+type FileTokenStore struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// NewFileTokenStore creates a FileTokenStore rooted at dir. dir is created
+// on first Save if it doesn't already exist.
+func NewFileTokenStore(dir string) *FileTokenStore {
+	return &FileTokenStore{Dir: dir}
+}
+
+// sanitizeTokenStoreKey replaces path-separator-like characters in key so
+// it's safe to use as a file name.
+func sanitizeTokenStoreKey(key string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(key)
+}
+
+func (s *FileTokenStore) path(key string) string {
+	return filepath.Join(s.Dir, sanitizeTokenStoreKey(key)+".json")
+}
+
+// Get implements TokenStore.
+func (s *FileTokenStore) Get(key string) (OAuthToken, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return OAuthToken{}, false, nil
+	}
+	if err != nil {
+		return OAuthToken{}, false, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var record fileTokenRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return OAuthToken{}, false, fmt.Errorf("failed to decode token file: %w", err)
+	}
+	return OAuthToken{AccessToken: record.AccessToken, RefreshToken: record.RefreshToken, Expiry: record.Expiry}, true, nil
+}
+
+// Save implements TokenStore.
+func (s *FileTokenStore) Save(key string, token OAuthToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create token store directory: %w", err)
+	}
+
+	data, err := json.Marshal(fileTokenRecord{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	if err := os.WriteFile(s.path(key), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// KeyringTokenStore persists OAuthToken values in the OS-native
+// credential store (macOS Keychain, or the Secret Service via
+// secret-tool on Linux), so a refresh token survives a process restart
+// without a plaintext file on disk. Service scopes entries within the
+// keyring the way FileTokenStore's Dir scopes files on disk.
+//
+// There's no pure-Go keyring access in the standard library, and this
+// repo takes no third-party dependencies, so both backends shell out to
+// the platform's own credential-store CLI (see keyringGet/keyringSet in
+// the per-OS files); an unsupported platform returns an error from
+// Get/Save rather than silently falling back to a less secure store.
+type KeyringTokenStore struct {
+	Service string
+}
+
+// NewKeyringTokenStore creates a KeyringTokenStore scoped to service, e.g.
+// "formation-mcp".
+func NewKeyringTokenStore(service string) *KeyringTokenStore {
+	return &KeyringTokenStore{Service: service}
+}
+
+// Get implements TokenStore.
+func (s *KeyringTokenStore) Get(key string) (OAuthToken, bool, error) {
+	raw, ok, err := keyringGet(s.Service, key)
+	if err != nil || !ok {
+		return OAuthToken{}, ok, err
+	}
+
+	var record fileTokenRecord
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		return OAuthToken{}, false, fmt.Errorf("failed to decode keyring entry: %w", err)
+	}
+	return OAuthToken{AccessToken: record.AccessToken, RefreshToken: record.RefreshToken, Expiry: record.Expiry}, true, nil
+}
+
+// Save implements TokenStore.
+func (s *KeyringTokenStore) Save(key string, token OAuthToken) error {
+	data, err := json.Marshal(fileTokenRecord{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+	return keyringSet(s.Service, key, string(data))
+}
@@ -0,0 +1,37 @@
+//go:build darwin
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keyringGet looks up service/key in the macOS Keychain via the security
+// CLI. Exit status 44 means "item not found", which security doesn't
+// otherwise distinguish from other failures in its output.
+func keyringGet(service, key string) (string, bool, error) {
+	cmd := exec.Command("security", "find-generic-password", "-s", service, "-a", key, "-w")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 44 {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("security find-generic-password failed: %w", err)
+	}
+	return strings.TrimRight(out.String(), "\n"), true, nil
+}
+
+// keyringSet writes service/key into the macOS Keychain, via security.
+// -U updates an existing entry in place instead of failing with a
+// duplicate-item error.
+func keyringSet(service, key, value string) error {
+	cmd := exec.Command("security", "add-generic-password", "-s", service, "-a", key, "-w", value, "-U")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("security add-generic-password failed: %w: %s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build linux
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keyringGet looks up service/key in the Secret Service (GNOME Keyring,
+// KWallet, etc.) via secret-tool. Exit status 1 means "not found", which
+// secret-tool doesn't otherwise distinguish from other failures.
+func keyringGet(service, key string) (string, bool, error) {
+	cmd := exec.Command("secret-tool", "lookup", "service", service, "account", key)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("secret-tool lookup failed: %w", err)
+	}
+	return strings.TrimRight(out.String(), "\n"), true, nil
+}
+
+// keyringSet writes service/key into the Secret Service via secret-tool,
+// passing value on stdin rather than as an argument so it doesn't show up
+// in a process listing.
+func keyringSet(service, key, value string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", fmt.Sprintf("%s (%s)", service, key), "service", service, "account", key)
+	cmd.Stdin = strings.NewReader(value)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store failed: %w: %s", err, out)
+	}
+	return nil
+}
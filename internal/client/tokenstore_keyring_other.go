@@ -0,0 +1,15 @@
+//go:build !darwin && !linux
+
+package client
+
+import "fmt"
+
+// keyringGet and keyringSet have no implementation on this platform; use
+// FileTokenStore instead.
+func keyringGet(service, key string) (string, bool, error) {
+	return "", false, fmt.Errorf("KeyringTokenStore is not supported on this platform")
+}
+
+func keyringSet(service, key, value string) error {
+	return fmt.Errorf("KeyringTokenStore is not supported on this platform")
+}
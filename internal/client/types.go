@@ -21,8 +21,12 @@ type Parameter struct {
 	Label        string      `json:"label"`
 	Description  string      `json:"description"`
 	Required     bool        `json:"required"`
-	Type         string      `json:"type"`
+	Type         string      `json:"type"` // "string", "number", "bool", "file", "folder", or "enum"
 	DefaultValue interface{} `json:"default_value,omitempty"`
+
+	// EnumValues lists the values a "enum"-typed parameter accepts. Unused
+	// for every other Type.
+	EnumValues []string `json:"enum_values,omitempty"`
 }
 
 // ParameterGroup represents a group of parameters.
@@ -37,11 +41,35 @@ type ParameterGroup struct {
 type AppParameters struct {
 	OverallJobType string           `json:"overall_job_type"`
 	Groups         []ParameterGroup `json:"groups"`
+
+	// ResourceCapabilities lists the device classes ("gpu", "qat",
+	// "hugepages_2mi", "sriov_nic") this app can be scheduled with. An app
+	// that omits a class cannot be launched with that ResourceRequests
+	// field set - see GPURequest and ResourceRequests.
+	ResourceCapabilities []string `json:"resource_capabilities,omitempty"`
 }
 
 // LaunchConfig represents the configuration parameters for an app.
 type LaunchConfig map[string]interface{}
 
+// GPURequest describes a requested GPU allocation: how many, and
+// optionally which vendor's device plugin should provide them.
+type GPURequest struct {
+	Count  int    `json:"count"`
+	Vendor string `json:"vendor,omitempty"` // e.g. "nvidia", "amd"
+}
+
+// ResourceRequests describes the accelerator/device resources an analysis
+// needs, using the same vocabulary as Kubernetes device plugins: GPUs,
+// Intel QuickAssist (QAT), 2Mi hugepages, and SR-IOV NICs. It is optional -
+// the zero value requests no special hardware.
+type ResourceRequests struct {
+	GPU          *GPURequest `json:"gpu,omitempty"`
+	QAT          int         `json:"qat,omitempty"`
+	Hugepages2Mi int         `json:"hugepages_2mi,omitempty"`
+	SRIOVNIC     int         `json:"sriov_nic,omitempty"`
+}
+
 // LaunchSubmission represents the complete submission for launching an app.
 // Matches the request body structure expected by POST /app/launch/{system_id}/{app_id}
 type LaunchSubmission struct {
@@ -53,6 +81,21 @@ type LaunchSubmission struct {
 	Notify       bool                   `json:"notify,omitempty"`
 	OutputDir    string                 `json:"output_dir,omitempty"`
 	Requirements map[string]interface{} `json:"requirements,omitempty"`
+
+	// DryRun, if true, makes LaunchApp run ValidateLaunchConfig against the
+	// app's parameters and return without ever submitting the analysis.
+	// Not sent to the Formation API - it's consumed by LaunchApp itself.
+	DryRun bool `json:"-"`
+}
+
+// CostEstimate represents the projected resource usage and cost of
+// launching an app with a given config, before it is submitted.
+type CostEstimate struct {
+	CPUHours       float64 `json:"cpu_hours"`
+	MemoryGBHours  float64 `json:"memory_gb_hours"`
+	StorageGBHours float64 `json:"storage_gb_hours"`
+	EstimatedCost  float64 `json:"estimated_cost"`
+	Currency       string  `json:"currency,omitempty"` // e.g. "USD" or "tokens"
 }
 
 // LaunchResponse represents the response from launching an app.
@@ -62,6 +105,12 @@ type LaunchResponse struct {
 	Name       string `json:"name"`
 	Status     string `json:"status"`
 	URL        string `json:"url,omitempty"`
+
+	// ValidationErrors is set instead of the fields above when LaunchApp
+	// rejected submission.Config against the app's parameter schema. A
+	// successful DryRun sets only Status ("valid") and no ValidationErrors.
+	// Not part of the Formation API's response body.
+	ValidationErrors []ValidationError `json:"-"`
 }
 
 // AnalysisStatus represents the status of an analysis.
@@ -109,6 +158,18 @@ type DirectoryContents struct {
 	Contents []DirectoryEntry `json:"contents"`
 }
 
+// FileMetadata describes a range read of a Data Store object, as reported by
+// DownloadFileRange's response headers: the range actually returned (which
+// the server may cap even if more was requested) and the object's total
+// size, so a caller can decide whether to request the next range.
+type FileMetadata struct {
+	Path        string `json:"path"`
+	Offset      int64  `json:"offset"`
+	Length      int64  `json:"length"`
+	TotalSize   int64  `json:"total_size"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
 // FileContent represents the content of a file with metadata.
 type FileContent struct {
 	Path     string                 `json:"path"`
@@ -135,6 +196,47 @@ type SetMetadataRequest struct {
 	Replace  bool                   `json:"replace"`
 }
 
+// MetadataPredicate is one AVU-style condition a SearchMetadata query
+// matches paths against: e.g. {Attribute: "experiment", Operator: "=",
+// Value: "RNA-seq"}. Operator follows the Data Store's metadata search
+// vocabulary: "=", "like", "<", ">", and "in" (Value is a []interface{}
+// for "in").
+type MetadataPredicate struct {
+	Attribute string      `json:"attribute"`
+	Operator  string      `json:"operator"`
+	Value     interface{} `json:"value"`
+}
+
+// MetadataQuery is a SearchMetadata request: every path returned must
+// satisfy all of Predicates (a logical AND), optionally narrowed by
+// PathPrefix and Type.
+type MetadataQuery struct {
+	Predicates []MetadataPredicate `json:"predicates"`
+
+	// PathPrefix, if set, restricts results to paths under it.
+	PathPrefix string `json:"path_prefix,omitempty"`
+
+	// Type, if set, restricts results to "collection" or "data_object".
+	Type string `json:"type,omitempty"`
+
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+}
+
+// MetadataSearchMatch is one path SearchMetadata found, with the metadata
+// that satisfied the query.
+type MetadataSearchMatch struct {
+	Path     string                 `json:"path"`
+	Type     string                 `json:"type"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// MetadataSearchResult is the response from SearchMetadata.
+type MetadataSearchResult struct {
+	Matches []MetadataSearchMatch `json:"matches"`
+	Total   int                   `json:"total"`
+}
+
 // DeleteRequest represents a request to delete a path.
 type DeleteRequest struct {
 	Path    string `json:"path"`
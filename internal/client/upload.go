@@ -0,0 +1,365 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// defaultUploadChunkSize is the chunk size UploadFileStream uses when
+// UploadOptions.ChunkSize is unset.
+const defaultUploadChunkSize = 64 * 1024 * 1024 // 64 MiB
+
+// UploadOptions configures UploadFileStream.
+type UploadOptions struct {
+	// ChunkSize is the number of bytes sent per PUT. A value <= 0 uses
+	// defaultUploadChunkSize.
+	ChunkSize int64
+
+	// Metadata is applied to the upload the same way UploadFile applies
+	// metadata: as X-Datastore-* headers, here on the completing request.
+	Metadata map[string]interface{}
+
+	// StateStore persists upload progress so a later call for the same
+	// path can resume rather than restart. A nil StateStore uses a
+	// throwaway in-memory store, which can't resume across process
+	// restarts; pass a FileUploadStateStore for that.
+	StateStore UploadStateStore
+
+	// OnProgress, if non-nil, is invoked at a throttled cadence (see
+	// newProgressThrottler) with cumulative bytes uploaded and the total
+	// size, as chunks are committed.
+	OnProgress func(written, total int64)
+}
+
+// UploadState is the progress record UploadStateStore persists for a
+// single upload, keyed by destination path.
+type UploadState struct {
+	SessionID    string
+	Path         string
+	Size         int64
+	ChunkSize    int64
+	Offset       int64  // bytes successfully committed so far
+	PrefixSHA256 string // hex SHA-256 of the first Offset bytes
+}
+
+// UploadStateStore persists UploadFileStream progress so an interrupted
+// upload can resume from its last committed chunk instead of restarting.
+// Implementations must be safe for concurrent use.
+type UploadStateStore interface {
+	Get(path string) (UploadState, bool, error)
+	Save(state UploadState) error
+	Delete(path string) error
+}
+
+// MemoryUploadStateStore is an in-memory UploadStateStore. It's the
+// default used when UploadOptions.StateStore is nil.
+type MemoryUploadStateStore struct {
+	mu     sync.Mutex
+	states map[string]UploadState
+}
+
+// NewMemoryUploadStateStore creates an empty MemoryUploadStateStore.
+func NewMemoryUploadStateStore() *MemoryUploadStateStore {
+	return &MemoryUploadStateStore{states: make(map[string]UploadState)}
+}
+
+// Get implements UploadStateStore.
+func (s *MemoryUploadStateStore) Get(path string) (UploadState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[path]
+	return state, ok, nil
+}
+
+// Save implements UploadStateStore.
+func (s *MemoryUploadStateStore) Save(state UploadState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state.Path] = state
+	return nil
+}
+
+// Delete implements UploadStateStore.
+func (s *MemoryUploadStateStore) Delete(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, path)
+	return nil
+}
+
+// FileUploadStateStore is an UploadStateStore backed by a JSON file on
+// disk, so upload progress survives a process restart.
+type FileUploadStateStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileUploadStateStore creates a FileUploadStateStore backed by the
+// JSON file at path.
+func NewFileUploadStateStore(path string) *FileUploadStateStore {
+	return &FileUploadStateStore{path: path}
+}
+
+func (s *FileUploadStateStore) load() (map[string]UploadState, error) {
+	states := make(map[string]UploadState)
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to parse upload state file: %w", err)
+	}
+	return states, nil
+}
+
+func (s *FileUploadStateStore) write(states map[string]UploadState) error {
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create upload state directory: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal upload state: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write upload state file: %w", err)
+	}
+	return nil
+}
+
+// Get implements UploadStateStore.
+func (s *FileUploadStateStore) Get(path string) (UploadState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states, err := s.load()
+	if err != nil {
+		return UploadState{}, false, err
+	}
+	state, ok := states[path]
+	return state, ok, nil
+}
+
+// Save implements UploadStateStore.
+func (s *FileUploadStateStore) Save(state UploadState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states, err := s.load()
+	if err != nil {
+		return err
+	}
+	states[state.Path] = state
+	return s.write(states)
+}
+
+// Delete implements UploadStateStore.
+func (s *FileUploadStateStore) Delete(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states, err := s.load()
+	if err != nil {
+		return err
+	}
+	delete(states, path)
+	return s.write(states)
+}
+
+// newUploadSessionID generates a random UUIDv4 to identify an upload
+// session across chunk requests and resumes.
+func newUploadSessionID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate upload session id: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// UploadFileStream uploads content (size bytes, read from r) to path in
+// fixed-size chunks, for Data Store objects too large to buffer in memory
+// in one PUT (see UploadFile for that simpler case). Each chunk is PUT
+// with a Content-Range header and a shared X-Upload-Session id; a final
+// POST commits the upload, carrying an X-Content-SHA256 of the whole
+// content, once every chunk has succeeded. Progress is persisted to
+// opts.StateStore after each chunk, so a later call for the same path
+// whose first Offset bytes hash the same as a prior attempt's resumes
+// from there instead of restarting, after confirming the remote still
+// recognizes the session via HEAD /data/<path>?upload_session=<id>.
+func (c *FormationClient) UploadFileStream(ctx context.Context, path string, r io.Reader, size int64, opts UploadOptions) error {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultUploadChunkSize
+	}
+	store := opts.StateStore
+	if store == nil {
+		store = NewMemoryUploadStateStore()
+	}
+
+	hasher := sha256.New()
+	sessionID, offset, src, err := c.resumeOrStartUpload(ctx, path, r, size, store, hasher)
+	if err != nil {
+		return err
+	}
+
+	throttler := newProgressThrottler(opts.OnProgress, size)
+	throttler.report(offset, false)
+
+	for offset < size {
+		end := offset + chunkSize
+		if end > size {
+			end = size
+		}
+
+		chunk := make([]byte, end-offset)
+		if _, err := io.ReadFull(io.TeeReader(src, hasher), chunk); err != nil {
+			return fmt.Errorf("failed to read chunk at offset %d: %w", offset, err)
+		}
+
+		if err := c.putUploadChunk(ctx, path, sessionID, chunk, offset, end, size); err != nil {
+			return fmt.Errorf("failed to upload chunk %d-%d: %w", offset, end-1, err)
+		}
+
+		offset = end
+		if err := store.Save(UploadState{
+			SessionID:    sessionID,
+			Path:         path,
+			Size:         size,
+			ChunkSize:    chunkSize,
+			Offset:       offset,
+			PrefixSHA256: hex.EncodeToString(hasher.Sum(nil)),
+		}); err != nil {
+			return fmt.Errorf("failed to persist upload progress: %w", err)
+		}
+		throttler.report(offset, offset == size)
+	}
+
+	if err := c.commitUpload(ctx, path, sessionID, hex.EncodeToString(hasher.Sum(nil)), opts.Metadata); err != nil {
+		return fmt.Errorf("failed to commit upload: %w", err)
+	}
+
+	return store.Delete(path)
+}
+
+// resumeOrStartUpload looks for progress already saved for path, and if
+// its size matches and the bytes r produces up through that progress's
+// Offset hash the same as recorded, confirms with the remote and resumes
+// on the existing session. Otherwise (no prior state, a size mismatch, a
+// content mismatch, or a remote that no longer recognizes the session) it
+// starts a new session at offset 0. In the content-mismatch case, the
+// bytes already read off r to check the hash are spliced back in front of
+// the rest of r, since they're still this attempt's first bytes.
+func (c *FormationClient) resumeOrStartUpload(ctx context.Context, path string, r io.Reader, size int64, store UploadStateStore, hasher hash.Hash) (sessionID string, offset int64, src io.Reader, err error) {
+	state, ok, err := store.Get(path)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to load upload state: %w", err)
+	}
+
+	if ok && state.Size == size && state.Offset > 0 {
+		prefix := make([]byte, state.Offset)
+		if _, err := io.ReadFull(r, prefix); err != nil {
+			return "", 0, nil, fmt.Errorf("failed to re-read uploaded prefix: %w", err)
+		}
+
+		sum := sha256.Sum256(prefix)
+		if hex.EncodeToString(sum[:]) == state.PrefixSHA256 {
+			if confirmed, cerr := c.confirmUploadSession(ctx, path, state.SessionID); cerr == nil && confirmed {
+				hasher.Write(prefix)
+				return state.SessionID, state.Offset, r, nil
+			}
+		}
+
+		sessionID, err := newUploadSessionID()
+		if err != nil {
+			return "", 0, nil, err
+		}
+		return sessionID, 0, io.MultiReader(bytes.NewReader(prefix), r), nil
+	}
+
+	sessionID, err = newUploadSessionID()
+	if err != nil {
+		return "", 0, nil, err
+	}
+	return sessionID, 0, r, nil
+}
+
+// confirmUploadSession checks whether the remote still recognizes
+// sessionID for path, via HEAD /data/<path>?upload_session=<id>. A 404
+// means the session expired or was never seen (e.g. a different server
+// instance took the request), which is reported as unconfirmed rather
+// than an error.
+func (c *FormationClient) confirmUploadSession(ctx context.Context, path, sessionID string) (bool, error) {
+	fullPath := c.buildDataPath(path) + "?" + url.Values{"upload_session": {sessionID}}.Encode()
+
+	resp, err := c.doRequest(ctx, "UploadFileStream.Confirm", http.MethodHead, fullPath, nil, nil)
+	if err != nil {
+		if strings.Contains(err.Error(), "status 404") {
+			return false, nil
+		}
+		return false, err
+	}
+	defer resp.Body.Close()
+	return true, nil
+}
+
+// putUploadChunk PUTs chunk, covering bytes [start, end) of a total-size
+// total upload, under sessionID. Chunk PUTs are idempotent (the server
+// can dedupe by session + range), so they're retried like any idempotent
+// request.
+func (c *FormationClient) putUploadChunk(ctx context.Context, path, sessionID string, chunk []byte, start, end, total int64) error {
+	headers := map[string]string{
+		"Content-Type":     "application/octet-stream",
+		"Content-Range":    fmt.Sprintf("bytes %d-%d/%d", start, end-1, total),
+		"X-Upload-Session": sessionID,
+	}
+
+	resp, err := c.doRequest(WithRetry(ctx, true), "UploadFileStream.Chunk", http.MethodPut, c.buildDataPath(path), bytes.NewReader(chunk), headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// commitUpload finalizes sessionID's chunks into the object at path, with
+// contentSHA256 (hex SHA-256 over the whole upload) for the server to
+// verify integrity.
+func (c *FormationClient) commitUpload(ctx context.Context, path, sessionID, contentSHA256 string, metadata map[string]interface{}) error {
+	headers := map[string]string{
+		"X-Upload-Session": sessionID,
+		"X-Content-SHA256": contentSHA256,
+	}
+	c.addMetadataHeaders(headers, metadata)
+
+	fullPath := c.buildDataPath(path) + "?action=complete"
+	resp, err := c.doRequest(ctx, "UploadFileStream.Commit", http.MethodPost, fullPath, nil, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// UploadChunkResult is returned by UploadFileChunk after each chunk.
+type UploadChunkResult struct {
+	// UploadID identifies the session; pass it back on every subsequent
+	// chunk. It's generated on the call that starts the session
+	// (uploadID == "") and echoed back on every later one.
+	UploadID string
+
+	// Offset is the number of bytes committed to the session so far,
+	// i.e. the offset the next chunk is expected to start at.
+	Offset int64
+
+	// ContentSHA256 is the hex SHA-256 of every byte committed so far.
+	ContentSHA256 string
+
+	// Complete is true once isFinal has been accepted and the upload
+	// committed to the data store.
+	Complete bool
+}
+
+// uploadChunkSession tracks one in-progress UploadFileChunk upload across
+// its separate tool calls.
+type uploadChunkSession struct {
+	path   string
+	offset int64
+	hasher hash.Hash
+}
+
+// uploadChunkSessionStore is the in-memory, server-issued-id-keyed store
+// UploadFileChunk uses to track progress between otherwise-independent
+// calls. Like MemoryUploadStateStore, sessions don't survive a process
+// restart.
+type uploadChunkSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*uploadChunkSession
+}
+
+func newUploadChunkSessionStore() *uploadChunkSessionStore {
+	return &uploadChunkSessionStore{sessions: make(map[string]*uploadChunkSession)}
+}
+
+// startOrResume creates a new session (uploadID == "") or validates offset
+// against an existing one, returning the session and its id either way.
+func (s *uploadChunkSessionStore) startOrResume(uploadID, path string, offset int64) (*uploadChunkSession, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if uploadID == "" {
+		if offset != 0 {
+			return nil, "", fmt.Errorf("a new upload must start at offset 0, got %d", offset)
+		}
+
+		id, err := newUploadSessionID()
+		if err != nil {
+			return nil, "", err
+		}
+
+		session := &uploadChunkSession{path: path, hasher: sha256.New()}
+		s.sessions[id] = session
+		return session, id, nil
+	}
+
+	session, ok := s.sessions[uploadID]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown or expired upload session %q", uploadID)
+	}
+	if session.path != path {
+		return nil, "", fmt.Errorf("upload session %q is for path %q, not %q", uploadID, session.path, path)
+	}
+	if offset != session.offset {
+		return nil, "", fmt.Errorf("chunk offset %d doesn't match upload session %q's expected offset %d; resend starting from the expected offset", offset, uploadID, session.offset)
+	}
+
+	return session, uploadID, nil
+}
+
+func (s *uploadChunkSessionStore) delete(uploadID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, uploadID)
+}
+
+// UploadFileChunk accepts a single chunk of an upload to path, for MCP
+// tool callers that can only send one bounded chunk per call rather than
+// a whole io.Reader (see UploadFileStream for that case). Passing
+// uploadID = "" starts a new session (offset must be 0); passing back a
+// prior call's UploadID continues it, so long as offset matches the
+// session's current offset exactly - the session only keeps a rolling
+// hash, not the raw bytes, so a mismatched offset is rejected rather than
+// silently re-accepted. Once isFinal is true, the chunk is accepted, the
+// upload is committed to the data store the same way UploadFileStream
+// commits one, and the session is discarded.
+func (c *FormationClient) UploadFileChunk(ctx context.Context, uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*UploadChunkResult, error) {
+	session, uploadID, err := c.uploadChunks.startOrResume(uploadID, path, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	end := offset + int64(len(chunk))
+	if len(chunk) > 0 {
+		total := "*"
+		if isFinal {
+			total = strconv.FormatInt(end, 10)
+		}
+		if err := c.putChunkRaw(ctx, path, uploadID, chunk, offset, end, total); err != nil {
+			return nil, fmt.Errorf("failed to upload chunk %d-%d: %w", offset, end-1, err)
+		}
+		session.hasher.Write(chunk)
+		session.offset = end
+	}
+
+	result := &UploadChunkResult{
+		UploadID:      uploadID,
+		Offset:        session.offset,
+		ContentSHA256: hex.EncodeToString(session.hasher.Sum(nil)),
+	}
+
+	if !isFinal {
+		return result, nil
+	}
+
+	if err := c.commitUpload(ctx, path, uploadID, result.ContentSHA256, metadata); err != nil {
+		return nil, fmt.Errorf("failed to commit upload: %w", err)
+	}
+	c.uploadChunks.delete(uploadID)
+	result.Complete = true
+	return result, nil
+}
+
+// putChunkRaw PUTs chunk, covering bytes [start, end) under uploadID. total
+// is the Content-Range total: the final size once known (the last chunk),
+// or "*" while it isn't yet. Chunk PUTs are idempotent (the server can
+// dedupe by session + range), so they're retried like any idempotent
+// request.
+func (c *FormationClient) putChunkRaw(ctx context.Context, path, uploadID string, chunk []byte, start, end int64, total string) error {
+	headers := map[string]string{
+		"Content-Type":     "application/octet-stream",
+		"Content-Range":    fmt.Sprintf("bytes %d-%d/%s", start, end-1, total),
+		"X-Upload-Session": uploadID,
+	}
+
+	resp, err := c.doRequest(WithRetry(ctx, true), "UploadFileChunk.Chunk", http.MethodPut, c.buildDataPath(path), bytes.NewReader(chunk), headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
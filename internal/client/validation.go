@@ -0,0 +1,98 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError describes one LaunchConfig entry that failed validation
+// against its app's declared Parameter schema.
+type ValidationError struct {
+	ParamID string `json:"param_id"`
+	Name    string `json:"name"`
+	Reason  string `json:"reason"`
+}
+
+// LaunchConfigValidationError is returned by LaunchApp when submission.Config
+// fails ValidateLaunchConfig. Callers that want the structured failure list
+// (rather than just its summarized Error() string) should use errors.As to
+// recover it.
+type LaunchConfigValidationError struct {
+	Errors []ValidationError
+}
+
+func (e *LaunchConfigValidationError) Error() string {
+	if len(e.Errors) == 1 {
+		return fmt.Sprintf("launch config failed validation: %s: %s", e.Errors[0].Name, e.Errors[0].Reason)
+	}
+	return fmt.Sprintf("launch config failed validation: %d parameters rejected", len(e.Errors))
+}
+
+// ValidateLaunchConfig checks cfg against app's declared Parameter schema:
+// every Required parameter must be present, every present value must
+// typecheck against its Parameter's declared Type, and every "enum"
+// parameter's value must be one of EnumValues. A missing optional parameter
+// with a DefaultValue is filled in with that default - cfg is a map, so this
+// mutates the caller's LaunchConfig in place, the same way a zero-value
+// optional field would be filled in server-side.
+//
+// The returned slice is empty (not nil) when cfg is valid.
+func ValidateLaunchConfig(app AppParameters, cfg LaunchConfig) []ValidationError {
+	errs := []ValidationError{}
+
+	for _, group := range app.Groups {
+		for _, param := range group.Parameters {
+			value, present := cfg[param.ID]
+			if !present {
+				if param.Required {
+					errs = append(errs, ValidationError{ParamID: param.ID, Name: param.Name, Reason: "required parameter is missing"})
+					continue
+				}
+				if param.DefaultValue != nil {
+					cfg[param.ID] = param.DefaultValue
+				}
+				continue
+			}
+
+			if reason := checkParamType(param, value); reason != "" {
+				errs = append(errs, ValidationError{ParamID: param.ID, Name: param.Name, Reason: reason})
+			}
+		}
+	}
+
+	return errs
+}
+
+// checkParamType reports why value doesn't satisfy param's declared Type, or
+// "" if it does.
+func checkParamType(param Parameter, value interface{}) string {
+	switch param.Type {
+	case "string", "file", "folder":
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("expected a string, got %T", value)
+		}
+	case "number":
+		switch value.(type) {
+		case float64, int, int64, float32:
+		default:
+			return fmt.Sprintf("expected a number, got %T", value)
+		}
+	case "bool":
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("expected a bool, got %T", value)
+		}
+	case "enum":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("expected a string, got %T", value)
+		}
+		for _, allowed := range param.EnumValues {
+			if str == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%q is not one of the allowed values: %s", str, strings.Join(param.EnumValues, ", "))
+	}
+
+	return ""
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateLaunchConfig(t *testing.T) {
+	app := AppParameters{
+		Groups: []ParameterGroup{
+			{
+				ID: "group-1",
+				Parameters: []Parameter{
+					{ID: "name", Name: "Name", Required: true, Type: "string"},
+					{ID: "count", Name: "Count", Type: "number", DefaultValue: float64(1)},
+					{ID: "mode", Name: "Mode", Type: "enum", EnumValues: []string{"fast", "slow"}},
+				},
+			},
+		},
+	}
+
+	cfg := LaunchConfig{"name": "widget", "mode": "medium"}
+	errs := ValidateLaunchConfig(app, cfg)
+
+	if len(errs) != 1 || errs[0].ParamID != "mode" {
+		t.Fatalf("ValidateLaunchConfig() = %+v, want exactly one error for mode", errs)
+	}
+	if cfg["count"] != float64(1) {
+		t.Errorf("ValidateLaunchConfig() did not apply count's default, cfg = %+v", cfg)
+	}
+}
+
+func TestValidateLaunchConfigMissingRequired(t *testing.T) {
+	app := AppParameters{
+		Groups: []ParameterGroup{
+			{Parameters: []Parameter{{ID: "name", Name: "Name", Required: true, Type: "string"}}},
+		},
+	}
+
+	errs := ValidateLaunchConfig(app, LaunchConfig{})
+	if len(errs) != 1 || errs[0].ParamID != "name" {
+		t.Fatalf("ValidateLaunchConfig() = %+v, want one missing-required error for name", errs)
+	}
+}
+
+func TestValidateLaunchConfigTypeMismatch(t *testing.T) {
+	app := AppParameters{
+		Groups: []ParameterGroup{
+			{Parameters: []Parameter{{ID: "count", Name: "Count", Type: "number"}}},
+		},
+	}
+
+	errs := ValidateLaunchConfig(app, LaunchConfig{"count": "not-a-number"})
+	if len(errs) != 1 || errs[0].ParamID != "count" {
+		t.Fatalf("ValidateLaunchConfig() = %+v, want one type-mismatch error for count", errs)
+	}
+}
+
+func TestLaunchAppRejectsInvalidConfigBeforeSubmitting(t *testing.T) {
+	var sawSubmit bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/apps/de/test-app-id/parameters":
+			json.NewEncoder(w).Encode(AppParameters{
+				Groups: []ParameterGroup{
+					{Parameters: []Parameter{{ID: "name", Name: "Name", Required: true, Type: "string"}}},
+				},
+			})
+		case r.Method == "POST":
+			sawSubmit = true
+			json.NewEncoder(w).Encode(LaunchResponse{AnalysisID: "analysis-123"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+
+	_, err := client.LaunchApp(context.Background(), "de", "test-app-id", LaunchSubmission{Config: LaunchConfig{}})
+	if err == nil {
+		t.Fatal("LaunchApp() expected a validation error for a missing required parameter, got nil")
+	}
+
+	var validationErr *LaunchConfigValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("LaunchApp() error = %v, want a *LaunchConfigValidationError", err)
+	}
+	if len(validationErr.Errors) != 1 || validationErr.Errors[0].ParamID != "name" {
+		t.Errorf("LaunchApp() validation errors = %+v, want one error for name", validationErr.Errors)
+	}
+	if sawSubmit {
+		t.Error("LaunchApp() submitted the analysis despite failing validation")
+	}
+}
+
+func TestLaunchAppDryRunDoesNotSubmit(t *testing.T) {
+	var sawSubmit bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/apps/de/test-app-id/parameters":
+			json.NewEncoder(w).Encode(AppParameters{
+				Groups: []ParameterGroup{
+					{Parameters: []Parameter{{ID: "name", Name: "Name", Required: true, Type: "string"}}},
+				},
+			})
+		case r.Method == "POST":
+			sawSubmit = true
+			json.NewEncoder(w).Encode(LaunchResponse{AnalysisID: "analysis-123"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewFormationClient(server.URL, "test-token", "", "", nil, nil)
+
+	resp, err := client.LaunchApp(context.Background(), "de", "test-app-id", LaunchSubmission{
+		Config: LaunchConfig{"name": "widget"},
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("LaunchApp() unexpected error = %v", err)
+	}
+	if resp.Status != "valid" {
+		t.Errorf("LaunchApp() dry run status = %q, want valid", resp.Status)
+	}
+	if sawSubmit {
+		t.Error("LaunchApp() submitted the analysis during a dry run")
+	}
+}
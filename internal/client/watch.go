@@ -0,0 +1,266 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AnalysisEvent is a single status update received from WatchAnalysis or
+// WatchAnalyses. Err is set on a frame that can't be decoded (the watch
+// goes on), and, with the channel closed immediately after, when the
+// watch ends abnormally after exhausting reconnect attempts.
+type AnalysisEvent struct {
+	AnalysisID string
+	Status     string
+	URLReady   bool
+	URL        string
+	Timestamp  time.Time
+	Err        error
+}
+
+// terminalAnalysisStatuses are the statuses after which no further updates
+// will arrive for an analysis, matching the terminal states LaunchAndWait
+// already checks for.
+var terminalAnalysisStatuses = map[string]bool{
+	"Completed": true,
+	"Failed":    true,
+	"Canceled":  true,
+}
+
+// WatchAnalysis streams status updates for a single analysis from
+// /apps/analyses/{id}/watch until it reaches a terminal status or ctx is
+// cancelled. See watch for connection and reconnect semantics.
+func (c *FormationClient) WatchAnalysis(ctx context.Context, analysisID string) (<-chan AnalysisEvent, error) {
+	path := fmt.Sprintf("/apps/analyses/%s/watch", analysisID)
+	return c.watch(ctx, path, func(raw map[string]interface{}) AnalysisEvent {
+		return analysisEventFromFrame(analysisID, raw)
+	})
+}
+
+// WatchAnalyses streams status updates for every analysis matching filter
+// (the same status filter ListAnalyses accepts) from /apps/analyses/watch,
+// for driving a live dashboard view instead of polling ListAnalyses.
+func (c *FormationClient) WatchAnalyses(ctx context.Context, filter string) (<-chan AnalysisEvent, error) {
+	path := "/apps/analyses/watch"
+	if filter != "" {
+		path += "?" + url.Values{"status": {filter}}.Encode()
+	}
+	return c.watch(ctx, path, func(raw map[string]interface{}) AnalysisEvent {
+		return analysisEventFromFrame("", raw)
+	})
+}
+
+// watch opens path as a long-lived connection (negotiating SSE via Accept:
+// text/event-stream, falling back to newline-delimited JSON if the server
+// ignores that), and streams parsed events on the returned channel until
+// a terminal status is seen or ctx is cancelled. On a transient disconnect
+// it reconnects, resuming from the last received Last-Event-ID, up to
+// c.retryPolicy.MaxAttempts consecutive times before giving up; any
+// successful reconnect resets that count, so a long-running watch can
+// ride out many individual disconnects over its lifetime.
+func (c *FormationClient) watch(ctx context.Context, path string, toEvent func(map[string]interface{}) AnalysisEvent) (<-chan AnalysisEvent, error) {
+	resp, isSSE, err := c.connectWatch(ctx, path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan AnalysisEvent)
+
+	go func() {
+		defer close(events)
+
+		var lastEventID string
+		failures := 0
+
+		for {
+			terminal, streamErr := c.streamFrames(ctx, resp, isSSE, &lastEventID, events, toEvent)
+			if terminal || ctx.Err() != nil {
+				return
+			}
+			if streamErr == nil {
+				// The server closed the stream cleanly; nothing more to watch.
+				return
+			}
+
+			for {
+				failures++
+				if failures > c.retryPolicy.MaxAttempts {
+					select {
+					case events <- AnalysisEvent{Err: fmt.Errorf("watch disconnected after %d attempts: %w", failures-1, streamErr)}:
+					case <-ctx.Done():
+					}
+					return
+				}
+
+				timer := time.NewTimer(c.retryPolicy.backoff(failures - 1))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+
+				var connectErr error
+				resp, isSSE, connectErr = c.connectWatch(ctx, path, lastEventID)
+				if connectErr != nil {
+					streamErr = connectErr
+					continue
+				}
+				failures = 0
+				break
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// connectWatch opens the long-lived watch connection for path. It doesn't
+// go through doRequest's retry wrapper since watch manages its own
+// reconnects, but does still consume a rate-limiter token per attempt.
+func (c *FormationClient) connectWatch(ctx context.Context, path, lastEventID string) (*http.Response, bool, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, false, fmt.Errorf("rate limiter: %w", err)
+	}
+	if err := c.ensureToken(ctx); err != nil {
+		return nil, false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create watch request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	// A watch connection is long-lived and must not be cut off by
+	// c.httpClient's request-wide timeout, so it's issued on a client that
+	// shares the transport but has no timeout of its own.
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("watch request failed: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, false, fmt.Errorf("watch failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	isSSE := strings.Contains(resp.Header.Get("Content-Type"), "text/event-stream")
+	return resp, isSSE, nil
+}
+
+// streamFrames reads events from resp's body, sending each parsed
+// AnalysisEvent on events, until the body ends or ctx is done. It reports
+// whether a terminal-status event was seen (the watch is done for good)
+// and the error that ended the stream, if any (nil on a clean EOF).
+func (c *FormationClient) streamFrames(ctx context.Context, resp *http.Response, isSSE bool, lastEventID *string, events chan<- AnalysisEvent, toEvent func(map[string]interface{}) AnalysisEvent) (bool, error) {
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var dataLines []string
+	flush := func() (bool, error) {
+		if len(dataLines) == 0 {
+			return false, nil
+		}
+		payload := strings.Join(dataLines, "\n")
+		dataLines = nil
+
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(payload), &raw); err != nil {
+			select {
+			case events <- AnalysisEvent{Err: fmt.Errorf("failed to decode watch event: %w", err)}:
+				return false, nil
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+		}
+
+		event := toEvent(raw)
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+		return terminalAnalysisStatuses[event.Status], nil
+	}
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+
+		line := scanner.Text()
+
+		if !isSSE {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			dataLines = []string{line}
+			terminal, err := flush()
+			if err != nil || terminal {
+				return terminal, err
+			}
+			continue
+		}
+
+		switch {
+		case line == "":
+			terminal, err := flush()
+			if err != nil || terminal {
+				return terminal, err
+			}
+		case strings.HasPrefix(line, "id:"):
+			*lastEventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			// Other SSE fields (event:, retry:, comments) carry nothing the
+			// watch endpoint needs and are ignored.
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// analysisEventFromFrame builds an AnalysisEvent from a decoded watch
+// frame. analysisID is used as a fallback when the frame itself omits it,
+// as single-analysis watch frames typically do.
+func analysisEventFromFrame(analysisID string, raw map[string]interface{}) AnalysisEvent {
+	event := AnalysisEvent{AnalysisID: analysisID, Timestamp: time.Now()}
+
+	if v, ok := raw["analysis_id"].(string); ok && v != "" {
+		event.AnalysisID = v
+	}
+	if v, ok := raw["status"].(string); ok {
+		event.Status = v
+	}
+	if v, ok := raw["url_ready"].(bool); ok {
+		event.URLReady = v
+	}
+	if v, ok := raw["url"].(string); ok {
+		event.URL = v
+	}
+	if v, ok := raw["timestamp"].(string); ok {
+		if ts, err := time.Parse(time.RFC3339, v); err == nil {
+			event.Timestamp = ts
+		}
+	}
+
+	return event
+}
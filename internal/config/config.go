@@ -6,10 +6,14 @@ package config
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/cyverse-de/formation-mcp/internal/logging"
 	"gopkg.in/yaml.v3"
 )
 
@@ -33,6 +37,32 @@ type Config struct {
 	// LogJSON enables JSON-formatted logging output
 	LogJSON bool `yaml:"log_json"`
 
+	// LogOTLP enables logging in the OpenTelemetry Logs Data Model's JSON
+	// format instead, so logs can be shipped into an OTLP-compatible sink
+	// alongside traces. Takes precedence over LogJSON if both are set.
+	LogOTLP bool `yaml:"log_otlp"`
+
+	// LogBackend selects the logging.Logger implementation internal/client
+	// and internal/workflows log through for a request (see
+	// client.WithLogger). Only "slog" is built in today; empty defaults to
+	// it. See logging.Logger's doc comment for why zerolog/zap aren't
+	// options here.
+	LogBackend string `yaml:"log_backend"`
+
+	// LogLevels overrides LogLevel for individual subsystems, as a
+	// comma-separated subsystem=level list (e.g.
+	// "client=debug,workflows=info,server=warn"). See
+	// logging.ParseSubsystemLevels and logging.SubsystemLevelHandler; the
+	// set_log_level/get_log_level MCP tools read and mutate the same
+	// overrides at runtime.
+	LogLevels string `yaml:"log_levels"`
+
+	// LogSampling enables zerolog-style sampling of debug-level logs (see
+	// logging.NewSamplingHandler), so the workflow layer's analysis status
+	// polling loop can't flood a sink at debug verbosity. Warn and Error
+	// are never sampled.
+	LogSampling bool `yaml:"log_sampling"`
+
 	// MetricsAddr is the address for the metrics endpoint (empty = disabled)
 	MetricsAddr string `yaml:"metrics_addr"`
 
@@ -41,15 +71,68 @@ type Config struct {
 
 	// PollInterval is the interval for polling analysis status (in seconds)
 	PollInterval int `yaml:"poll_interval"`
+
+	// LogSyslog enables writing logs to the local syslog daemon, in
+	// addition to stderr
+	LogSyslog bool `yaml:"log_syslog"`
+
+	// LogSyslogFacility is the syslog facility to log under (empty = daemon)
+	LogSyslogFacility string `yaml:"log_syslog_facility"`
+
+	// LogFile, if set, additionally writes logs to this path with
+	// size/age-based rotation
+	LogFile string `yaml:"log_file"`
+
+	// LogFileMaxSizeMB is the size in MB at which LogFile is rotated (0 = no limit)
+	LogFileMaxSizeMB int `yaml:"log_file_max_size_mb"`
+
+	// LogFileMaxAgeDays is how long rotated LogFile backups are kept (0 = forever)
+	LogFileMaxAgeDays int `yaml:"log_file_max_age_days"`
+
+	// LogFileMaxBackups is how many rotated LogFile backups are kept,
+	// beyond LogFileMaxAgeDays - the newest LogFileMaxBackups survive a
+	// prune pass, the rest are removed regardless of age (0 = no limit)
+	LogFileMaxBackups int `yaml:"log_file_max_backups"`
+
+	// AuditFile, if set, records every MCP tool call as a JSON line
+	// appended to this path, in addition to the server's in-memory
+	// history used by list_tool_history and replay_tool_call.
+	AuditFile string `yaml:"audit_file"`
+
+	// AuditSyslog enables recording every MCP tool call to the local
+	// syslog daemon, in addition to the in-memory history.
+	AuditSyslog bool `yaml:"audit_syslog"`
+
+	// AuditSyslogFacility is the syslog facility audit records are sent
+	// under (empty = daemon), mirroring LogSyslogFacility.
+	AuditSyslogFacility string `yaml:"audit_syslog_facility"`
+
+	// AuditIRODSCollection, if set, records every MCP tool call as its own
+	// JSON data object under this iRODS collection path.
+	AuditIRODSCollection string `yaml:"audit_irods_collection"`
+
+	// DeleteGracePeriodSeconds is delete_data's default grace_period_seconds
+	// when a call doesn't specify one: -1 keeps today's synchronous
+	// trash/purge behavior, 0 still schedules through the delete job
+	// manager but fires immediately, and a positive value delays execution
+	// that long so it can be cancelled first.
+	DeleteGracePeriodSeconds int `yaml:"delete_grace_period_seconds"`
+
+	// DeleteJobStateFile, if set, persists the delete job queue (jobs
+	// scheduled by a positive grace_period_seconds) to this path so a
+	// restart doesn't drop a pending deletion.
+	DeleteJobStateFile string `yaml:"delete_job_state_file"`
 }
 
 // DefaultConfig returns a Config with default values.
 func DefaultConfig() *Config {
 	return &Config{
-		LogLevel:     "info",
-		LogJSON:      false,
-		MetricsAddr:  "",
-		PollInterval: 5,
+		LogLevel:                 "info",
+		LogJSON:                  false,
+		LogBackend:               string(logging.LogBackendSlog),
+		MetricsAddr:              "",
+		PollInterval:             5,
+		DeleteGracePeriodSeconds: -1,
 	}
 }
 
@@ -62,13 +145,24 @@ func FromEnv() *Config {
 	}
 
 	cfg := &Config{
-		BaseURL:      strings.TrimSuffix(baseURL, "/"),
-		Token:        os.Getenv("FORMATION_TOKEN"),
-		Username:     os.Getenv("FORMATION_USERNAME"),
-		Password:     os.Getenv("FORMATION_PASSWORD"),
-		LogLevel:     os.Getenv("LOG_LEVEL"),
-		MetricsAddr:  os.Getenv("METRICS_ADDR"),
-		PollInterval: 5, // default
+		BaseURL:           strings.TrimSuffix(baseURL, "/"),
+		Token:             os.Getenv("FORMATION_TOKEN"),
+		Username:          os.Getenv("FORMATION_USERNAME"),
+		Password:          os.Getenv("FORMATION_PASSWORD"),
+		LogLevel:          os.Getenv("LOG_LEVEL"),
+		MetricsAddr:       os.Getenv("METRICS_ADDR"),
+		PollInterval:      5, // default
+		LogSyslogFacility: os.Getenv("LOG_SYSLOG_FACILITY"),
+		LogFile:           os.Getenv("LOG_FILE"),
+		LogBackend:        os.Getenv("LOG_BACKEND"),
+		LogLevels:         os.Getenv("LOG_LEVELS"),
+
+		AuditFile:            os.Getenv("AUDIT_FILE"),
+		AuditSyslogFacility:  os.Getenv("AUDIT_SYSLOG_FACILITY"),
+		AuditIRODSCollection: os.Getenv("AUDIT_IRODS_COLLECTION"),
+
+		DeleteGracePeriodSeconds: -1, // default
+		DeleteJobStateFile:       os.Getenv("DELETE_JOB_STATE_FILE"),
 	}
 
 	// Handle LOG_JSON env var
@@ -76,6 +170,39 @@ func FromEnv() *Config {
 		cfg.LogJSON = true
 	}
 
+	// Handle LOG_OTLP env var
+	if logOTLP := os.Getenv("LOG_OTLP"); logOTLP == "true" || logOTLP == "1" {
+		cfg.LogOTLP = true
+	}
+
+	// Handle LOG_SYSLOG env var
+	if logSyslog := os.Getenv("LOG_SYSLOG"); logSyslog == "true" || logSyslog == "1" {
+		cfg.LogSyslog = true
+	}
+
+	// Handle LOG_SAMPLING env var
+	if logSampling := os.Getenv("LOG_SAMPLING"); logSampling == "true" || logSampling == "1" {
+		cfg.LogSampling = true
+	}
+
+	// Handle AUDIT_SYSLOG env var
+	if auditSyslog := os.Getenv("AUDIT_SYSLOG"); auditSyslog == "true" || auditSyslog == "1" {
+		cfg.AuditSyslog = true
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("LOG_FILE_MAX_SIZE_MB")); err == nil {
+		cfg.LogFileMaxSizeMB = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOG_FILE_MAX_AGE_DAYS")); err == nil {
+		cfg.LogFileMaxAgeDays = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOG_FILE_MAX_BACKUPS")); err == nil {
+		cfg.LogFileMaxBackups = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("DELETE_GRACE_PERIOD_SECONDS")); err == nil {
+		cfg.DeleteGracePeriodSeconds = v
+	}
+
 	return cfg
 }
 
@@ -102,6 +229,21 @@ func FromFile(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// A top-level `raw_env: true` opts a file out of environment-variable
+	// expansion, for configs that legitimately contain a literal `$`.
+	var rawFlag struct {
+		RawEnv bool `yaml:"raw_env"`
+	}
+	if err := yaml.Unmarshal(data, &rawFlag); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if !rawFlag.RawEnv {
+		data, err = expandEnvVars(data, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	cfg := DefaultConfig()
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
@@ -115,6 +257,252 @@ func FromFile(path string) (*Config, error) {
 	return cfg, nil
 }
 
+// expandEnvVars expands `${VAR}` and `${VAR:-default}` references in a YAML
+// config file's raw bytes using os.LookupEnv, so non-secret config can be
+// committed while secrets are injected at deploy time. Expansion is skipped
+// inside single-quoted YAML scalars, and `$$` is treated as an escaped,
+// literal `$`. path is only used to produce file/line context in errors.
+func expandEnvVars(data []byte, path string) ([]byte, error) {
+	runes := []rune(string(data))
+
+	var out strings.Builder
+	line := 1
+	inSingleQuote := false
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		if c == '\n' {
+			line++
+		}
+
+		if c == '\'' {
+			inSingleQuote = !inSingleQuote
+			out.WriteRune(c)
+			continue
+		}
+
+		if inSingleQuote {
+			out.WriteRune(c)
+			continue
+		}
+
+		if c == '$' && i+1 < len(runes) && runes[i+1] == '$' {
+			out.WriteRune('$')
+			i++
+			continue
+		}
+
+		if c == '$' && i+1 < len(runes) && runes[i+1] == '{' {
+			end := strings.IndexRune(string(runes[i+2:]), '}')
+			if end == -1 {
+				return nil, fmt.Errorf("%s:%d: unterminated ${...} expansion", path, line)
+			}
+			end += i + 2
+
+			name, defaultVal, hasDefault := splitVarExpr(string(runes[i+2 : end]))
+			val, ok := os.LookupEnv(name)
+			if !ok {
+				if !hasDefault {
+					return nil, fmt.Errorf("%s:%d: required environment variable %q is not set", path, line, name)
+				}
+				val = defaultVal
+			}
+			out.WriteString(val)
+			i = end
+			continue
+		}
+
+		out.WriteRune(c)
+	}
+
+	return []byte(out.String()), nil
+}
+
+// splitVarExpr splits a `${...}` expansion body (without the braces) into
+// the variable name and, if present, the `:-default` fallback value.
+func splitVarExpr(expr string) (name, defaultVal string, hasDefault bool) {
+	if idx := strings.Index(expr, ":-"); idx >= 0 {
+		return expr[:idx], expr[idx+2:], true
+	}
+	return expr, "", false
+}
+
+// Storage abstracts where file-based configuration values come from, so
+// alternative backends - an in-memory store for tests, a Vault/Secrets
+// Manager reader, a keyring-backed store - can be swapped in via
+// RegisterStorage without touching the merge/validate pipeline in Load.
+// Keys are the Config struct's YAML field names (see the Key* constants).
+type Storage interface {
+	// Get returns the value for key and whether it was present.
+	Get(key string) (string, bool)
+	// Set stores value for key, to be written out by a later Save.
+	Set(key, value string)
+	// Save persists any pending changes made via Set.
+	Save() error
+	// Load reads the backend and populates it with its current contents.
+	Load() error
+}
+
+// Well-known configuration keys used with the Storage interface. These
+// mirror the Config struct's `yaml` tags.
+const (
+	KeyBaseURL      = "base_url"
+	KeyToken        = "token"
+	KeyUsername     = "username"
+	KeyPassword     = "password"
+	KeyLogLevel     = "log_level"
+	KeyLogJSON      = "log_json"
+	KeyMetricsAddr  = "metrics_addr"
+	KeyPollInterval = "poll_interval"
+)
+
+var (
+	storageMu      sync.RWMutex
+	storageFactory = func(path string) Storage { return NewFileStorage(path) }
+)
+
+// RegisterStorage overrides the Storage implementation Load uses to read
+// file-based configuration. factory is called with the resolved config
+// file path each time Load needs to read it.
+func RegisterStorage(factory func(path string) Storage) {
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	storageFactory = factory
+}
+
+// newStorage constructs a Storage for path using the currently registered
+// factory (FileStorage by default).
+func newStorage(path string) Storage {
+	storageMu.RLock()
+	defer storageMu.RUnlock()
+	return storageFactory(path)
+}
+
+// FileStorage is the default Storage implementation. It loads from, and
+// saves to, a YAML file on disk, delegating to FromFile so the existing
+// ${VAR} expansion and raw_env opt-out behavior applies unchanged.
+type FileStorage struct {
+	path   string
+	values map[string]string
+}
+
+// NewFileStorage creates a FileStorage backed by the YAML file at path.
+func NewFileStorage(path string) *FileStorage {
+	return &FileStorage{path: path, values: make(map[string]string)}
+}
+
+// Get implements Storage.
+func (s *FileStorage) Get(key string) (string, bool) {
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Set implements Storage.
+func (s *FileStorage) Set(key, value string) {
+	s.values[key] = value
+}
+
+// Load implements Storage by reading s.path via FromFile. If the file
+// doesn't exist, Load succeeds and leaves the value map empty.
+func (s *FileStorage) Load() error {
+	cfg, err := FromFile(s.path)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return nil
+	}
+
+	s.values[KeyBaseURL] = cfg.BaseURL
+	s.values[KeyToken] = cfg.Token
+	s.values[KeyUsername] = cfg.Username
+	s.values[KeyPassword] = cfg.Password
+	s.values[KeyLogLevel] = cfg.LogLevel
+	s.values[KeyLogJSON] = strconv.FormatBool(cfg.LogJSON)
+	s.values[KeyMetricsAddr] = cfg.MetricsAddr
+	s.values[KeyPollInterval] = strconv.Itoa(cfg.PollInterval)
+	return nil
+}
+
+// Save implements Storage by writing the current value map out as a YAML
+// config file at s.path, created 0600 if it contains secrets (an existing
+// file keeps its permissions).
+func (s *FileStorage) Save() error {
+	cfg := &Config{
+		BaseURL:     s.values[KeyBaseURL],
+		Token:       s.values[KeyToken],
+		Username:    s.values[KeyUsername],
+		Password:    s.values[KeyPassword],
+		LogLevel:    s.values[KeyLogLevel],
+		MetricsAddr: s.values[KeyMetricsAddr],
+	}
+	if v, ok := s.values[KeyLogJSON]; ok {
+		cfg.LogJSON, _ = strconv.ParseBool(v)
+	}
+	if v, ok := s.values[KeyPollInterval]; ok {
+		cfg.PollInterval, _ = strconv.Atoi(v)
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	mode := os.FileMode(0o644)
+	if cfg.Token != "" || cfg.Password != "" {
+		mode = 0o600
+	}
+	return os.WriteFile(s.path, data, mode)
+}
+
+// configFromStorage builds a *Config from a Storage's currently loaded
+// values, starting from DefaultConfig so unset numeric/bool keys fall
+// back sanely.
+func configFromStorage(s Storage) *Config {
+	cfg := DefaultConfig()
+	if v, ok := s.Get(KeyBaseURL); ok {
+		cfg.BaseURL = v
+	}
+	if v, ok := s.Get(KeyToken); ok {
+		cfg.Token = v
+	}
+	if v, ok := s.Get(KeyUsername); ok {
+		cfg.Username = v
+	}
+	if v, ok := s.Get(KeyPassword); ok {
+		cfg.Password = v
+	}
+	if v, ok := s.Get(KeyLogLevel); ok {
+		cfg.LogLevel = v
+	}
+	if v, ok := s.Get(KeyLogJSON); ok {
+		cfg.LogJSON, _ = strconv.ParseBool(v)
+	}
+	if v, ok := s.Get(KeyMetricsAddr); ok {
+		cfg.MetricsAddr = v
+	}
+	if v, ok := s.Get(KeyPollInterval); ok {
+		cfg.PollInterval, _ = strconv.Atoi(v)
+	}
+	return cfg
+}
+
+// loadStorageConfig loads path through the currently registered Storage
+// implementation. It returns (nil, nil) when the backend has nothing for
+// path (e.g. the default FileStorage and a nonexistent file), matching
+// FromFile's "not found is not an error" behavior.
+func loadStorageConfig(path string) (*Config, error) {
+	storage := newStorage(path)
+	if err := storage.Load(); err != nil {
+		return nil, err
+	}
+	if _, ok := storage.Get(KeyBaseURL); !ok {
+		return nil, nil
+	}
+	return configFromStorage(storage), nil
+}
+
 // Load loads configuration with proper precedence:
 // CLI flags (via cfg parameter) > environment variables > config file > defaults
 func Load(cfg *Config) (*Config, error) {
@@ -123,7 +511,7 @@ func Load(cfg *Config) (*Config, error) {
 
 	// Try to load from config file if specified
 	if cfg != nil && cfg.ConfigFile != "" {
-		fileCfg, err := FromFile(cfg.ConfigFile)
+		fileCfg, err := loadStorageConfig(cfg.ConfigFile)
 		if err != nil {
 			return nil, err
 		}
@@ -136,7 +524,7 @@ func Load(cfg *Config) (*Config, error) {
 			"~/.formation-mcp.yaml",
 			"~/.config/formation-mcp/config.yaml",
 		} {
-			fileCfg, err := FromFile(defaultPath)
+			fileCfg, err := loadStorageConfig(defaultPath)
 			if err != nil {
 				return nil, err
 			}
@@ -194,9 +582,102 @@ func (c *Config) Validate() error {
 	// Normalize log level to lowercase
 	c.LogLevel = strings.ToLower(c.LogLevel)
 
+	// Validate log backend. zerolog and zap are deliberately not supported -
+	// see logging.Logger's doc comment - so they're rejected here with an
+	// explanation rather than silently falling back to slog.
+	switch logging.LogBackend(strings.ToLower(c.LogBackend)) {
+	case "", logging.LogBackendSlog:
+	case "zerolog", "zap":
+		return fmt.Errorf("log backend %q is not available: only %q is built in (zerolog/zap would require vendoring a new dependency); implement logging.Logger against one yourself and pass it to client.WithLogger instead", c.LogBackend, logging.LogBackendSlog)
+	default:
+		return fmt.Errorf("invalid log backend: %s (must be %q)", c.LogBackend, logging.LogBackendSlog)
+	}
+	c.LogBackend = strings.ToLower(c.LogBackend)
+	if c.LogBackend == "" {
+		c.LogBackend = string(logging.LogBackendSlog)
+	}
+
+	// Validate LogLevels eagerly so a typo in --log-levels/LOG_LEVELS is
+	// reported at startup rather than silently ignored the first time
+	// set_log_level or get_log_level is called.
+	if _, err := logging.ParseSubsystemLevels(c.LogLevels); err != nil {
+		return fmt.Errorf("invalid log_levels: %w", err)
+	}
+
 	return nil
 }
 
+// Reloader hot-reloads configuration on demand (e.g. in response to a
+// SIGHUP signal) by re-running the same file+env merge pipeline used at
+// startup. Only fields that are safe to change at runtime are applied;
+// changes to immutable fields are logged as warnings and discarded.
+type Reloader struct {
+	mu      sync.RWMutex
+	cliCfg  *Config
+	current *Config
+}
+
+// NewReloader creates a Reloader. cliCfg is the CLI-flag configuration
+// passed to the original Load call (highest precedence input); current is
+// the fully-resolved configuration currently in effect.
+func NewReloader(cliCfg *Config, current *Config) *Reloader {
+	return &Reloader{
+		cliCfg:  cliCfg,
+		current: current,
+	}
+}
+
+// Current returns a copy of the configuration currently in effect.
+func (r *Reloader) Current() *Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cfg := *r.current
+	return &cfg
+}
+
+// immutableFields are Config fields that cannot be changed by a reload.
+// Changing the Formation endpoint or credentials out from under a running
+// server would invalidate in-flight requests and cached tokens, so these
+// require a restart instead.
+var immutableFields = []struct {
+	name string
+	get  func(*Config) string
+}{
+	{"base_url", func(c *Config) string { return c.BaseURL }},
+	{"token", func(c *Config) string { return c.Token }},
+	{"username", func(c *Config) string { return c.Username }},
+	{"password", func(c *Config) string { return c.Password }},
+}
+
+// Reload re-runs the file+env merge pipeline and applies any safe changes
+// (log level, log format, metrics address, poll interval) to the running
+// configuration. It returns the newly effective configuration. Changes to
+// immutable fields are logged as warnings and otherwise ignored.
+func (r *Reloader) Reload() (*Config, error) {
+	next, err := Load(r.cliCfg)
+	if err != nil {
+		return nil, fmt.Errorf("reload failed: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range immutableFields {
+		if f.get(next) != f.get(r.current) {
+			slog.Warn("ignoring change to immutable config field on reload", "field", f.name)
+		}
+	}
+	// Immutable fields always carry forward from the running config,
+	// regardless of what the reloaded file/env produced.
+	next.BaseURL = r.current.BaseURL
+	next.Token = r.current.Token
+	next.Username = r.current.Username
+	next.Password = r.current.Password
+
+	r.current = next
+	return next, nil
+}
+
 // mergeConfigs merges two configs, with values from 'override' taking precedence.
 // Only non-zero values from 'override' are used.
 func mergeConfigs(base, override *Config) *Config {
@@ -220,6 +701,18 @@ func mergeConfigs(base, override *Config) *Config {
 	if override.LogJSON {
 		result.LogJSON = override.LogJSON
 	}
+	if override.LogOTLP {
+		result.LogOTLP = override.LogOTLP
+	}
+	if override.LogBackend != "" {
+		result.LogBackend = override.LogBackend
+	}
+	if override.LogLevels != "" {
+		result.LogLevels = override.LogLevels
+	}
+	if override.LogSampling {
+		result.LogSampling = override.LogSampling
+	}
 	if override.MetricsAddr != "" {
 		result.MetricsAddr = override.MetricsAddr
 	}
@@ -229,6 +722,42 @@ func mergeConfigs(base, override *Config) *Config {
 	if override.PollInterval > 0 {
 		result.PollInterval = override.PollInterval
 	}
+	if override.LogSyslog {
+		result.LogSyslog = override.LogSyslog
+	}
+	if override.LogSyslogFacility != "" {
+		result.LogSyslogFacility = override.LogSyslogFacility
+	}
+	if override.LogFile != "" {
+		result.LogFile = override.LogFile
+	}
+	if override.LogFileMaxSizeMB > 0 {
+		result.LogFileMaxSizeMB = override.LogFileMaxSizeMB
+	}
+	if override.LogFileMaxAgeDays > 0 {
+		result.LogFileMaxAgeDays = override.LogFileMaxAgeDays
+	}
+	if override.LogFileMaxBackups > 0 {
+		result.LogFileMaxBackups = override.LogFileMaxBackups
+	}
+	if override.AuditFile != "" {
+		result.AuditFile = override.AuditFile
+	}
+	if override.AuditSyslog {
+		result.AuditSyslog = override.AuditSyslog
+	}
+	if override.AuditSyslogFacility != "" {
+		result.AuditSyslogFacility = override.AuditSyslogFacility
+	}
+	if override.AuditIRODSCollection != "" {
+		result.AuditIRODSCollection = override.AuditIRODSCollection
+	}
+	if override.DeleteGracePeriodSeconds != 0 {
+		result.DeleteGracePeriodSeconds = override.DeleteGracePeriodSeconds
+	}
+	if override.DeleteJobStateFile != "" {
+		result.DeleteJobStateFile = override.DeleteJobStateFile
+	}
 
 	return &result
 }
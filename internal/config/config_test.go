@@ -13,8 +13,10 @@ func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
 	assert.Equal(t, "info", cfg.LogLevel)
 	assert.False(t, cfg.LogJSON)
+	assert.Equal(t, "slog", cfg.LogBackend)
 	assert.Empty(t, cfg.MetricsAddr)
 	assert.Equal(t, 5, cfg.PollInterval)
+	assert.Equal(t, -1, cfg.DeleteGracePeriodSeconds)
 }
 
 func TestFromEnv(t *testing.T) {
@@ -34,8 +36,9 @@ func TestFromEnv(t *testing.T) {
 				"FORMATION_BASE_URL": "https://example.com",
 			},
 			expected: &Config{
-				BaseURL:      "https://example.com",
-				PollInterval: 5,
+				BaseURL:                  "https://example.com",
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
 			},
 		},
 		{
@@ -44,8 +47,9 @@ func TestFromEnv(t *testing.T) {
 				"FORMATION_BASE_URL": "https://example.com/",
 			},
 			expected: &Config{
-				BaseURL:      "https://example.com",
-				PollInterval: 5,
+				BaseURL:                  "https://example.com",
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
 			},
 		},
 		{
@@ -55,9 +59,10 @@ func TestFromEnv(t *testing.T) {
 				"FORMATION_TOKEN":    "test-token",
 			},
 			expected: &Config{
-				BaseURL:      "https://example.com",
-				Token:        "test-token",
-				PollInterval: 5,
+				BaseURL:                  "https://example.com",
+				Token:                    "test-token",
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
 			},
 		},
 		{
@@ -68,10 +73,11 @@ func TestFromEnv(t *testing.T) {
 				"FORMATION_PASSWORD": "testpass",
 			},
 			expected: &Config{
-				BaseURL:      "https://example.com",
-				Username:     "testuser",
-				Password:     "testpass",
-				PollInterval: 5,
+				BaseURL:                  "https://example.com",
+				Username:                 "testuser",
+				Password:                 "testpass",
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
 			},
 		},
 		{
@@ -83,11 +89,72 @@ func TestFromEnv(t *testing.T) {
 				"LOG_JSON":           "true",
 			},
 			expected: &Config{
-				BaseURL:      "https://example.com",
-				Token:        "test-token",
-				LogLevel:     "debug",
-				LogJSON:      true,
-				PollInterval: 5,
+				BaseURL:                  "https://example.com",
+				Token:                    "test-token",
+				LogLevel:                 "debug",
+				LogJSON:                  true,
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
+			},
+		},
+		{
+			name: "with otlp logging",
+			envVars: map[string]string{
+				"FORMATION_BASE_URL": "https://example.com",
+				"FORMATION_TOKEN":    "test-token",
+				"LOG_OTLP":           "true",
+			},
+			expected: &Config{
+				BaseURL:                  "https://example.com",
+				Token:                    "test-token",
+				LogOTLP:                  true,
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
+			},
+		},
+		{
+			name: "with log backend",
+			envVars: map[string]string{
+				"FORMATION_BASE_URL": "https://example.com",
+				"FORMATION_TOKEN":    "test-token",
+				"LOG_BACKEND":        "slog",
+			},
+			expected: &Config{
+				BaseURL:                  "https://example.com",
+				Token:                    "test-token",
+				LogBackend:               "slog",
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
+			},
+		},
+		{
+			name: "with per-subsystem log levels",
+			envVars: map[string]string{
+				"FORMATION_BASE_URL": "https://example.com",
+				"FORMATION_TOKEN":    "test-token",
+				"LOG_LEVELS":         "client=debug,workflows=warn",
+			},
+			expected: &Config{
+				BaseURL:                  "https://example.com",
+				Token:                    "test-token",
+				LogLevels:                "client=debug,workflows=warn",
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
+			},
+		},
+		{
+			name: "with log sampling",
+			envVars: map[string]string{
+				"FORMATION_BASE_URL": "https://example.com",
+				"FORMATION_TOKEN":    "test-token",
+				"LOG_SAMPLING":       "true",
+			},
+			expected: &Config{
+				BaseURL:                  "https://example.com",
+				Token:                    "test-token",
+				LogSampling:              true,
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
 			},
 		},
 	}
@@ -130,12 +197,14 @@ metrics_addr: :9090
 poll_interval: 10
 `,
 			expected: &Config{
-				BaseURL:      "https://example.com",
-				Token:        "test-token",
-				LogLevel:     "debug",
-				LogJSON:      true,
-				MetricsAddr:  ":9090",
-				PollInterval: 10,
+				BaseURL:                  "https://example.com",
+				Token:                    "test-token",
+				LogLevel:                 "debug",
+				LogJSON:                  true,
+				LogBackend:               "slog",
+				MetricsAddr:              ":9090",
+				PollInterval:             10,
+				DeleteGracePeriodSeconds: -1,
 			},
 		},
 		{
@@ -146,13 +215,15 @@ username: testuser
 password: testpass
 `,
 			expected: &Config{
-				BaseURL:      "https://example.com",
-				Username:     "testuser",
-				Password:     "testpass",
-				LogLevel:     "info",
-				LogJSON:      false,
-				MetricsAddr:  "",
-				PollInterval: 5,
+				BaseURL:                  "https://example.com",
+				Username:                 "testuser",
+				Password:                 "testpass",
+				LogLevel:                 "info",
+				LogJSON:                  false,
+				LogBackend:               "slog",
+				MetricsAddr:              "",
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
 			},
 		},
 		{
@@ -223,6 +294,151 @@ token: test-token`
 	assert.Equal(t, "https://example.com", cfg.BaseURL)
 }
 
+// writeTempConfig writes content to a temporary YAML file and returns its path.
+func writeTempConfig(t *testing.T, content string) string {
+	t.Helper()
+	tmpfile, err := os.CreateTemp("", "config-*.yaml")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(tmpfile.Name()) })
+
+	_, err = tmpfile.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, tmpfile.Close())
+
+	return tmpfile.Name()
+}
+
+func TestFromFileEnvSubstitution(t *testing.T) {
+	t.Setenv("TEST_FORMATION_TOKEN", "secret-token")
+	t.Setenv("TEST_FORMATION_LOG_LEVEL", "warn")
+
+	path := writeTempConfig(t, `
+base_url: https://example.com
+token: ${TEST_FORMATION_TOKEN}
+log_level: ${TEST_FORMATION_LOG_LEVEL}
+metrics_addr: ${TEST_FORMATION_METRICS_ADDR:-:9090}
+`)
+
+	cfg, err := FromFile(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, "secret-token", cfg.Token)
+	assert.Equal(t, "warn", cfg.LogLevel)
+	assert.Equal(t, ":9090", cfg.MetricsAddr)
+}
+
+func TestFromFileEnvSubstitutionNested(t *testing.T) {
+	t.Setenv("TEST_FORMATION_HOST", "example.com")
+	t.Setenv("TEST_FORMATION_PORT", "8443")
+
+	path := writeTempConfig(t, `
+base_url: https://${TEST_FORMATION_HOST}:${TEST_FORMATION_PORT}
+token: test-token
+`)
+
+	cfg, err := FromFile(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, "https://example.com:8443", cfg.BaseURL)
+}
+
+func TestFromFileEnvSubstitutionEscaped(t *testing.T) {
+	path := writeTempConfig(t, `
+base_url: https://example.com
+token: literal-$$-sign
+`)
+
+	cfg, err := FromFile(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, "literal-$-sign", cfg.Token)
+}
+
+func TestFromFileEnvSubstitutionRequiredMissing(t *testing.T) {
+	path := writeTempConfig(t, `
+base_url: https://example.com
+token: ${TEST_FORMATION_DOES_NOT_EXIST}
+`)
+
+	cfg, err := FromFile(path)
+	assert.Nil(t, cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TEST_FORMATION_DOES_NOT_EXIST")
+	assert.Contains(t, err.Error(), path)
+}
+
+func TestFromFileRawEnvOptOut(t *testing.T) {
+	path := writeTempConfig(t, `
+raw_env: true
+base_url: https://example.com
+token: literal-${NOT_EXPANDED}
+`)
+
+	cfg, err := FromFile(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, "literal-${NOT_EXPANDED}", cfg.Token)
+}
+
+func TestFileStorageLoadSave(t *testing.T) {
+	path := writeTempConfig(t, `
+base_url: https://example.com
+token: test-token
+log_level: debug
+poll_interval: 10
+`)
+
+	storage := NewFileStorage(path)
+	require.NoError(t, storage.Load())
+
+	v, ok := storage.Get(KeyBaseURL)
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com", v)
+
+	storage.Set(KeyLogLevel, "warn")
+	require.NoError(t, storage.Save())
+
+	reloaded := NewFileStorage(path)
+	require.NoError(t, reloaded.Load())
+	v, _ = reloaded.Get(KeyLogLevel)
+	assert.Equal(t, "warn", v)
+}
+
+// memStorage is a minimal in-memory Storage used to test that Load
+// consults whatever backend is registered via RegisterStorage.
+type memStorage struct {
+	values map[string]string
+}
+
+func (s *memStorage) Get(key string) (string, bool) { v, ok := s.values[key]; return v, ok }
+func (s *memStorage) Set(key, value string)          { s.values[key] = value }
+func (s *memStorage) Save() error                    { return nil }
+func (s *memStorage) Load() error                    { return nil }
+
+func TestRegisterStorageIsUsedByLoad(t *testing.T) {
+	// Isolate from env vars a prior test in this file may have left set
+	// (e.g. TestFromEnv's hand-rolled os.Setenv calls): FromEnv takes
+	// precedence over the registered storage backend by design, so a
+	// leftover FORMATION_BASE_URL would otherwise mask the values below.
+	t.Setenv("FORMATION_BASE_URL", "")
+
+	store := &memStorage{values: map[string]string{
+		KeyBaseURL:  "https://from-custom-storage.example.com",
+		KeyToken:    "custom-storage-token",
+		KeyLogLevel: "info",
+	}}
+
+	RegisterStorage(func(path string) Storage { return store })
+	t.Cleanup(func() {
+		RegisterStorage(func(path string) Storage { return NewFileStorage(path) })
+	})
+
+	cfg, err := Load(&Config{ConfigFile: "/unused/path.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://from-custom-storage.example.com", cfg.BaseURL)
+	assert.Equal(t, "custom-storage-token", cfg.Token)
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -296,6 +512,59 @@ func TestValidate(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "valid slog backend",
+			config: &Config{
+				BaseURL:    "https://example.com",
+				Token:      "test-token",
+				LogLevel:   "info",
+				LogBackend: "slog",
+			},
+			expectError: false,
+		},
+		{
+			name: "zerolog backend not available",
+			config: &Config{
+				BaseURL:    "https://example.com",
+				Token:      "test-token",
+				LogLevel:   "info",
+				LogBackend: "zerolog",
+			},
+			expectError: true,
+			errorMsg:    "not available",
+		},
+		{
+			name: "unknown log backend",
+			config: &Config{
+				BaseURL:    "https://example.com",
+				Token:      "test-token",
+				LogLevel:   "info",
+				LogBackend: "bunyan",
+			},
+			expectError: true,
+			errorMsg:    "invalid log backend",
+		},
+		{
+			name: "valid per-subsystem log levels",
+			config: &Config{
+				BaseURL:   "https://example.com",
+				Token:     "test-token",
+				LogLevel:  "info",
+				LogLevels: "client=debug,workflows=warn",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid per-subsystem log levels",
+			config: &Config{
+				BaseURL:   "https://example.com",
+				Token:     "test-token",
+				LogLevel:  "info",
+				LogLevels: "client=verbose",
+			},
+			expectError: true,
+			errorMsg:    "invalid log_levels",
+		},
 	}
 
 	for _, tt := range tests {
@@ -334,12 +603,14 @@ token: file-token`,
 				Token:   "cli-token",
 			},
 			expected: &Config{
-				BaseURL:      "https://cli.com",
-				Token:        "cli-token",
-				LogLevel:     "info",
-				LogJSON:      false,
-				MetricsAddr:  "",
-				PollInterval: 5,
+				BaseURL:                  "https://cli.com",
+				Token:                    "cli-token",
+				LogLevel:                 "info",
+				LogJSON:                  false,
+				LogBackend:               "slog",
+				MetricsAddr:              "",
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
 			},
 		},
 		{
@@ -352,12 +623,14 @@ token: file-token`,
 token: file-token`,
 			cliConfig: &Config{},
 			expected: &Config{
-				BaseURL:      "https://env.com",
-				Token:        "env-token",
-				LogLevel:     "info",
-				LogJSON:      false,
-				MetricsAddr:  "",
-				PollInterval: 5,
+				BaseURL:                  "https://env.com",
+				Token:                    "env-token",
+				LogLevel:                 "info",
+				LogJSON:                  false,
+				LogBackend:               "slog",
+				MetricsAddr:              "",
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
 			},
 		},
 		{
@@ -368,12 +641,14 @@ token: file-token
 log_level: debug`,
 			cliConfig: &Config{},
 			expected: &Config{
-				BaseURL:      "https://file.com",
-				Token:        "file-token",
-				LogLevel:     "debug",
-				LogJSON:      false,
-				MetricsAddr:  "",
-				PollInterval: 5,
+				BaseURL:                  "https://file.com",
+				Token:                    "file-token",
+				LogLevel:                 "debug",
+				LogJSON:                  false,
+				LogBackend:               "slog",
+				MetricsAddr:              "",
+				PollInterval:             5,
+				DeleteGracePeriodSeconds: -1,
 			},
 		},
 	}
@@ -430,3 +705,47 @@ func TestMergeConfigs(t *testing.T) {
 	assert.Equal(t, "debug", result.LogLevel)
 	assert.Equal(t, 5, result.PollInterval)
 }
+
+func TestReloaderAppliesSafeChanges(t *testing.T) {
+	// Load (with no ConfigFile set) falls back to expanding
+	// ~/.formation-mcp.yaml, which needs $HOME - set it explicitly rather
+	// than relying on whatever the environment happened to leave behind
+	// (e.g. TestFromEnv's os.Clearenv never restores it).
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("FORMATION_BASE_URL", "https://example.com")
+	t.Setenv("FORMATION_TOKEN", "test-token")
+	t.Setenv("LOG_LEVEL", "info")
+
+	cliCfg := &Config{}
+	cfg, err := Load(cliCfg)
+	require.NoError(t, err)
+
+	reloader := NewReloader(cliCfg, cfg)
+
+	t.Setenv("LOG_LEVEL", "debug")
+	next, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, "debug", next.LogLevel)
+	assert.Equal(t, "debug", reloader.Current().LogLevel)
+}
+
+func TestReloaderIgnoresImmutableFieldChanges(t *testing.T) {
+	// See TestReloaderAppliesSafeChanges: Load needs $HOME to expand
+	// ~/.formation-mcp.yaml even though no such file exists here.
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("FORMATION_BASE_URL", "https://example.com")
+	t.Setenv("FORMATION_TOKEN", "test-token")
+
+	cliCfg := &Config{}
+	cfg, err := Load(cliCfg)
+	require.NoError(t, err)
+
+	reloader := NewReloader(cliCfg, cfg)
+
+	t.Setenv("FORMATION_BASE_URL", "https://attacker.example.com")
+	t.Setenv("FORMATION_TOKEN", "different-token")
+	next, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", next.BaseURL)
+	assert.Equal(t, "test-token", next.Token)
+}
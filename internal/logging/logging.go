@@ -7,6 +7,7 @@ import (
 	"io"
 	"log/slog"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,13 +16,16 @@ import (
 // 2025-11-03T10:15:30.123 INFO message key1=value1 key2=value2
 type HumanReadableHandler struct {
 	out   io.Writer
-	level slog.Level
+	level slog.Leveler
 	attrs []slog.Attr
 	group string
 }
 
-// NewHumanReadableHandler creates a new HumanReadableHandler.
-func NewHumanReadableHandler(w io.Writer, level slog.Level) *HumanReadableHandler {
+// NewHumanReadableHandler creates a new HumanReadableHandler. level is a
+// slog.Leveler rather than a bare slog.Level so a caller can pass a
+// *slog.LevelVar and change the handler's verbosity later without
+// rebuilding it - see SubsystemLevelHandler.
+func NewHumanReadableHandler(w io.Writer, level slog.Leveler) *HumanReadableHandler {
 	return &HumanReadableHandler{
 		out:   w,
 		level: level,
@@ -30,7 +34,7 @@ func NewHumanReadableHandler(w io.Writer, level slog.Level) *HumanReadableHandle
 
 // Enabled reports whether the handler handles records at the given level.
 func (h *HumanReadableHandler) Enabled(_ context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.level.Level()
 }
 
 // Handle handles the record.
@@ -153,24 +157,181 @@ func (h *HumanReadableHandler) WithGroup(name string) slog.Handler {
 
 // Setup configures the global slog logger based on the provided configuration.
 func Setup(w io.Writer, logLevel string, useJSON bool) *slog.Logger {
-	level := parseLevel(logLevel)
-
-	var handler slog.Handler
+	format := LogFormatHuman
 	if useJSON {
-		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{
-			Level: level,
-		})
-	} else {
-		handler = NewHumanReadableHandler(w, level)
+		format = LogFormatJSON
 	}
+	return SetupWithFormat(w, logLevel, format)
+}
 
+// SetupWithFormat behaves like Setup, but accepts any LogFormat (including
+// LogFormatOTLP) rather than just the human/JSON choice useJSON offers.
+func SetupWithFormat(w io.Writer, logLevel string, format LogFormat) *slog.Logger {
+	handler := BuildHandlerWithFormat(w, logLevel, format)
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 	return logger
 }
 
-// parseLevel converts a string log level to slog.Level
-func parseLevel(level string) slog.Level {
+// SetupReloadable behaves like Setup but wraps the resulting handler in a
+// ReloadableHandler, so its output destination or format can be changed
+// later (e.g. in response to a SIGHUP-triggered config reload) via the
+// returned handler's Swap method, without callers needing a new logger.
+func SetupReloadable(w io.Writer, logLevel string, useJSON bool) (*slog.Logger, *ReloadableHandler) {
+	handler := NewReloadableHandler(BuildHandler(w, logLevel, useJSON))
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	return logger, handler
+}
+
+// BuildHandler constructs the handler matching the given log level and
+// format, without installing it as the default logger. It is shared by
+// Setup and SetupReloadable, and can be used to build a replacement
+// handler to pass to ReloadableHandler.Swap.
+func BuildHandler(w io.Writer, logLevel string, useJSON bool) slog.Handler {
+	format := LogFormatHuman
+	if useJSON {
+		format = LogFormatJSON
+	}
+	return BuildHandlerWithFormat(w, logLevel, format)
+}
+
+// LogFormat selects the handler BuildHandlerWithFormat constructs.
+type LogFormat string
+
+const (
+	LogFormatHuman LogFormat = "human"
+	LogFormatJSON  LogFormat = "json"
+	LogFormatOTLP  LogFormat = "otlp"
+)
+
+// BuildHandlerWithFormat is BuildHandler with a third option beyond
+// human/JSON: "otlp" emits the OpenTelemetry Logs Data Model JSON that
+// OTLPHandler produces, for operators who want to ship formation-mcp logs
+// into the same collector as their traces.
+func BuildHandlerWithFormat(w io.Writer, logLevel string, format LogFormat) slog.Handler {
+	return BuildHandlerWithLeveler(w, ParseLevel(logLevel), format)
+}
+
+// BuildHandlerWithLeveler is BuildHandlerWithFormat with a slog.Leveler
+// instead of a level string, so a caller wanting to change a sink's
+// verbosity at runtime (e.g. in response to the set_log_level MCP tool)
+// can pass a *slog.LevelVar instead of rebuilding the handler.
+func BuildHandlerWithLeveler(w io.Writer, level slog.Leveler, format LogFormat) slog.Handler {
+	switch format {
+	case LogFormatJSON:
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	case LogFormatOTLP:
+		return NewOTLPHandler(w, level)
+	default:
+		return NewHumanReadableHandler(w, level)
+	}
+}
+
+// ReloadableHandler is a slog.Handler whose underlying handler can be
+// swapped atomically. A *slog.Logger built on top of one survives a
+// swap transparently, so goroutines holding a reference to the logger
+// from before a config reload keep logging through the new handler.
+type ReloadableHandler struct {
+	handler atomic.Pointer[slog.Handler]
+}
+
+// NewReloadableHandler wraps h so it can be replaced later via Swap.
+func NewReloadableHandler(h slog.Handler) *ReloadableHandler {
+	rh := &ReloadableHandler{}
+	rh.handler.Store(&h)
+	return rh
+}
+
+// Swap atomically replaces the underlying handler. Safe to call
+// concurrently with logging from other goroutines.
+//
+// Note: a handler derived from this one via WithAttrs/WithGroup is not
+// itself reloadable - it captures the underlying handler at the time it
+// was derived. This matches how slog.Logger.With works elsewhere and is
+// fine for the global default logger, which is what reload targets.
+func (r *ReloadableHandler) Swap(h slog.Handler) {
+	r.handler.Store(&h)
+}
+
+// Enabled implements slog.Handler.
+func (r *ReloadableHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return (*r.handler.Load()).Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (r *ReloadableHandler) Handle(ctx context.Context, rec slog.Record) error {
+	return (*r.handler.Load()).Handle(ctx, rec)
+}
+
+// WithAttrs implements slog.Handler.
+func (r *ReloadableHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return (*r.handler.Load()).WithAttrs(attrs)
+}
+
+// WithGroup implements slog.Handler.
+func (r *ReloadableHandler) WithGroup(name string) slog.Handler {
+	return (*r.handler.Load()).WithGroup(name)
+}
+
+// multiHandler fans a record out to several handlers, so a deployment can
+// log to stderr, a rotating file, and syslog at the same time.
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+// NewMultiHandler combines handlers into a single slog.Handler that fans
+// every record out to each of them.
+func NewMultiHandler(handlers ...slog.Handler) slog.Handler {
+	return &multiHandler{handlers: handlers}
+}
+
+// Enabled implements slog.Handler, reporting enabled if any sink is.
+func (m *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle implements slog.Handler, passing the record to every enabled sink.
+// It continues on error, returning the first one encountered.
+func (m *multiHandler) Handle(ctx context.Context, rec slog.Record) error {
+	var firstErr error
+	for _, h := range m.handlers {
+		if !h.Enabled(ctx, rec.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, rec.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// WithAttrs implements slog.Handler.
+func (m *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := make([]slog.Handler, len(m.handlers))
+	for i, h := range m.handlers {
+		next[i] = h.WithAttrs(attrs)
+	}
+	return &multiHandler{handlers: next}
+}
+
+// WithGroup implements slog.Handler.
+func (m *multiHandler) WithGroup(name string) slog.Handler {
+	next := make([]slog.Handler, len(m.handlers))
+	for i, h := range m.handlers {
+		next[i] = h.WithGroup(name)
+	}
+	return &multiHandler{handlers: next}
+}
+
+// ParseLevel converts a string log level ("debug", "info", "warn"/"warning",
+// "error") to a slog.Level, defaulting to LevelInfo for anything else.
+func ParseLevel(level string) slog.Level {
 	switch strings.ToLower(level) {
 	case "debug":
 		return slog.LevelDebug
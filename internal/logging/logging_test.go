@@ -2,8 +2,11 @@ package logging
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -276,7 +279,7 @@ func TestParseLevel(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			level := parseLevel(tt.input)
+			level := ParseLevel(tt.input)
 			assert.Equal(t, tt.expected, level)
 		})
 	}
@@ -319,3 +322,263 @@ func TestSetupWithDifferentLevels(t *testing.T) {
 		})
 	}
 }
+
+func TestReloadableHandlerSwap(t *testing.T) {
+	var humanBuf, jsonBuf bytes.Buffer
+
+	logger, handler := SetupReloadable(&humanBuf, "info", false)
+	logger.Info("before swap")
+	assert.Contains(t, humanBuf.String(), "before swap")
+	assert.Empty(t, jsonBuf.String())
+
+	handler.Swap(BuildHandler(&jsonBuf, "info", true))
+	logger.Info("after swap")
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonBuf.Bytes(), &decoded))
+	assert.Equal(t, "after swap", decoded["msg"])
+}
+
+func TestMultiHandlerFansOutToEachSink(t *testing.T) {
+	var bufA, bufB bytes.Buffer
+	handler := NewMultiHandler(
+		NewHumanReadableHandler(&bufA, slog.LevelInfo),
+		NewHumanReadableHandler(&bufB, slog.LevelInfo),
+	)
+
+	logger := slog.New(handler)
+	logger.Info("fan out")
+
+	assert.Contains(t, bufA.String(), "fan out")
+	assert.Contains(t, bufB.String(), "fan out")
+}
+
+func TestMultiHandlerRespectsPerSinkLevel(t *testing.T) {
+	var debugBuf, infoBuf bytes.Buffer
+	handler := NewMultiHandler(
+		NewHumanReadableHandler(&debugBuf, slog.LevelDebug),
+		NewHumanReadableHandler(&infoBuf, slog.LevelInfo),
+	)
+
+	logger := slog.New(handler)
+	logger.Debug("debug only")
+
+	assert.Contains(t, debugBuf.String(), "debug only")
+	assert.Empty(t, infoBuf.String())
+}
+
+func TestRotatingFileHandlerRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/formation-mcp.log"
+
+	// maxSizeMB can't go below 1MB, so drive rotation directly through the
+	// writer to keep the test fast.
+	w, err := newRotatingWriter(path, 0, 0, 0)
+	require.NoError(t, err)
+	defer w.Close()
+	w.maxSizeMB = 1 // size-based rotation on; rotate is called directly below
+
+	// Force rotation manually rather than writing a full megabyte.
+	_, err = w.file.WriteString("existing content\n")
+	require.NoError(t, err)
+	require.NoError(t, w.rotate())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, len(entries), 2, "expected original plus rotated backup")
+
+	handler, err := NewRotatingFileHandler(path, 0, 0, 0, "info", false)
+	require.NoError(t, err)
+	defer handler.(io.Closer).Close()
+	logger := slog.New(handler)
+	logger.Info("after rotation")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "after rotation")
+}
+
+func TestRotatingFileHandlerPrunesOldBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/formation-mcp.log"
+
+	w, err := newRotatingWriter(path, 0, 1, 0)
+	require.NoError(t, err)
+	defer w.Close()
+
+	old := path + ".20000101T000000"
+	require.NoError(t, os.WriteFile(old, []byte("old"), 0o644))
+	require.NoError(t, os.Chtimes(old, time.Now().AddDate(0, 0, -30), time.Now().AddDate(0, 0, -30)))
+
+	w.pruneOld()
+
+	_, err = os.Stat(old)
+	assert.True(t, os.IsNotExist(err), "expected old backup to be pruned")
+}
+
+func TestRotatingFileHandlerPrunesByBackupCount(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/formation-mcp.log"
+
+	w, err := newRotatingWriter(path, 0, 0, 2)
+	require.NoError(t, err)
+	defer w.Close()
+
+	now := time.Now()
+	names := []string{
+		path + ".20000101T000000",
+		path + ".20000102T000000",
+		path + ".20000103T000000",
+	}
+	for i, name := range names {
+		require.NoError(t, os.WriteFile(name, []byte("old"), 0o644))
+		// Oldest first, so the newest two (indices 1 and 2) are the ones
+		// that should survive pruning to maxBackups=2.
+		modTime := now.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(name, modTime, modTime))
+	}
+
+	w.pruneOld()
+
+	_, err = os.Stat(names[0])
+	assert.True(t, os.IsNotExist(err), "expected oldest backup beyond maxBackups to be pruned")
+	for _, name := range names[1:] {
+		_, err = os.Stat(name)
+		assert.NoError(t, err, "expected newest backups within maxBackups to survive")
+	}
+}
+
+func TestOTLPHandlerEmitsLogsDataModelFields(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewOTLPHandler(&buf, slog.LevelInfo)
+	logger := slog.New(handler).With("component", "watcher")
+
+	ctx := WithTraceContext(context.Background(), "trace-abc", "span-123")
+	logger.InfoContext(ctx, "polling analysis", "analysis_id", "a-1")
+
+	var rec otlpRecord
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+
+	assert.Equal(t, "INFO", rec.SeverityText)
+	assert.Equal(t, 9, rec.SeverityNumber)
+	assert.Equal(t, "polling analysis", rec.Body.StringValue)
+	assert.Equal(t, "trace-abc", rec.TraceID)
+	assert.Equal(t, "span-123", rec.SpanID)
+	assert.Equal(t, "formation-mcp", rec.Resource.Attributes[0].Value.StringValue)
+
+	attrs := map[string]string{}
+	for _, kv := range rec.Attributes {
+		attrs[kv.Key] = kv.Value.StringValue
+	}
+	assert.Equal(t, "watcher", attrs["component"])
+	assert.Equal(t, "a-1", attrs["analysis_id"])
+}
+
+func TestOTLPHandlerWithoutTraceContext(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewOTLPHandler(&buf, slog.LevelWarn)
+	logger := slog.New(handler)
+
+	logger.Warn("no trace here")
+
+	var rec otlpRecord
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	assert.Empty(t, rec.TraceID)
+	assert.Empty(t, rec.SpanID)
+	assert.Equal(t, 13, rec.SeverityNumber)
+}
+
+func TestSlogLoggerWritesThroughUnderlyingLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	logger.Info("hello", "key", "value")
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "hello", m["msg"])
+	assert.Equal(t, "value", m["key"])
+}
+
+func TestSlogLoggerWithAndWithGroup(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	logger.With("component", "watcher").WithGroup("req").Info("tick", "n", 1)
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "watcher", m["component"])
+	req, ok := m["req"].(map[string]interface{})
+	require.True(t, ok, "expected a req group in the output")
+	assert.Equal(t, float64(1), req["n"])
+}
+
+func TestNoopLoggerDiscardsEverything(t *testing.T) {
+	var logger Logger = NoopLogger{}
+
+	// None of these should panic; there's nothing else to assert since a
+	// NoopLogger has no observable output.
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+	logger = logger.With("k", "v").WithGroup("g")
+	logger.Info("still discarded")
+}
+
+func TestTestLoggerCapturesEntries(t *testing.T) {
+	logger := NewTestLogger()
+
+	logger.Info("plain message", "a", 1)
+	logger.With("request_id", "r-1").Warn("annotated message", "b", 2)
+
+	entries := logger.Entries()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "INFO", entries[0].Level)
+	assert.Equal(t, "plain message", entries[0].Msg)
+	assert.Equal(t, []any{"a", 1}, entries[0].Args)
+
+	assert.Equal(t, "WARN", entries[1].Level)
+	assert.Equal(t, "annotated message", entries[1].Msg)
+	assert.Equal(t, []any{"request_id", "r-1", "b", 2}, entries[1].Args)
+}
+
+func TestTestLoggerWithGroupIsRecorded(t *testing.T) {
+	logger := NewTestLogger()
+
+	logger.WithGroup("outer").WithGroup("inner").Error("boom")
+
+	entries := logger.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "outer.inner", entries[0].Group)
+}
+
+func TestBuildLoggerDefaultsToSlog(t *testing.T) {
+	var buf bytes.Buffer
+	underlying := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	logger := BuildLogger(LogBackendSlog, underlying)
+	logger.Info("via build logger")
+
+	assert.Contains(t, buf.String(), "via build logger")
+}
+
+func TestTraceContextHelpers(t *testing.T) {
+	ctx := context.Background()
+
+	if _, ok := TraceIDFromContext(ctx); ok {
+		t.Error("TraceIDFromContext() found a trace ID on a bare context")
+	}
+
+	ctx = WithTraceContext(ctx, "trace-xyz", "span-1")
+
+	traceID, ok := TraceIDFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "trace-xyz", traceID)
+
+	spanID, ok := SpanIDFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "span-1", spanID)
+}
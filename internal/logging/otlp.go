@@ -0,0 +1,200 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// traceContextKey is the context.Context key WithTraceContext stores the
+// active trace/span IDs under, so an OTLPHandler can correlate a log line
+// with whatever trace a caller has wired in around it - e.g. a long
+// analysis-polling loop that wants every slog call inside it tagged with
+// the same trace_id.
+type traceContextKey struct{}
+
+type traceContext struct {
+	traceID string
+	spanID  string
+}
+
+// WithTraceContext returns a context carrying traceID/spanID, so any log
+// line emitted through it via an OTLPHandler is correlated with the active
+// trace. formation-mcp has no tracer of its own; this is the seam a caller
+// wraps around an MCP tool handler once it does.
+func WithTraceContext(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, traceContext{traceID: traceID, spanID: spanID})
+}
+
+// TraceIDFromContext returns the trace ID WithTraceContext stored on ctx,
+// if any.
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(traceContext)
+	return tc.traceID, ok && tc.traceID != ""
+}
+
+// SpanIDFromContext returns the span ID WithTraceContext stored on ctx, if
+// any.
+func SpanIDFromContext(ctx context.Context) (string, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(traceContext)
+	return tc.spanID, ok && tc.spanID != ""
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+// otlpResourceAttrs is the fixed "resource" block OTLPHandler stamps on
+// every record, identifying the emitting service the way the OpenTelemetry
+// Logs Data Model expects.
+var otlpResourceAttrs = []otlpKeyValue{
+	{Key: "service.name", Value: otlpValue{StringValue: "formation-mcp"}},
+}
+
+// otlpRecord is one line of OTLPHandler's output, using the OpenTelemetry
+// Logs Data Model's field names so an OTLP/JSON-compatible collector (e.g.
+// its otlpjson file receiver) can ingest it directly.
+type otlpRecord struct {
+	TimeUnixNano   string         `json:"time_unix_nano"`
+	SeverityNumber int            `json:"severity_number"`
+	SeverityText   string         `json:"severity_text"`
+	Body           otlpValue      `json:"body"`
+	Attributes     []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID        string         `json:"trace_id,omitempty"`
+	SpanID         string         `json:"span_id,omitempty"`
+	Resource       otlpResource   `json:"resource"`
+}
+
+// OTLPHandler is a slog.Handler that emits one OTel Logs Data Model JSON
+// object per record, so formation-mcp's logs can be shipped into any
+// OTLP-compatible sink alongside traces of long-running analysis polling
+// loops. Trace/span IDs are pulled from the record's context via
+// TraceIDFromContext/SpanIDFromContext, when WithTraceContext set them.
+type OTLPHandler struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level slog.Leveler
+	attrs []otlpKeyValue
+	group string
+}
+
+// NewOTLPHandler creates an OTLPHandler writing to w at the given level.
+// level is a slog.Leveler rather than a bare slog.Level so a caller can
+// pass a *slog.LevelVar and change the handler's verbosity later without
+// rebuilding it - see SubsystemLevelHandler.
+func NewOTLPHandler(w io.Writer, level slog.Leveler) *OTLPHandler {
+	return &OTLPHandler{out: w, level: level}
+}
+
+// Enabled implements slog.Handler.
+func (h *OTLPHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *OTLPHandler) Handle(ctx context.Context, r slog.Record) error {
+	rec := otlpRecord{
+		TimeUnixNano:   fmt.Sprintf("%d", r.Time.UnixNano()),
+		SeverityNumber: otlpSeverityNumber(r.Level),
+		SeverityText:   r.Level.String(),
+		Body:           otlpValue{StringValue: r.Message},
+		Attributes:     append([]otlpKeyValue{}, h.attrs...),
+		Resource:       otlpResource{Attributes: otlpResourceAttrs},
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		rec.Attributes = append(rec.Attributes, flattenOTLPAttr(h.group, a)...)
+		return true
+	})
+
+	if traceID, ok := TraceIDFromContext(ctx); ok {
+		rec.TraceID = traceID
+	}
+	if spanID, ok := SpanIDFromContext(ctx); ok {
+		rec.SpanID = spanID
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.out.Write(data)
+	return err
+}
+
+// flattenOTLPAttr renders a, prefixing its key with group (dotted, as
+// HumanReadableHandler does), and recursing into group-kind values so a
+// WithGroup'd attribute still shows up as individual key/value pairs
+// rather than one opaque blob.
+func flattenOTLPAttr(group string, a slog.Attr) []otlpKeyValue {
+	key := a.Key
+	if group != "" {
+		key = group + "." + key
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		var out []otlpKeyValue
+		for _, sub := range a.Value.Group() {
+			out = append(out, flattenOTLPAttr(key, sub)...)
+		}
+		return out
+	}
+
+	return []otlpKeyValue{{Key: key, Value: otlpValue{StringValue: fmt.Sprint(a.Value.Any())}}}
+}
+
+// WithAttrs implements slog.Handler.
+func (h *OTLPHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := append([]otlpKeyValue{}, h.attrs...)
+	for _, a := range attrs {
+		next = append(next, flattenOTLPAttr(h.group, a)...)
+	}
+	return &OTLPHandler{out: h.out, level: h.level, attrs: next, group: h.group}
+}
+
+// WithGroup implements slog.Handler.
+func (h *OTLPHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	group := name
+	if h.group != "" {
+		group = h.group + "." + name
+	}
+	return &OTLPHandler{out: h.out, level: h.level, attrs: h.attrs, group: group}
+}
+
+// otlpSeverityNumber maps an slog.Level to the OpenTelemetry Logs Data
+// Model's SeverityNumber enum (1-24, TRACE through FATAL). slog has no
+// levels below Debug or above Error, so this only ever produces the
+// DEBUG/INFO/WARN/ERROR bands (5-8, 9-12, 13-16, 17-20), offset within each
+// band by how far a custom level (e.g. slog.LevelInfo+2, which maps to 11)
+// sits above its band's base level.
+func otlpSeverityNumber(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return 5 + int(level-slog.LevelDebug)
+	case level < slog.LevelWarn:
+		return 9 + int(level-slog.LevelInfo)
+	case level < slog.LevelError:
+		return 13 + int(level-slog.LevelWarn)
+	default:
+		return 17 + int(level-slog.LevelError)
+	}
+}
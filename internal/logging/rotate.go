@@ -0,0 +1,242 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pruneTick is how often a rotatingWriter's background goroutine checks
+// for backups to prune by age or count, independent of whether a write
+// happens to trigger a size-based rotation in the meantime. Pruning is
+// cheap (a directory listing), so an hour is frequent enough that a
+// long-lived, low-traffic MCP session doesn't accumulate backups for days
+// before the next size-triggered rotation gets around to pruning them.
+const pruneTick = time.Hour
+
+// rotatingWriter is an io.Writer that writes to a file, rotating it once
+// it exceeds maxSizeMB and pruning rotated backups older than maxAgeDays
+// or beyond maxBackups. It gives long-running, stdio-based MCP
+// deployments lumberjack-like size+age+count rollover without an
+// external log shipper - important since formation-mcp's stdio transport
+// means stderr, its only other log channel, disappears with the MCP
+// client process.
+type rotatingWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxSizeMB  int
+	maxAgeDays int
+	maxBackups int
+	file       *os.File
+	size       int64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newRotatingWriter opens (creating if necessary) the log file at path
+// and starts the background goroutine that prunes backups every
+// pruneTick. Callers must call Close when done with it, to stop that
+// goroutine.
+func newRotatingWriter(path string, maxSizeMB, maxAgeDays, maxBackups int) (*rotatingWriter, error) {
+	w := &rotatingWriter{
+		path:       path,
+		maxSizeMB:  maxSizeMB,
+		maxAgeDays: maxAgeDays,
+		maxBackups: maxBackups,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	go w.pruneLoop()
+
+	return w, nil
+}
+
+// pruneLoop runs pruneOld every pruneTick until Close is called.
+func (w *rotatingWriter) pruneLoop() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(pruneTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.pruneOld()
+		}
+	}
+}
+
+// Close stops the background pruning goroutine and closes the underlying
+// file. Safe to call more than once.
+func (w *rotatingWriter) Close() error {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+func (w *rotatingWriter) open() error {
+	if dir := filepath.Dir(w.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// Write implements io.Writer, rotating the file first if writing p would
+// put it over maxSizeMB (a maxSizeMB of 0 disables size-based rotation).
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSizeMB > 0 {
+		maxBytes := int64(w.maxSizeMB) * 1024 * 1024
+		if w.size+int64(len(p)) > maxBytes {
+			if err := w.rotate(); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate closes the current file, renames it with a timestamp suffix,
+// opens a fresh file in its place, and prunes old rotated backups.
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file for rotation: %w", err)
+	}
+
+	rotated := fmt.Sprintf("%s.%s", w.path, time.Now().Format("20060102T150405"))
+	if err := os.Rename(w.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.pruneOld()
+	return nil
+}
+
+// pruneOld removes rotated backups older than maxAgeDays (0 disables age
+// pruning) and, among whatever survives that, all but the maxBackups most
+// recent (0 disables count pruning). Failures are ignored - pruning is
+// best-effort and must never block logging.
+func (w *rotatingWriter) pruneOld() {
+	if w.maxAgeDays <= 0 && w.maxBackups <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(w.path)
+	base := filepath.Base(w.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	type backup struct {
+		name    string
+		modTime time.Time
+	}
+	var backups []backup
+
+	cutoff := time.Now().AddDate(0, 0, -w.maxAgeDays)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if w.maxAgeDays > 0 && info.ModTime().Before(cutoff) {
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		backups = append(backups, backup{name: name, modTime: info.ModTime()})
+	}
+
+	if w.maxBackups <= 0 || len(backups) <= w.maxBackups {
+		return
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].modTime.After(backups[j].modTime) })
+	for _, b := range backups[w.maxBackups:] {
+		_ = os.Remove(filepath.Join(dir, b.name))
+	}
+}
+
+// rotatingFileHandler pairs a slog.Handler with the rotatingWriter backing
+// it, so a caller replacing it (e.g. after a SIGHUP config reload builds a
+// new one) can Close the old one to stop its background pruning goroutine
+// and release its file, instead of leaking both.
+type rotatingFileHandler struct {
+	slog.Handler
+	w *rotatingWriter
+}
+
+// Close stops the handler's rotatingWriter. See rotatingWriter.Close.
+func (h *rotatingFileHandler) Close() error {
+	return h.w.Close()
+}
+
+// NewRotatingFileHandler builds a slog.Handler (human-readable or JSON,
+// matching useJSON) that writes to path, rotating it once it exceeds
+// maxSizeMB and pruning rotated backups older than maxAgeDays or beyond
+// maxBackups. A maxSizeMB, maxAgeDays, or maxBackups of 0 disables that
+// behavior. The returned handler also implements io.Closer; callers
+// replacing it should Close the old one once it's no longer in use.
+func NewRotatingFileHandler(path string, maxSizeMB, maxAgeDays, maxBackups int, logLevel string, useJSON bool) (slog.Handler, error) {
+	w, err := newRotatingWriter(path, maxSizeMB, maxAgeDays, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	level := ParseLevel(logLevel)
+	var handler slog.Handler
+	if useJSON {
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	} else {
+		handler = NewHumanReadableHandler(w, level)
+	}
+
+	return &rotatingFileHandler{Handler: handler, w: w}, nil
+}
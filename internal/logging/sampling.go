@@ -0,0 +1,181 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SamplingOptions configures NewSamplingHandler's zerolog-style sampling:
+// the first First debug records sharing a (level, message, caller) key
+// within Interval are logged in full, then only 1 in Thereafter after
+// that, with the number suppressed since the last kept record attached to
+// it as sampled_dropped=<n> so operators can tell suppression is
+// happening instead of assuming the loop went quiet.
+type SamplingOptions struct {
+	// First is how many records of a key are kept before sampling kicks
+	// in, each time Interval elapses.
+	First int
+
+	// Thereafter is the 1-in-N rate applied once First has been kept for
+	// a key within the current Interval.
+	Thereafter int
+
+	// Interval is how long a key's counters stay in effect before
+	// resetting back to First.
+	Interval time.Duration
+
+	// MaxKeys bounds the LRU of per-key counters, so a caller that varies
+	// its message on every call (and so never reuses a key) can't grow it
+	// without bound.
+	MaxKeys int
+}
+
+// DefaultSamplingOptions returns the SamplingOptions --log-sampling uses:
+// the first 5 records of a key per second, then 1 in 100 thereafter,
+// tracking up to 1000 distinct keys.
+func DefaultSamplingOptions() SamplingOptions {
+	return SamplingOptions{First: 5, Thereafter: 100, Interval: time.Second, MaxKeys: 1000}
+}
+
+// samplingCounter tracks one (level, message, caller) key's state within
+// SamplingHandler's LRU.
+type samplingCounter struct {
+	key         string
+	windowStart time.Time
+	count       int
+	dropped     int
+}
+
+// SamplingHandler wraps inner, rate-limiting slog.LevelDebug records so a
+// hot loop (the workflow layer's analysis status polling, notably) can't
+// flood a sink - Warn and Error always pass through untouched, and Info
+// isn't noisy enough in this codebase to need it either. Records at other
+// levels, and the first SamplingOptions.First debug records of a given
+// (level, message, caller) key per Interval, pass through unchanged;
+// after that only 1 in Thereafter is kept, with sampled_dropped=<n>
+// attached to report how many were suppressed since the last one kept.
+type SamplingHandler struct {
+	next slog.Handler
+	opts SamplingOptions
+
+	mu    sync.Mutex
+	order []*samplingCounter // most-recently-used last
+	byKey map[string]*samplingCounter
+}
+
+// NewSamplingHandler wraps inner with sampling governed by opts. Zero
+// fields in opts fall back to DefaultSamplingOptions's values.
+func NewSamplingHandler(inner slog.Handler, opts SamplingOptions) *SamplingHandler {
+	defaults := DefaultSamplingOptions()
+	if opts.First <= 0 {
+		opts.First = defaults.First
+	}
+	if opts.Thereafter <= 0 {
+		opts.Thereafter = defaults.Thereafter
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaults.Interval
+	}
+	if opts.MaxKeys <= 0 {
+		opts.MaxKeys = defaults.MaxKeys
+	}
+	return &SamplingHandler{next: inner, opts: opts, byKey: make(map[string]*samplingCounter)}
+}
+
+// Enabled implements slog.Handler.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler, sampling r if it's a debug record and
+// passing everything else straight through to next.
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level != slog.LevelDebug {
+		return h.next.Handle(ctx, r)
+	}
+
+	dropped, keep := h.allow(samplingKey(r))
+	if !keep {
+		return nil
+	}
+	if dropped > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Int("sampled_dropped", dropped))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{next: h.next.WithAttrs(attrs), opts: h.opts, byKey: h.byKey, order: h.order}
+}
+
+// WithGroup implements slog.Handler.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{next: h.next.WithGroup(name), opts: h.opts, byKey: h.byKey, order: h.order}
+}
+
+// samplingKey identifies a record for sampling purposes by its level,
+// message, and call site, so "polling analysis status" debug logs from
+// one call site are sampled separately from another's.
+func samplingKey(r slog.Record) string {
+	return fmt.Sprintf("%d|%s|%d", r.Level, r.Message, r.PC)
+}
+
+// allow reports whether the next record with key should be kept, and if
+// so, how many since the last kept record for key were dropped.
+func (h *SamplingHandler) allow(key string) (dropped int, keep bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	c, ok := h.byKey[key]
+	if !ok {
+		c = &samplingCounter{key: key, windowStart: now}
+		h.byKey[key] = c
+		h.evictLocked()
+	}
+	h.touchLocked(c)
+
+	if now.Sub(c.windowStart) >= h.opts.Interval {
+		c.windowStart = now
+		c.count = 0
+	}
+	c.count++
+
+	if c.count <= h.opts.First {
+		return 0, true
+	}
+	if (c.count-h.opts.First)%h.opts.Thereafter != 0 {
+		c.dropped++
+		return 0, false
+	}
+
+	dropped, c.dropped = c.dropped, 0
+	return dropped, true
+}
+
+// touchLocked moves c to the back of h.order, marking it most-recently
+// used for evictLocked's purposes. h.mu must be held.
+func (h *SamplingHandler) touchLocked(c *samplingCounter) {
+	for i, existing := range h.order {
+		if existing == c {
+			h.order = append(h.order[:i], h.order[i+1:]...)
+			break
+		}
+	}
+	h.order = append(h.order, c)
+}
+
+// evictLocked drops the least-recently-used counter once byKey grows
+// past MaxKeys. h.mu must be held.
+func (h *SamplingHandler) evictLocked() {
+	for len(h.byKey) > h.opts.MaxKeys && len(h.order) > 0 {
+		oldest := h.order[0]
+		h.order = h.order[1:]
+		delete(h.byKey, oldest.key)
+	}
+}
@@ -0,0 +1,169 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// SubsystemKey is the slog attribute key a package-level logger sets (via
+// slog.Logger.With(SubsystemKey, "client")) to identify itself to a
+// SubsystemLevelHandler, so set_log_level/get_log_level can tune
+// client/server/workflows verbosity independently instead of changing one
+// level for the whole process.
+const SubsystemKey = "subsystem"
+
+// SubsystemLevels holds per-subsystem level overrides, mutable at runtime
+// (by the set_log_level MCP tool, or hourly ops work) and safe for
+// concurrent use by any number of logging goroutines.
+type SubsystemLevels struct {
+	mu        sync.RWMutex
+	overrides map[string]slog.Level
+}
+
+// NewSubsystemLevels creates an empty SubsystemLevels - every subsystem
+// falls back to a SubsystemLevelHandler's base level until Set is called.
+func NewSubsystemLevels() *SubsystemLevels {
+	return &SubsystemLevels{overrides: make(map[string]slog.Level)}
+}
+
+// Set overrides subsystem's level.
+func (s *SubsystemLevels) Set(subsystem string, level slog.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[subsystem] = level
+}
+
+// Clear removes subsystem's override, so it falls back to the base level
+// again.
+func (s *SubsystemLevels) Clear(subsystem string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.overrides, subsystem)
+}
+
+// Get returns subsystem's override level, if one is set.
+func (s *SubsystemLevels) Get(subsystem string) (slog.Level, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	level, ok := s.overrides[subsystem]
+	return level, ok
+}
+
+// All returns a copy of every subsystem currently overridden, keyed by
+// name, for get_log_level to report.
+func (s *SubsystemLevels) All() map[string]slog.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]slog.Level, len(s.overrides))
+	for k, v := range s.overrides {
+		out[k] = v
+	}
+	return out
+}
+
+// ParseSubsystemLevels parses a comma-separated subsystem=level list (e.g.
+// "client=debug,workflows=info,server=warn") into a SubsystemLevels, for
+// wiring a --log-levels flag or LOG_LEVELS env var into main.
+func ParseSubsystemLevels(spec string) (*SubsystemLevels, error) {
+	levels := NewSubsystemLevels()
+	if spec == "" {
+		return levels, nil
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		subsystem, levelStr, ok := strings.Cut(pair, "=")
+		subsystem = strings.TrimSpace(subsystem)
+		if !ok || subsystem == "" {
+			return nil, fmt.Errorf("invalid log level override %q: expected subsystem=level", pair)
+		}
+		level, err := ParseLevelStrict(levelStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level override %q: %w", pair, err)
+		}
+		levels.Set(subsystem, level)
+	}
+
+	return levels, nil
+}
+
+// ParseLevelStrict is like ParseLevel but rejects an unrecognized level
+// string instead of silently defaulting to info, since a typo in a
+// --log-levels flag or a set_log_level call should be reported back to
+// the caller, not swallowed.
+func ParseLevelStrict(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown level %q (must be debug, info, warn, or error)", s)
+	}
+}
+
+// SubsystemLevelHandler wraps a slog.Handler, consulting levels for a
+// per-subsystem override - set via a logger's
+// .With(SubsystemKey, name) - in place of base's level, when one exists
+// for that logger's subsystem. A logger that hasn't tagged itself with
+// SubsystemKey (or whose subsystem has no override) behaves exactly like
+// base.
+//
+// base is a *slog.LevelVar rather than a fixed slog.Level so the global
+// level, too, can change at runtime - e.g. via the set_log_level MCP
+// tool - without rebuilding the handler chain.
+type SubsystemLevelHandler struct {
+	next      slog.Handler
+	base      *slog.LevelVar
+	levels    *SubsystemLevels
+	subsystem string
+}
+
+// NewSubsystemLevelHandler wraps next, consulting levels for a subsystem
+// override and falling back to base otherwise.
+func NewSubsystemLevelHandler(next slog.Handler, base *slog.LevelVar, levels *SubsystemLevels) *SubsystemLevelHandler {
+	return &SubsystemLevelHandler{next: next, base: base, levels: levels}
+}
+
+// Enabled implements slog.Handler, consulting h's subsystem override if
+// one is set, otherwise base.
+func (h *SubsystemLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.subsystem != "" {
+		if override, ok := h.levels.Get(h.subsystem); ok {
+			return level >= override
+		}
+	}
+	return level >= h.base.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *SubsystemLevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler, picking up SubsystemKey if it's among
+// attrs so Enabled consults the right override from here on.
+func (h *SubsystemLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	subsystem := h.subsystem
+	for _, a := range attrs {
+		if a.Key == SubsystemKey {
+			subsystem = a.Value.String()
+		}
+	}
+	return &SubsystemLevelHandler{next: h.next.WithAttrs(attrs), base: h.base, levels: h.levels, subsystem: subsystem}
+}
+
+// WithGroup implements slog.Handler.
+func (h *SubsystemLevelHandler) WithGroup(name string) slog.Handler {
+	return &SubsystemLevelHandler{next: h.next.WithGroup(name), base: h.base, levels: h.levels, subsystem: h.subsystem}
+}
@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevelStrict(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    slog.Level
+		expectError bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"DEBUG", slog.LevelDebug, false},
+		{"info", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := ParseLevelStrict(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
+
+func TestParseSubsystemLevels(t *testing.T) {
+	levels, err := ParseSubsystemLevels("client=debug, workflows=warn,server=error")
+	require.NoError(t, err)
+
+	level, ok := levels.Get("client")
+	assert.True(t, ok)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	level, ok = levels.Get("workflows")
+	assert.True(t, ok)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	level, ok = levels.Get("server")
+	assert.True(t, ok)
+	assert.Equal(t, slog.LevelError, level)
+
+	_, ok = levels.Get("unknown")
+	assert.False(t, ok)
+}
+
+func TestParseSubsystemLevelsEmpty(t *testing.T) {
+	levels, err := ParseSubsystemLevels("")
+	require.NoError(t, err)
+	assert.Empty(t, levels.All())
+}
+
+func TestParseSubsystemLevelsInvalid(t *testing.T) {
+	tests := []string{"client", "client=bogus", "=debug"}
+
+	for _, spec := range tests {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseSubsystemLevels(spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSubsystemLevelsClear(t *testing.T) {
+	levels := NewSubsystemLevels()
+	levels.Set("client", slog.LevelDebug)
+
+	_, ok := levels.Get("client")
+	assert.True(t, ok)
+
+	levels.Clear("client")
+	_, ok = levels.Get("client")
+	assert.False(t, ok)
+}
+
+func TestSubsystemLevelHandlerFallsBackToBase(t *testing.T) {
+	base := &slog.LevelVar{}
+	base.Set(slog.LevelWarn)
+	handler := NewSubsystemLevelHandler(slog.NewTextHandler(io.Discard, nil), base, NewSubsystemLevels())
+
+	assert.False(t, handler.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelWarn))
+
+	base.Set(slog.LevelDebug)
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelInfo))
+}
+
+func TestSubsystemLevelHandlerRespectsOverride(t *testing.T) {
+	base := &slog.LevelVar{}
+	base.Set(slog.LevelWarn)
+	levels := NewSubsystemLevels()
+	levels.Set("client", slog.LevelDebug)
+	handler := NewSubsystemLevelHandler(slog.NewTextHandler(io.Discard, nil), base, levels)
+
+	tagged := handler.WithAttrs([]slog.Attr{slog.String(SubsystemKey, "client")})
+	assert.True(t, tagged.Enabled(context.Background(), slog.LevelDebug))
+
+	// An untagged handler still falls back to base, unaffected by client's override.
+	assert.False(t, handler.Enabled(context.Background(), slog.LevelInfo))
+}
@@ -0,0 +1,48 @@
+//go:build !windows
+
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"log/syslog"
+)
+
+// syslogFacilities maps the facility names operators configure via
+// `log_syslog_facility` to their syslog.Priority value.
+var syslogFacilities = map[string]syslog.Priority{
+	"daemon": syslog.LOG_DAEMON,
+	"user":   syslog.LOG_USER,
+	"local0": syslog.LOG_LOCAL0,
+	"local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4,
+	"local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6,
+	"local7": syslog.LOG_LOCAL7,
+}
+
+// NewSyslogHandler builds a slog.Handler (human-readable or JSON,
+// matching useJSON) that writes to the local syslog daemon under the
+// given facility and tag.
+func NewSyslogHandler(facility, tag, logLevel string, useJSON bool) (slog.Handler, error) {
+	if facility == "" {
+		facility = "daemon"
+	}
+	priority, ok := syslogFacilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility: %q", facility)
+	}
+
+	w, err := syslog.New(priority|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+
+	level := ParseLevel(logLevel)
+	if useJSON {
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}), nil
+	}
+	return NewHumanReadableHandler(w, level), nil
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package logging
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// NewSyslogHandler is unsupported on Windows, which has no syslog daemon.
+func NewSyslogHandler(facility, tag, logLevel string, useJSON bool) (slog.Handler, error) {
+	return nil, errors.New("syslog logging is not supported on windows")
+}
@@ -0,0 +1,67 @@
+package mock
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// Alerts is an in-memory fake of analysis alert subscriptions:
+// CreateAnalysisAlert, ListAnalysisAlerts, DeleteAnalysisAlert. Unlike the
+// real AlertManager it never actually polls or dispatches webhooks - tests
+// that need to assert on dispatch behavior should exercise
+// client.AlertManager directly instead.
+type Alerts struct {
+	mu     sync.Mutex
+	byID   map[string]client.AnalysisAlert
+	nextID int
+}
+
+func newAlerts() *Alerts {
+	return &Alerts{byID: map[string]client.AnalysisAlert{}}
+}
+
+func (a *Alerts) create(alert client.AnalysisAlert) (*client.AnalysisAlert, error) {
+	if alert.AnalysisID == "" {
+		return nil, fmt.Errorf("alert: analysis_id is required")
+	}
+	if len(alert.Events) == 0 {
+		return nil, fmt.Errorf("alert: at least one event is required")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.nextID++
+	alert.ID = fmt.Sprintf("alert-%d", a.nextID)
+	alert.Phase = client.AlertPhaseActive
+	a.byID[alert.ID] = alert
+
+	result := alert
+	return &result, nil
+}
+
+func (a *Alerts) list(analysisID string) ([]client.AnalysisAlert, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	out := make([]client.AnalysisAlert, 0, len(a.byID))
+	for _, alert := range a.byID {
+		if analysisID == "" || alert.AnalysisID == analysisID {
+			out = append(out, alert)
+		}
+	}
+	return out, nil
+}
+
+func (a *Alerts) delete(alertID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.byID[alertID]; !ok {
+		return fmt.Errorf("no such alert %q", alertID)
+	}
+	delete(a.byID, alertID)
+	return nil
+}
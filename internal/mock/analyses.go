@@ -0,0 +1,393 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// StatusAt is one entry in an analysis's scripted status timeline: Status
+// (and the accompanying URL fields) become current once After has elapsed
+// since the analysis was launched, letting a test drive an analysis through
+// Submitted -> Running -> Completed/Failed without sleeping in real time.
+type StatusAt struct {
+	After    time.Duration
+	Status   string
+	URLReady bool
+	URL      string
+}
+
+// ControlCall records one ControlAnalysis invocation, so tests can assert
+// on what was asked for (e.g. that a cancelled launch_app_and_stream really
+// sent "exit" with saveOutputs=false).
+type ControlCall struct {
+	AnalysisID  string
+	Operation   string
+	SaveOutputs bool
+}
+
+// analysisRecord is the in-memory state for one launched analysis.
+type analysisRecord struct {
+	appID      string
+	systemID   string
+	name       string
+	launchedAt time.Time
+	timeline   []StatusAt
+	log        []byte
+}
+
+// currentStatus returns the timeline entry in effect at now, falling back
+// to a bare "Submitted" status if no timeline has been scripted yet.
+func (r *analysisRecord) currentStatus(now time.Time) StatusAt {
+	current := StatusAt{Status: "Submitted"}
+	elapsed := now.Sub(r.launchedAt)
+	for _, step := range r.timeline {
+		if step.After > elapsed {
+			break
+		}
+		current = step
+	}
+	return current
+}
+
+// Analyses is an in-memory fake of the analysis lifecycle: launch, status
+// polling, watching, and control (stop/save/extend-time).
+type Analyses struct {
+	mu             sync.Mutex
+	byID           map[string]*analysisRecord
+	nextID         int
+	failNextLaunch error
+	controls       []ControlCall
+}
+
+func newAnalyses() *Analyses {
+	return &Analyses{byID: map[string]*analysisRecord{}}
+}
+
+// FailNextLaunch makes the next LaunchApp call return err instead of
+// creating an analysis.
+func (a *Analyses) FailNextLaunch(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failNextLaunch = err
+}
+
+// SetStatusTimes scripts analysisID's status timeline: it will report
+// entries[i].Status (and URLReady/URL) once entries[i].After has elapsed
+// since the analysis was launched. Entries should be supplied in
+// increasing After order.
+func (a *Analyses) SetStatusTimes(analysisID string, entries ...StatusAt) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	record, ok := a.byID[analysisID]
+	if !ok {
+		return
+	}
+	record.timeline = entries
+}
+
+// AppendLog appends chunk to analysisID's log buffer, so a streaming
+// consumer (real or test-only) can be simulated incrementally.
+func (a *Analyses) AppendLog(analysisID string, chunk []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	record, ok := a.byID[analysisID]
+	if !ok {
+		return
+	}
+	record.log = append(record.log, chunk...)
+}
+
+// Log returns a copy of analysisID's accumulated log buffer.
+func (a *Analyses) Log(analysisID string) []byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	record, ok := a.byID[analysisID]
+	if !ok {
+		return nil
+	}
+	log := make([]byte, len(record.log))
+	copy(log, record.log)
+	return log
+}
+
+// Controls returns every recorded ControlAnalysis call for analysisID, in
+// call order.
+func (a *Analyses) Controls(analysisID string) []ControlCall {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var calls []ControlCall
+	for _, call := range a.controls {
+		if call.AnalysisID == analysisID {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (a *Analyses) launch(systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.failNextLaunch != nil {
+		err := a.failNextLaunch
+		a.failNextLaunch = nil
+		return nil, err
+	}
+
+	a.nextID++
+	id := fmt.Sprintf("analysis-%d", a.nextID)
+
+	name := submission.Name
+	if name == "" {
+		name = id
+	}
+
+	a.byID[id] = &analysisRecord{
+		appID:      appID,
+		systemID:   systemID,
+		name:       name,
+		launchedAt: time.Now(),
+	}
+
+	return &client.LaunchResponse{AnalysisID: id, Name: name, Status: "Submitted"}, nil
+}
+
+func (a *Analyses) status(analysisID string) (*client.AnalysisStatus, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	record, ok := a.byID[analysisID]
+	if !ok {
+		return nil, fmt.Errorf("mock: analysis %s not found", analysisID)
+	}
+
+	current := record.currentStatus(time.Now())
+	return &client.AnalysisStatus{
+		AnalysisID: analysisID,
+		Status:     current.Status,
+		URLReady:   current.URLReady,
+		URL:        current.URL,
+	}, nil
+}
+
+func (a *Analyses) list(status string) ([]client.Analysis, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ids := make([]string, 0, len(a.byID))
+	for id := range a.byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	now := time.Now()
+	var analyses []client.Analysis
+	for _, id := range ids {
+		record := a.byID[id]
+		current := record.currentStatus(now)
+		if status != "" && current.Status != status {
+			continue
+		}
+		analyses = append(analyses, client.Analysis{
+			AnalysisID: id,
+			AppID:      record.appID,
+			SystemID:   record.systemID,
+			Status:     current.Status,
+		})
+	}
+	return analyses, nil
+}
+
+func (a *Analyses) control(analysisID, operation string, saveOutputs bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.byID[analysisID]; !ok {
+		return fmt.Errorf("mock: analysis %s not found", analysisID)
+	}
+
+	a.controls = append(a.controls, ControlCall{AnalysisID: analysisID, Operation: operation, SaveOutputs: saveOutputs})
+	return nil
+}
+
+// watch emits one AnalysisEvent per distinct status analysisID's timeline
+// transitions through from the moment watch is called, then closes the
+// channel once it reaches a terminal status or ctx is cancelled. It's a
+// simplified stand-in for the real client's reconnecting SSE/long-poll
+// watch - good enough to drive tests of callers that consume the channel,
+// not a faithful reproduction of the wire protocol.
+func (a *Analyses) watch(ctx context.Context, analysisID string) (<-chan client.AnalysisEvent, error) {
+	a.mu.Lock()
+	_, ok := a.byID[analysisID]
+	a.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("mock: analysis %s not found", analysisID)
+	}
+
+	events := make(chan client.AnalysisEvent)
+	go a.streamStatus(ctx, analysisID, events)
+	return events, nil
+}
+
+// watchAll emits events for every analysis matching filter, interleaved on
+// one channel, using the same simplified polling loop as watch.
+func (a *Analyses) watchAll(ctx context.Context, filter string) (<-chan client.AnalysisEvent, error) {
+	events := make(chan client.AnalysisEvent)
+
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		last := map[string]string{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				analyses, err := a.list(filter)
+				if err != nil {
+					return
+				}
+				for _, analysis := range analyses {
+					if last[analysis.AnalysisID] == analysis.Status {
+						continue
+					}
+					last[analysis.AnalysisID] = analysis.Status
+					select {
+					case events <- client.AnalysisEvent{AnalysisID: analysis.AnalysisID, Status: analysis.Status, Timestamp: time.Now()}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (a *Analyses) streamStatus(ctx context.Context, analysisID string, events chan<- client.AnalysisEvent) {
+	defer close(events)
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	var lastStatus string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			status, err := a.status(analysisID)
+			if err != nil {
+				return
+			}
+			if status.Status == lastStatus {
+				continue
+			}
+			lastStatus = status.Status
+
+			select {
+			case events <- client.AnalysisEvent{AnalysisID: analysisID, Status: status.Status, URLReady: status.URLReady, URL: status.URL, Timestamp: time.Now()}:
+			case <-ctx.Done():
+				return
+			}
+
+			if terminalAnalysisStatuses[status.Status] {
+				return
+			}
+		}
+	}
+}
+
+// terminalAnalysisStatuses mirrors the real client's terminal status set,
+// so watch/watchAll stop polling at the same point the real API would stop
+// sending updates.
+var terminalAnalysisStatuses = map[string]bool{
+	"Completed": true,
+	"Failed":    true,
+	"Canceled":  true,
+}
+
+// streamLog delivers analysisID's log buffer (as scripted via AppendLog)
+// one line at a time, honoring opts.TailLines as a backlog limit, then
+// either closes the channel or, if opts.Follow is set, keeps polling for
+// newly appended lines until ctx is cancelled. It's a simplified
+// stand-in for the real client's reconnecting SSE/long-poll log stream.
+func (a *Analyses) streamLog(ctx context.Context, analysisID string, opts client.LogStreamOptions) (<-chan client.LogLine, error) {
+	a.mu.Lock()
+	_, ok := a.byID[analysisID]
+	a.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("mock: analysis %s not found", analysisID)
+	}
+
+	lines := make(chan client.LogLine)
+
+	go func() {
+		defer close(lines)
+
+		sent := 0
+		emit := func(all []string) bool {
+			for _, text := range all[sent:] {
+				select {
+				case lines <- client.LogLine{Container: opts.Container, Line: text, Timestamp: time.Now()}:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			sent = len(all)
+			return true
+		}
+
+		all := splitLogLines(a.Log(analysisID))
+		if opts.TailLines > 0 && len(all) > opts.TailLines {
+			sent = len(all) - opts.TailLines
+		}
+		if !emit(all) {
+			return
+		}
+		if !opts.Follow {
+			return
+		}
+
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !emit(splitLogLines(a.Log(analysisID))) {
+					return
+				}
+			}
+		}
+	}()
+
+	return lines, nil
+}
+
+// splitLogLines splits a log buffer on newlines, dropping the trailing
+// empty element left by a log that ends in "\n".
+func splitLogLines(log []byte) []string {
+	if len(log) == 0 {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(string(log), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines
+}
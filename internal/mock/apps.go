@@ -0,0 +1,119 @@
+package mock
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// Apps is an in-memory fake of the app catalog: ListApps and
+// GetAppParameters.
+type Apps struct {
+	mu                sync.Mutex
+	apps              []client.App
+	parametersByID    map[string]*client.AppParameters
+	costEstimatesByID map[string]*client.CostEstimate
+	failNextList      error
+}
+
+func newApps() *Apps {
+	return &Apps{
+		parametersByID:    map[string]*client.AppParameters{},
+		costEstimatesByID: map[string]*client.CostEstimate{},
+	}
+}
+
+// Seed adds app to the catalog returned by ListApps.
+func (a *Apps) Seed(app client.App) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.apps = append(a.apps, app)
+}
+
+// SetParameters records the parameter definitions GetAppParameters returns
+// for the given systemID/appID pair.
+func (a *Apps) SetParameters(systemID, appID string, params *client.AppParameters) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.parametersByID[systemID+"/"+appID] = params
+}
+
+// SetCostEstimate records the estimate EstimateCost returns for the given
+// systemID/appID pair. Apps with no estimate seeded return the zero
+// estimate - a free launch - rather than an error, since unlike
+// GetAppParameters an unseeded cost isn't an exceptional case to simulate.
+func (a *Apps) SetCostEstimate(systemID, appID string, estimate *client.CostEstimate) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.costEstimatesByID[systemID+"/"+appID] = estimate
+}
+
+// FailNextList makes the next call to ListApps return err instead of
+// looking at the seeded catalog, so tests can exercise upstream error
+// handling without a real Formation API to misbehave.
+func (a *Apps) FailNextList(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failNextList = err
+}
+
+func (a *Apps) list(name, integrator, description, jobType string, limit, offset int) ([]client.App, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.failNextList != nil {
+		err := a.failNextList
+		a.failNextList = nil
+		return nil, err
+	}
+
+	var matches []client.App
+	for _, app := range a.apps {
+		if name != "" && app.Name != name {
+			continue
+		}
+		if integrator != "" && app.IntegratorUsername != integrator {
+			continue
+		}
+		if description != "" && app.Description != description {
+			continue
+		}
+		// jobType isn't part of client.App (it's resolved from
+		// GetAppParameters), so there's nothing to filter on here - the
+		// real API handles it server-side.
+		matches = append(matches, app)
+	}
+
+	if offset > len(matches) {
+		offset = len(matches)
+	}
+	matches = matches[offset:]
+
+	if limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+func (a *Apps) parameters(systemID, appID string) (*client.AppParameters, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	params, ok := a.parametersByID[systemID+"/"+appID]
+	if !ok {
+		return nil, fmt.Errorf("mock: no parameters seeded for app %s/%s", systemID, appID)
+	}
+	return params, nil
+}
+
+func (a *Apps) estimateCost(systemID, appID string, config client.LaunchConfig) (*client.CostEstimate, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if estimate, ok := a.costEstimatesByID[systemID+"/"+appID]; ok {
+		return estimate, nil
+	}
+	return &client.CostEstimate{}, nil
+}
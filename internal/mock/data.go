@@ -0,0 +1,244 @@
+package mock
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// dataEntry is one file or directory in the in-memory iRODS tree.
+type dataEntry struct {
+	isDir    bool
+	content  string
+	metadata map[string]interface{}
+}
+
+// Data is an in-memory fake of the iRODS data tree: browsing, creating
+// directories, and deleting. Metadata reads/writes go through Metadata
+// instead, the same way the real API exposes them as separate operations
+// even though they act on the same paths.
+type Data struct {
+	mu             sync.Mutex
+	byPath         map[string]*dataEntry
+	failNextCreate error
+	failNextDelete error
+}
+
+func newData() *Data {
+	return &Data{byPath: map[string]*dataEntry{}}
+}
+
+// SeedDirectory adds an empty directory at path.
+func (d *Data) SeedDirectory(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.byPath[path] = &dataEntry{isDir: true}
+}
+
+// SeedFile adds a file at path with the given content.
+func (d *Data) SeedFile(path, content string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.byPath[path] = &dataEntry{content: content}
+}
+
+// Exists reports whether path has been seeded or created.
+func (d *Data) Exists(path string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.byPath[path]
+	return ok
+}
+
+// FailNextCreate makes the next CreateDirectory call return err instead of
+// creating the directory.
+func (d *Data) FailNextCreate(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.failNextCreate = err
+}
+
+// FailNextDelete makes the next DeleteData call return err instead of
+// deleting.
+func (d *Data) FailNextDelete(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.failNextDelete = err
+}
+
+func (d *Data) browse(path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.byPath[path]
+	if !ok {
+		return nil, fmt.Errorf("mock: path %s not found", path)
+	}
+
+	if !entry.isDir {
+		file := &client.FileContent{Path: path, Content: entry.content}
+		if includeMetadata {
+			file.Metadata = entry.metadata
+		}
+		return file, nil
+	}
+
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	var names []string
+	for candidate := range d.byPath {
+		if !strings.HasPrefix(candidate, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(candidate, prefix)
+		if strings.Contains(rest, "/") {
+			continue // not a direct child
+		}
+		names = append(names, rest)
+	}
+	sort.Strings(names)
+
+	if offset > len(names) {
+		offset = len(names)
+	}
+	names = names[offset:]
+	if limit > 0 && limit < len(names) {
+		names = names[:limit]
+	}
+
+	contents := make([]client.DirectoryEntry, 0, len(names))
+	for _, name := range names {
+		child := d.byPath[prefix+name]
+		entryType := "data_object"
+		if child.isDir {
+			entryType = "collection"
+		}
+		contents = append(contents, client.DirectoryEntry{Name: name, Type: entryType})
+	}
+
+	return &client.DirectoryContents{Path: path, Type: "collection", Contents: contents}, nil
+}
+
+// pathsOf returns every path known to Data, mapped to its type
+// ("collection" or "data_object"), for SearchMetadata to filter before
+// checking their AVUs.
+func (d *Data) pathsOf() map[string]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	types := make(map[string]string, len(d.byPath))
+	for path, entry := range d.byPath {
+		if entry.isDir {
+			types[path] = "collection"
+		} else {
+			types[path] = "data_object"
+		}
+	}
+	return types
+}
+
+// download writes path's content to w, reporting the whole write as one
+// progress call since there's no real network transfer to throttle.
+func (d *Data) download(path string, w io.Writer, onProgress func(n, total int64)) error {
+	d.mu.Lock()
+	entry, ok := d.byPath[path]
+	d.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("mock: path %s not found", path)
+	}
+	if entry.isDir {
+		return fmt.Errorf("mock: path %s is a directory", path)
+	}
+
+	n, err := w.Write([]byte(entry.content))
+	if err != nil {
+		return err
+	}
+	if onProgress != nil {
+		onProgress(int64(n), int64(n))
+	}
+	return nil
+}
+
+// downloadRange returns length bytes of path's content starting at offset,
+// mirroring DownloadFileRange's Range-request semantics. length <= 0 reads
+// through the end of the content.
+func (d *Data) downloadRange(path string, offset, length int64) (io.ReadCloser, *client.FileMetadata, error) {
+	d.mu.Lock()
+	entry, ok := d.byPath[path]
+	d.mu.Unlock()
+
+	if !ok {
+		return nil, nil, fmt.Errorf("mock: path %s not found", path)
+	}
+	if entry.isDir {
+		return nil, nil, fmt.Errorf("mock: path %s is a directory", path)
+	}
+
+	total := int64(len(entry.content))
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if length > 0 && offset+length < total {
+		end = offset + length
+	}
+
+	slice := entry.content[offset:end]
+	return io.NopCloser(strings.NewReader(slice)), &client.FileMetadata{
+		Path:      path,
+		Offset:    offset,
+		Length:    int64(len(slice)),
+		TotalSize: total,
+	}, nil
+}
+
+func (d *Data) createDirectory(path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.failNextCreate != nil {
+		err := d.failNextCreate
+		d.failNextCreate = nil
+		return nil, err
+	}
+
+	d.byPath[path] = &dataEntry{isDir: true, metadata: metadata}
+	return &client.CreateDirectoryResponse{Path: path, Type: "collection"}, nil
+}
+
+func (d *Data) delete(path string, recurse, dryRun bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.failNextDelete != nil {
+		err := d.failNextDelete
+		d.failNextDelete = nil
+		return err
+	}
+
+	if _, ok := d.byPath[path]; !ok {
+		return fmt.Errorf("mock: path %s not found", path)
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	delete(d.byPath, path)
+
+	if recurse {
+		prefix := strings.TrimSuffix(path, "/") + "/"
+		for candidate := range d.byPath {
+			if strings.HasPrefix(candidate, prefix) {
+				delete(d.byPath, candidate)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package mock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// Metadata is an in-memory fake of per-path metadata: SetMetadata and
+// SnapshotMetadata.
+type Metadata struct {
+	mu     sync.Mutex
+	byPath map[string]map[string]interface{}
+}
+
+func newMetadata() *Metadata {
+	return &Metadata{byPath: map[string]map[string]interface{}{}}
+}
+
+// Seed sets path's metadata directly, bypassing set's add/replace merge
+// logic, so a test can establish a starting snapshot to restore later.
+func (m *Metadata) Seed(path string, metadata map[string]interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byPath[path] = cloneMetadata(metadata)
+}
+
+func (m *Metadata) set(path string, metadata map[string]interface{}, replace bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if replace || m.byPath[path] == nil {
+		m.byPath[path] = cloneMetadata(metadata)
+		return nil
+	}
+
+	for k, v := range metadata {
+		m.byPath[path][k] = v
+	}
+	return nil
+}
+
+func (m *Metadata) snapshot(path string) (map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return cloneMetadata(m.byPath[path]), nil
+}
+
+// search filters pathTypes (path -> "collection"/"data_object", as
+// reported by Data) down to the ones under query.PathPrefix and matching
+// query.Type, then further down to the ones whose metadata satisfies every
+// predicate in query.Predicates, applying Offset/Limit to the result in
+// path-sorted order.
+func (m *Metadata) search(query client.MetadataQuery, pathTypes map[string]string) (*client.MetadataSearchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prefix := strings.TrimSuffix(query.PathPrefix, "/")
+
+	var matches []client.MetadataSearchMatch
+	for path, entryType := range pathTypes {
+		if prefix != "" && !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		if query.Type != "" && query.Type != entryType {
+			continue
+		}
+		metadata := m.byPath[path]
+		if !matchesAllPredicates(metadata, query.Predicates) {
+			continue
+		}
+		matches = append(matches, client.MetadataSearchMatch{Path: path, Type: entryType, Metadata: cloneMetadata(metadata)})
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Path < matches[j].Path })
+	total := len(matches)
+
+	if query.Offset > 0 {
+		if query.Offset > len(matches) {
+			matches = nil
+		} else {
+			matches = matches[query.Offset:]
+		}
+	}
+	if query.Limit > 0 && query.Limit < len(matches) {
+		matches = matches[:query.Limit]
+	}
+
+	return &client.MetadataSearchResult{Matches: matches, Total: total}, nil
+}
+
+// matchesAllPredicates reports whether metadata satisfies every predicate
+// (a logical AND, matching SearchMetadata's documented semantics).
+func matchesAllPredicates(metadata map[string]interface{}, predicates []client.MetadataPredicate) bool {
+	for _, p := range predicates {
+		if !matchesPredicate(metadata, p) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesPredicate(metadata map[string]interface{}, p client.MetadataPredicate) bool {
+	value, ok := metadata[p.Attribute]
+	if !ok {
+		return false
+	}
+
+	switch p.Operator {
+	case "", "=":
+		return fmt.Sprintf("%v", value) == fmt.Sprintf("%v", p.Value)
+	case "like":
+		pattern, _ := p.Value.(string)
+		return strings.Contains(fmt.Sprintf("%v", value), strings.Trim(pattern, "%"))
+	case "<":
+		return compareNumeric(value, p.Value) < 0
+	case ">":
+		return compareNumeric(value, p.Value) > 0
+	case "in":
+		values, _ := p.Value.([]interface{})
+		for _, v := range values {
+			if fmt.Sprintf("%v", v) == fmt.Sprintf("%v", value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// compareNumeric compares a and b as numbers when both parse as one,
+// falling back to a string comparison otherwise - good enough for a mock
+// exercising "<"/">" over AVU values, which are usually strings anyway.
+func compareNumeric(a, b interface{}) int {
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if !aok || !bok {
+		return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+	}
+	switch {
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case string:
+		var f float64
+		if _, err := fmt.Sscanf(n, "%f", &f); err != nil {
+			return 0, false
+		}
+		return f, true
+	default:
+		return 0, false
+	}
+}
+
+func cloneMetadata(metadata map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		clone[k] = v
+	}
+	return clone
+}
@@ -0,0 +1,153 @@
+// Package mock provides an in-memory, thread-safe fake of
+// client.FormationAPIClient, modeled on hashicorp/go-tfe's mock client: a
+// top-level Client composes focused sub-mocks (Apps, Analyses, Data,
+// Metadata, Uploads, Alerts), each backed by its own in-memory state, so tests can
+// exercise scripted analysis transitions, streaming logs, and failure
+// injection without a real Formation API to talk to.
+//
+// The bare function-field mocks in internal/server and internal/workflows's
+// test files remain the right tool for a single handler test that only
+// cares about one call; reach for Client when a test needs state to persist
+// or evolve across several calls (e.g. launch an analysis, then poll it, then
+// stop it).
+package mock
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// Client is an in-memory client.FormationAPIClient assembled from
+// resource-focused sub-mocks.
+type Client struct {
+	Apps     *Apps
+	Analyses *Analyses
+	Data     *Data
+	Metadata *Metadata
+	Uploads  *Uploads
+	Alerts   *Alerts
+}
+
+// Compile-time check that Client satisfies client.FormationAPIClient.
+var _ client.FormationAPIClient = (*Client)(nil)
+
+// NewClient returns a Client with all sub-mocks initialized and empty.
+func NewClient() *Client {
+	data := newData()
+	return &Client{
+		Apps:     newApps(),
+		Analyses: newAnalyses(),
+		Data:     data,
+		Metadata: newMetadata(),
+		Uploads:  newUploads(data),
+		Alerts:   newAlerts(),
+	}
+}
+
+// Login is a no-op; Client has no notion of token expiry to simulate.
+func (c *Client) Login(ctx context.Context) error { return nil }
+
+// Shutdown is a no-op; Client has no real in-flight requests to drain.
+func (c *Client) Shutdown(ctx context.Context) error { return nil }
+
+// LastActivity returns the zero time; Client doesn't track request timing.
+func (c *Client) LastActivity() time.Time { return time.Time{} }
+
+func (c *Client) ListApps(ctx context.Context, name, integrator, description, jobType string, limit, offset int) ([]client.App, error) {
+	return c.Apps.list(name, integrator, description, jobType, limit, offset)
+}
+
+func (c *Client) GetAppParameters(ctx context.Context, systemID, appID string) (*client.AppParameters, error) {
+	return c.Apps.parameters(systemID, appID)
+}
+
+func (c *Client) LaunchApp(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error) {
+	return c.Analyses.launch(systemID, appID, submission)
+}
+
+func (c *Client) EstimateCost(ctx context.Context, systemID, appID string, config client.LaunchConfig) (*client.CostEstimate, error) {
+	return c.Apps.estimateCost(systemID, appID, config)
+}
+
+func (c *Client) GetAnalysisStatus(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
+	return c.Analyses.status(analysisID)
+}
+
+func (c *Client) ListAnalyses(ctx context.Context, status string) ([]client.Analysis, error) {
+	return c.Analyses.list(status)
+}
+
+func (c *Client) WatchAnalysis(ctx context.Context, analysisID string) (<-chan client.AnalysisEvent, error) {
+	return c.Analyses.watch(ctx, analysisID)
+}
+
+func (c *Client) WatchAnalyses(ctx context.Context, filter string) (<-chan client.AnalysisEvent, error) {
+	return c.Analyses.watchAll(ctx, filter)
+}
+
+func (c *Client) StreamAnalysisLogs(ctx context.Context, analysisID string, opts client.LogStreamOptions) (<-chan client.LogLine, error) {
+	return c.Analyses.streamLog(ctx, analysisID, opts)
+}
+
+func (c *Client) ControlAnalysis(ctx context.Context, analysisID, operation string, saveOutputs bool) error {
+	return c.Analyses.control(analysisID, operation, saveOutputs)
+}
+
+func (c *Client) BrowseData(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+	return c.Data.browse(path, offset, limit, includeMetadata)
+}
+
+func (c *Client) DownloadFileStream(ctx context.Context, path string, w io.Writer, onProgress func(n, total int64)) error {
+	return c.Data.download(path, w, onProgress)
+}
+
+func (c *Client) DownloadFileRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, *client.FileMetadata, error) {
+	return c.Data.downloadRange(path, offset, length)
+}
+
+func (c *Client) CreateDirectory(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error) {
+	return c.Data.createDirectory(path, metadata)
+}
+
+func (c *Client) UploadFile(ctx context.Context, path, content string, metadata map[string]interface{}) error {
+	return c.Uploads.upload(path, content, metadata)
+}
+
+func (c *Client) UploadFileStream(ctx context.Context, path string, r io.Reader, size int64, opts client.UploadOptions) error {
+	return c.Uploads.uploadStream(path, r, opts.Metadata)
+}
+
+func (c *Client) UploadFileChunk(ctx context.Context, uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*client.UploadChunkResult, error) {
+	return c.Uploads.uploadChunk(uploadID, path, offset, chunk, isFinal, metadata)
+}
+
+func (c *Client) SetMetadata(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
+	return c.Metadata.set(path, metadata, replace)
+}
+
+func (c *Client) SnapshotMetadata(ctx context.Context, path string) (map[string]interface{}, error) {
+	return c.Metadata.snapshot(path)
+}
+
+func (c *Client) SearchMetadata(ctx context.Context, query client.MetadataQuery) (*client.MetadataSearchResult, error) {
+	return c.Metadata.search(query, c.Data.pathsOf())
+}
+
+func (c *Client) DeleteData(ctx context.Context, path string, recurse, dryRun bool) error {
+	return c.Data.delete(path, recurse, dryRun)
+}
+
+func (c *Client) CreateAnalysisAlert(ctx context.Context, alert client.AnalysisAlert) (*client.AnalysisAlert, error) {
+	return c.Alerts.create(alert)
+}
+
+func (c *Client) ListAnalysisAlerts(ctx context.Context, analysisID string) ([]client.AnalysisAlert, error) {
+	return c.Alerts.list(analysisID)
+}
+
+func (c *Client) DeleteAnalysisAlert(ctx context.Context, alertID string) error {
+	return c.Alerts.delete(alertID)
+}
@@ -0,0 +1,336 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+func TestClientImplementsFormationAPIClient(t *testing.T) {
+	var _ client.FormationAPIClient = NewClient()
+}
+
+func TestAppsListAndParameters(t *testing.T) {
+	c := NewClient()
+	c.Apps.Seed(client.App{ID: "app-1", Name: "Jupyter", IntegratorUsername: "alice"})
+	c.Apps.Seed(client.App{ID: "app-2", Name: "RStudio", IntegratorUsername: "bob"})
+	c.Apps.SetParameters("de", "app-1", &client.AppParameters{OverallJobType: "Interactive"})
+
+	apps, err := c.ListApps(context.Background(), "", "alice", "", "", 10, 0)
+	if err != nil {
+		t.Fatalf("ListApps() unexpected error = %v", err)
+	}
+	if len(apps) != 1 || apps[0].ID != "app-1" {
+		t.Errorf("ListApps() = %v, want just app-1", apps)
+	}
+
+	params, err := c.GetAppParameters(context.Background(), "de", "app-1")
+	if err != nil {
+		t.Fatalf("GetAppParameters() unexpected error = %v", err)
+	}
+	if params.OverallJobType != "Interactive" {
+		t.Errorf("GetAppParameters().OverallJobType = %v, want Interactive", params.OverallJobType)
+	}
+
+	c.Apps.FailNextList(errors.New("catalog unavailable"))
+	if _, err := c.ListApps(context.Background(), "", "", "", "", 10, 0); err == nil {
+		t.Error("ListApps() expected injected failure, got nil error")
+	}
+	if _, err := c.ListApps(context.Background(), "", "", "", "", 10, 0); err != nil {
+		t.Errorf("ListApps() after failure injection unexpected error = %v", err)
+	}
+}
+
+func TestAppsEstimateCost(t *testing.T) {
+	c := NewClient()
+
+	estimate, err := c.EstimateCost(context.Background(), "de", "unseeded-app", client.LaunchConfig{})
+	if err != nil {
+		t.Fatalf("EstimateCost() unexpected error = %v", err)
+	}
+	if estimate.EstimatedCost != 0 {
+		t.Errorf("EstimateCost() for an unseeded app = %+v, want the zero estimate", estimate)
+	}
+
+	c.Apps.SetCostEstimate("de", "app-1", &client.CostEstimate{CPUHours: 2, EstimatedCost: 1.5, Currency: "USD"})
+	estimate, err = c.EstimateCost(context.Background(), "de", "app-1", client.LaunchConfig{})
+	if err != nil {
+		t.Fatalf("EstimateCost() unexpected error = %v", err)
+	}
+	if estimate.EstimatedCost != 1.5 {
+		t.Errorf("EstimateCost() = %+v, want EstimatedCost 1.5", estimate)
+	}
+}
+
+func TestAnalysesLaunchAndScriptedTransitions(t *testing.T) {
+	c := NewClient()
+
+	resp, err := c.LaunchApp(context.Background(), "de", "app-1", client.LaunchSubmission{Name: "my-analysis"})
+	if err != nil {
+		t.Fatalf("LaunchApp() unexpected error = %v", err)
+	}
+	if resp.AnalysisID != "analysis-1" {
+		t.Errorf("AnalysisID = %v, want analysis-1 (deterministic IDs)", resp.AnalysisID)
+	}
+
+	c.Analyses.SetStatusTimes(resp.AnalysisID,
+		StatusAt{After: 0, Status: "Submitted"},
+		StatusAt{After: 10 * time.Millisecond, Status: "Running"},
+		StatusAt{After: 20 * time.Millisecond, Status: "Completed", URLReady: true, URL: "https://test.cyverse.run"},
+	)
+
+	status, err := c.GetAnalysisStatus(context.Background(), resp.AnalysisID)
+	if err != nil {
+		t.Fatalf("GetAnalysisStatus() unexpected error = %v", err)
+	}
+	if status.Status != "Submitted" {
+		t.Errorf("Status = %v, want Submitted immediately after launch", status.Status)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+
+	status, err = c.GetAnalysisStatus(context.Background(), resp.AnalysisID)
+	if err != nil {
+		t.Fatalf("GetAnalysisStatus() unexpected error = %v", err)
+	}
+	if status.Status != "Completed" || !status.URLReady || status.URL != "https://test.cyverse.run" {
+		t.Errorf("GetAnalysisStatus() = %+v, want Completed with URL ready", status)
+	}
+}
+
+func TestAnalysesControlRecordsCalls(t *testing.T) {
+	c := NewClient()
+	resp, _ := c.LaunchApp(context.Background(), "de", "app-1", client.LaunchSubmission{})
+
+	if err := c.ControlAnalysis(context.Background(), resp.AnalysisID, "exit", false); err != nil {
+		t.Fatalf("ControlAnalysis() unexpected error = %v", err)
+	}
+
+	calls := c.Analyses.Controls(resp.AnalysisID)
+	if len(calls) != 1 || calls[0].Operation != "exit" || calls[0].SaveOutputs {
+		t.Errorf("Controls() = %+v, want one exit/saveOutputs=false call", calls)
+	}
+}
+
+func TestAnalysesWatchEmitsUntilTerminal(t *testing.T) {
+	c := NewClient()
+	resp, _ := c.LaunchApp(context.Background(), "de", "app-1", client.LaunchSubmission{})
+	c.Analyses.SetStatusTimes(resp.AnalysisID,
+		StatusAt{After: 0, Status: "Running"},
+		StatusAt{After: 15 * time.Millisecond, Status: "Completed"},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	events, err := c.WatchAnalysis(ctx, resp.AnalysisID)
+	if err != nil {
+		t.Fatalf("WatchAnalysis() unexpected error = %v", err)
+	}
+
+	var statuses []string
+	for event := range events {
+		if event.Err != nil {
+			t.Fatalf("unexpected event error: %v", event.Err)
+		}
+		statuses = append(statuses, event.Status)
+	}
+
+	if len(statuses) == 0 || statuses[len(statuses)-1] != "Completed" {
+		t.Errorf("watch statuses = %v, want it to end at Completed", statuses)
+	}
+}
+
+func TestAnalysesFailNextLaunch(t *testing.T) {
+	c := NewClient()
+	c.Analyses.FailNextLaunch(errors.New("quota exceeded"))
+
+	if _, err := c.LaunchApp(context.Background(), "de", "app-1", client.LaunchSubmission{}); err == nil {
+		t.Error("LaunchApp() expected injected failure, got nil error")
+	}
+
+	resp, err := c.LaunchApp(context.Background(), "de", "app-1", client.LaunchSubmission{})
+	if err != nil {
+		t.Fatalf("LaunchApp() after failure injection unexpected error = %v", err)
+	}
+	if resp.AnalysisID == "" {
+		t.Error("LaunchApp() after failure injection returned no analysis ID")
+	}
+}
+
+func TestDataCreateBrowseDelete(t *testing.T) {
+	c := NewClient()
+
+	if _, err := c.CreateDirectory(context.Background(), "/cyverse/home/alice/project", nil); err != nil {
+		t.Fatalf("CreateDirectory() unexpected error = %v", err)
+	}
+	if err := c.UploadFile(context.Background(), "/cyverse/home/alice/project/notes.txt", "hello", nil); err != nil {
+		t.Fatalf("UploadFile() unexpected error = %v", err)
+	}
+
+	result, err := c.BrowseData(context.Background(), "/cyverse/home/alice/project", 0, 100, false)
+	if err != nil {
+		t.Fatalf("BrowseData() unexpected error = %v", err)
+	}
+	dir, ok := result.(*client.DirectoryContents)
+	if !ok || len(dir.Contents) != 1 || dir.Contents[0].Name != "notes.txt" {
+		t.Errorf("BrowseData() = %+v, want one entry named notes.txt", result)
+	}
+
+	c.Data.FailNextDelete(errors.New("permission denied"))
+	if err := c.DeleteData(context.Background(), "/cyverse/home/alice/project/notes.txt", false, false); err == nil {
+		t.Error("DeleteData() expected injected failure, got nil error")
+	}
+
+	if err := c.DeleteData(context.Background(), "/cyverse/home/alice/project/notes.txt", false, false); err != nil {
+		t.Fatalf("DeleteData() after failure injection unexpected error = %v", err)
+	}
+	if c.Data.Exists("/cyverse/home/alice/project/notes.txt") {
+		t.Error("DeleteData() did not remove the file")
+	}
+}
+
+func TestMetadataSetAndSnapshot(t *testing.T) {
+	c := NewClient()
+
+	if err := c.SetMetadata(context.Background(), "/cyverse/home/alice/project", map[string]interface{}{"status": "original"}, true); err != nil {
+		t.Fatalf("SetMetadata() unexpected error = %v", err)
+	}
+
+	snapshot, err := c.SnapshotMetadata(context.Background(), "/cyverse/home/alice/project")
+	if err != nil {
+		t.Fatalf("SnapshotMetadata() unexpected error = %v", err)
+	}
+	if snapshot["status"] != "original" {
+		t.Fatalf("snapshot = %v, want status=original", snapshot)
+	}
+
+	if err := c.SetMetadata(context.Background(), "/cyverse/home/alice/project", map[string]interface{}{"status": "overwritten"}, true); err != nil {
+		t.Fatalf("SetMetadata() unexpected error = %v", err)
+	}
+
+	// Restoring the earlier snapshot should not be affected by the mutation
+	// above - Snapshot returns a copy, not a live view.
+	if err := c.SetMetadata(context.Background(), "/cyverse/home/alice/project", snapshot, true); err != nil {
+		t.Fatalf("SetMetadata() restore unexpected error = %v", err)
+	}
+	restored, _ := c.SnapshotMetadata(context.Background(), "/cyverse/home/alice/project")
+	if restored["status"] != "original" {
+		t.Errorf("restored metadata = %v, want status=original", restored)
+	}
+}
+
+func TestMetadataSearch(t *testing.T) {
+	c := NewClient()
+
+	c.Data.SeedFile("/cyverse/home/alice/rnaseq/sample1.fastq", "reads1")
+	c.Data.SeedFile("/cyverse/home/alice/rnaseq/sample2.fastq", "reads2")
+	c.Data.SeedDirectory("/cyverse/home/alice/chipseq")
+	c.Metadata.Seed("/cyverse/home/alice/rnaseq/sample1.fastq", map[string]interface{}{"experiment": "RNA-seq"})
+	c.Metadata.Seed("/cyverse/home/alice/rnaseq/sample2.fastq", map[string]interface{}{"experiment": "RNA-seq"})
+	c.Metadata.Seed("/cyverse/home/alice/chipseq", map[string]interface{}{"experiment": "ChIP-seq"})
+
+	result, err := c.SearchMetadata(context.Background(), client.MetadataQuery{
+		Predicates: []client.MetadataPredicate{{Attribute: "experiment", Operator: "=", Value: "RNA-seq"}},
+	})
+	if err != nil {
+		t.Fatalf("SearchMetadata() unexpected error = %v", err)
+	}
+	if result.Total != 2 || len(result.Matches) != 2 {
+		t.Fatalf("SearchMetadata() = %+v, want 2 matches", result)
+	}
+
+	filtered, err := c.SearchMetadata(context.Background(), client.MetadataQuery{
+		Predicates: []client.MetadataPredicate{{Attribute: "experiment", Operator: "=", Value: "RNA-seq"}},
+		PathPrefix: "/cyverse/home/alice/chipseq",
+	})
+	if err != nil {
+		t.Fatalf("SearchMetadata() with path_prefix unexpected error = %v", err)
+	}
+	if filtered.Total != 0 {
+		t.Fatalf("SearchMetadata() with path_prefix = %+v, want no matches", filtered)
+	}
+
+	typed, err := c.SearchMetadata(context.Background(), client.MetadataQuery{
+		Predicates: []client.MetadataPredicate{{Attribute: "experiment", Operator: "=", Value: "RNA-seq"}},
+		Type:       "collection",
+	})
+	if err != nil {
+		t.Fatalf("SearchMetadata() with type unexpected error = %v", err)
+	}
+	if typed.Total != 0 {
+		t.Fatalf("SearchMetadata() with type=collection = %+v, want no matches (both hits are data objects)", typed)
+	}
+
+	paged, err := c.SearchMetadata(context.Background(), client.MetadataQuery{
+		Predicates: []client.MetadataPredicate{{Attribute: "experiment", Operator: "=", Value: "RNA-seq"}},
+		Limit:      1,
+		Offset:     1,
+	})
+	if err != nil {
+		t.Fatalf("SearchMetadata() with limit/offset unexpected error = %v", err)
+	}
+	if paged.Total != 2 || len(paged.Matches) != 1 {
+		t.Fatalf("SearchMetadata() with limit/offset = %+v, want Total=2 len(Matches)=1", paged)
+	}
+}
+
+func TestUploadsChunkedSession(t *testing.T) {
+	c := NewClient()
+
+	first, err := c.UploadFileChunk(context.Background(), "", "/cyverse/home/alice/big.bin", 0, []byte("hello "), false, nil)
+	if err != nil {
+		t.Fatalf("UploadFileChunk() first chunk unexpected error = %v", err)
+	}
+	if first.Complete {
+		t.Error("first chunk should not complete the upload")
+	}
+
+	final, err := c.UploadFileChunk(context.Background(), first.UploadID, "/cyverse/home/alice/big.bin", first.Offset, []byte("world"), true, nil)
+	if err != nil {
+		t.Fatalf("UploadFileChunk() final chunk unexpected error = %v", err)
+	}
+	if !final.Complete {
+		t.Error("final chunk should complete the upload")
+	}
+
+	content, ok := c.Uploads.Contents("/cyverse/home/alice/big.bin")
+	if !ok || content != "hello world" {
+		t.Errorf("Contents() = %q, %v, want \"hello world\", true", content, ok)
+	}
+}
+
+func TestAlertsCreateListDelete(t *testing.T) {
+	c := NewClient()
+
+	alert, err := c.CreateAnalysisAlert(context.Background(), client.AnalysisAlert{
+		AnalysisID:    "analysis-1",
+		Events:        []client.AlertEvent{client.AlertEventCompleted},
+		SlackWebhooks: []client.SlackWebhook{{URL: "https://hooks.example.test/x"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateAnalysisAlert() unexpected error = %v", err)
+	}
+	if alert.ID == "" {
+		t.Error("CreateAnalysisAlert() did not assign an id")
+	}
+
+	if _, err := c.CreateAnalysisAlert(context.Background(), client.AnalysisAlert{AnalysisID: "analysis-2"}); err == nil {
+		t.Error("CreateAnalysisAlert() expected an error for an alert with no events")
+	}
+
+	alerts, err := c.ListAnalysisAlerts(context.Background(), "analysis-1")
+	if err != nil || len(alerts) != 1 {
+		t.Fatalf("ListAnalysisAlerts() = %v, %v, want one alert", alerts, err)
+	}
+
+	if err := c.DeleteAnalysisAlert(context.Background(), alert.ID); err != nil {
+		t.Fatalf("DeleteAnalysisAlert() unexpected error = %v", err)
+	}
+	if err := c.DeleteAnalysisAlert(context.Background(), alert.ID); err == nil {
+		t.Error("DeleteAnalysisAlert() expected an error deleting an already-deleted alert")
+	}
+}
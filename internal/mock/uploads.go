@@ -0,0 +1,115 @@
+package mock
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// uploadSession tracks one in-progress UploadFileChunk upload, mirroring
+// the real client's uploadChunkSession.
+type uploadSession struct {
+	path    string
+	offset  int64
+	content []byte
+}
+
+// Uploads is an in-memory fake of file uploads: whole-file, streamed, and
+// chunked. Every completed upload is also written into data's path tree,
+// the same one BrowseData/DeleteData operate on, so a file uploaded
+// through Client is visible to - and removable via - Data too.
+type Uploads struct {
+	mu           sync.Mutex
+	data         *Data
+	files        map[string]string
+	sessions     map[string]*uploadSession
+	nextUploadID int
+}
+
+func newUploads(data *Data) *Uploads {
+	return &Uploads{
+		data:     data,
+		files:    map[string]string{},
+		sessions: map[string]*uploadSession{},
+	}
+}
+
+// Contents returns the content previously uploaded to path, and whether
+// anything has been uploaded there.
+func (u *Uploads) Contents(path string) (string, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	content, ok := u.files[path]
+	return content, ok
+}
+
+func (u *Uploads) upload(path, content string, metadata map[string]interface{}) error {
+	u.mu.Lock()
+	u.files[path] = content
+	u.mu.Unlock()
+	u.data.SeedFile(path, content)
+	return nil
+}
+
+func (u *Uploads) uploadStream(path string, r io.Reader, metadata map[string]interface{}) error {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("mock: reading upload stream: %w", err)
+	}
+
+	u.mu.Lock()
+	u.files[path] = string(raw)
+	u.mu.Unlock()
+	u.data.SeedFile(path, string(raw))
+	return nil
+}
+
+func (u *Uploads) uploadChunk(uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*client.UploadChunkResult, error) {
+	u.mu.Lock()
+
+	session, ok := u.sessions[uploadID]
+	if !ok {
+		if uploadID != "" {
+			u.mu.Unlock()
+			return nil, fmt.Errorf("mock: unknown upload session %s", uploadID)
+		}
+		if offset != 0 {
+			u.mu.Unlock()
+			return nil, fmt.Errorf("mock: new upload must start at offset 0, got %d", offset)
+		}
+		u.nextUploadID++
+		uploadID = fmt.Sprintf("upload-%d", u.nextUploadID)
+		session = &uploadSession{path: path}
+		u.sessions[uploadID] = session
+	}
+
+	if offset != session.offset {
+		u.mu.Unlock()
+		return nil, fmt.Errorf("mock: chunk offset %d does not match session offset %d", offset, session.offset)
+	}
+
+	session.content = append(session.content, chunk...)
+	session.offset += int64(len(chunk))
+
+	result := &client.UploadChunkResult{
+		UploadID: uploadID,
+		Offset:   session.offset,
+	}
+
+	var completedContent string
+	if isFinal {
+		completedContent = string(session.content)
+		u.files[path] = completedContent
+		delete(u.sessions, uploadID)
+		result.Complete = true
+	}
+	u.mu.Unlock()
+
+	if isFinal {
+		u.data.SeedFile(path, completedContent)
+	}
+
+	return result, nil
+}
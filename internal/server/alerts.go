@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (s *FormationMCPServer) createAnalysisAlertTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "create_analysis_alert",
+		Description: "Subscribe to lifecycle events (status changes, URL readiness, completion, failure) on an analysis, delivering them to emails, Slack webhooks, and/or generic HTTP webhooks",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"analysis_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The analysis ID to watch",
+				},
+				"events": map[string]interface{}{
+					"type":        "array",
+					"description": "Lifecycle events to alert on",
+					"items": map[string]interface{}{
+						"type": "string",
+						"enum": []string{"status_changed", "url_ready", "completed", "failed"},
+					},
+				},
+				"emails": map[string]interface{}{
+					"type":        "array",
+					"description": "Email addresses to notify (accepted and reported back; not yet delivered by this module)",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"slack_webhooks": map[string]interface{}{
+					"type":        "array",
+					"description": "Slack incoming webhooks to post a summary to",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"url":     map[string]interface{}{"type": "string"},
+							"channel": map[string]interface{}{"type": "string"},
+						},
+						"required": []string{"url"},
+					},
+				},
+				"http_webhooks": map[string]interface{}{
+					"type":        "array",
+					"description": "Generic HTTP endpoints to POST the event payload to, HMAC-signed if secret is set",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"url":     map[string]interface{}{"type": "string"},
+							"headers": map[string]interface{}{"type": "object"},
+						},
+						"required": []string{"url"},
+					},
+				},
+				"secret": map[string]interface{}{
+					"type":        "string",
+					"description": "Shared secret used to HMAC-sign http_webhooks deliveries (X-Formation-Signature-256)",
+				},
+			},
+			Required: []string{"analysis_id", "events"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) listAnalysisAlertsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_analysis_alerts",
+		Description: "List registered analysis alerts, optionally filtered to one analysis",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"analysis_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Only return alerts watching this analysis ID (default: all alerts)",
+				},
+				"response_format": responseFormatProperty,
+			},
+		},
+	}
+}
+
+func (s *FormationMCPServer) deleteAnalysisAlertTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "delete_analysis_alert",
+		Description: "Cancel a registered analysis alert",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"alert_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The alert ID to delete, as returned by create_analysis_alert",
+				},
+			},
+			Required: []string{"alert_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleCreateAnalysisAlert(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AnalysisID    string               `json:"analysis_id"`
+		Events        []client.AlertEvent  `json:"events"`
+		Emails        []string             `json:"emails"`
+		SlackWebhooks []client.SlackWebhook `json:"slack_webhooks"`
+		HTTPWebhooks  []client.HTTPWebhook  `json:"http_webhooks"`
+		Secret        string               `json:"secret"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	slog.Info("creating analysis alert", "analysis_id", params.AnalysisID, "events", params.Events)
+
+	alert, err := s.client.CreateAnalysisAlert(ctx, client.AnalysisAlert{
+		AnalysisID:    params.AnalysisID,
+		Events:        params.Events,
+		Emails:        params.Emails,
+		SlackWebhooks: params.SlackWebhooks,
+		HTTPWebhooks:  params.HTTPWebhooks,
+		Secret:        params.Secret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("✅ **Analysis Alert Created**\n\n")
+	builder.WriteString(fmt.Sprintf("- **Alert ID**: `%s`\n", alert.ID))
+	builder.WriteString(fmt.Sprintf("- **Analysis ID**: `%s`\n", alert.AnalysisID))
+	builder.WriteString(fmt.Sprintf("- **Events**: %v\n", alert.Events))
+	builder.WriteString(fmt.Sprintf("- **Phase**: %s\n", alert.Phase))
+	builder.WriteString("\nDelete with delete_analysis_alert using this alert ID.")
+
+	return mcp.NewToolResultText(builder.String()), nil
+}
+
+func (s *FormationMCPServer) handleListAnalysisAlerts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AnalysisID     string         `json:"analysis_id"`
+		ResponseFormat responseFormat `json:"response_format"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.client.ListAnalysisAlerts(ctx, params.AnalysisID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.respondWithFormat(params.ResponseFormat, "formation://analysis-alerts", alerts, func() string {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("## Analysis Alerts (%d)\n\n", len(alerts)))
+		if len(alerts) == 0 {
+			builder.WriteString("No alerts registered.")
+		} else {
+			for _, alert := range alerts {
+				builder.WriteString(fmt.Sprintf("### Alert `%s`\n", alert.ID))
+				builder.WriteString(fmt.Sprintf("- **Analysis ID**: `%s`\n", alert.AnalysisID))
+				builder.WriteString(fmt.Sprintf("- **Events**: %v\n", alert.Events))
+				builder.WriteString(fmt.Sprintf("- **Phase**: %s\n", alert.Phase))
+				if alert.LastError != "" {
+					builder.WriteString(fmt.Sprintf("- **Last error**: %s\n", alert.LastError))
+				}
+				builder.WriteString(fmt.Sprintf("- **Deliveries sent**: %d\n\n", alert.DeliveriesSent))
+			}
+		}
+		return builder.String()
+	})
+}
+
+func (s *FormationMCPServer) handleDeleteAnalysisAlert(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AlertID string `json:"alert_id"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	slog.Info("deleting analysis alert", "alert_id", params.AlertID)
+
+	if err := s.client.DeleteAnalysisAlert(ctx, params.AlertID); err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("✅ Deleted analysis alert %s", params.AlertID)), nil
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/cyverse-de/formation-mcp/internal/workflows"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (s *FormationMCPServer) launchBatchTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "launch_batch",
+		Description: "Launch an app over many inputs in one call - either every file under inputs_path, or an inline matrix of config overrides - submitting up to max_parallel analyses concurrently instead of one launch_app_and_wait call per input. Returns a table of analysis_id/status/input plus a batch_id get_batch_status can poll for progress.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"app_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The application ID",
+				},
+				"system_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The system ID (default: de)",
+					"default":     "de",
+				},
+				"config": map[string]interface{}{
+					"type":        "object",
+					"description": "Base configuration parameters for the app, merged into every item (inputs_path/matrix keys take precedence)",
+				},
+				"inputs_path": map[string]interface{}{
+					"type":        "string",
+					"description": "An iRODS directory to fan out over - one analysis per file it contains. Requires input_param. Mutually exclusive with matrix.",
+				},
+				"input_param": map[string]interface{}{
+					"type":        "string",
+					"description": "The config parameter each fanned-out file's path is assigned to. Required when inputs_path is set.",
+				},
+				"matrix": map[string]interface{}{
+					"type":        "array",
+					"description": "An inline list of config overrides, one analysis per entry. Mutually exclusive with inputs_path.",
+					"items": map[string]interface{}{
+						"type": "object",
+					},
+				},
+				"max_parallel": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum number of analyses to submit concurrently (default 4)",
+					"default":     4,
+				},
+			},
+			Required: []string{"app_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleLaunchBatch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AppID       string                `json:"app_id"`
+		SystemID    string                `json:"system_id"`
+		Config      client.LaunchConfig   `json:"config"`
+		InputsPath  string                `json:"inputs_path"`
+		InputParam  string                `json:"input_param"`
+		Matrix      []client.LaunchConfig `json:"matrix"`
+		MaxParallel int                   `json:"max_parallel"`
+	}
+	params.SystemID = "de" // default
+	params.MaxParallel = 4 // default
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	if params.InputsPath != "" && len(params.Matrix) > 0 {
+		return nil, fmt.Errorf("launch_batch takes inputs_path or matrix, not both")
+	}
+
+	items, err := s.batchItemsFor(ctx, params.InputsPath, params.InputParam, params.Matrix)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("launching batch", "app_id", params.AppID, "system_id", params.SystemID, "items", len(items))
+
+	batch, err := s.workflows.LaunchBatch(ctx, params.AppID, params.SystemID, params.Config, items, params.MaxParallel)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(formatBatch(batch)), nil
+}
+
+// batchItemsFor builds the BatchItem list for a launch_batch call from
+// either inputsPath (browsed for its data objects, each one tagged onto
+// inputParam) or matrix, whichever was set - the caller has already
+// rejected both being set together.
+func (s *FormationMCPServer) batchItemsFor(ctx context.Context, inputsPath, inputParam string, matrix []client.LaunchConfig) ([]workflows.BatchItem, error) {
+	switch {
+	case inputsPath != "":
+		if inputParam == "" {
+			return nil, fmt.Errorf("input_param is required when inputs_path is set")
+		}
+
+		data, err := s.client.BrowseData(ctx, inputsPath, 0, 0, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to browse inputs_path: %w", err)
+		}
+		dir, ok := data.(*client.DirectoryContents)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a directory", inputsPath)
+		}
+
+		var items []workflows.BatchItem
+		for _, entry := range dir.Contents {
+			if entry.Type != "data_object" {
+				continue
+			}
+			path := fmt.Sprintf("%s/%s", strings.TrimSuffix(inputsPath, "/"), entry.Name)
+			items = append(items, workflows.BatchItem{
+				Input:  path,
+				Config: client.LaunchConfig{inputParam: path},
+			})
+		}
+		if len(items) == 0 {
+			return nil, fmt.Errorf("no files found under %s", inputsPath)
+		}
+		return items, nil
+
+	case len(matrix) > 0:
+		items := make([]workflows.BatchItem, len(matrix))
+		for i, override := range matrix {
+			items[i] = workflows.BatchItem{Input: fmt.Sprintf("matrix[%d]", i), Config: override}
+		}
+		return items, nil
+
+	default:
+		return nil, fmt.Errorf("launch_batch requires one of inputs_path or matrix")
+	}
+}
+
+func (s *FormationMCPServer) getBatchStatusTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "get_batch_status",
+		Description: "Poll the status of every analysis launch_batch submitted for batch_id, refreshing each item's status from get_analysis_status.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"batch_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The batch_id returned by launch_batch",
+				},
+			},
+			Required: []string{"batch_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleGetBatchStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		BatchID string `json:"batch_id"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	batch, err := s.workflows.GetBatchStatus(ctx, params.BatchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(formatBatch(batch)), nil
+}
+
+// formatBatch renders a Batch as a markdown table of analysis_id/status/
+// input, one row per item in its original input order.
+func formatBatch(batch *workflows.Batch) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "## Batch %s (%s on %s)\n\n", batch.ID, batch.AppID, batch.SystemID)
+	builder.WriteString("| analysis_id | status | input |\n")
+	builder.WriteString("| --- | --- | --- |\n")
+	for _, item := range batch.Items {
+		if item.Err != nil {
+			fmt.Fprintf(&builder, "| - | error: %s | %s |\n", item.Err, item.Input)
+			continue
+		}
+		fmt.Fprintf(&builder, "| %s | %s | %s |\n", item.AnalysisID, item.Status, item.Input)
+	}
+	return builder.String()
+}
@@ -0,0 +1,672 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// bulkMode controls how a bulk_* tool handles a failure partway through its
+// item list.
+type bulkMode string
+
+const (
+	// bulkBestEffort applies every item regardless of earlier failures.
+	bulkBestEffort bulkMode = "best_effort"
+	// bulkStopOnError applies items in order and stops at the first
+	// failure, leaving already-applied items in place.
+	bulkStopOnError bulkMode = "stop_on_error"
+	// bulkAllOrNothing preflights every item before applying any of them,
+	// and best-effort compensates already-applied items if a later one
+	// fails.
+	bulkAllOrNothing bulkMode = "all_or_nothing"
+)
+
+// bulkModeProperty is the shared input schema property for mode, reused by
+// every bulk_* tool.
+var bulkModeProperty = map[string]interface{}{
+	"type":        "string",
+	"description": "How to handle a failure partway through the batch: \"best_effort\" (apply every item regardless), \"stop_on_error\" (stop at the first failure), or \"all_or_nothing\" (preflight everything first, and best-effort roll back already-applied items if a later one fails)",
+	"enum":        []string{"best_effort", "stop_on_error", "all_or_nothing"},
+	"default":     "best_effort",
+}
+
+// bulkItemResult is one item's outcome in a bulk_* tool's response.
+type bulkItemResult struct {
+	Path       string `json:"path"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+	RolledBack bool   `json:"rolled_back,omitempty"`
+}
+
+// runBulk applies an operation to n items according to mode.
+//
+// preflight is only consulted when mode is bulkAllOrNothing, before any
+// apply call runs; if it fails for any item, nothing is applied and every
+// item is reported as failed. apply performs the real operation for item i.
+// compensate best-effort undoes apply(i) and is only invoked, during an
+// all_or_nothing rollback, for items that were already applied; pass nil
+// when an operation can't meaningfully be undone (e.g. a delete), and the
+// affected items are reported as not rolled back instead.
+func runBulk(mode bulkMode, n int, pathAt func(i int) string, preflight func(i int) error, apply func(i int) error, compensate func(i int)) []bulkItemResult {
+	results := make([]bulkItemResult, n)
+	for i := 0; i < n; i++ {
+		results[i].Path = pathAt(i)
+	}
+
+	if mode == bulkAllOrNothing && preflight != nil {
+		for i := 0; i < n; i++ {
+			if err := preflight(i); err != nil {
+				results[i].Error = fmt.Sprintf("preflight failed: %v", err)
+				for j := 0; j < n; j++ {
+					if j != i {
+						results[j].Error = fmt.Sprintf("aborted: preflight failed for %s", results[i].Path)
+					}
+				}
+				return results
+			}
+		}
+	}
+
+	var applied []int
+	for i := 0; i < n; i++ {
+		if err := apply(i); err != nil {
+			results[i].Error = err.Error()
+
+			if mode == bulkBestEffort {
+				continue
+			}
+
+			if mode == bulkAllOrNothing {
+				for j := len(applied) - 1; j >= 0; j-- {
+					idx := applied[j]
+					results[idx].OK = false
+					if compensate != nil {
+						compensate(idx)
+						results[idx].RolledBack = true
+					} else {
+						results[idx].Error = "succeeded but could not be rolled back after a later failure in this batch"
+					}
+				}
+			}
+
+			for k := i + 1; k < n; k++ {
+				results[k].Error = "skipped: an earlier item failed"
+			}
+			return results
+		}
+
+		results[i].OK = true
+		applied = append(applied, i)
+	}
+
+	return results
+}
+
+// formatBulkResults renders the per-item outcome list shared by every
+// bulk_* tool's text response.
+func formatBulkResults(label string, mode bulkMode, results []bulkItemResult) string {
+	succeeded := 0
+	for _, r := range results {
+		if r.OK {
+			succeeded++
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## %s (%s)\n\n", label, mode))
+	builder.WriteString(fmt.Sprintf("%d/%d succeeded\n\n", succeeded, len(results)))
+	for _, r := range results {
+		switch {
+		case r.OK:
+			builder.WriteString(fmt.Sprintf("- ✅ %s\n", r.Path))
+		case r.RolledBack:
+			builder.WriteString(fmt.Sprintf("- ⏪ %s: %s (rolled back)\n", r.Path, r.Error))
+		default:
+			builder.WriteString(fmt.Sprintf("- ❌ %s: %s\n", r.Path, r.Error))
+		}
+	}
+	return builder.String()
+}
+
+func (s *FormationMCPServer) bulkCreateDirectoriesTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "bulk_create_directories",
+		Description: "Create multiple directories in iRODS in one call",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"items": map[string]interface{}{
+					"type":        "array",
+					"description": "The directories to create",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"path": map[string]interface{}{
+								"type":        "string",
+								"description": "The path for the new directory",
+							},
+							"metadata": map[string]interface{}{
+								"type":        "object",
+								"description": "Optional metadata to attach to the directory",
+							},
+						},
+						"required": []string{"path"},
+					},
+				},
+				"mode": bulkModeProperty,
+			},
+			Required: []string{"items"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleBulkCreateDirectories(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Items []struct {
+			Path     string                 `json:"path"`
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"items"`
+		Mode bulkMode `json:"mode"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+	if params.Mode == "" {
+		params.Mode = bulkBestEffort
+	}
+
+	slog.Info("bulk creating directories", "count", len(params.Items), "mode", params.Mode)
+
+	results := runBulk(params.Mode, len(params.Items),
+		func(i int) string { return params.Items[i].Path },
+		func(i int) error {
+			if _, err := s.client.BrowseData(ctx, params.Items[i].Path, 0, 0, false); err == nil {
+				return fmt.Errorf("path already exists")
+			}
+			return nil
+		},
+		func(i int) error {
+			_, err := s.client.CreateDirectory(ctx, params.Items[i].Path, params.Items[i].Metadata)
+			return err
+		},
+		func(i int) {
+			if err := s.client.DeleteData(ctx, params.Items[i].Path, true, false); err != nil {
+				slog.Warn("failed to roll back created directory", "path", params.Items[i].Path, "error", err)
+			}
+		},
+	)
+
+	return mcp.NewToolResultText(formatBulkResults("Bulk Create Directories", params.Mode, results)), nil
+}
+
+func (s *FormationMCPServer) bulkUploadFilesTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "bulk_upload_files",
+		Description: "Upload multiple files to iRODS in one call",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"items": map[string]interface{}{
+					"type":        "array",
+					"description": "The files to upload",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"path": map[string]interface{}{
+								"type":        "string",
+								"description": "The destination path for the file",
+							},
+							"content": map[string]interface{}{
+								"type":        "string",
+								"description": "The file content",
+							},
+							"metadata": map[string]interface{}{
+								"type":        "object",
+								"description": "Optional metadata to attach to the file",
+							},
+						},
+						"required": []string{"path", "content"},
+					},
+				},
+				"mode": bulkModeProperty,
+			},
+			Required: []string{"items"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleBulkUploadFiles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Items []struct {
+			Path     string                 `json:"path"`
+			Content  string                 `json:"content"`
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"items"`
+		Mode bulkMode `json:"mode"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+	if params.Mode == "" {
+		params.Mode = bulkBestEffort
+	}
+
+	slog.Info("bulk uploading files", "count", len(params.Items), "mode", params.Mode)
+
+	existed := make([]bool, len(params.Items))
+	snapshots := make([]map[string]interface{}, len(params.Items))
+
+	results := runBulk(params.Mode, len(params.Items),
+		func(i int) string { return params.Items[i].Path },
+		func(i int) error {
+			// Uploads may legitimately create or overwrite, so existence
+			// isn't a failure; record the prior state so a rollback knows
+			// whether to delete the file or just restore its metadata.
+			if _, err := s.client.BrowseData(ctx, params.Items[i].Path, 0, 0, false); err == nil {
+				existed[i] = true
+				if snap, err := s.client.SnapshotMetadata(ctx, params.Items[i].Path); err == nil {
+					snapshots[i] = snap
+				}
+			}
+			return nil
+		},
+		func(i int) error {
+			return s.client.UploadFile(ctx, params.Items[i].Path, params.Items[i].Content, params.Items[i].Metadata)
+		},
+		func(i int) {
+			path := params.Items[i].Path
+			if existed[i] {
+				if snapshots[i] != nil {
+					if err := s.client.SetMetadata(ctx, path, snapshots[i], true); err != nil {
+						slog.Warn("failed to restore metadata after rollback", "path", path, "error", err)
+					}
+				}
+				return
+			}
+			if err := s.client.DeleteData(ctx, path, false, false); err != nil {
+				slog.Warn("failed to roll back uploaded file", "path", path, "error", err)
+			}
+		},
+	)
+
+	return mcp.NewToolResultText(formatBulkResults("Bulk Upload Files", params.Mode, results)), nil
+}
+
+func (s *FormationMCPServer) bulkSetMetadataTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "bulk_set_metadata",
+		Description: "Add or replace metadata on multiple paths in one call",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"items": map[string]interface{}{
+					"type":        "array",
+					"description": "The metadata updates to apply",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"path": map[string]interface{}{
+								"type":        "string",
+								"description": "The path to set metadata on",
+							},
+							"metadata": map[string]interface{}{
+								"type":        "object",
+								"description": "Metadata to set",
+							},
+							"replace": map[string]interface{}{
+								"type":        "boolean",
+								"description": "Whether to replace existing metadata (default false)",
+								"default":     false,
+							},
+						},
+						"required": []string{"path", "metadata"},
+					},
+				},
+				"mode": bulkModeProperty,
+			},
+			Required: []string{"items"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleBulkSetMetadata(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Items []struct {
+			Path     string                 `json:"path"`
+			Metadata map[string]interface{} `json:"metadata"`
+			Replace  bool                   `json:"replace"`
+		} `json:"items"`
+		Mode bulkMode `json:"mode"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+	if params.Mode == "" {
+		params.Mode = bulkBestEffort
+	}
+
+	slog.Info("bulk setting metadata", "count", len(params.Items), "mode", params.Mode)
+
+	snapshots := make([]map[string]interface{}, len(params.Items))
+
+	results := runBulk(params.Mode, len(params.Items),
+		func(i int) string { return params.Items[i].Path },
+		func(i int) error {
+			if _, err := s.client.BrowseData(ctx, params.Items[i].Path, 0, 0, false); err != nil {
+				return fmt.Errorf("path not found: %w", err)
+			}
+			if snap, err := s.client.SnapshotMetadata(ctx, params.Items[i].Path); err == nil {
+				snapshots[i] = snap
+			}
+			return nil
+		},
+		func(i int) error {
+			return s.client.SetMetadata(ctx, params.Items[i].Path, params.Items[i].Metadata, params.Items[i].Replace)
+		},
+		func(i int) {
+			if snapshots[i] == nil {
+				return
+			}
+			if err := s.client.SetMetadata(ctx, params.Items[i].Path, snapshots[i], true); err != nil {
+				slog.Warn("failed to restore metadata after rollback", "path", params.Items[i].Path, "error", err)
+			}
+		},
+	)
+
+	return mcp.NewToolResultText(formatBulkResults("Bulk Set Metadata", params.Mode, results)), nil
+}
+
+// bulkDeletePropagation controls what happens to a deleted directory's
+// children, borrowing the foreground/background/orphan vocabulary from
+// GitOps-style deletion semantics.
+type bulkDeletePropagation string
+
+const (
+	// bulkDeleteForeground recursively deletes (when recurse is set) and
+	// waits for every item's result before returning - the default.
+	bulkDeleteForeground bulkDeletePropagation = "foreground"
+	// bulkDeleteBackground submits every delete to run independently of
+	// this call and returns immediately, best-effort.
+	bulkDeleteBackground bulkDeletePropagation = "background"
+	// bulkDeleteOrphan removes only the parent collection entry, leaving
+	// children unreachable through it but not themselves deleted; it
+	// forces recurse off regardless of what the item requested.
+	bulkDeleteOrphan bulkDeletePropagation = "orphan"
+)
+
+// bulkDeleteItemResult is one item's outcome in bulk_delete_data's response.
+// It's a distinct shape from bulkItemResult because delete has no notion of
+// "rolled back" but does need a would_delete_count and a richer status than
+// a plain ok/not-ok.
+type bulkDeleteItemResult struct {
+	Path             string `json:"path"`
+	Status           string `json:"status"`
+	Error            string `json:"error,omitempty"`
+	WouldDeleteCount int    `json:"would_delete_count"`
+}
+
+// defaultBulkDeleteConcurrency mirrors workflows.defaultBulkConcurrency's
+// min(8, GOMAXPROCS) choice for a sensible default worker pool size.
+func defaultBulkDeleteConcurrency() int {
+	if n := runtime.GOMAXPROCS(0); n < 8 {
+		return n
+	}
+	return 8
+}
+
+// countForDelete returns a shallow estimate of how many iRODS entries
+// deleting path would remove: just the path itself, or the path plus its
+// immediate children when recurse is set. It's not a full recursive walk,
+// so a browse failure (e.g. path is a plain file, or is already gone) just
+// falls back to 1 rather than failing the delete over an estimate.
+func countForDelete(ctx context.Context, c client.FormationAPIClient, path string, recurse bool) int {
+	if !recurse {
+		return 1
+	}
+	data, err := c.BrowseData(ctx, path, 0, 0, false)
+	if err != nil {
+		return 1
+	}
+	dir, ok := data.(*client.DirectoryContents)
+	if !ok {
+		return 1
+	}
+	return 1 + len(dir.Contents)
+}
+
+// formatBulkDeleteResults renders bulk_delete_data's structured per-item
+// results as a markdown table, since its status/would_delete_count shape
+// doesn't fit formatBulkResults's ok/rolled-back rendering.
+func formatBulkDeleteResults(mode bulkMode, propagation bulkDeletePropagation, results []bulkDeleteItemResult) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## Bulk Delete Data (%s, %s)\n\n", mode, propagation))
+	builder.WriteString("| path | status | would_delete_count | error |\n")
+	builder.WriteString("| --- | --- | --- | --- |\n")
+	for _, r := range results {
+		builder.WriteString(fmt.Sprintf("| %s | %s | %d | %s |\n", r.Path, r.Status, r.WouldDeleteCount, r.Error))
+	}
+	return builder.String()
+}
+
+func (s *FormationMCPServer) bulkDeleteDataTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "bulk_delete_data",
+		Description: "Delete multiple files or directories from iRODS in one call. Deletes can't be rolled back, so an all_or_nothing batch only preflights every path before deleting any of them; it can't undo deletes that already happened before a later failure. best_effort batches (the default) run concurrently across max_parallel workers.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"items": map[string]interface{}{
+					"type":        "array",
+					"description": "The paths to delete",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"path": map[string]interface{}{
+								"type":        "string",
+								"description": "The path to delete",
+							},
+							"recurse": map[string]interface{}{
+								"type":        "boolean",
+								"description": "Whether to recursively delete directories (default false; ignored when propagation is \"orphan\", which always behaves as if this were false)",
+								"default":     false,
+							},
+							"version_id": map[string]interface{}{
+								"type":        "string",
+								"description": "Delete only this version of the object. Not yet supported by the underlying Formation client; accepted for forward compatibility but currently has no effect.",
+							},
+							"dry_run": map[string]interface{}{
+								"type":        "boolean",
+								"description": "Preview what would be deleted without actually deleting (default false; also true whenever the top-level dry_run is true)",
+								"default":     false,
+							},
+						},
+						"required": []string{"path"},
+					},
+				},
+				"mode": bulkModeProperty,
+				"propagation": map[string]interface{}{
+					"type":        "string",
+					"description": "What happens to each path's children: \"foreground\" (delete and wait for every result, the default), \"background\" (submit every delete to run independently of this call and return immediately, best-effort), or \"orphan\" (remove only the parent collection entry, leaving children unreachable through it but not themselves deleted)",
+					"enum":        []string{"foreground", "background", "orphan"},
+					"default":     "foreground",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Preview every item regardless of its own dry_run value (default false)",
+					"default":     false,
+				},
+				"max_parallel": map[string]interface{}{
+					"type":        "integer",
+					"description": "How many deletes to run at once in best_effort mode (default: min(8, GOMAXPROCS)); ignored by stop_on_error/all_or_nothing, which always run strictly in order",
+				},
+				"timeout_seconds": map[string]interface{}{
+					"type":        "integer",
+					"description": "Abort any deletes still in flight after this many seconds (default: no timeout beyond the caller's own)",
+				},
+			},
+			Required: []string{"items"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleBulkDeleteData(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Items []struct {
+			Path      string `json:"path"`
+			Recurse   bool   `json:"recurse"`
+			VersionID string `json:"version_id"`
+			DryRun    bool   `json:"dry_run"`
+		} `json:"items"`
+		Mode           bulkMode              `json:"mode"`
+		Propagation    bulkDeletePropagation `json:"propagation"`
+		DryRun         bool                  `json:"dry_run"`
+		MaxParallel    int                   `json:"max_parallel"`
+		TimeoutSeconds int                   `json:"timeout_seconds"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+	if params.Mode == "" {
+		params.Mode = bulkBestEffort
+	}
+	if params.Propagation == "" {
+		params.Propagation = bulkDeleteForeground
+	}
+	if params.MaxParallel <= 0 {
+		params.MaxParallel = defaultBulkDeleteConcurrency()
+	}
+	if params.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(params.TimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+
+	slog.Info("bulk deleting data", "count", len(params.Items), "mode", params.Mode, "propagation", params.Propagation, "max_parallel", params.MaxParallel)
+
+	recurseFor := func(i int) bool {
+		if params.Propagation == bulkDeleteOrphan {
+			return false
+		}
+		return params.Items[i].Recurse
+	}
+	dryRunFor := func(i int) bool {
+		return params.DryRun || params.Items[i].DryRun
+	}
+
+	deleteOne := func(i int) bulkDeleteItemResult {
+		path := params.Items[i].Path
+		result := bulkDeleteItemResult{Path: path, WouldDeleteCount: countForDelete(ctx, s.client, path, recurseFor(i))}
+
+		if err := s.client.DeleteData(ctx, path, recurseFor(i), dryRunFor(i)); err != nil {
+			result.Status = "error"
+			result.Error = err.Error()
+			return result
+		}
+
+		switch {
+		case dryRunFor(i):
+			result.Status = "would_delete"
+		case params.Propagation == bulkDeleteOrphan:
+			result.Status = "orphaned"
+		default:
+			result.Status = "deleted"
+		}
+		return result
+	}
+
+	results := make([]bulkDeleteItemResult, len(params.Items))
+
+	switch {
+	case params.Propagation == bulkDeleteBackground:
+		// Fire-and-forget: the handler returns before these finish, so each
+		// goroutine gets its own detached context rather than the request's,
+		// which will be cancelled the moment this function returns.
+		for i := range params.Items {
+			path, recurse, dryRun := params.Items[i].Path, recurseFor(i), dryRunFor(i)
+			results[i] = bulkDeleteItemResult{
+				Path:             path,
+				Status:           "submitted",
+				WouldDeleteCount: countForDelete(ctx, s.client, path, recurse),
+			}
+			go func() {
+				if err := s.client.DeleteData(context.Background(), path, recurse, dryRun); err != nil {
+					slog.Warn("background bulk delete failed", "path", path, "error", err)
+				}
+			}()
+		}
+
+	case params.Mode != bulkBestEffort:
+		// stop_on_error/all_or_nothing depend on strict ordering (does item
+		// i fail before item i+1 even runs?), so they stay sequential
+		// regardless of max_parallel.
+		if params.Mode == bulkAllOrNothing {
+			// Deletes can't be rolled back, so all_or_nothing preflights
+			// every path before deleting any of them instead of trying to
+			// undo already-applied deletes after the fact.
+			for i := range params.Items {
+				if _, err := s.client.BrowseData(ctx, params.Items[i].Path, 0, 0, false); err != nil {
+					for j := range params.Items {
+						path := params.Items[j].Path
+						if j == i {
+							results[j] = bulkDeleteItemResult{Path: path, Status: "error", Error: fmt.Sprintf("preflight failed: %v", err)}
+						} else {
+							results[j] = bulkDeleteItemResult{Path: path, Status: "error", Error: fmt.Sprintf("aborted: preflight failed for %s", params.Items[i].Path)}
+						}
+					}
+					return mcp.NewToolResultText(formatBulkDeleteResults(params.Mode, params.Propagation, results)), nil
+				}
+			}
+		}
+		for i := range params.Items {
+			results[i] = deleteOne(i)
+			if results[i].Status != "error" {
+				continue
+			}
+
+			if params.Mode == bulkAllOrNothing {
+				// Deletes can't be rolled back, so report every earlier
+				// success as unrecoverable instead of pretending to undo it.
+				for j := 0; j < i; j++ {
+					if results[j].Status != "error" {
+						results[j].Error = "succeeded but could not be rolled back after a later failure in this batch"
+					}
+				}
+			}
+			for j := i + 1; j < len(params.Items); j++ {
+				results[j] = bulkDeleteItemResult{Path: params.Items[j].Path, Status: "skipped", Error: "skipped: an earlier item failed"}
+			}
+			break
+		}
+
+	default:
+		// best_effort items are independent of each other, so they run
+		// concurrently across a bounded worker pool.
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, params.MaxParallel)
+		for i := range params.Items {
+			i := i
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				results[i] = deleteOne(i)
+			}()
+		}
+		wg.Wait()
+	}
+
+	return mcp.NewToolResultText(formatBulkDeleteResults(params.Mode, params.Propagation, results)), nil
+}
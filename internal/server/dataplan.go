@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (s *FormationMCPServer) applyDataPlanTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "apply_data_plan",
+		Description: "Apply (or preview, with dry_run) an ordered batch of create_directory/set_metadata/delete operations against iRODS as a single plan. set_metadata and delete paths may be glob patterns, expanded against the current tree at apply time. Use dry_run to see which paths a plan would touch - including what a glob or a recursive delete would resolve to - before committing it.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"ops": map[string]interface{}{
+					"type":        "array",
+					"description": "The operations to apply, in order",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"type": map[string]interface{}{
+								"type":        "string",
+								"description": "The kind of operation",
+								"enum":        []string{"create_directory", "set_metadata", "delete"},
+							},
+							"path": map[string]interface{}{
+								"type":        "string",
+								"description": "The target path. For set_metadata and delete, may be a glob pattern (e.g. \"/cyverse/home/user/runs/*.log\")",
+							},
+							"metadata": map[string]interface{}{
+								"type":        "object",
+								"description": "Metadata for create_directory and set_metadata ops",
+							},
+							"replace": map[string]interface{}{
+								"type":        "boolean",
+								"description": "For set_metadata: whether to replace existing metadata (default false)",
+								"default":     false,
+							},
+							"recurse": map[string]interface{}{
+								"type":        "boolean",
+								"description": "For delete: whether to recursively delete directories (default false)",
+								"default":     false,
+							},
+						},
+						"required": []string{"type", "path"},
+					},
+				},
+				"stop_on_error": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Stop at the first op that fails and best-effort roll back ops already applied earlier in this plan (default false, meaning every op is attempted regardless of earlier failures)",
+					"default":     false,
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Resolve every op against the current tree without applying any of them (default false)",
+					"default":     false,
+				},
+			},
+			Required: []string{"ops"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleApplyDataPlan(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Ops []struct {
+			Type     string                 `json:"type"`
+			Path     string                 `json:"path"`
+			Metadata map[string]interface{} `json:"metadata"`
+			Replace  bool                   `json:"replace"`
+			Recurse  bool                   `json:"recurse"`
+		} `json:"ops"`
+		StopOnError bool `json:"stop_on_error"`
+		DryRun      bool `json:"dry_run"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	plan := client.BulkDataPlan{
+		StopOnError: params.StopOnError,
+		DryRun:      params.DryRun,
+	}
+	for _, op := range params.Ops {
+		plan.Ops = append(plan.Ops, client.BulkOp{
+			Type:     client.BulkOpType(op.Type),
+			Path:     op.Path,
+			Metadata: op.Metadata,
+			Replace:  op.Replace,
+			Recurse:  op.Recurse,
+		})
+	}
+
+	slog.Info("applying data plan", "ops", len(plan.Ops), "stop_on_error", plan.StopOnError, "dry_run", plan.DryRun)
+
+	result, err := client.ApplyBulkPlan(ctx, s.client, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(formatDataPlanResult(result)), nil
+}
+
+// formatDataPlanResult renders an ApplyBulkPlan result as a per-op summary,
+// analogous to formatBulkResults for the single-operation bulk_* tools.
+func formatDataPlanResult(result *client.BulkPlanResult) string {
+	succeeded := 0
+	for _, r := range result.Results {
+		if r.OK {
+			succeeded++
+		}
+	}
+
+	var builder strings.Builder
+	if result.DryRun {
+		builder.WriteString(fmt.Sprintf("## Data Plan (dry run, %d/%d ops resolved)\n\n", succeeded, len(result.Results)))
+	} else {
+		builder.WriteString(fmt.Sprintf("## Data Plan (%d/%d ops succeeded)\n\n", succeeded, len(result.Results)))
+	}
+
+	for _, r := range result.Results {
+		switch {
+		case result.DryRun && r.OK:
+			builder.WriteString(fmt.Sprintf("- 🔍 %s %s -> %s\n", r.Op.Type, r.Op.Path, strings.Join(r.ResolvedPaths, ", ")))
+		case r.RolledBack:
+			builder.WriteString(fmt.Sprintf("- ⏪ %s %s: %s (rolled back)\n", r.Op.Type, r.Op.Path, r.Error))
+		case r.OK:
+			builder.WriteString(fmt.Sprintf("- ✅ %s %s -> %s\n", r.Op.Type, r.Op.Path, strings.Join(r.ResolvedPaths, ", ")))
+		default:
+			builder.WriteString(fmt.Sprintf("- ❌ %s %s: %s\n", r.Op.Type, r.Op.Path, r.Error))
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,347 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// deleteJobStatus is the lifecycle state of a deleteJob.
+type deleteJobStatus string
+
+const (
+	deleteJobPending   deleteJobStatus = "pending"
+	deleteJobCancelled deleteJobStatus = "cancelled"
+	deleteJobDone      deleteJobStatus = "done"
+	deleteJobFailed    deleteJobStatus = "failed"
+)
+
+// deleteJob is a delete_data call scheduled with a positive
+// grace_period_seconds, recorded so it can be inspected or cancelled
+// before ExecuteAt, and persisted so a server restart doesn't drop it.
+type deleteJob struct {
+	ID        string          `json:"id"`
+	Path      string          `json:"path"`
+	Recurse   bool            `json:"recurse"`
+	Purge     bool            `json:"purge"`
+	VersionID string          `json:"version_id,omitempty"`
+	ExecuteAt time.Time       `json:"execute_at"`
+	Status    deleteJobStatus `json:"status"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// deleteJobManager tracks scheduled delete_data jobs in memory, backed by
+// a JSON file on disk when a path is configured via loadFrom. It's
+// intentionally separate from the bulk_delete_data worker pool in bulk.go:
+// that one runs a fixed batch to completion, this one holds single jobs
+// open across a grace period so they can be cancelled.
+type deleteJobManager struct {
+	mu     sync.Mutex
+	path   string
+	jobs   map[string]*deleteJob
+	timers map[string]*time.Timer
+	run    func(ctx context.Context, job *deleteJob) error
+}
+
+// newDeleteJobManager returns an in-memory-only manager that calls run to
+// actually perform each job's deletion once its grace period elapses.
+func newDeleteJobManager(run func(ctx context.Context, job *deleteJob) error) *deleteJobManager {
+	return &deleteJobManager{
+		jobs:   make(map[string]*deleteJob),
+		timers: make(map[string]*time.Timer),
+		run:    run,
+	}
+}
+
+// loadFrom points m at a JSON state file, loading any jobs already queued
+// there and rescheduling the still-pending ones (immediately, if
+// ExecuteAt has already passed). It's a no-op if path doesn't exist yet.
+func (m *deleteJobManager) loadFrom(path string) error {
+	m.mu.Lock()
+	m.path = path
+	m.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read delete job store: %w", err)
+	}
+
+	var jobs []*deleteJob
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return fmt.Errorf("failed to parse delete job store: %w", err)
+	}
+
+	m.mu.Lock()
+	for _, job := range jobs {
+		m.jobs[job.ID] = job
+	}
+	m.mu.Unlock()
+
+	for _, job := range jobs {
+		if job.Status == deleteJobPending {
+			m.schedule(job)
+		}
+	}
+	return nil
+}
+
+// enqueue records a new pending job for path, schedules its execution
+// after graceSeconds, and persists the updated queue.
+func (m *deleteJobManager) enqueue(path string, recurse, purge bool, versionID string, graceSeconds int) *deleteJob {
+	job := &deleteJob{
+		ID:        fmt.Sprintf("delete-job-%d", time.Now().UnixNano()),
+		Path:      path,
+		Recurse:   recurse,
+		Purge:     purge,
+		VersionID: versionID,
+		ExecuteAt: time.Now().Add(time.Duration(graceSeconds) * time.Second),
+		Status:    deleteJobPending,
+	}
+
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	m.persist()
+	m.schedule(job)
+
+	slog.Info("enqueued delete job", "job_id", job.ID, "path", path, "execute_at", job.ExecuteAt)
+	return job
+}
+
+// schedule arms a timer that calls execute once job.ExecuteAt arrives (or
+// immediately, if it has already passed - e.g. a restart loaded a job
+// whose grace period elapsed while the server was down).
+func (m *deleteJobManager) schedule(job *deleteJob) {
+	delay := time.Until(job.ExecuteAt)
+	if delay < 0 {
+		delay = 0
+	}
+
+	timer := time.AfterFunc(delay, func() { m.execute(job.ID) })
+
+	m.mu.Lock()
+	m.timers[job.ID] = timer
+	m.mu.Unlock()
+}
+
+// execute runs the job's deletion via m.run and records the outcome. It's
+// a no-op if the job was cancelled (or already ran) before its timer fired.
+func (m *deleteJobManager) execute(id string) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok || job.Status != deleteJobPending {
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Unlock()
+
+	err := m.run(context.Background(), job)
+
+	m.mu.Lock()
+	if err != nil {
+		job.Status = deleteJobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = deleteJobDone
+	}
+	delete(m.timers, id)
+	m.mu.Unlock()
+
+	m.persist()
+
+	if err != nil {
+		slog.Warn("delete job failed", "job_id", id, "path", job.Path, "error", err)
+		return
+	}
+	slog.Info("executed delete job", "job_id", id, "path", job.Path)
+}
+
+// cancel stops id's timer and marks it cancelled, if it's still pending.
+func (m *deleteJobManager) cancel(id string) (*deleteJob, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("no delete job found with id %q", id)
+	}
+	if job.Status != deleteJobPending {
+		status := job.Status
+		m.mu.Unlock()
+		return nil, fmt.Errorf("delete job %q is already %s", id, status)
+	}
+
+	if timer, ok := m.timers[id]; ok {
+		timer.Stop()
+		delete(m.timers, id)
+	}
+	job.Status = deleteJobCancelled
+	m.mu.Unlock()
+
+	m.persist()
+	slog.Info("cancelled delete job", "job_id", id, "path", job.Path)
+	return job, nil
+}
+
+// get returns the job recorded under id, if any.
+func (m *deleteJobManager) get(id string) (*deleteJob, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// list returns every known job, ordered by ExecuteAt.
+func (m *deleteJobManager) list() []*deleteJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]*deleteJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ExecuteAt.Before(jobs[j].ExecuteAt) })
+	return jobs
+}
+
+// persist rewrites the whole queue to m.path as a JSON array, the same
+// whole-file-rewrite approach config.FileStorage.Save uses. It's a no-op
+// if no path has been configured via loadFrom, and failures are logged
+// rather than returned, since a persistence hiccup shouldn't fail the
+// enqueue/cancel/execute call that triggered it.
+func (m *deleteJobManager) persist() {
+	m.mu.Lock()
+	path := m.path
+	jobs := make([]*deleteJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	m.mu.Unlock()
+
+	if path == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		slog.Warn("failed to marshal delete job store", "error", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		slog.Warn("failed to persist delete job store", "path", path, "error", err)
+	}
+}
+
+func (s *FormationMCPServer) getDeleteJobTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "get_delete_job",
+		Description: "Get the status of a delete job delete_data scheduled with a positive grace_period_seconds",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"job_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The job_id returned by delete_data",
+				},
+			},
+			Required: []string{"job_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleGetDeleteJob(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		JobID string `json:"job_id"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	job, ok := s.deleteJobs.get(params.JobID)
+	if !ok {
+		return nil, fmt.Errorf("no delete job found with id %q", params.JobID)
+	}
+
+	return mcp.NewToolResultText(formatDeleteJob(job)), nil
+}
+
+func (s *FormationMCPServer) listDeleteJobsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_delete_jobs",
+		Description: "List every delete job delete_data has scheduled with a positive grace_period_seconds, pending or resolved",
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]interface{}{},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleListDeleteJobs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jobs := s.deleteJobs.list()
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## Delete Jobs (%d)\n\n", len(jobs)))
+	for _, job := range jobs {
+		builder.WriteString(formatDeleteJob(job))
+		builder.WriteString("\n")
+	}
+	return mcp.NewToolResultText(builder.String()), nil
+}
+
+func (s *FormationMCPServer) cancelDeleteJobTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "cancel_delete_job",
+		Description: "Cancel a pending delete job before its grace period elapses",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"job_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The job_id returned by delete_data",
+				},
+			},
+			Required: []string{"job_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleCancelDeleteJob(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		JobID string `json:"job_id"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	job, err := s.deleteJobs.cancel(params.JobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("✅ Cancelled delete job %s for %s", job.ID, job.Path)), nil
+}
+
+// formatDeleteJob renders job as a single markdown bullet line.
+func formatDeleteJob(job *deleteJob) string {
+	action := "trash"
+	if job.Purge {
+		action = "purge"
+	}
+
+	line := fmt.Sprintf("- %s: %s %s, scheduled for %s, status %s", job.ID, action, job.Path, job.ExecuteAt.Format(time.RFC3339), job.Status)
+	if job.Error != "" {
+		line += fmt.Sprintf(" (%s)", job.Error)
+	}
+	return line
+}
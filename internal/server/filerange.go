@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (s *FormationMCPServer) readFileRangeTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "read_file_range",
+		Description: "Read one byte range of a file in iRODS, for files too large to read whole via browse_data - e.g. an agent paging through a multi-GB file larger than its context window. Returns base64-encoded content alongside the range actually returned and the file's total size.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "The file path to read from",
+				},
+				"offset": map[string]interface{}{
+					"type":        "integer",
+					"description": "The byte offset to start reading at (default 0)",
+					"default":     0,
+				},
+				"length": map[string]interface{}{
+					"type":        "integer",
+					"description": "The number of bytes to read (default/0 reads through the end of the file)",
+					"default":     0,
+				},
+			},
+			Required: []string{"path"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleReadFileRange(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Path   string `json:"path"`
+		Offset int64  `json:"offset"`
+		Length int64  `json:"length"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	slog.Info("reading file range", "path", params.Path, "offset", params.Offset, "length", params.Length)
+
+	r, metadata, err := s.client.DownloadFileRange(ctx, params.Path, params.Offset, params.Length)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file range: %w", err)
+	}
+
+	text := fmt.Sprintf(
+		"Read %d bytes of `%s` [%d-%d] of %d total\n\n%s",
+		len(content), params.Path, metadata.Offset, metadata.Offset+metadata.Length-1, metadata.TotalSize,
+		base64.StdEncoding.EncodeToString(content),
+	)
+
+	return mcp.NewToolResultText(text), nil
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/audit"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (s *FormationMCPServer) listToolHistoryTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_tool_history",
+		Description: "List recently audited tool calls this server has handled, for reviewing or replaying a multi-step session.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"tool": map[string]interface{}{
+					"type":        "string",
+					"description": "Only include calls to this tool name",
+				},
+				"since": map[string]interface{}{
+					"type":        "string",
+					"description": "Only include calls at or after this RFC3339 timestamp",
+				},
+				"until": map[string]interface{}{
+					"type":        "string",
+					"description": "Only include calls at or before this RFC3339 timestamp",
+				},
+				"failed_only": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Only include calls that returned an error",
+					"default":     false,
+				},
+			},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleListToolHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Tool       string `json:"tool"`
+		Since      string `json:"since"`
+		Until      string `json:"until"`
+		FailedOnly bool   `json:"failed_only"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	filter := audit.Filter{Tool: params.Tool, FailedOnly: params.FailedOnly}
+	if params.Since != "" {
+		since, err := time.Parse(time.RFC3339, params.Since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since: %w", err)
+		}
+		filter.Since = since
+	}
+	if params.Until != "" {
+		until, err := time.Parse(time.RFC3339, params.Until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until: %w", err)
+		}
+		filter.Until = until
+	}
+
+	records := s.auditLog.Query(filter)
+	return mcp.NewToolResultText(formatToolHistory(records)), nil
+}
+
+// formatToolHistory renders records as a markdown table, most recent last
+// (the order Query already returns them in).
+func formatToolHistory(records []audit.Record) string {
+	if len(records) == 0 {
+		return "No matching tool calls recorded."
+	}
+
+	var builder strings.Builder
+	builder.WriteString("| id | timestamp | tool | duration | status | summary |\n")
+	builder.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, record := range records {
+		status := "ok"
+		summary := record.ResultSummary
+		if record.Error != "" {
+			status = "error"
+			summary = record.Error
+		}
+		fmt.Fprintf(&builder, "| %s | %s | %s | %s | %s | %s |\n",
+			record.ID, record.Timestamp.Format(time.RFC3339), record.Tool, record.Duration, status, summary)
+	}
+	return builder.String()
+}
+
+func (s *FormationMCPServer) replayToolCallTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "replay_tool_call",
+		Description: "Re-execute a previously audited tool call by its id, optionally overriding some of its original parameters. Pass dry_run=true to preview instead of repeating a mutation, for tools that support it (see upload_file, create_directory, etc.).",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"id": map[string]interface{}{
+					"type":        "string",
+					"description": "The id of the audited call to replay (see list_tool_history)",
+				},
+				"param_overrides": map[string]interface{}{
+					"type":        "object",
+					"description": "Parameters to merge over the original call's arguments before replaying",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Preview the replayed call instead of performing it, if the original tool supports dry_run",
+					"default":     false,
+				},
+			},
+			Required: []string{"id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleReplayToolCall(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		ID             string                 `json:"id"`
+		ParamOverrides map[string]interface{} `json:"param_overrides"`
+		DryRun         bool                   `json:"dry_run"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	original, ok := s.auditLog.Get(params.ID)
+	if !ok {
+		return nil, fmt.Errorf("no audited call found with id %q", params.ID)
+	}
+
+	factory, ok := Lookup(original.Tool)
+	if !ok {
+		return nil, fmt.Errorf("tool %q is no longer registered, can't replay call %q", original.Tool, params.ID)
+	}
+
+	arguments := make(map[string]interface{}, len(original.Params)+len(params.ParamOverrides)+1)
+	for k, v := range original.Params {
+		arguments[k] = v
+	}
+	for k, v := range params.ParamOverrides {
+		arguments[k] = v
+	}
+	if params.DryRun {
+		arguments["dry_run"] = true
+	}
+
+	_, handler := factory(s)
+	return handler(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      original.Tool,
+			Arguments: arguments,
+		},
+	})
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cyverse-de/formation-mcp/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (s *FormationMCPServer) setLogLevelTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "set_log_level",
+		Description: "Change the server's log verbosity at runtime, so a stuck or misbehaving analysis can be debugged without restarting the server. Pass subsystem to tune client/workflows/server independently instead of the whole process.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"level": map[string]interface{}{
+					"type":        "string",
+					"description": "New level: debug, info, warn, or error",
+				},
+				"subsystem": map[string]interface{}{
+					"type":        "string",
+					"description": "Only change this subsystem's level (e.g. client, workflows, server); omit to change the process-wide default",
+				},
+			},
+			Required: []string{"level"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleSetLogLevel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Level     string `json:"level"`
+		Subsystem string `json:"subsystem"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	if s.baseLevel == nil || s.subsystemLevels == nil {
+		return nil, fmt.Errorf("log level controls are not wired up on this server (see SetLogLevelControls)")
+	}
+
+	level, err := logging.ParseLevelStrict(params.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Subsystem == "" {
+		s.baseLevel.Set(level)
+		return mcp.NewToolResultText(fmt.Sprintf("process-wide log level set to %s", level)), nil
+	}
+
+	s.subsystemLevels.Set(params.Subsystem, level)
+	return mcp.NewToolResultText(fmt.Sprintf("log level for subsystem %q set to %s", params.Subsystem, level)), nil
+}
+
+func (s *FormationMCPServer) getLogLevelTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "get_log_level",
+		Description: "Get the server's current log level and any per-subsystem overrides set by set_log_level, as JSON.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+		},
+	}
+}
+
+// logLevelReport is get_log_level's JSON payload.
+type logLevelReport struct {
+	Level      string            `json:"level"`
+	Subsystems map[string]string `json:"subsystems,omitempty"`
+}
+
+func (s *FormationMCPServer) handleGetLogLevel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.baseLevel == nil || s.subsystemLevels == nil {
+		return nil, fmt.Errorf("log level controls are not wired up on this server (see SetLogLevelControls)")
+	}
+
+	report := logLevelReport{Level: s.baseLevel.Level().String()}
+	overrides := s.subsystemLevels.All()
+	if len(overrides) > 0 {
+		report.Subsystems = make(map[string]string, len(overrides))
+		for subsystem, level := range overrides {
+			report.Subsystems[subsystem] = level.String()
+		}
+	}
+
+	payload, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal log level report: %w", err)
+	}
+
+	return mcp.NewToolResultText(string(payload)), nil
+}
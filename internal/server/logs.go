@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// analysisTerminalStatuses mirrors client's own terminalAnalysisStatuses
+// (unexported there, so not reusable directly): once watch_analysis sees
+// one of these, it stops waiting.
+var analysisTerminalStatuses = map[string]bool{
+	"Completed": true,
+	"Failed":    true,
+	"Canceled":  true,
+}
+
+func (s *FormationMCPServer) watchAnalysisTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "watch_analysis",
+		Description: "Wait for an analysis to reach a terminal status or have its URL become ready, emitting MCP progress notifications along the way instead of requiring repeated get_analysis_status polls. Returns once a terminal status or URLReady is seen, or max_wait elapses, whichever comes first. Send a progressToken for notifications to be delivered.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"analysis_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The analysis ID to watch",
+				},
+				"max_wait": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum time to wait in seconds (default 300)",
+					"default":     300,
+				},
+			},
+			Required: []string{"analysis_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleWatchAnalysis(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AnalysisID string `json:"analysis_id"`
+		MaxWait    int    `json:"max_wait"`
+	}
+	params.MaxWait = 300 // default
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	progressToken, hasProgressToken := progressTokenFromRequest(request)
+
+	slog.Info("watching analysis", "analysis_id", params.AnalysisID, "has_progress_token", hasProgressToken)
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(params.MaxWait)*time.Second)
+	defer cancel()
+
+	events, err := s.client.WatchAnalysis(waitCtx, params.AnalysisID)
+	if err != nil {
+		return nil, err
+	}
+
+	var last client.AnalysisEvent
+	seen := 0
+	timedOut := false
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return mcp.NewToolResultText(formatWatchResult(last, seen, timedOut)), nil
+			}
+			if event.Err != nil {
+				return nil, event.Err
+			}
+			last = event
+			seen++
+			if hasProgressToken {
+				if err := s.sendAnalysisEventProgress(ctx, progressToken, event, seen); err != nil {
+					slog.Warn("failed to send progress notification", "analysis_id", params.AnalysisID, "error", err)
+				}
+			}
+			if event.URLReady || analysisTerminalStatuses[event.Status] {
+				return mcp.NewToolResultText(formatWatchResult(last, seen, timedOut)), nil
+			}
+		case <-waitCtx.Done():
+			timedOut = true
+			return mcp.NewToolResultText(formatWatchResult(last, seen, timedOut)), nil
+		}
+	}
+}
+
+// sendAnalysisEventProgress emits a notifications/progress message carrying
+// one AnalysisEvent from WatchAnalysis, for the given progress token.
+// progress is the number of events seen so far, the way sendLaunchProgress
+// reports elapsed seconds - there's no total to measure against, so it's
+// reported as a monotonically increasing counter rather than a fraction.
+func (s *FormationMCPServer) sendAnalysisEventProgress(ctx context.Context, token mcp.ProgressToken, event client.AnalysisEvent, progress int) error {
+	message := fmt.Sprintf("status=%s", event.Status)
+	if event.URLReady {
+		message = fmt.Sprintf("%s url=%s", message, event.URL)
+	}
+
+	return s.server.SendNotificationToClient(ctx, "notifications/progress", map[string]interface{}{
+		"progressToken": token,
+		"progress":      progress,
+		"message":       message,
+	})
+}
+
+// formatWatchResult renders the outcome of a watch_analysis call: the last
+// event seen (if any), how many events arrived, and whether max_wait was
+// hit before a terminal status or URLReady.
+func formatWatchResult(last client.AnalysisEvent, seen int, timedOut bool) string {
+	if seen == 0 {
+		if timedOut {
+			return "No status updates received before max_wait elapsed."
+		}
+		return "Watch ended with no status updates."
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "## Analysis %s\n\n", last.AnalysisID)
+	fmt.Fprintf(&builder, "- Status: %s\n", last.Status)
+	if last.URLReady {
+		fmt.Fprintf(&builder, "- URL: %s\n", last.URL)
+	}
+	fmt.Fprintf(&builder, "- Updates received: %d\n", seen)
+	if timedOut {
+		builder.WriteString("- max_wait elapsed before a terminal status or URLReady was seen\n")
+	}
+	return builder.String()
+}
+
+func (s *FormationMCPServer) tailAnalysisLogsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "tail_analysis_logs",
+		Description: "Read recent stdout/stderr for a running or finished analysis, optionally following new output as it's produced, so an agent can diagnose a stuck job without repeatedly polling get_analysis_status.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"analysis_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The analysis ID to read logs for",
+				},
+				"follow": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Keep streaming new log lines until max_wait elapses, instead of returning once the current backlog is delivered (default false)",
+					"default":     false,
+				},
+				"since": map[string]interface{}{
+					"type":        "string",
+					"description": "RFC3339 timestamp; only lines logged at or after this time are returned",
+				},
+				"container": map[string]interface{}{
+					"type":        "string",
+					"description": "Which container's logs to read, for multi-container VICE apps (default: the app's primary container)",
+				},
+				"tail_lines": map[string]interface{}{
+					"type":        "integer",
+					"description": "Limit the backlog to the last N lines (default: no limit)",
+					"default":     0,
+				},
+				"max_wait": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum time to wait in seconds, mainly relevant when follow is true (default 60)",
+					"default":     60,
+				},
+			},
+			Required: []string{"analysis_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleTailAnalysisLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AnalysisID string `json:"analysis_id"`
+		Follow     bool   `json:"follow"`
+		Since      string `json:"since"`
+		Container  string `json:"container"`
+		TailLines  int    `json:"tail_lines"`
+		MaxWait    int    `json:"max_wait"`
+	}
+	params.MaxWait = 60 // default
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	opts := client.LogStreamOptions{
+		Follow:    params.Follow,
+		Container: params.Container,
+		TailLines: params.TailLines,
+	}
+	if params.Since != "" {
+		since, err := time.Parse(time.RFC3339, params.Since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since timestamp: %w", err)
+		}
+		opts.Since = since
+	}
+
+	progressToken, hasProgressToken := progressTokenFromRequest(request)
+
+	slog.Info("tailing analysis logs", "analysis_id", params.AnalysisID, "follow", params.Follow, "has_progress_token", hasProgressToken)
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(params.MaxWait)*time.Second)
+	defer cancel()
+
+	lines, err := s.client.StreamAnalysisLogs(waitCtx, params.AnalysisID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var collected []client.LogLine
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return mcp.NewToolResultText(formatLogLines(collected)), nil
+			}
+			if line.Err != nil {
+				return nil, line.Err
+			}
+			collected = append(collected, line)
+			if hasProgressToken {
+				if err := s.sendLogLineProgress(ctx, progressToken, line, len(collected)); err != nil {
+					slog.Warn("failed to send progress notification", "analysis_id", params.AnalysisID, "error", err)
+				}
+			}
+		case <-waitCtx.Done():
+			return mcp.NewToolResultText(formatLogLines(collected)), nil
+		}
+	}
+}
+
+// sendLogLineProgress emits a notifications/progress message carrying one
+// LogLine from StreamAnalysisLogs, for the given progress token. progress
+// is the number of lines delivered so far, for the same reason
+// sendAnalysisEventProgress counts events instead of reporting a fraction.
+func (s *FormationMCPServer) sendLogLineProgress(ctx context.Context, token mcp.ProgressToken, line client.LogLine, progress int) error {
+	message := line.Line
+	if line.Container != "" {
+		message = fmt.Sprintf("[%s] %s", line.Container, message)
+	}
+
+	return s.server.SendNotificationToClient(ctx, "notifications/progress", map[string]interface{}{
+		"progressToken": token,
+		"progress":      progress,
+		"message":       message,
+	})
+}
+
+// formatLogLines renders collected log lines as a fenced code block, one
+// line per entry, prefixed with its container when known.
+func formatLogLines(lines []client.LogLine) string {
+	if len(lines) == 0 {
+		return "No log lines received."
+	}
+
+	var builder strings.Builder
+	builder.WriteString("```\n")
+	for _, line := range lines {
+		if line.Container != "" {
+			fmt.Fprintf(&builder, "[%s] %s\n", line.Container, line.Line)
+		} else {
+			fmt.Fprintf(&builder, "%s\n", line.Line)
+		}
+	}
+	builder.WriteString("```\n")
+	return builder.String()
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// metadataStrategy selects how set_metadata reconciles params.Metadata (or
+// params.Attributes, or params.Patch) against whatever's already on path.
+type metadataStrategy string
+
+const (
+	metadataStrategyMerge     metadataStrategy = "merge"
+	metadataStrategyReplace   metadataStrategy = "replace"
+	metadataStrategyRemove    metadataStrategy = "remove"
+	metadataStrategyStrategic metadataStrategy = "strategic"
+	metadataStrategyJSONPatch metadataStrategy = "json-patch"
+)
+
+// jsonPatchOp is one RFC 6902 operation applied against the current
+// metadata map, which set_metadata treats as a flat JSON object - paths are
+// therefore limited to top-level attributes ("/experiment"), since this
+// repo's metadata model has no nested structure for a patch to walk.
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// applyJSONPatch applies ops to current and returns the result, leaving
+// current untouched. Only add, replace, and remove are supported - test,
+// move, and copy don't have an obvious meaning over a flat attribute=value
+// map with no array structure to index into.
+func applyJSONPatch(current map[string]interface{}, ops []jsonPatchOp) (map[string]interface{}, error) {
+	result := cloneMetadataMap(current)
+	for _, op := range ops {
+		attr := strings.TrimPrefix(op.Path, "/")
+		if attr == "" || attr == op.Path {
+			return nil, fmt.Errorf("json-patch: path %q must be a top-level attribute pointer like \"/experiment\"", op.Path)
+		}
+
+		switch op.Op {
+		case "add", "replace":
+			result[attr] = op.Value
+		case "remove":
+			delete(result, attr)
+		default:
+			return nil, fmt.Errorf("json-patch: unsupported op %q (only add, replace, and remove are)", op.Op)
+		}
+	}
+	return result, nil
+}
+
+// mergeStrategic applies updates onto current the way strategy=strategic
+// set_metadata does: an attribute whose current and incoming values are
+// both arrays is unioned instead of one clobbering the other. This repo's
+// metadata model doesn't carry the iRODS AVU unit field, so the merge key
+// is just the attribute name rather than (attribute,unit).
+func mergeStrategic(current, updates map[string]interface{}) map[string]interface{} {
+	result := cloneMetadataMap(current)
+	for attr, newValue := range updates {
+		existing, ok := result[attr]
+		if !ok {
+			result[attr] = newValue
+			continue
+		}
+
+		existingList, existingIsList := existing.([]interface{})
+		newList, newIsList := newValue.([]interface{})
+		if !existingIsList || !newIsList {
+			result[attr] = newValue
+			continue
+		}
+
+		result[attr] = unionValues(existingList, newList)
+	}
+	return result
+}
+
+// unionValues concatenates a and b, keeping the first occurrence of each
+// distinct value (compared by its formatted string, same as
+// matchesPredicate's equality check in the mock package).
+func unionValues(a, b []interface{}) []interface{} {
+	seen := make(map[string]bool, len(a)+len(b))
+	union := make([]interface{}, 0, len(a)+len(b))
+	for _, v := range a {
+		key := fmt.Sprintf("%v", v)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		union = append(union, v)
+	}
+	for _, v := range b {
+		key := fmt.Sprintf("%v", v)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		union = append(union, v)
+	}
+	return union
+}
+
+// overlayMetadata applies updates onto current key by key, the same
+// add-or-replace-per-key semantics SetMetadata's replace=false sends to
+// iRODS - used for strategy=merge, where (unlike strategic) array values
+// simply clobber rather than union.
+func overlayMetadata(current, updates map[string]interface{}) map[string]interface{} {
+	result := cloneMetadataMap(current)
+	for k, v := range updates {
+		result[k] = v
+	}
+	return result
+}
+
+func cloneMetadataMap(metadata map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		clone[k] = v
+	}
+	return clone
+}
+
+// metadataDiff summarizes how one metadata snapshot changed into another,
+// so set_metadata's result can tell a caller what actually happened
+// without a follow-up read.
+type metadataDiff struct {
+	Added     []string `json:"added"`
+	Removed   []string `json:"removed"`
+	Unchanged []string `json:"unchanged"`
+}
+
+// diffMetadataAttrs compares before and after attribute by attribute. An
+// attribute whose value changed counts as added alongside attributes that
+// are genuinely new, since both are represented by their value in after;
+// removed covers only attributes that disappeared entirely.
+func diffMetadataAttrs(before, after map[string]interface{}) metadataDiff {
+	var diff metadataDiff
+	for attr, newValue := range after {
+		if oldValue, ok := before[attr]; ok && fmt.Sprintf("%v", oldValue) == fmt.Sprintf("%v", newValue) {
+			diff.Unchanged = append(diff.Unchanged, attr)
+			continue
+		}
+		diff.Added = append(diff.Added, attr)
+	}
+	for attr := range before {
+		if _, ok := after[attr]; !ok {
+			diff.Removed = append(diff.Removed, attr)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Unchanged)
+	return diff
+}
+
+// formatMetadataDiff renders diff as a short plain-text summary.
+func formatMetadataDiff(diff metadataDiff) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("added: %s\n", formatAttrList(diff.Added)))
+	b.WriteString(fmt.Sprintf("removed: %s\n", formatAttrList(diff.Removed)))
+	b.WriteString(fmt.Sprintf("unchanged: %s", formatAttrList(diff.Unchanged)))
+	return b.String()
+}
+
+func formatAttrList(attrs []string) string {
+	if len(attrs) == 0 {
+		return "(none)"
+	}
+	return strings.Join(attrs, ", ")
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (s *FormationMCPServer) describeAppParametersTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "describe_app_parameters",
+		Description: "Get an app's parameters rendered as a JSON Schema object, so a caller can prefill a valid config before calling launch_app_and_wait",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"app_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The application ID",
+				},
+				"system_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The system ID (default: de)",
+					"default":     "de",
+				},
+			},
+			Required: []string{"app_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleDescribeAppParameters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AppID    string `json:"app_id"`
+		SystemID string `json:"system_id"`
+	}
+	params.SystemID = "de" // default
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	slog.Info("describing app parameters", "app_id", params.AppID, "system_id", params.SystemID)
+
+	appParams, err := s.client.GetAppParameters(ctx, params.SystemID, params.AppID)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := launchConfigJSONSchema(appParams)
+
+	payload, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameter schema: %w", err)
+	}
+
+	return mcp.NewToolResultResource(string(payload), mcp.TextResourceContents{
+		URI:      appResourceURI(params.SystemID, params.AppID) + "/schema",
+		MIMEType: "application/json",
+		Text:     string(payload),
+	}), nil
+}
+
+// launchConfigJSONSchema renders app's parameters as a JSON Schema object
+// describing the config map launch_app_and_wait's config argument (and
+// ultimately client.LaunchConfig) expects, so an LLM client can prefill a
+// config that passes client.ValidateLaunchConfig before ever submitting it.
+func launchConfigJSONSchema(app *client.AppParameters) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for _, group := range app.Groups {
+		for _, param := range group.Parameters {
+			properties[param.ID] = parameterJSONSchema(param)
+			if param.Required {
+				required = append(required, param.ID)
+			}
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// parameterJSONSchema renders a single Parameter as a JSON Schema property,
+// using the same Type vocabulary client.ValidateLaunchConfig checks against.
+func parameterJSONSchema(param client.Parameter) map[string]interface{} {
+	prop := map[string]interface{}{
+		"description": param.Description,
+	}
+	if param.Label != "" {
+		prop["title"] = param.Label
+	}
+	if param.DefaultValue != nil {
+		prop["default"] = param.DefaultValue
+	}
+
+	switch param.Type {
+	case "number":
+		prop["type"] = "number"
+	case "bool":
+		prop["type"] = "boolean"
+	case "enum":
+		prop["type"] = "string"
+		prop["enum"] = param.EnumValues
+	default: // "string", "file", "folder"
+		prop["type"] = "string"
+	}
+
+	return prop
+}
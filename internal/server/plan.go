@@ -0,0 +1,441 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// PlanResult describes what a PlannableTool's Apply step would do to
+// Formation, without doing it - the one-call analogue of Terraform's plan
+// step, returned instead of applying when a caller passes dry_run=true.
+type PlanResult struct {
+	// Operation is the tool name Apply would run, e.g. "upload_file".
+	Operation string `json:"operation"`
+
+	// Target is the path, analysis ID, or other identifier Apply would act on.
+	Target string `json:"target"`
+
+	// Parameters are Apply's arguments after defaulting, e.g. system_id
+	// resolved to "de" or max_wait resolved to 300.
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+
+	// Estimate is a human-readable cost/duration estimate where Plan has
+	// one, e.g. an upload's byte count or a launch's queue position.
+	Estimate string `json:"estimate,omitempty"`
+
+	// Errors lists pre-flight validation problems that would make Apply
+	// fail as-is. A non-empty Errors means Apply is expected to fail.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// dryRunProperty is the shared input schema property for dry_run, reused by
+// every PlannableTool.
+var dryRunProperty = map[string]interface{}{
+	"type":        "boolean",
+	"description": "Preview the operation instead of performing it: resolve parameters, run pre-flight validation, and report any estimated cost/duration, without mutating anything (default false)",
+	"default":     false,
+}
+
+// PlannableTool is a tool whose mutation can be previewed with Plan before
+// Apply actually performs it. dispatchPlannable wires the pair together
+// behind the tool's dry_run argument, mirroring the plan-then-apply split
+// from Terraform's remote backend - collapsed here into one MCP tool call
+// gated by a parameter instead of two separate commands.
+type PlannableTool interface {
+	Plan(ctx context.Context, request mcp.CallToolRequest) (*PlanResult, error)
+	Apply(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+}
+
+// dryRunRequested reads dry_run directly off the raw request arguments, so
+// dispatchPlannable can decide between Plan and Apply before a
+// PlannableTool has unmarshalled its own typed params.
+func dryRunRequested(request mcp.CallToolRequest) bool {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	dryRun, _ := args["dry_run"].(bool)
+	return dryRun
+}
+
+// dispatchPlannable wraps tool's Plan/Apply pair as a single ToolHandler,
+// routing to Plan when the caller passes dry_run=true.
+func dispatchPlannable(tool PlannableTool) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !dryRunRequested(request) {
+			return tool.Apply(ctx, request)
+		}
+
+		plan, err := tool.Plan(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		return renderPlanResult(plan)
+	}
+}
+
+// renderPlanResult renders plan as the markdown summary callers see by
+// default, with the full struct embedded as a JSON resource the same way
+// respondWithFormat embeds response_format="json" payloads.
+func renderPlanResult(plan *PlanResult) (*mcp.CallToolResult, error) {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("📋 **Dry Run: %s**\n\n", plan.Operation))
+	if plan.Target != "" {
+		builder.WriteString(fmt.Sprintf("- **Target**: `%s`\n", plan.Target))
+	}
+	if plan.Estimate != "" {
+		builder.WriteString(fmt.Sprintf("- **Estimate**: %s\n", plan.Estimate))
+	}
+	if len(plan.Parameters) > 0 {
+		builder.WriteString("- **Resolved Parameters**:\n")
+		for k, v := range plan.Parameters {
+			builder.WriteString(fmt.Sprintf("  - %s: `%v`\n", k, v))
+		}
+	}
+
+	if len(plan.Errors) > 0 {
+		builder.WriteString("\n⚠️  **Pre-flight Validation Errors**\n\n")
+		for _, e := range plan.Errors {
+			builder.WriteString(fmt.Sprintf("- %s\n", e))
+		}
+	} else {
+		builder.WriteString("\nNo changes have been made. Call again with dry_run=false (or omit it) to apply.")
+	}
+
+	payload, err := json.Marshal(plan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plan result: %w", err)
+	}
+
+	uri := fmt.Sprintf("formation://plan/%s", plan.Operation)
+	return mcp.NewToolResultResource(builder.String(), mcp.TextResourceContents{
+		URI:      uri,
+		MIMEType: "application/json",
+		Text:     string(payload),
+	}), nil
+}
+
+// missingRequiredParams reports the required parameters missing from
+// config, the same check FormationWorkflows.checkMissingParams applies
+// before a real launch - duplicated narrowly here since Plan only needs a
+// read-only preview and checkMissingParams is unexported on the concrete
+// workflows type.
+func missingRequiredParams(params *client.AppParameters, config map[string]interface{}) []string {
+	var missing []string
+	for _, group := range params.Groups {
+		for _, param := range group.Parameters {
+			if param.Required {
+				if _, ok := config[param.ID]; !ok {
+					missing = append(missing, param.Name)
+				}
+			}
+		}
+	}
+	return missing
+}
+
+// unsupportedResourceRequests reports which classes of resourceRequests
+// params's app does not declare support for, the same check
+// workflows.unsupportedResources applies before a real launch - duplicated
+// narrowly here for the same reason as missingRequiredParams.
+func unsupportedResourceRequests(params *client.AppParameters, resourceRequests *client.ResourceRequests) []string {
+	if resourceRequests == nil {
+		return nil
+	}
+
+	capable := make(map[string]bool, len(params.ResourceCapabilities))
+	for _, class := range params.ResourceCapabilities {
+		capable[class] = true
+	}
+
+	var unsupported []string
+	if resourceRequests.GPU != nil && resourceRequests.GPU.Count > 0 && !capable["gpu"] {
+		unsupported = append(unsupported, "gpu")
+	}
+	if resourceRequests.QAT > 0 && !capable["qat"] {
+		unsupported = append(unsupported, "qat")
+	}
+	if resourceRequests.Hugepages2Mi > 0 && !capable["hugepages_2mi"] {
+		unsupported = append(unsupported, "hugepages_2mi")
+	}
+	if resourceRequests.SRIOVNIC > 0 && !capable["sriov_nic"] {
+		unsupported = append(unsupported, "sriov_nic")
+	}
+	return unsupported
+}
+
+// uploadFilePlan adapts upload_file to PlannableTool.
+type uploadFilePlan struct{ s *FormationMCPServer }
+
+func (t uploadFilePlan) Apply(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return t.s.handleUploadFile(ctx, request)
+}
+
+func (t uploadFilePlan) Plan(ctx context.Context, request mcp.CallToolRequest) (*PlanResult, error) {
+	var params struct {
+		Path      string                 `json:"path"`
+		Content   string                 `json:"content"`
+		SourceURL string                 `json:"source_url"`
+		Metadata  map[string]interface{} `json:"metadata"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	var errs []string
+	if params.Path == "" {
+		errs = append(errs, "path is required")
+	}
+	if params.Content == "" && params.SourceURL == "" {
+		errs = append(errs, "one of content or source_url is required")
+	}
+
+	estimate := fmt.Sprintf("%d bytes", len(params.Content))
+	if params.SourceURL != "" {
+		estimate = fmt.Sprintf("streamed from %s", params.SourceURL)
+	}
+
+	return &PlanResult{
+		Operation: "upload_file",
+		Target:    params.Path,
+		Parameters: map[string]interface{}{
+			"content_bytes": len(params.Content),
+			"source_url":    params.SourceURL,
+			"metadata":      params.Metadata,
+		},
+		Estimate: estimate,
+		Errors:   errs,
+	}, nil
+}
+
+// createDirectoryPlan adapts create_directory to PlannableTool.
+type createDirectoryPlan struct{ s *FormationMCPServer }
+
+func (t createDirectoryPlan) Apply(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return t.s.handleCreateDirectory(ctx, request)
+}
+
+func (t createDirectoryPlan) Plan(ctx context.Context, request mcp.CallToolRequest) (*PlanResult, error) {
+	var params struct {
+		Path     string                 `json:"path"`
+		Metadata map[string]interface{} `json:"metadata"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	var errs []string
+	if params.Path == "" {
+		errs = append(errs, "path is required")
+	} else if _, err := t.s.client.BrowseData(ctx, params.Path, 0, 1, false); err == nil {
+		errs = append(errs, "path already exists")
+	}
+
+	return &PlanResult{
+		Operation:  "create_directory",
+		Target:     params.Path,
+		Parameters: map[string]interface{}{"metadata": params.Metadata},
+		Errors:     errs,
+	}, nil
+}
+
+// setMetadataPlan adapts set_metadata to PlannableTool.
+type setMetadataPlan struct{ s *FormationMCPServer }
+
+func (t setMetadataPlan) Apply(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return t.s.handleSetMetadata(ctx, request)
+}
+
+func (t setMetadataPlan) Plan(ctx context.Context, request mcp.CallToolRequest) (*PlanResult, error) {
+	var params struct {
+		Path       string                 `json:"path"`
+		Metadata   map[string]interface{} `json:"metadata"`
+		Attributes []string               `json:"attributes"`
+		Patch      []jsonPatchOp          `json:"patch"`
+		Replace    bool                   `json:"replace"`
+		Strategy   string                 `json:"strategy"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	strategy := metadataStrategy(params.Strategy)
+	if strategy == "" {
+		strategy = metadataStrategyMerge
+		if params.Replace {
+			strategy = metadataStrategyReplace
+		}
+	}
+
+	var errs []string
+	if params.Path == "" {
+		errs = append(errs, "path is required")
+	}
+	if strategy == metadataStrategyRemove && len(params.Attributes) == 0 {
+		errs = append(errs, "attributes is required when strategy is \"remove\"")
+	}
+	if strategy == metadataStrategyJSONPatch && len(params.Patch) == 0 {
+		errs = append(errs, "patch is required when strategy is \"json-patch\"")
+	}
+
+	parameters := map[string]interface{}{
+		"strategy":   strategy,
+		"metadata":   params.Metadata,
+		"attributes": params.Attributes,
+		"patch":      params.Patch,
+	}
+	if current, err := t.s.client.SnapshotMetadata(ctx, params.Path); err == nil {
+		parameters["current_metadata"] = current
+
+		var after map[string]interface{}
+		switch strategy {
+		case metadataStrategyMerge:
+			after = overlayMetadata(current, params.Metadata)
+		case metadataStrategyReplace:
+			after = cloneMetadataMap(params.Metadata)
+		case metadataStrategyRemove:
+			after = cloneMetadataMap(current)
+			for _, attr := range params.Attributes {
+				delete(after, attr)
+			}
+		case metadataStrategyStrategic:
+			after = mergeStrategic(current, params.Metadata)
+		case metadataStrategyJSONPatch:
+			if patched, err := applyJSONPatch(current, params.Patch); err == nil {
+				after = patched
+			}
+		}
+		if after != nil {
+			parameters["diff"] = diffMetadataAttrs(current, after)
+		}
+	}
+
+	return &PlanResult{
+		Operation:  "set_metadata",
+		Target:     params.Path,
+		Parameters: parameters,
+		Errors:     errs,
+	}, nil
+}
+
+// stopAnalysisPlan adapts stop_analysis to PlannableTool.
+type stopAnalysisPlan struct{ s *FormationMCPServer }
+
+func (t stopAnalysisPlan) Apply(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return t.s.handleStopAnalysis(ctx, request)
+}
+
+func (t stopAnalysisPlan) Plan(ctx context.Context, request mcp.CallToolRequest) (*PlanResult, error) {
+	var params struct {
+		AnalysisID         string `json:"analysis_id"`
+		SaveOutputs        bool   `json:"save_outputs"`
+		SnapshotBeforeExit bool   `json:"snapshot_before_exit"`
+	}
+	params.SaveOutputs = true // default
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	var errs []string
+	parameters := map[string]interface{}{"save_outputs": params.SaveOutputs, "snapshot_before_exit": params.SnapshotBeforeExit}
+
+	if params.AnalysisID == "" {
+		errs = append(errs, "analysis_id is required")
+	} else if status, err := t.s.client.GetAnalysisStatus(ctx, params.AnalysisID); err != nil {
+		errs = append(errs, fmt.Sprintf("analysis not found: %v", err))
+	} else {
+		parameters["current_status"] = status.Status
+	}
+
+	return &PlanResult{
+		Operation:  "stop_analysis",
+		Target:     params.AnalysisID,
+		Parameters: parameters,
+		Errors:     errs,
+	}, nil
+}
+
+// launchAppAndWaitPlan adapts launch_app_and_wait to PlannableTool.
+type launchAppAndWaitPlan struct{ s *FormationMCPServer }
+
+func (t launchAppAndWaitPlan) Apply(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return t.s.handleLaunchAppAndWait(ctx, request)
+}
+
+func (t launchAppAndWaitPlan) Plan(ctx context.Context, request mcp.CallToolRequest) (*PlanResult, error) {
+	var params struct {
+		AppID            string                   `json:"app_id"`
+		SystemID         string                   `json:"system_id"`
+		Name             string                   `json:"name"`
+		Config           map[string]interface{}   `json:"config"`
+		MaxWait          int                      `json:"max_wait"`
+		ResourceRequests *client.ResourceRequests `json:"resource_requests"`
+		MaxCost          float64                  `json:"max_cost"`
+		ConfirmOverCost  bool                     `json:"confirm_over_cost"`
+	}
+	params.SystemID = "de" // default
+	params.MaxWait = 300   // default
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+	if params.Config == nil {
+		params.Config = make(map[string]interface{})
+	}
+
+	var errs []string
+	if params.AppID == "" {
+		errs = append(errs, "app_id is required")
+		return &PlanResult{Operation: "launch_app_and_wait", Errors: errs}, nil
+	}
+
+	appParams, err := t.s.client.GetAppParameters(ctx, params.SystemID, params.AppID)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("failed to get app parameters: %v", err))
+		return &PlanResult{Operation: "launch_app_and_wait", Target: params.AppID, Errors: errs}, nil
+	}
+
+	for _, missing := range missingRequiredParams(appParams, params.Config) {
+		errs = append(errs, fmt.Sprintf("missing required parameter: %s", missing))
+	}
+	for _, class := range unsupportedResourceRequests(appParams, params.ResourceRequests) {
+		errs = append(errs, fmt.Sprintf("app does not support requested resource: %s", class))
+	}
+
+	estimate := ""
+	if running, err := t.s.workflows.GetRunningAnalyses(ctx); err == nil {
+		estimate = fmt.Sprintf("%d other analyses currently running", len(running))
+	}
+
+	planParameters := map[string]interface{}{
+		"system_id":         params.SystemID,
+		"name":              params.Name,
+		"max_wait":          params.MaxWait,
+		"job_type":          appParams.OverallJobType,
+		"resource_requests": params.ResourceRequests,
+	}
+
+	if params.MaxCost > 0 {
+		if cost, err := t.s.workflows.CostEstimate(ctx, params.AppID, params.SystemID, params.Config); err == nil {
+			planParameters["estimated_cost"] = cost.EstimatedCost
+			if cost.EstimatedCost > params.MaxCost && !params.ConfirmOverCost {
+				errs = append(errs, fmt.Sprintf("estimated cost %.2f exceeds max_cost %.2f", cost.EstimatedCost, params.MaxCost))
+			}
+		}
+	}
+
+	return &PlanResult{
+		Operation:  "launch_app_and_wait",
+		Target:     params.AppID,
+		Parameters: planParameters,
+		Estimate:   estimate,
+		Errors:     errs,
+	}, nil
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerPrompts wires up reusable prompt templates for common multi-tool
+// workflows, so a client can offer them as a one-click starting point
+// instead of a user (or an agent) hand-assembling the right sequence of
+// tool calls from scratch.
+func (s *FormationMCPServer) registerPrompts() {
+	s.server.AddPrompt(
+		mcp.NewPrompt("launch_vice_app",
+			mcp.WithPromptDescription("Launch a VICE app with parameters, checking its resource estimate before submitting"),
+			mcp.WithArgument("app_id", mcp.ArgumentDescription("The application ID to launch"), mcp.RequiredArgument()),
+			mcp.WithArgument("system_id", mcp.ArgumentDescription("The system ID (default: de)")),
+		),
+		s.handleLaunchVICEAppPrompt,
+	)
+	s.server.AddPrompt(
+		mcp.NewPrompt("diagnose_failed_analysis",
+			mcp.WithPromptDescription("Diagnose a failed or stuck analysis by checking its status and tailing its logs"),
+			mcp.WithArgument("analysis_id", mcp.ArgumentDescription("The analysis ID to diagnose"), mcp.RequiredArgument()),
+		),
+		s.handleDiagnoseFailedAnalysisPrompt,
+	)
+	s.server.AddPrompt(
+		mcp.NewPrompt("organize_data_folder",
+			mcp.WithPromptDescription("Organize an iRODS folder by reviewing its contents and applying metadata"),
+			mcp.WithArgument("path", mcp.ArgumentDescription("The iRODS path to organize"), mcp.RequiredArgument()),
+		),
+		s.handleOrganizeDataFolderPrompt,
+	)
+}
+
+// handleLaunchVICEAppPrompt pre-fills a starting message for launching
+// app_id, pointed at the tool sequence (parameters -> cost estimate ->
+// launch) rather than jumping straight to launch_app_and_wait.
+func (s *FormationMCPServer) handleLaunchVICEAppPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	appID := request.Params.Arguments["app_id"]
+	systemID := request.Params.Arguments["system_id"]
+	if systemID == "" {
+		systemID = "de"
+	}
+
+	text := fmt.Sprintf(
+		"Launch app %s on system %s. First call get_app_parameters (or describe_app_parameters) to see what "+
+			"config it needs, then estimate_cost with a draft config, and once the estimate looks reasonable "+
+			"call launch_app_and_wait (or launch_app_and_stream for progress notifications).",
+		appID, systemID,
+	)
+
+	return mcp.NewGetPromptResult(
+		"Launch a VICE app with parameters",
+		[]mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+		},
+	), nil
+}
+
+// handleDiagnoseFailedAnalysisPrompt pre-fills a starting message for
+// diagnosing analysis_id, pointed at get_analysis_status, tail_analysis_logs,
+// and watch_analysis rather than a busy-loop of status polling.
+func (s *FormationMCPServer) handleDiagnoseFailedAnalysisPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	analysisID := request.Params.Arguments["analysis_id"]
+
+	text := fmt.Sprintf(
+		"Diagnose analysis %s. Call get_analysis_status first to see its current status, then tail_analysis_logs "+
+			"to read recent stderr/stdout for clues about why it failed or is stuck. If it's still running and you "+
+			"need to wait for a status change or URLReady, use watch_analysis instead of polling.",
+		analysisID,
+	)
+
+	return mcp.NewGetPromptResult(
+		"Diagnose a failed analysis",
+		[]mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+		},
+	), nil
+}
+
+// handleOrganizeDataFolderPrompt pre-fills a starting message for
+// organizing path, pointed at browse_data and apply_data_plan rather than
+// one-off set_metadata/create_directory calls.
+func (s *FormationMCPServer) handleOrganizeDataFolderPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	path := request.Params.Arguments["path"]
+
+	text := fmt.Sprintf(
+		"Organize the iRODS folder %s. Call browse_data to see what's there, then use apply_data_plan to apply "+
+			"an ordered batch of create_directory/set_metadata/delete operations - dry_run it first to confirm "+
+			"which paths a glob or recursive delete would resolve to.",
+		path,
+	)
+
+	return mcp.NewGetPromptResult(
+		"Organize an iRODS folder with metadata",
+		[]mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+		},
+	), nil
+}
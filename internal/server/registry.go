@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ToolHandler is the function signature the MCP server invokes when a
+// registered tool is called.
+type ToolHandler func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// ToolFactory builds a tool's definition and handler for a given server
+// instance. Factories are methods closing over *FormationMCPServer (e.g.
+// `func(s *FormationMCPServer) (mcp.Tool, ToolHandler) { return
+// s.listAppsTool(), s.handleListApps }`) rather than package-level functions,
+// since handlers need access to the server's client and workflows.
+type ToolFactory func(*FormationMCPServer) (mcp.Tool, ToolHandler)
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]ToolFactory{}
+	initOnce   sync.Once
+)
+
+// Register adds factory to the tool registry under name. It panics if name
+// is already registered, the same way Terraform's backend/init package
+// panics on a duplicate backend registration - a collision here is a
+// programming error, not a runtime condition callers should handle.
+func Register(name string, factory ToolFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("server: tool %q already registered", name))
+	}
+	registry[name] = factory
+}
+
+// Remove unregisters name, if present. It exists mainly so tests can
+// register a throwaway tool and clean up afterwards.
+func Remove(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, name)
+}
+
+// Lookup returns the factory registered under name, if any.
+func Lookup(name string) (ToolFactory, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+// List returns the names of all registered tools, sorted for deterministic
+// iteration order.
+func List() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Init seeds the registry with Formation's built-in tools. It is safe to
+// call more than once - only the first call has any effect - so that
+// repeatedly constructing a FormationMCPServer (as the tests do) doesn't
+// trip Register's duplicate-name panic. Downstream consumers that compile
+// their own tools into a formation-mcp-based binary can call Register for
+// additional tools before or after Init runs.
+func Init() {
+	initOnce.Do(func() {
+		// App management tools
+		Register("list_apps", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.listAppsTool(), s.handleListApps
+		})
+		Register("get_app_parameters", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.getAppParametersTool(), s.handleGetAppParameters
+		})
+		Register("describe_app_parameters", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.describeAppParametersTool(), s.handleDescribeAppParameters
+		})
+		Register("list_app_resource_capabilities", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.listAppResourceCapabilitiesTool(), s.handleListAppResourceCapabilities
+		})
+		Register("estimate_cost", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.estimateCostTool(), s.handleEstimateCost
+		})
+		Register("launch_app_and_wait", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.launchAppAndWaitTool(), dispatchPlannable(launchAppAndWaitPlan{s: s})
+		})
+		Register("launch_app_and_stream", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.launchAppAndStreamTool(), s.handleLaunchAppAndStream
+		})
+		Register("get_analysis_status", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.getAnalysisStatusTool(), s.handleGetAnalysisStatus
+		})
+		Register("list_running_analyses", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.listRunningAnalysesTool(), s.handleListRunningAnalyses
+		})
+		Register("stop_analysis", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.stopAnalysisTool(), dispatchPlannable(stopAnalysisPlan{s: s})
+		})
+		Register("open_in_browser", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.openInBrowserTool(), s.handleOpenInBrowser
+		})
+		Register("snapshot_analysis", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.snapshotAnalysisTool(), s.handleSnapshotAnalysis
+		})
+		Register("resume_from_snapshot", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.resumeFromSnapshotTool(), s.handleResumeFromSnapshot
+		})
+		Register("list_snapshots", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.listSnapshotsTool(), s.handleListSnapshots
+		})
+		Register("launch_batch", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.launchBatchTool(), s.handleLaunchBatch
+		})
+		Register("get_batch_status", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.getBatchStatusTool(), s.handleGetBatchStatus
+		})
+		Register("list_tool_history", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.listToolHistoryTool(), s.handleListToolHistory
+		})
+		Register("replay_tool_call", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.replayToolCallTool(), s.handleReplayToolCall
+		})
+		Register("set_log_level", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.setLogLevelTool(), s.handleSetLogLevel
+		})
+		Register("get_log_level", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.getLogLevelTool(), s.handleGetLogLevel
+		})
+
+		// Data management tools
+		Register("browse_data", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.browseDataTool(), s.handleBrowseData
+		})
+		Register("create_directory", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.createDirectoryTool(), dispatchPlannable(createDirectoryPlan{s: s})
+		})
+		Register("upload_file", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.uploadFileTool(), dispatchPlannable(uploadFilePlan{s: s})
+		})
+		Register("upload_file_chunk", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.uploadFileChunkTool(), s.handleUploadFileChunk
+		})
+		Register("upload_file_from_path", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.uploadFileFromPathTool(), s.handleUploadFileFromPath
+		})
+		Register("read_file_range", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.readFileRangeTool(), s.handleReadFileRange
+		})
+		Register("set_metadata", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.setMetadataTool(), dispatchPlannable(setMetadataPlan{s: s})
+		})
+		Register("delete_data", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.deleteDataTool(), s.handleDeleteData
+		})
+		Register("search_metadata", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.searchMetadataTool(), s.handleSearchMetadata
+		})
+		Register("delete_by_selector", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.deleteBySelectorTool(), s.handleDeleteBySelector
+		})
+		Register("list_deleted", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.listDeletedTool(), s.handleListDeleted
+		})
+		Register("restore_data", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.restoreDataTool(), s.handleRestoreData
+		})
+		Register("purge_deleted", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.purgeDeletedTool(), s.handlePurgeDeleted
+		})
+		Register("get_delete_job", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.getDeleteJobTool(), s.handleGetDeleteJob
+		})
+		Register("list_delete_jobs", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.listDeleteJobsTool(), s.handleListDeleteJobs
+		})
+		Register("cancel_delete_job", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.cancelDeleteJobTool(), s.handleCancelDeleteJob
+		})
+
+		// Analysis alerting tools
+		Register("create_analysis_alert", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.createAnalysisAlertTool(), s.handleCreateAnalysisAlert
+		})
+		Register("list_analysis_alerts", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.listAnalysisAlertsTool(), s.handleListAnalysisAlerts
+		})
+		Register("delete_analysis_alert", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.deleteAnalysisAlertTool(), s.handleDeleteAnalysisAlert
+		})
+		Register("watch_analysis", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.watchAnalysisTool(), s.handleWatchAnalysis
+		})
+		Register("tail_analysis_logs", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.tailAnalysisLogsTool(), s.handleTailAnalysisLogs
+		})
+
+		// Batch data management tools
+		Register("apply_data_plan", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.applyDataPlanTool(), s.handleApplyDataPlan
+		})
+		Register("bulk_create_directories", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.bulkCreateDirectoriesTool(), s.handleBulkCreateDirectories
+		})
+		Register("bulk_upload_files", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.bulkUploadFilesTool(), s.handleBulkUploadFiles
+		})
+		Register("bulk_set_metadata", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.bulkSetMetadataTool(), s.handleBulkSetMetadata
+		})
+		Register("bulk_delete_data", func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+			return s.bulkDeleteDataTool(), s.handleBulkDeleteData
+		})
+	})
+}
+
+// deprecatedToolShim wraps an already-registered tool so it stays callable
+// under a previous name, analogous to Terraform's deprecatedBackendShim.
+// Renamed tools should register the new name normally and then call
+// RegisterDeprecated to keep the old name alive with a warning instead of
+// breaking existing callers outright.
+type deprecatedToolShim struct {
+	oldName string
+	newName string
+}
+
+func (d deprecatedToolShim) factory(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+	factory, ok := Lookup(d.newName)
+	if !ok {
+		panic(fmt.Sprintf("server: deprecated tool shim %q refers to unregistered tool %q", d.oldName, d.newName))
+	}
+
+	tool, handler := factory(s)
+	tool.Name = d.oldName
+	tool.Description = fmt.Sprintf("Deprecated: use %s instead. %s", d.newName, tool.Description)
+
+	wrapped := ToolHandler(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		slog.Warn("deprecated tool invoked, switch to its replacement", "tool", d.oldName, "replacement", d.newName)
+		return handler(ctx, request)
+	})
+	return tool, wrapped
+}
+
+// RegisterDeprecated registers oldName as a deprecated alias of the
+// already-registered newName. Calls under oldName log a warning but
+// otherwise behave exactly like calling newName.
+func RegisterDeprecated(oldName, newName string) {
+	Register(oldName, deprecatedToolShim{oldName: oldName, newName: newName}.factory)
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// responseFormat is the per-request "response_format" argument honored by
+// handlers that return a typed result: "text" (markdown, the default),
+// "json" (the raw struct embedded as a JSON resource), or "resource" (the
+// struct is registered under a stable URI for later resources/read).
+type responseFormat string
+
+const (
+	formatText     responseFormat = "text"
+	formatJSON     responseFormat = "json"
+	formatResource responseFormat = "resource"
+)
+
+// responseFormatProperty is the shared input schema property for
+// response_format, reused by every tool that supports it.
+var responseFormatProperty = map[string]interface{}{
+	"type":        "string",
+	"description": "Output format: \"text\" (markdown, default), \"json\" (embed the raw result as JSON), or \"resource\" (register the result for resources/read and return its URI)",
+	"enum":        []string{"text", "json", "resource"},
+	"default":     "text",
+}
+
+// resourceEntry is one object a handler has registered under a URI via
+// response_format="resource".
+type resourceEntry struct {
+	mimeType string
+	data     interface{}
+}
+
+// resourceStore holds the most recent response_format="resource" payload
+// registered under each URI. Like uploadChunkSessionStore, it's in-memory
+// and doesn't survive a process restart.
+type resourceStore struct {
+	mu      sync.Mutex
+	entries map[string]resourceEntry
+}
+
+func newResourceStore() *resourceStore {
+	return &resourceStore{entries: make(map[string]resourceEntry)}
+}
+
+func (s *resourceStore) put(uri, mimeType string, data interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[uri] = resourceEntry{mimeType: mimeType, data: data}
+}
+
+func (s *resourceStore) get(uri string) (resourceEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[uri]
+	return entry, ok
+}
+
+// registerResources wires up resource templates for every URI scheme a
+// response_format="resource" handler can register an object under, so a
+// client can re-fetch the last result of a tool call via resources/read
+// instead of calling the tool again.
+func (s *FormationMCPServer) registerResources() {
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate("formation://analyses/{id}", "analysis",
+			mcp.WithTemplateDescription("The status of an analysis, as last reported by get_analysis_status"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readRegisteredResource,
+	)
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate("formation://analyses", "analyses-list",
+			mcp.WithTemplateDescription("The analyses matching a status filter, as last reported by list_running_analyses"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readRegisteredResource,
+	)
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate("formation://apps/{system}/{id}", "app-parameters",
+			mcp.WithTemplateDescription("An application's parameters, resource capabilities, or cost estimate, whichever get_app_parameters, list_app_resource_capabilities, or estimate_cost last registered"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readRegisteredResource,
+	)
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate("formation://apps", "apps-list",
+			mcp.WithTemplateDescription("The apps matching a filter, as last reported by list_apps"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readRegisteredResource,
+	)
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate("formation://analysis-alerts", "analysis-alerts-list",
+			mcp.WithTemplateDescription("The registered analysis alerts, as last reported by list_analysis_alerts"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readRegisteredResource,
+	)
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate("formation://snapshots", "snapshots-list",
+			mcp.WithTemplateDescription("The analysis snapshots, as last reported by list_snapshots"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readRegisteredResource,
+	)
+	s.server.AddResourceTemplate(
+		mcp.NewResourceTemplate("cyverse://data{path}", "data",
+			mcp.WithTemplateDescription("A directory listing or file's contents, as last reported by browse_data"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readRegisteredResource,
+	)
+}
+
+// readRegisteredResource serves resources/read for every template
+// registered above: they all resolve the same way, by looking up the
+// literal requested URI in the store a handler populated on its last call.
+func (s *FormationMCPServer) readRegisteredResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	entry, ok := s.resources.get(request.Params.URI)
+	if !ok {
+		return nil, fmt.Errorf("resource %q isn't registered - call the tool that produces it again first", request.Params.URI)
+	}
+
+	payload, err := json.Marshal(entry.data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: entry.mimeType,
+			Text:     string(payload),
+		},
+	}, nil
+}
+
+// analysisResourceURI is the stable resource URI for one analysis's status.
+func analysisResourceURI(analysisID string) string {
+	return fmt.Sprintf("formation://analyses/%s", analysisID)
+}
+
+// appResourceURI is the stable resource URI for one app, shared by its
+// parameters, resource capabilities, and cost estimate.
+func appResourceURI(systemID, appID string) string {
+	return fmt.Sprintf("formation://apps/%s/%s", systemID, appID)
+}
+
+// dataResourceURI is the stable resource URI for a browse_data result.
+func dataResourceURI(path string) string {
+	return fmt.Sprintf("cyverse://data%s", path)
+}
+
+// respondWithFormat renders data according to format, falling back to the
+// markdown text renderText produces for the default ("text") format:
+//   - "json" embeds data as an application/json resource alongside the
+//     markdown text.
+//   - "resource" registers data under uri for resources/read and points
+//     the caller at it instead of inlining the full result.
+func (s *FormationMCPServer) respondWithFormat(format responseFormat, uri string, data interface{}, renderText func() string) (*mcp.CallToolResult, error) {
+	switch format {
+	case "", formatText:
+		return mcp.NewToolResultText(renderText()), nil
+	case formatJSON:
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal response: %w", err)
+		}
+		return mcp.NewToolResultResource(renderText(), mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(payload),
+		}), nil
+	case formatResource:
+		s.resources.put(uri, "application/json", data)
+		return mcp.NewToolResultText(fmt.Sprintf("%s\n\nResource URI: `%s` (fetch the full result with resources/read)", renderText(), uri)), nil
+	default:
+		return nil, fmt.Errorf("unknown response_format %q: want \"text\", \"json\", or \"resource\"", format)
+	}
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (s *FormationMCPServer) searchMetadataTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "search_metadata",
+		Description: "Find paths in iRODS by the AVU metadata set on them, instead of walking directories with browse_data - e.g. every path with experiment=RNA-seq under a home directory.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"predicates": map[string]interface{}{
+					"type":        "array",
+					"description": "AVU conditions a matching path's metadata must all satisfy (a logical AND)",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"attribute": map[string]interface{}{
+								"type":        "string",
+								"description": "The metadata attribute name",
+							},
+							"operator": map[string]interface{}{
+								"type":        "string",
+								"description": "How value is compared against the attribute's value",
+								"enum":        []string{"=", "like", "<", ">", "in"},
+								"default":     "=",
+							},
+							"value": map[string]interface{}{
+								"description": "The value to compare against. An array when operator is \"in\".",
+							},
+						},
+						"required": []string{"attribute", "value"},
+					},
+				},
+				"path_prefix": map[string]interface{}{
+					"type":        "string",
+					"description": "Restrict results to paths under this prefix",
+				},
+				"type": map[string]interface{}{
+					"type":        "string",
+					"description": "Restrict results to collections or data objects",
+					"enum":        []string{"collection", "data_object"},
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum number of matches to return (default: no limit)",
+				},
+				"offset": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of matches to skip, for pagination (default 0)",
+				},
+			},
+			Required: []string{"predicates"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleSearchMetadata(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Predicates []client.MetadataPredicate `json:"predicates"`
+		PathPrefix string                     `json:"path_prefix"`
+		Type       string                     `json:"type"`
+		Limit      int                        `json:"limit"`
+		Offset     int                        `json:"offset"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	if len(params.Predicates) == 0 {
+		return nil, fmt.Errorf("predicates is required")
+	}
+
+	result, err := s.client.SearchMetadata(ctx, client.MetadataQuery{
+		Predicates: params.Predicates,
+		PathPrefix: params.PathPrefix,
+		Type:       params.Type,
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(formatMetadataSearchResult(result)), nil
+}
+
+// formatMetadataSearchResult renders a MetadataSearchResult as a markdown
+// table of path/type/metadata, one row per match.
+func formatMetadataSearchResult(result *client.MetadataSearchResult) string {
+	if len(result.Matches) == 0 {
+		return "No paths matched."
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d of %d matching paths:\n\n", len(result.Matches), result.Total)
+	builder.WriteString("| path | type | metadata |\n")
+	builder.WriteString("| --- | --- | --- |\n")
+	for _, match := range result.Matches {
+		fmt.Fprintf(&builder, "| %s | %s | %v |\n", match.Path, match.Type, match.Metadata)
+	}
+	return builder.String()
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// selectorClause is one condition in a delete_by_selector expression:
+// "attr=value" (equality), "attr!=value" (negated equality), "attr in
+// (a, b)" (set membership), a bare "attr" (existence), or "!attr" (negated
+// existence) - a small subset of kubectl's `-l` label-selector grammar.
+type selectorClause struct {
+	Attribute string
+	Op        string // "=", "!=", "in", "exists", "!exists"
+	Values    []string
+}
+
+// matches reports whether metadata satisfies c.
+func (c selectorClause) matches(metadata map[string]interface{}) bool {
+	raw, present := metadata[c.Attribute]
+	switch c.Op {
+	case "exists":
+		return present
+	case "!exists":
+		return !present
+	}
+	if !present {
+		return false
+	}
+
+	value := fmt.Sprintf("%v", raw)
+	switch c.Op {
+	case "=":
+		return value == c.Values[0]
+	case "!=":
+		return value != c.Values[0]
+	case "in":
+		for _, v := range c.Values {
+			if value == v {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// splitSelectorClauses splits selector on top-level commas, treating a
+// comma inside parentheses (an "in (a, b)" value list) as part of the
+// current clause rather than a clause separator.
+func splitSelectorClauses(selector string) []string {
+	var clauses []string
+	depth := 0
+	start := 0
+
+	for i, r := range selector {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				clauses = append(clauses, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	clauses = append(clauses, selector[start:])
+	return clauses
+}
+
+// parseSelector parses a comma-separated selector expression into its
+// clauses. Negation ("!=" and "!attr") has no equivalent in
+// client.MetadataQuery's AND-only predicate list, so callers apply those
+// clauses themselves after a broader SearchMetadata call.
+func parseSelector(selector string) ([]selectorClause, error) {
+	var clauses []selectorClause
+
+	for _, raw := range splitSelectorClauses(selector) {
+		term := strings.TrimSpace(raw)
+		if term == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(term, "!"):
+			clauses = append(clauses, selectorClause{Attribute: strings.TrimSpace(term[1:]), Op: "!exists"})
+
+		case strings.Contains(term, "!="):
+			parts := strings.SplitN(term, "!=", 2)
+			clauses = append(clauses, selectorClause{
+				Attribute: strings.TrimSpace(parts[0]),
+				Op:        "!=",
+				Values:    []string{strings.TrimSpace(parts[1])},
+			})
+
+		case strings.Contains(term, " in "):
+			idx := strings.Index(term, " in ")
+			attribute := strings.TrimSpace(term[:idx])
+			rest := strings.TrimSpace(term[idx+len(" in "):])
+			if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+				return nil, fmt.Errorf("selector clause %q: expected \"in (value, ...)\"", term)
+			}
+			rest = strings.TrimSuffix(strings.TrimPrefix(rest, "("), ")")
+
+			var values []string
+			for _, v := range strings.Split(rest, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+			if len(values) == 0 {
+				return nil, fmt.Errorf("selector clause %q: \"in\" needs at least one value", term)
+			}
+			clauses = append(clauses, selectorClause{Attribute: attribute, Op: "in", Values: values})
+
+		case strings.Contains(term, "="):
+			parts := strings.SplitN(term, "=", 2)
+			clauses = append(clauses, selectorClause{
+				Attribute: strings.TrimSpace(parts[0]),
+				Op:        "=",
+				Values:    []string{strings.TrimSpace(parts[1])},
+			})
+
+		default:
+			clauses = append(clauses, selectorClause{Attribute: term, Op: "exists"})
+		}
+	}
+
+	if len(clauses) == 0 {
+		return nil, fmt.Errorf("selector must contain at least one clause")
+	}
+	return clauses, nil
+}
+
+// selectorConfirmToken derives a short token from selector and the exact
+// candidate set it matched, so a confirm_token only authorizes execution
+// against the same candidates the caller saw in the dry run - any change
+// to the matching set (a file added or removed between calls) produces a
+// different token and falls back to another dry run.
+func selectorConfirmToken(selector string, candidates []client.MetadataSearchMatch) string {
+	paths := make([]string, len(candidates))
+	for i, c := range candidates {
+		paths[i] = c.Path
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	h.Write([]byte(selector))
+	for _, p := range paths {
+		h.Write([]byte{0})
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// formatSelectorDryRun renders the candidate list delete_by_selector
+// returns before a caller has supplied a matching confirm_token.
+func formatSelectorDryRun(selector string, candidates []client.MetadataSearchMatch, token string) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## Delete By Selector: %s\n\n", selector))
+	builder.WriteString(fmt.Sprintf("%d matching path(s). No changes have been made.\n\n", len(candidates)))
+	for _, c := range candidates {
+		builder.WriteString(fmt.Sprintf("- %s (%s)\n", c.Path, c.Type))
+	}
+	builder.WriteString(fmt.Sprintf("\nCall again with confirm_token=%q against this same candidate set to delete.\n", token))
+	return builder.String()
+}
+
+func (s *FormationMCPServer) deleteBySelectorTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "delete_by_selector",
+		Description: "Delete data objects/collections matching an iRODS metadata selector, analogous to `kubectl delete -l`. Always returns the candidate list and a confirm_token on the first call; pass that confirm_token back, unchanged, to actually delete the same candidates.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"selector": map[string]interface{}{
+					"type":        "string",
+					"description": "A comma-separated metadata selector, e.g. \"project=foo,stage in (draft,archived),!protected\". Supports equality (attr=value), negation (attr!=value, !attr), set membership (attr in (a,b)), and existence (attr)",
+				},
+				"path_prefix": map[string]interface{}{
+					"type":        "string",
+					"description": "Restrict candidates to paths under this prefix",
+				},
+				"type": map[string]interface{}{
+					"type":        "string",
+					"description": "Restrict candidates to \"collection\" or \"data_object\"",
+				},
+				"recurse": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Whether to recursively delete matched collections (default false)",
+					"default":     false,
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum number of candidates to consider in one call (default 100), as a safety cap against accidentally matching a huge tree",
+				},
+				"confirm_token": map[string]interface{}{
+					"type":        "string",
+					"description": "The confirm_token returned by a prior call against this exact selector and candidate set. Omit it (or pass a stale one) to get a dry-run candidate list instead of deleting anything",
+				},
+			},
+			Required: []string{"selector"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleDeleteBySelector(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		Selector     string `json:"selector"`
+		PathPrefix   string `json:"path_prefix"`
+		Type         string `json:"type"`
+		Recurse      bool   `json:"recurse"`
+		Limit        int    `json:"limit"`
+		ConfirmToken string `json:"confirm_token"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+	if params.Limit <= 0 {
+		params.Limit = 100
+	}
+
+	clauses, err := parseSelector(params.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+
+	query := client.MetadataQuery{PathPrefix: params.PathPrefix, Type: params.Type, Limit: params.Limit}
+	for _, c := range clauses {
+		switch c.Op {
+		case "=":
+			query.Predicates = append(query.Predicates, client.MetadataPredicate{Attribute: c.Attribute, Operator: "=", Value: c.Values[0]})
+		case "in":
+			values := make([]interface{}, len(c.Values))
+			for i, v := range c.Values {
+				values[i] = v
+			}
+			query.Predicates = append(query.Predicates, client.MetadataPredicate{Attribute: c.Attribute, Operator: "in", Value: values})
+		}
+		// "!=", "exists", and "!exists" have no AND-only predicate
+		// equivalent, so they're applied below instead, over whatever the
+		// positive predicates (if any) already narrowed the result to.
+	}
+
+	searchResult, err := s.client.SearchMetadata(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []client.MetadataSearchMatch
+	for _, match := range searchResult.Matches {
+		matched := true
+		for _, c := range clauses {
+			if !c.matches(match.Metadata) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			candidates = append(candidates, match)
+		}
+	}
+
+	token := selectorConfirmToken(params.Selector, candidates)
+	if params.ConfirmToken == "" || params.ConfirmToken != token {
+		return mcp.NewToolResultText(formatSelectorDryRun(params.Selector, candidates, token)), nil
+	}
+
+	slog.Info("deleting by selector", "selector", params.Selector, "count", len(candidates))
+
+	results := make([]bulkDeleteItemResult, len(candidates))
+	for i, match := range candidates {
+		results[i] = bulkDeleteItemResult{Path: match.Path, WouldDeleteCount: countForDelete(ctx, s.client, match.Path, params.Recurse)}
+
+		if err := s.client.DeleteData(ctx, match.Path, params.Recurse, false); err != nil {
+			results[i].Status = "error"
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Status = "deleted"
+	}
+
+	return mcp.NewToolResultText(formatBulkDeleteResults(bulkBestEffort, bulkDeleteForeground, results)), nil
+}
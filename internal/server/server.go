@@ -3,13 +3,17 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/cyverse-de/formation-mcp/internal/audit"
 	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/cyverse-de/formation-mcp/internal/logging"
 	"github.com/cyverse-de/formation-mcp/internal/workflows"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -18,26 +22,74 @@ import (
 // FormationMCPServer wraps the MCP server with Formation-specific functionality.
 type FormationMCPServer struct {
 	server    *server.MCPServer
-	workflows *workflows.FormationWorkflows
-	client    *client.FormationClient
+	workflows workflows.Workflows
+	client    client.FormationAPIClient
+	resources *resourceStore
+
+	// auditLog records every tool call for list_tool_history and
+	// replay_tool_call. It's always present - in-memory only until
+	// AuditLog().AddSink is called - so both tools work even on a server
+	// with no external audit sink configured.
+	auditLog *audit.Log
+
+	// currentUser is recorded on every audit.Record as the User field. It
+	// has no bearing on request behavior, only on attribution in the
+	// audit trail, so it's fine for it to start empty and be set later via
+	// SetCurrentUser once configuration is available.
+	currentUser string
+
+	// logBackend is the logging.Logger implementation withRequestContext
+	// builds its per-request logger from (see client.WithLogger). Empty
+	// defaults to logging.LogBackendSlog, same as config.Config.
+	logBackend logging.LogBackend
+
+	// baseLevel and subsystemLevels back the set_log_level/get_log_level
+	// tools: base is the process's default log level, subsystemLevels
+	// holds per-package overrides (e.g. "client"=debug). Both are nil
+	// until SetLogLevelControls is called (normally once at startup, from
+	// the same *slog.LevelVar/*logging.SubsystemLevels main wires into its
+	// logging.SubsystemLevelHandler) - the two tools report an error
+	// rather than panicking if that hasn't happened.
+	baseLevel       *slog.LevelVar
+	subsystemLevels *logging.SubsystemLevels
+
+	// deleteJobs tracks delete_data calls scheduled with a positive
+	// grace_period_seconds. It's always present - in-memory only until
+	// LoadDeleteJobs points it at a state file - so get/list/cancel_delete_job
+	// work even on a server with no configured job store.
+	deleteJobs *deleteJobManager
+
+	// defaultDeleteGracePeriodSeconds is the grace_period_seconds
+	// delete_data assumes when a call doesn't specify one. -1 preserves
+	// today's synchronous trash/purge behavior; it's normally set from
+	// config.DeleteGracePeriodSeconds at startup.
+	defaultDeleteGracePeriodSeconds int
 }
 
 // NewFormationMCPServer creates a new Formation MCP server.
-func NewFormationMCPServer(workflows *workflows.FormationWorkflows, c *client.FormationClient) *FormationMCPServer {
+func NewFormationMCPServer(workflows workflows.Workflows, c client.FormationAPIClient) *FormationMCPServer {
 	mcpServer := server.NewMCPServer(
 		"formation-mcp",
 		"1.0.0",
 		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, false),
+		server.WithPromptCapabilities(true),
 	)
 
 	s := &FormationMCPServer{
-		server:    mcpServer,
-		workflows: workflows,
-		client:    c,
+		server:                          mcpServer,
+		workflows:                       workflows,
+		client:                          c,
+		resources:                       newResourceStore(),
+		auditLog:                        audit.NewLog(),
+		defaultDeleteGracePeriodSeconds: -1,
 	}
+	s.deleteJobs = newDeleteJobManager(s.executeDeleteJob)
 
-	// Register all 13 tools
+	// Register all tools
 	s.registerTools()
+	s.registerResources()
+	s.registerPrompts()
 
 	return s
 }
@@ -47,23 +99,163 @@ func (s *FormationMCPServer) Server() *server.MCPServer {
 	return s.server
 }
 
-// registerTools registers all Formation MCP tools.
+// AuditLog returns the server's audit.Log, so callers outside this package
+// (e.g. main, wiring up configured sinks) can call AddSink on it after
+// construction.
+func (s *FormationMCPServer) AuditLog() *audit.Log {
+	return s.auditLog
+}
+
+// SetCurrentUser sets the user attributed to every audit.Record this
+// server produces from here on. Formation MCP servers act as a single
+// configured identity, so this is normally set once at startup from
+// config.Username rather than varying per request.
+func (s *FormationMCPServer) SetCurrentUser(user string) {
+	s.currentUser = user
+}
+
+// SetDefaultDeleteGracePeriodSeconds sets the grace_period_seconds
+// delete_data assumes when a call doesn't specify one. It's normally set
+// once at startup from config.DeleteGracePeriodSeconds.
+func (s *FormationMCPServer) SetDefaultDeleteGracePeriodSeconds(seconds int) {
+	s.defaultDeleteGracePeriodSeconds = seconds
+}
+
+// SetLogBackend sets the logging.Logger backend withRequestContext builds
+// per-request loggers from. It's normally set once at startup from
+// config.Config.LogBackend; an empty value uses logging.LogBackendSlog.
+func (s *FormationMCPServer) SetLogBackend(backend logging.LogBackend) {
+	s.logBackend = backend
+}
+
+// SetLogLevelControls points set_log_level/get_log_level at the live
+// *slog.LevelVar and *logging.SubsystemLevels main's logging.
+// SubsystemLevelHandler was built against, so changes the tools make take
+// effect immediately without restarting (or signaling) the server. It's
+// normally called once at startup, right after SetLogBackend.
+func (s *FormationMCPServer) SetLogLevelControls(base *slog.LevelVar, levels *logging.SubsystemLevels) {
+	s.baseLevel = base
+	s.subsystemLevels = levels
+}
+
+// LoadDeleteJobs points the server's delete job queue at a JSON state
+// file, loading any jobs already queued there and rescheduling the
+// pending ones, so a restart doesn't drop a scheduled grace-period
+// deletion. It's opt-in, like AuditLog's sinks - not every deployment
+// needs scheduled deletions to survive a restart.
+func (s *FormationMCPServer) LoadDeleteJobs(path string) error {
+	return s.deleteJobs.loadFrom(path)
+}
+
+// executeDeleteJob performs job's actual deletion once its grace period
+// elapses: a hard DeleteData for purge jobs, a trash marker otherwise -
+// the same branch handleDeleteData takes for grace_period_seconds < 0.
+func (s *FormationMCPServer) executeDeleteJob(ctx context.Context, job *deleteJob) error {
+	if job.Purge {
+		return s.client.DeleteData(ctx, job.Path, job.Recurse, false)
+	}
+	_, err := s.trashPath(ctx, job.Path, job.VersionID)
+	return err
+}
+
+// registerTools wires up every tool in the registry against this server
+// instance. The built-in tools are seeded by Init; downstream consumers that
+// compile their own tools into a formation-mcp-based binary can Register
+// additional factories (or RegisterDeprecated aliases) before constructing a
+// FormationMCPServer and they'll be picked up here too.
 func (s *FormationMCPServer) registerTools() {
-	// App management tools
-	s.server.AddTool(s.listAppsTool(), s.handleListApps)
-	s.server.AddTool(s.getAppParametersTool(), s.handleGetAppParameters)
-	s.server.AddTool(s.launchAppAndWaitTool(), s.handleLaunchAppAndWait)
-	s.server.AddTool(s.getAnalysisStatusTool(), s.handleGetAnalysisStatus)
-	s.server.AddTool(s.listRunningAnalysesTool(), s.handleListRunningAnalyses)
-	s.server.AddTool(s.stopAnalysisTool(), s.handleStopAnalysis)
-	s.server.AddTool(s.openInBrowserTool(), s.handleOpenInBrowser)
-
-	// Data management tools
-	s.server.AddTool(s.browseDataTool(), s.handleBrowseData)
-	s.server.AddTool(s.createDirectoryTool(), s.handleCreateDirectory)
-	s.server.AddTool(s.uploadFileTool(), s.handleUploadFile)
-	s.server.AddTool(s.setMetadataTool(), s.handleSetMetadata)
-	s.server.AddTool(s.deleteDataTool(), s.handleDeleteData)
+	Init()
+
+	for _, name := range List() {
+		factory, ok := Lookup(name)
+		if !ok {
+			continue
+		}
+		tool, handler := factory(s)
+		s.server.AddTool(tool, server.ToolHandlerFunc(withRequestContext(s.logBackend, name, s.withAudit(name, handler))))
+	}
+}
+
+// withRequestContext wraps handler so every tool invocation gets its own
+// request id (propagated to Formation as X-Request-ID, see
+// client.WithRequestID) and a logger annotated with that id plus
+// toolName, so doRequest's and the workflows package's log lines for one
+// call - login, parameter fetch, launch, poll - can all be traced back to
+// the MCP tool call that triggered them. backend selects which
+// logging.Logger implementation that per-request logger is built on top
+// of (see logging.BuildLogger); empty uses logging.LogBackendSlog. The
+// logger is tagged logging.SubsystemKey="server" - client and workflows
+// re-tag it to their own subsystem name as it's passed down to them (see
+// client.defaultClientLogger, workflows.subsystemLogger), so
+// set_log_level/get_log_level can tune each package's verbosity
+// independently.
+func withRequestContext(backend logging.LogBackend, toolName string, handler ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = client.WithRequestID(ctx, "")
+		requestID, _ := client.RequestIDFromContext(ctx)
+		logger := logging.BuildLogger(backend, slog.With("request_id", requestID, "tool", toolName)).With(logging.SubsystemKey, "server")
+		ctx = client.WithLogger(ctx, logger)
+		return handler(ctx, request)
+	}
+}
+
+// withAudit wraps handler so every call to it is recorded to s.auditLog -
+// params, a summary of the result, any error, and how long it took - for
+// list_tool_history and replay_tool_call. It must run inside
+// withRequestContext so the request id set there is already on ctx and
+// becomes the audit.Record's ID.
+func (s *FormationMCPServer) withAudit(toolName string, handler ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		params, _ := request.Params.Arguments.(map[string]interface{})
+
+		result, err := handler(ctx, request)
+
+		entry := audit.Record{
+			Timestamp: start,
+			Tool:      toolName,
+			Params:    params,
+			Duration:  time.Since(start),
+			User:      s.currentUser,
+		}
+		if requestID, ok := client.RequestIDFromContext(ctx); ok {
+			entry.ID = requestID
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		} else {
+			entry.ResultSummary = summarizeResult(result)
+		}
+
+		if _, recErr := s.auditLog.Record(ctx, entry); recErr != nil {
+			client.LoggerFromContext(ctx).Warn("audit sink write failed", "tool", toolName, "error", recErr)
+		}
+
+		return result, err
+	}
+}
+
+// summarizeResultMaxLen bounds how much of a tool result's text content
+// ends up in an audit.Record, so a large browse_data or read_file_range
+// response doesn't blow up the in-memory audit log.
+const summarizeResultMaxLen = 200
+
+// summarizeResult renders result's first text content block as a short,
+// single-line summary for an audit.Record.
+func summarizeResult(result *mcp.CallToolResult) string {
+	if result == nil || len(result.Content) == 0 {
+		return ""
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return ""
+	}
+
+	summary := strings.ReplaceAll(strings.TrimSpace(text.Text), "\n", " ")
+	if len(summary) > summarizeResultMaxLen {
+		summary = summary[:summarizeResultMaxLen] + "..."
+	}
+	return summary
 }
 
 // Tool definitions
@@ -101,6 +293,7 @@ func (s *FormationMCPServer) listAppsTool() mcp.Tool {
 					"description": "Offset for pagination (default 0)",
 					"default":     0,
 				},
+				"response_format": responseFormatProperty,
 			},
 		},
 	}
@@ -122,6 +315,57 @@ func (s *FormationMCPServer) getAppParametersTool() mcp.Tool {
 					"description": "The system ID (default: de)",
 					"default":     "de",
 				},
+				"response_format": responseFormatProperty,
+			},
+			Required: []string{"app_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) listAppResourceCapabilitiesTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_app_resource_capabilities",
+		Description: "List the accelerator/device classes (gpu, qat, hugepages_2mi, sriov_nic) an app supports, so an agent can check before passing resource_requests to launch_app_and_wait",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"app_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The application ID",
+				},
+				"system_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The system ID (default: de)",
+					"default":     "de",
+				},
+				"response_format": responseFormatProperty,
+			},
+			Required: []string{"app_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) estimateCostTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "estimate_cost",
+		Description: "Estimate the resource usage (CPU-hours, memory-hours, storage-GB-hours) and dollar-or-token cost of launching an app with a given config, without submitting it",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"app_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The application ID",
+				},
+				"system_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The system ID (default: de)",
+					"default":     "de",
+				},
+				"config": map[string]interface{}{
+					"type":        "object",
+					"description": "Configuration parameters for the app",
+				},
+				"response_format": responseFormatProperty,
 			},
 			Required: []string{"app_id"},
 		},
@@ -132,6 +376,89 @@ func (s *FormationMCPServer) launchAppAndWaitTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "launch_app_and_wait",
 		Description: "Launch an application and wait for it to be ready (interactive apps only). Returns immediately for batch jobs.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"app_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The application ID",
+				},
+				"system_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The system ID (default: de)",
+					"default":     "de",
+				},
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name for the analysis",
+				},
+				"config": map[string]interface{}{
+					"type":        "object",
+					"description": "Configuration parameters for the app",
+				},
+				"max_wait": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum time to wait in seconds (default 300)",
+					"default":     300,
+				},
+				"resource_requests": resourceRequestsProperty,
+				"max_cost": map[string]interface{}{
+					"type":        "number",
+					"description": "Reject the launch instead of submitting it if its estimated cost exceeds this amount (omit for no cap)",
+				},
+				"confirm_over_cost": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Submit the launch even if its estimated cost exceeds max_cost (default false)",
+					"default":     false,
+				},
+				"dry_run": dryRunProperty,
+			},
+			Required: []string{"app_id"},
+		},
+	}
+}
+
+// resourceRequestsProperty is the launch_app_and_wait input schema property
+// for resource_requests, using the Kubernetes device-plugin vocabulary for
+// accelerator scheduling. It is optional - apps that don't need special
+// hardware omit it entirely.
+var resourceRequestsProperty = map[string]interface{}{
+	"type":        "object",
+	"description": "Optional accelerator/device resources the analysis needs. The app must declare support for any class requested here (see list_app_resource_capabilities) or the launch is rejected before submission.",
+	"properties": map[string]interface{}{
+		"gpu": map[string]interface{}{
+			"type":        "object",
+			"description": "GPU allocation",
+			"properties": map[string]interface{}{
+				"count": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of GPUs requested",
+				},
+				"vendor": map[string]interface{}{
+					"type":        "string",
+					"description": "GPU vendor hint, e.g. nvidia or amd (default nvidia)",
+				},
+			},
+		},
+		"qat": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of Intel QuickAssist (QAT) devices requested",
+		},
+		"hugepages_2mi": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of 2Mi hugepages requested",
+		},
+		"sriov_nic": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of SR-IOV NICs requested",
+		},
+	},
+}
+
+func (s *FormationMCPServer) launchAppAndStreamTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "launch_app_and_stream",
+		Description: "Launch an application like launch_app_and_wait, but emit MCP progress notifications while waiting and stop the analysis (without saving outputs) if the request is cancelled. Requires the caller to send a progressToken for notifications to be delivered.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -174,6 +501,7 @@ func (s *FormationMCPServer) getAnalysisStatusTool() mcp.Tool {
 					"type":        "string",
 					"description": "The analysis ID",
 				},
+				"response_format": responseFormatProperty,
 			},
 			Required: []string{"analysis_id"},
 		},
@@ -192,6 +520,7 @@ func (s *FormationMCPServer) listRunningAnalysesTool() mcp.Tool {
 					"description": "Status filter (default: Running). Common values: Running, Completed, Failed, Submitted, Canceled",
 					"default":     "Running",
 				},
+				"response_format": responseFormatProperty,
 			},
 		},
 	}
@@ -213,12 +542,73 @@ func (s *FormationMCPServer) stopAnalysisTool() mcp.Tool {
 					"description": "Whether to save outputs before stopping (default true)",
 					"default":     true,
 				},
+				"snapshot_before_exit": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Checkpoint the analysis's launch config before stopping it, so it can be relaunched later with resume_from_snapshot (default false)",
+					"default":     false,
+				},
+				"dry_run": dryRunProperty,
+			},
+			Required: []string{"analysis_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) snapshotAnalysisTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "snapshot_analysis",
+		Description: "Checkpoint a running or previously-launched analysis's launch configuration so it can be relaunched later with resume_from_snapshot",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"analysis_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The analysis ID to snapshot",
+				},
+				"label": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional human-readable label for the snapshot",
+				},
 			},
 			Required: []string{"analysis_id"},
 		},
 	}
 }
 
+func (s *FormationMCPServer) resumeFromSnapshotTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "resume_from_snapshot",
+		Description: "Relaunch an analysis from a previously taken snapshot",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"snapshot_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The snapshot ID to resume from",
+				},
+				"config": map[string]interface{}{
+					"type":        "object",
+					"description": "Configuration overrides to apply on top of the snapshotted launch config",
+				},
+			},
+			Required: []string{"snapshot_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) listSnapshotsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_snapshots",
+		Description: "List the available analysis snapshots taken with snapshot_analysis",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"response_format": responseFormatProperty,
+			},
+		},
+	}
+}
+
 func (s *FormationMCPServer) openInBrowserTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "open_in_browser",
@@ -262,6 +652,7 @@ func (s *FormationMCPServer) browseDataTool() mcp.Tool {
 					"description": "Include metadata in the response (default false)",
 					"default":     false,
 				},
+				"response_format": responseFormatProperty,
 			},
 			Required: []string{"path"},
 		},
@@ -283,6 +674,7 @@ func (s *FormationMCPServer) createDirectoryTool() mcp.Tool {
 					"type":        "object",
 					"description": "Optional metadata to attach to the directory",
 				},
+				"dry_run": dryRunProperty,
 			},
 			Required: []string{"path"},
 		},
@@ -292,7 +684,7 @@ func (s *FormationMCPServer) createDirectoryTool() mcp.Tool {
 func (s *FormationMCPServer) uploadFileTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "upload_file",
-		Description: "Upload a file to iRODS",
+		Description: "Upload a file to iRODS. content is inline - for files too large to inline, use upload_file_from_path (local filesystem) or upload_file_chunk (caller-paced chunks); for a remote HTTP(S) source, pass source_url instead of content to stream it into iRODS without round-tripping the bytes through the caller.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -302,14 +694,88 @@ func (s *FormationMCPServer) uploadFileTool() mcp.Tool {
 				},
 				"content": map[string]interface{}{
 					"type":        "string",
-					"description": "The file content",
+					"description": "The file content, encoded per the encoding field. Ignored if source_url is set.",
+				},
+				"encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "How content is encoded: \"utf8\" (default) for text, \"base64\" for binary content",
+					"enum":        []string{"utf8", "base64"},
+					"default":     "utf8",
+				},
+				"source_url": map[string]interface{}{
+					"type":        "string",
+					"description": "An HTTP(S) URL to stream directly into iRODS instead of using content",
+				},
+				"metadata": map[string]interface{}{
+					"type":        "object",
+					"description": "Optional metadata to attach to the file",
+				},
+				"dry_run": dryRunProperty,
+			},
+			Required: []string{"path"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) uploadFileChunkTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "upload_file_chunk",
+		Description: "Upload one chunk of a large file to iRODS. Omit upload_id to start a new upload (offset must be 0); pass back the upload_id returned by the previous call to continue it. Set is_final on the last chunk to commit the upload.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"upload_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The upload session id from a previous call, or omitted to start a new upload",
+				},
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "The destination path for the file",
+				},
+				"offset": map[string]interface{}{
+					"type":        "integer",
+					"description": "The byte offset this chunk starts at; must match the offset returned by the previous call",
+				},
+				"chunk": map[string]interface{}{
+					"type":        "string",
+					"description": "Base64-encoded chunk content",
+				},
+				"is_final": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Whether this is the last chunk; committing the upload if true (default false)",
+					"default":     false,
+				},
+				"metadata": map[string]interface{}{
+					"type":        "object",
+					"description": "Optional metadata to attach to the file, applied when is_final completes the upload",
+				},
+			},
+			Required: []string{"path", "offset", "chunk"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) uploadFileFromPathTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "upload_file_from_path",
+		Description: "Stream a file from the local filesystem (where this MCP server runs) into iRODS, for files too large to inline as tool call content. Emits MCP progress notifications as chunks commit if the caller sends a progressToken.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"local_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to the file on the local filesystem",
+				},
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "The destination path in iRODS",
 				},
 				"metadata": map[string]interface{}{
 					"type":        "object",
 					"description": "Optional metadata to attach to the file",
 				},
 			},
-			Required: []string{"path", "content"},
+			Required: []string{"local_path", "path"},
 		},
 	}
 }
@@ -317,7 +783,7 @@ func (s *FormationMCPServer) uploadFileTool() mcp.Tool {
 func (s *FormationMCPServer) setMetadataTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "set_metadata",
-		Description: "Add or replace metadata on an existing path",
+		Description: "Add, replace, remove, or patch metadata on an existing path, per strategy",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -331,11 +797,35 @@ func (s *FormationMCPServer) setMetadataTool() mcp.Tool {
 				},
 				"replace": map[string]interface{}{
 					"type":        "boolean",
-					"description": "Whether to replace existing metadata (default false)",
+					"description": "Whether to replace existing metadata (default false). Deprecated: use strategy instead - replace=true is equivalent to strategy=\"replace\" and is only still read when strategy is omitted.",
 					"default":     false,
 				},
+				"strategy": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"merge", "replace", "remove", "strategic", "json-patch"},
+					"description": "How to reconcile metadata with what's already on path (default: merge, or replace if replace=true). merge/replace add/overwrite the metadata keys as today; remove deletes the attributes named in attributes; strategic merges array-valued attributes by unioning them instead of overwriting; json-patch applies the RFC 6902 operations in patch against the current metadata.",
+				},
+				"attributes": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Attribute names to delete. Only used when strategy is \"remove\".",
+				},
+				"patch": map[string]interface{}{
+					"type":        "array",
+					"description": "RFC 6902 operations (add, replace, remove) to apply against the current metadata. Only used when strategy is \"json-patch\". Paths must be top-level attribute pointers, e.g. \"/experiment\".",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"op":    map[string]interface{}{"type": "string", "enum": []string{"add", "replace", "remove"}},
+							"path":  map[string]interface{}{"type": "string"},
+							"value": map[string]interface{}{},
+						},
+						"required": []string{"op", "path"},
+					},
+				},
+				"dry_run": dryRunProperty,
 			},
-			Required: []string{"path", "metadata"},
+			Required: []string{"path"},
 		},
 	}
 }
@@ -343,7 +833,7 @@ func (s *FormationMCPServer) setMetadataTool() mcp.Tool {
 func (s *FormationMCPServer) deleteDataTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "delete_data",
-		Description: "Delete a file or directory from iRODS",
+		Description: "Delete a file or directory from iRODS. By default this only places a trash marker on the path (see list_deleted/restore_data/purge_deleted); pass purge=true for today's immediate, unrecoverable delete. grace_period_seconds schedules the trash/purge through a cancellable job instead of running it inline.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -353,14 +843,27 @@ func (s *FormationMCPServer) deleteDataTool() mcp.Tool {
 				},
 				"recurse": map[string]interface{}{
 					"type":        "boolean",
-					"description": "Whether to recursively delete directories (default false)",
+					"description": "Whether to recursively delete directories when purge is true (default false; ignored otherwise, since trashing only tags the named path)",
+					"default":     false,
+				},
+				"version_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Record this version as the one being trashed. Not yet supported by the underlying Formation client; stored on the trash marker for forward compatibility but otherwise unused.",
+				},
+				"purge": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Permanently delete immediately instead of placing a trash marker (today's behavior, irreversible)",
 					"default":     false,
 				},
 				"dry_run": map[string]interface{}{
 					"type":        "boolean",
-					"description": "Preview what would be deleted without actually deleting (default false)",
+					"description": "Preview what would happen without actually trashing or deleting (default false)",
 					"default":     false,
 				},
+				"grace_period_seconds": map[string]interface{}{
+					"type":        "integer",
+					"description": "-1 runs the trash/purge immediately, as if this parameter were omitted (the server's configured default). 0 still goes through the delete job queue but fires right away. A positive value delays execution that long, returning a job_id that get_delete_job/cancel_delete_job can inspect or cancel before it fires.",
+				},
 			},
 			Required: []string{"path"},
 		},
@@ -383,12 +886,13 @@ func unmarshalParams(request mcp.CallToolRequest, params interface{}) error {
 
 func (s *FormationMCPServer) handleListApps(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		Name        string `json:"name"`
-		Integrator  string `json:"integrator"`
-		Description string `json:"description"`
-		JobType     string `json:"job_type"`
-		Limit       int    `json:"limit"`
-		Offset      int    `json:"offset"`
+		Name           string         `json:"name"`
+		Integrator     string         `json:"integrator"`
+		Description    string         `json:"description"`
+		JobType        string         `json:"job_type"`
+		Limit          int            `json:"limit"`
+		Offset         int            `json:"offset"`
+		ResponseFormat responseFormat `json:"response_format"`
 	}
 	params.Limit = 10 // default
 
@@ -403,26 +907,27 @@ func (s *FormationMCPServer) handleListApps(ctx context.Context, request mcp.Cal
 		return nil, err
 	}
 
-	// Format as markdown
-	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("## Available Applications (%d)\n\n", len(apps)))
-	for _, app := range apps {
-		builder.WriteString(fmt.Sprintf("### %s\n", app.Name))
-		builder.WriteString(fmt.Sprintf("- **ID**: `%s`\n", app.ID))
-		builder.WriteString(fmt.Sprintf("- **System**: `%s`\n", app.SystemID))
-		if app.IntegratorUsername != "" {
-			builder.WriteString(fmt.Sprintf("- **Integrator**: %s\n", app.IntegratorUsername))
+	return s.respondWithFormat(params.ResponseFormat, "formation://apps", apps, func() string {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("## Available Applications (%d)\n\n", len(apps)))
+		for _, app := range apps {
+			builder.WriteString(fmt.Sprintf("### %s\n", app.Name))
+			builder.WriteString(fmt.Sprintf("- **ID**: `%s`\n", app.ID))
+			builder.WriteString(fmt.Sprintf("- **System**: `%s`\n", app.SystemID))
+			if app.IntegratorUsername != "" {
+				builder.WriteString(fmt.Sprintf("- **Integrator**: %s\n", app.IntegratorUsername))
+			}
+			builder.WriteString(fmt.Sprintf("- **Description**: %s\n\n", app.Description))
 		}
-		builder.WriteString(fmt.Sprintf("- **Description**: %s\n\n", app.Description))
-	}
-
-	return mcp.NewToolResultText(builder.String()), nil
+		return builder.String()
+	})
 }
 
 func (s *FormationMCPServer) handleGetAppParameters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		AppID    string `json:"app_id"`
-		SystemID string `json:"system_id"`
+		AppID          string         `json:"app_id"`
+		SystemID       string         `json:"system_id"`
+		ResponseFormat responseFormat `json:"response_format"`
 	}
 	params.SystemID = "de" // default
 
@@ -437,82 +942,204 @@ func (s *FormationMCPServer) handleGetAppParameters(ctx context.Context, request
 		return nil, err
 	}
 
-	// Format as markdown
-	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("## App Parameters\n\n"))
-	builder.WriteString(fmt.Sprintf("**Job Type**: %s\n\n", appParams.OverallJobType))
-
-	for _, group := range appParams.Groups {
-		builder.WriteString(fmt.Sprintf("### %s\n\n", group.Label))
-		for _, param := range group.Parameters {
-			required := ""
-			if param.Required {
-				required = " (required)"
-			}
-			builder.WriteString(fmt.Sprintf("- **%s**%s: %s\n", param.Label, required, param.Description))
-			builder.WriteString(fmt.Sprintf("  - ID: `%s`\n", param.ID))
-			builder.WriteString(fmt.Sprintf("  - Type: `%s`\n", param.Type))
-			if param.DefaultValue != nil {
-				builder.WriteString(fmt.Sprintf("  - Default: `%v`\n", param.DefaultValue))
+	return s.respondWithFormat(params.ResponseFormat, appResourceURI(params.SystemID, params.AppID), appParams, func() string {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("## App Parameters\n\n"))
+		builder.WriteString(fmt.Sprintf("**Job Type**: %s\n\n", appParams.OverallJobType))
+
+		for _, group := range appParams.Groups {
+			builder.WriteString(fmt.Sprintf("### %s\n\n", group.Label))
+			for _, param := range group.Parameters {
+				required := ""
+				if param.Required {
+					required = " (required)"
+				}
+				builder.WriteString(fmt.Sprintf("- **%s**%s: %s\n", param.Label, required, param.Description))
+				builder.WriteString(fmt.Sprintf("  - ID: `%s`\n", param.ID))
+				builder.WriteString(fmt.Sprintf("  - Type: `%s`\n", param.Type))
+				if param.DefaultValue != nil {
+					builder.WriteString(fmt.Sprintf("  - Default: `%v`\n", param.DefaultValue))
+				}
 			}
+			builder.WriteString("\n")
 		}
-		builder.WriteString("\n")
-	}
-
-	return mcp.NewToolResultText(builder.String()), nil
+		return builder.String()
+	})
 }
 
-func (s *FormationMCPServer) handleLaunchAppAndWait(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *FormationMCPServer) handleListAppResourceCapabilities(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		AppID    string                 `json:"app_id"`
-		SystemID string                 `json:"system_id"`
-		Name     string                 `json:"name"`
-		Config   map[string]interface{} `json:"config"`
-		MaxWait  int                    `json:"max_wait"`
+		AppID          string         `json:"app_id"`
+		SystemID       string         `json:"system_id"`
+		ResponseFormat responseFormat `json:"response_format"`
 	}
 	params.SystemID = "de" // default
-	params.MaxWait = 300   // default
 
 	if err := unmarshalParams(request, &params); err != nil {
 		return nil, err
 	}
 
-	if params.Config == nil {
-		params.Config = make(map[string]interface{})
-	}
+	slog.Info("listing app resource capabilities", "app_id", params.AppID, "system_id", params.SystemID)
 
-	if params.Name == "" {
-		params.Name = fmt.Sprintf("analysis-%d", time.Now().Unix())
+	appParams, err := s.client.GetAppParameters(ctx, params.SystemID, params.AppID)
+	if err != nil {
+		return nil, err
 	}
 
-	slog.Info("launching app", "app_id", params.AppID, "system_id", params.SystemID, "name", params.Name)
+	return s.respondWithFormat(params.ResponseFormat, appResourceURI(params.SystemID, params.AppID), appParams.ResourceCapabilities, func() string {
+		if len(appParams.ResourceCapabilities) == 0 {
+			return fmt.Sprintf("App `%s` does not declare support for any accelerator/device classes.", params.AppID)
+		}
+
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("## Resource Capabilities for `%s`\n\n", params.AppID))
+		for _, class := range appParams.ResourceCapabilities {
+			builder.WriteString(fmt.Sprintf("- %s\n", class))
+		}
+		return builder.String()
+	})
+}
 
-	result, err := s.workflows.LaunchAndWait(
+func (s *FormationMCPServer) handleEstimateCost(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AppID          string                 `json:"app_id"`
+		SystemID       string                 `json:"system_id"`
+		Config         map[string]interface{} `json:"config"`
+		ResponseFormat responseFormat         `json:"response_format"`
+	}
+	params.SystemID = "de" // default
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+	if params.Config == nil {
+		params.Config = make(map[string]interface{})
+	}
+
+	slog.Info("estimating cost", "app_id", params.AppID, "system_id", params.SystemID)
+
+	estimate, err := s.workflows.CostEstimate(ctx, params.AppID, params.SystemID, params.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.respondWithFormat(params.ResponseFormat, appResourceURI(params.SystemID, params.AppID), estimate, func() string {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("## Cost Estimate for `%s`\n\n", params.AppID))
+		builder.WriteString(fmt.Sprintf("- **CPU-hours**: %.2f\n", estimate.CPUHours))
+		builder.WriteString(fmt.Sprintf("- **Memory-GB-hours**: %.2f\n", estimate.MemoryGBHours))
+		builder.WriteString(fmt.Sprintf("- **Storage-GB-hours**: %.2f\n", estimate.StorageGBHours))
+		currency := estimate.Currency
+		if currency == "" {
+			currency = "USD"
+		}
+		builder.WriteString(fmt.Sprintf("- **Estimated Cost**: %.2f %s\n", estimate.EstimatedCost, currency))
+		return builder.String()
+	})
+}
+
+func (s *FormationMCPServer) handleLaunchAppAndWait(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AppID            string                   `json:"app_id"`
+		SystemID         string                   `json:"system_id"`
+		Name             string                   `json:"name"`
+		Config           map[string]interface{}   `json:"config"`
+		MaxWait          int                      `json:"max_wait"`
+		ResourceRequests *client.ResourceRequests `json:"resource_requests"`
+		MaxCost          float64                  `json:"max_cost"`
+		ConfirmOverCost  bool                     `json:"confirm_over_cost"`
+	}
+	params.SystemID = "de" // default
+	params.MaxWait = 300   // default
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	if params.Config == nil {
+		params.Config = make(map[string]interface{})
+	}
+
+	if params.Name == "" {
+		params.Name = fmt.Sprintf("analysis-%d", time.Now().Unix())
+	}
+
+	var costGate *workflows.CostGate
+	if params.MaxCost > 0 {
+		costGate = &workflows.CostGate{MaxCost: params.MaxCost, ConfirmOverCost: params.ConfirmOverCost}
+	}
+
+	progressToken, hasProgressToken := progressTokenFromRequest(request)
+
+	slog.Info("launching app", "app_id", params.AppID, "system_id", params.SystemID, "name", params.Name, "has_progress_token", hasProgressToken)
+
+	events, err := s.workflows.LaunchAndWaitStream(
 		ctx,
 		params.AppID,
 		params.SystemID,
 		params.Name,
 		params.Config,
+		params.ResourceRequests,
+		costGate,
 		time.Duration(params.MaxWait)*time.Second,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check for missing parameters
+	result := &workflows.LaunchResult{}
+	maxWait := time.Duration(params.MaxWait) * time.Second
+	for event := range events {
+		if hasProgressToken {
+			if err := s.sendWorkflowEventProgress(ctx, progressToken, event); err != nil {
+				slog.Warn("failed to send progress notification", "analysis_id", event.AnalysisID, "error", err)
+			}
+		}
+		if terminal, err := workflows.ApplyWorkflowEvent(result, event, maxWait); terminal {
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return mcp.NewToolResultText(formatLaunchResult(result)), nil
+}
+
+// formatLaunchResult renders the markdown summary shared by
+// launch_app_and_wait and launch_app_and_stream: a missing-parameters
+// warning, or a success message for the interactive/batch case.
+func formatLaunchResult(result *workflows.LaunchResult) string {
+	var builder strings.Builder
+
 	if len(result.MissingParams) > 0 {
-		var builder strings.Builder
 		builder.WriteString("⚠️  **Missing Required Parameters**\n\n")
 		builder.WriteString("The following required parameters are missing:\n\n")
 		for _, param := range result.MissingParams {
 			builder.WriteString(fmt.Sprintf("- %s\n", param))
 		}
 		builder.WriteString("\nPlease provide these parameters in the config and try again.")
-		return mcp.NewToolResultText(builder.String()), nil
+		return builder.String()
+	}
+
+	if len(result.UnsupportedResources) > 0 {
+		builder.WriteString("⚠️  **Unsupported Resource Requests**\n\n")
+		builder.WriteString("This app does not support the following requested resource classes:\n\n")
+		for _, class := range result.UnsupportedResources {
+			builder.WriteString(fmt.Sprintf("- %s\n", class))
+		}
+		builder.WriteString("\nCheck list_app_resource_capabilities and remove unsupported classes from resource_requests.")
+		return builder.String()
+	}
+
+	if result.CostExceeded != nil {
+		builder.WriteString("⚠️  **Estimated Cost Exceeds max_cost**\n\n")
+		builder.WriteString(fmt.Sprintf("- **Estimated Cost**: %.2f\n", result.CostExceeded.Estimate.EstimatedCost))
+		builder.WriteString(fmt.Sprintf("- **max_cost**: %.2f\n", result.CostExceeded.MaxCost))
+		builder.WriteString("\nPass confirm_over_cost=true to launch anyway, or lower the app's resource usage.")
+		return builder.String()
 	}
 
-	// Format result
-	var builder strings.Builder
 	if result.IsInteractive {
 		builder.WriteString("✅ **Interactive App Launched Successfully**\n\n")
 		builder.WriteString(fmt.Sprintf("- **Analysis ID**: `%s`\n", result.AnalysisID))
@@ -529,12 +1156,105 @@ func (s *FormationMCPServer) handleLaunchAppAndWait(ctx context.Context, request
 		builder.WriteString("\nThe batch job has been submitted and is running in the background.")
 	}
 
-	return mcp.NewToolResultText(builder.String()), nil
+	return builder.String()
+}
+
+// progressTokenFromRequest returns the progressToken the caller attached to
+// request's _meta, if any. Handlers that stream progress only emit
+// notifications when one is present.
+func progressTokenFromRequest(request mcp.CallToolRequest) (mcp.ProgressToken, bool) {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil, false
+	}
+	return request.Params.Meta.ProgressToken, true
+}
+
+// sendLaunchProgress emits a notifications/progress message carrying one
+// LaunchProgress update for the given progress token.
+func (s *FormationMCPServer) sendLaunchProgress(ctx context.Context, token mcp.ProgressToken, update workflows.LaunchProgress) error {
+	message := fmt.Sprintf("status=%s", update.Status)
+	if update.URLReady {
+		message = fmt.Sprintf("%s url=%s", message, update.URL)
+	}
+
+	return s.server.SendNotificationToClient(ctx, "notifications/progress", map[string]interface{}{
+		"progressToken": token,
+		"progress":      update.Elapsed.Seconds(),
+		"message":       message,
+	})
+}
+
+// sendWorkflowEventProgress emits a notifications/progress message carrying
+// one WorkflowEvent from a LaunchAndWaitStream channel, for the given
+// progress token.
+func (s *FormationMCPServer) sendWorkflowEventProgress(ctx context.Context, token mcp.ProgressToken, event workflows.WorkflowEvent) error {
+	message := fmt.Sprintf("%s status=%s", event.Type, event.Status)
+	if event.Type == workflows.EventURLReady {
+		message = fmt.Sprintf("%s url=%s", message, event.URL)
+	}
+
+	return s.server.SendNotificationToClient(ctx, "notifications/progress", map[string]interface{}{
+		"progressToken": token,
+		"progress":      event.Elapsed.Seconds(),
+		"message":       message,
+	})
+}
+
+func (s *FormationMCPServer) handleLaunchAppAndStream(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AppID    string                 `json:"app_id"`
+		SystemID string                 `json:"system_id"`
+		Name     string                 `json:"name"`
+		Config   map[string]interface{} `json:"config"`
+		MaxWait  int                    `json:"max_wait"`
+	}
+	params.SystemID = "de" // default
+	params.MaxWait = 300   // default
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	if params.Config == nil {
+		params.Config = make(map[string]interface{})
+	}
+
+	if params.Name == "" {
+		params.Name = fmt.Sprintf("analysis-%d", time.Now().Unix())
+	}
+
+	progressToken, hasProgressToken := progressTokenFromRequest(request)
+
+	slog.Info("launching app with progress streaming", "app_id", params.AppID, "system_id", params.SystemID, "name", params.Name, "has_progress_token", hasProgressToken)
+
+	result, err := s.workflows.LaunchAndStream(
+		ctx,
+		params.AppID,
+		params.SystemID,
+		params.Name,
+		params.Config,
+		time.Duration(params.MaxWait)*time.Second,
+		func(update workflows.LaunchProgress) error {
+			if !hasProgressToken {
+				return nil
+			}
+			if err := s.sendLaunchProgress(ctx, progressToken, update); err != nil {
+				slog.Warn("failed to send progress notification", "analysis_id", update.AnalysisID, "error", err)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(formatLaunchResult(result)), nil
 }
 
 func (s *FormationMCPServer) handleGetAnalysisStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		AnalysisID string `json:"analysis_id"`
+		AnalysisID     string         `json:"analysis_id"`
+		ResponseFormat responseFormat `json:"response_format"`
 	}
 
 	if err := unmarshalParams(request, &params); err != nil {
@@ -548,25 +1268,27 @@ func (s *FormationMCPServer) handleGetAnalysisStatus(ctx context.Context, reques
 		return nil, err
 	}
 
-	var builder strings.Builder
-	builder.WriteString("## Analysis Status\n\n")
-	builder.WriteString(fmt.Sprintf("- **Analysis ID**: `%s`\n", status.AnalysisID))
-	builder.WriteString(fmt.Sprintf("- **Status**: %s\n", status.Status))
-	if status.URLReady {
-		builder.WriteString(fmt.Sprintf("- **URL Ready**: Yes\n"))
-		if status.URL != "" {
-			builder.WriteString(fmt.Sprintf("- **URL**: %s\n", status.URL))
+	return s.respondWithFormat(params.ResponseFormat, analysisResourceURI(params.AnalysisID), status, func() string {
+		var builder strings.Builder
+		builder.WriteString("## Analysis Status\n\n")
+		builder.WriteString(fmt.Sprintf("- **Analysis ID**: `%s`\n", status.AnalysisID))
+		builder.WriteString(fmt.Sprintf("- **Status**: %s\n", status.Status))
+		if status.URLReady {
+			builder.WriteString(fmt.Sprintf("- **URL Ready**: Yes\n"))
+			if status.URL != "" {
+				builder.WriteString(fmt.Sprintf("- **URL**: %s\n", status.URL))
+			}
+		} else {
+			builder.WriteString(fmt.Sprintf("- **URL Ready**: No\n"))
 		}
-	} else {
-		builder.WriteString(fmt.Sprintf("- **URL Ready**: No\n"))
-	}
-
-	return mcp.NewToolResultText(builder.String()), nil
+		return builder.String()
+	})
 }
 
 func (s *FormationMCPServer) handleListRunningAnalyses(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		Status string `json:"status"`
+		Status         string         `json:"status"`
+		ResponseFormat responseFormat `json:"response_format"`
 	}
 	params.Status = "Running" // default
 
@@ -581,27 +1303,30 @@ func (s *FormationMCPServer) handleListRunningAnalyses(ctx context.Context, requ
 		return nil, err
 	}
 
-	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("## %s Analyses (%d)\n\n", params.Status, len(analyses)))
-	if len(analyses) == 0 {
-		builder.WriteString(fmt.Sprintf("No %s analyses found.", params.Status))
-	} else {
-		for _, analysis := range analyses {
-			builder.WriteString(fmt.Sprintf("### Analysis `%s`\n", analysis.AnalysisID))
-			builder.WriteString(fmt.Sprintf("- **Analysis ID**: `%s`\n", analysis.AnalysisID))
-			builder.WriteString(fmt.Sprintf("- **App ID**: `%s`\n", analysis.AppID))
-			builder.WriteString(fmt.Sprintf("- **System**: `%s`\n", analysis.SystemID))
-			builder.WriteString(fmt.Sprintf("- **Status**: %s\n\n", analysis.Status))
+	uri := fmt.Sprintf("formation://analyses?status=%s", params.Status)
+	return s.respondWithFormat(params.ResponseFormat, uri, analyses, func() string {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("## %s Analyses (%d)\n\n", params.Status, len(analyses)))
+		if len(analyses) == 0 {
+			builder.WriteString(fmt.Sprintf("No %s analyses found.", params.Status))
+		} else {
+			for _, analysis := range analyses {
+				builder.WriteString(fmt.Sprintf("### Analysis `%s`\n", analysis.AnalysisID))
+				builder.WriteString(fmt.Sprintf("- **Analysis ID**: `%s`\n", analysis.AnalysisID))
+				builder.WriteString(fmt.Sprintf("- **App ID**: `%s`\n", analysis.AppID))
+				builder.WriteString(fmt.Sprintf("- **System**: `%s`\n", analysis.SystemID))
+				builder.WriteString(fmt.Sprintf("- **Status**: %s\n\n", analysis.Status))
+			}
 		}
-	}
-
-	return mcp.NewToolResultText(builder.String()), nil
+		return builder.String()
+	})
 }
 
 func (s *FormationMCPServer) handleStopAnalysis(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		AnalysisID  string `json:"analysis_id"`
-		SaveOutputs bool   `json:"save_outputs"`
+		AnalysisID         string `json:"analysis_id"`
+		SaveOutputs        bool   `json:"save_outputs"`
+		SnapshotBeforeExit bool   `json:"snapshot_before_exit"`
 	}
 	params.SaveOutputs = true // default
 
@@ -609,9 +1334,9 @@ func (s *FormationMCPServer) handleStopAnalysis(ctx context.Context, request mcp
 		return nil, err
 	}
 
-	slog.Info("stopping analysis", "analysis_id", params.AnalysisID, "save_outputs", params.SaveOutputs)
+	slog.Info("stopping analysis", "analysis_id", params.AnalysisID, "save_outputs", params.SaveOutputs, "snapshot_before_exit", params.SnapshotBeforeExit)
 
-	if err := s.workflows.StopAnalysis(ctx, params.AnalysisID, params.SaveOutputs); err != nil {
+	if err := s.workflows.StopAnalysis(ctx, params.AnalysisID, params.SaveOutputs, params.SnapshotBeforeExit); err != nil {
 		return nil, err
 	}
 
@@ -623,10 +1348,102 @@ func (s *FormationMCPServer) handleStopAnalysis(ctx context.Context, request mcp
 	} else {
 		builder.WriteString("- **Outputs**: Not saved")
 	}
+	if params.SnapshotBeforeExit {
+		builder.WriteString("\n- **Snapshot**: Taken before stopping, resume with resume_from_snapshot")
+	}
 
 	return mcp.NewToolResultText(builder.String()), nil
 }
 
+func (s *FormationMCPServer) handleSnapshotAnalysis(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		AnalysisID string `json:"analysis_id"`
+		Label      string `json:"label"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	slog.Info("snapshotting analysis", "analysis_id", params.AnalysisID, "label", params.Label)
+
+	snapshot, err := s.workflows.SnapshotAnalysis(ctx, params.AnalysisID, params.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("✅ **Snapshot Taken**\n\n")
+	builder.WriteString(fmt.Sprintf("- **Snapshot ID**: `%s`\n", snapshot.ID))
+	builder.WriteString(fmt.Sprintf("- **Analysis ID**: `%s`\n", snapshot.AnalysisID))
+	builder.WriteString(fmt.Sprintf("- **App ID**: `%s`\n", snapshot.AppID))
+	if snapshot.Label != "" {
+		builder.WriteString(fmt.Sprintf("- **Label**: %s\n", snapshot.Label))
+	}
+	builder.WriteString("\nResume with resume_from_snapshot using this snapshot ID.")
+
+	return mcp.NewToolResultText(builder.String()), nil
+}
+
+func (s *FormationMCPServer) handleResumeFromSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		SnapshotID string                 `json:"snapshot_id"`
+		Config     map[string]interface{} `json:"config"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	if params.Config == nil {
+		params.Config = make(map[string]interface{})
+	}
+
+	slog.Info("resuming analysis from snapshot", "snapshot_id", params.SnapshotID)
+
+	result, err := s.workflows.ResumeFromSnapshot(ctx, params.SnapshotID, params.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(formatLaunchResult(result)), nil
+}
+
+func (s *FormationMCPServer) handleListSnapshots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		ResponseFormat responseFormat `json:"response_format"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.workflows.ListSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.respondWithFormat(params.ResponseFormat, "formation://snapshots", snapshots, func() string {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("## Snapshots (%d)\n\n", len(snapshots)))
+		if len(snapshots) == 0 {
+			builder.WriteString("No snapshots found.")
+		} else {
+			for _, snapshot := range snapshots {
+				builder.WriteString(fmt.Sprintf("### Snapshot `%s`\n", snapshot.ID))
+				builder.WriteString(fmt.Sprintf("- **Analysis ID**: `%s`\n", snapshot.AnalysisID))
+				builder.WriteString(fmt.Sprintf("- **App ID**: `%s`\n", snapshot.AppID))
+				builder.WriteString(fmt.Sprintf("- **System**: `%s`\n", snapshot.SystemID))
+				if snapshot.Label != "" {
+					builder.WriteString(fmt.Sprintf("- **Label**: %s\n", snapshot.Label))
+				}
+				builder.WriteString(fmt.Sprintf("- **Created**: %s\n\n", snapshot.CreatedAt))
+			}
+		}
+		return builder.String()
+	})
+}
+
 func (s *FormationMCPServer) handleOpenInBrowser(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
 		URL string `json:"url"`
@@ -647,10 +1464,11 @@ func (s *FormationMCPServer) handleOpenInBrowser(ctx context.Context, request mc
 
 func (s *FormationMCPServer) handleBrowseData(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		Path            string `json:"path"`
-		Offset          int    `json:"offset"`
-		Limit           int    `json:"limit"`
-		IncludeMetadata bool   `json:"include_metadata"`
+		Path            string         `json:"path"`
+		Offset          int            `json:"offset"`
+		Limit           int            `json:"limit"`
+		IncludeMetadata bool           `json:"include_metadata"`
+		ResponseFormat  responseFormat `json:"response_format"`
 	}
 	// No default limit - 0 means unlimited for files, all entries for directories
 	// Users can specify limit for pagination if needed
@@ -666,56 +1484,58 @@ func (s *FormationMCPServer) handleBrowseData(ctx context.Context, request mcp.C
 		return nil, err
 	}
 
-	var builder strings.Builder
+	return s.respondWithFormat(params.ResponseFormat, dataResourceURI(params.Path), result, func() string {
+		var builder strings.Builder
 
-	if isDir {
-		dirContents := result.(*client.DirectoryContents)
-		builder.WriteString(fmt.Sprintf("## Directory: %s\n\n", dirContents.Path))
-
-		// Separate directories and files from contents
-		var directories, files []client.DirectoryEntry
-		for _, entry := range dirContents.Contents {
-			if entry.Type == "collection" {
-				directories = append(directories, entry)
-			} else if entry.Type == "data_object" {
-				files = append(files, entry)
+		if isDir {
+			dirContents := result.(*client.DirectoryContents)
+			builder.WriteString(fmt.Sprintf("## Directory: %s\n\n", dirContents.Path))
+
+			// Separate directories and files from contents
+			var directories, files []client.DirectoryEntry
+			for _, entry := range dirContents.Contents {
+				if entry.Type == "collection" {
+					directories = append(directories, entry)
+				} else if entry.Type == "data_object" {
+					files = append(files, entry)
+				}
 			}
-		}
 
-		if len(directories) > 0 {
-			builder.WriteString("### 📁 Directories\n\n")
-			for _, dir := range directories {
-				builder.WriteString(fmt.Sprintf("- %s\n", dir.Name))
+			if len(directories) > 0 {
+				builder.WriteString("### 📁 Directories\n\n")
+				for _, dir := range directories {
+					builder.WriteString(fmt.Sprintf("- %s\n", dir.Name))
+				}
+				builder.WriteString("\n")
 			}
-			builder.WriteString("\n")
-		}
 
-		if len(files) > 0 {
-			builder.WriteString("### 📄 Files\n\n")
-			for _, file := range files {
-				builder.WriteString(fmt.Sprintf("- %s\n", file.Name))
+			if len(files) > 0 {
+				builder.WriteString("### 📄 Files\n\n")
+				for _, file := range files {
+					builder.WriteString(fmt.Sprintf("- %s\n", file.Name))
+				}
 			}
-		}
 
-		if len(dirContents.Contents) == 0 {
-			builder.WriteString("*Empty directory*\n")
-		}
-	} else {
-		fileContent := result.(*client.FileContent)
-		builder.WriteString(fmt.Sprintf("## File: %s\n\n", fileContent.Path))
-		if params.IncludeMetadata && len(fileContent.Metadata) > 0 {
-			builder.WriteString("### Metadata\n\n")
-			for k, v := range fileContent.Metadata {
-				builder.WriteString(fmt.Sprintf("- **%s**: %v\n", k, v))
+			if len(dirContents.Contents) == 0 {
+				builder.WriteString("*Empty directory*\n")
 			}
-			builder.WriteString("\n")
+		} else {
+			fileContent := result.(*client.FileContent)
+			builder.WriteString(fmt.Sprintf("## File: %s\n\n", fileContent.Path))
+			if params.IncludeMetadata && len(fileContent.Metadata) > 0 {
+				builder.WriteString("### Metadata\n\n")
+				for k, v := range fileContent.Metadata {
+					builder.WriteString(fmt.Sprintf("- **%s**: %v\n", k, v))
+				}
+				builder.WriteString("\n")
+			}
+			builder.WriteString("### Content\n\n```\n")
+			builder.WriteString(fileContent.Content)
+			builder.WriteString("\n```\n")
 		}
-		builder.WriteString("### Content\n\n```\n")
-		builder.WriteString(fileContent.Content)
-		builder.WriteString("\n```\n")
-	}
 
-	return mcp.NewToolResultText(builder.String()), nil
+		return builder.String()
+	})
 }
 
 func (s *FormationMCPServer) handleCreateDirectory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -740,8 +1560,57 @@ func (s *FormationMCPServer) handleCreateDirectory(ctx context.Context, request
 
 func (s *FormationMCPServer) handleUploadFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
+		Path      string                 `json:"path"`
+		Content   string                 `json:"content"`
+		Encoding  string                 `json:"encoding"`
+		SourceURL string                 `json:"source_url"`
+		Metadata  map[string]interface{} `json:"metadata"`
+	}
+	params.Encoding = "utf8" // default
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	if params.SourceURL != "" {
+		slog.Info("uploading file from source url", "path", params.Path, "source_url", params.SourceURL)
+
+		size, err := s.uploadFileFromURL(ctx, params.Path, params.SourceURL, params.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("✅ Uploaded file: %s (%d bytes, streamed from %s)", params.Path, size, params.SourceURL)), nil
+	}
+
+	content := params.Content
+	switch params.Encoding {
+	case "", "utf8":
+	case "base64":
+		decoded, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 content: %w", err)
+		}
+		content = string(decoded)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q: want \"utf8\" or \"base64\"", params.Encoding)
+	}
+
+	slog.Info("uploading file", "path", params.Path, "size", len(content))
+
+	if err := s.client.UploadFile(ctx, params.Path, content, params.Metadata); err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("✅ Uploaded file: %s (%d bytes)", params.Path, len(content))), nil
+}
+
+func (s *FormationMCPServer) handleUploadFileChunk(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		UploadID string                 `json:"upload_id"`
 		Path     string                 `json:"path"`
-		Content  string                 `json:"content"`
+		Offset   int64                  `json:"offset"`
+		Chunk    string                 `json:"chunk"`
+		IsFinal  bool                   `json:"is_final"`
 		Metadata map[string]interface{} `json:"metadata"`
 	}
 
@@ -749,60 +1618,231 @@ func (s *FormationMCPServer) handleUploadFile(ctx context.Context, request mcp.C
 		return nil, err
 	}
 
-	slog.Info("uploading file", "path", params.Path, "size", len(params.Content))
+	chunk, err := base64.StdEncoding.DecodeString(params.Chunk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 chunk: %w", err)
+	}
+
+	slog.Info("uploading file chunk", "upload_id", params.UploadID, "path", params.Path, "offset", params.Offset, "size", len(chunk), "is_final", params.IsFinal)
+
+	result, err := s.client.UploadFileChunk(ctx, params.UploadID, params.Path, params.Offset, chunk, params.IsFinal, params.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Complete {
+		return mcp.NewToolResultText(fmt.Sprintf("✅ Uploaded file: %s (%d bytes)", params.Path, result.Offset)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Chunk accepted. upload_id=`%s` offset=%d - send the next chunk with this upload_id and offset.", result.UploadID, result.Offset)), nil
+}
+
+func (s *FormationMCPServer) handleUploadFileFromPath(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		LocalPath string                 `json:"local_path"`
+		Path      string                 `json:"path"`
+		Metadata  map[string]interface{} `json:"metadata"`
+	}
+
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
 
-	if err := s.client.UploadFile(ctx, params.Path, params.Content, params.Metadata); err != nil {
+	slog.Info("uploading file from local path", "local_path", params.LocalPath, "path", params.Path)
+
+	f, err := os.Open(params.LocalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat local file: %w", err)
+	}
+
+	progressToken, hasProgressToken := progressTokenFromRequest(request)
+
+	opts := client.UploadOptions{Metadata: params.Metadata}
+	if hasProgressToken {
+		opts.OnProgress = func(written, total int64) {
+			if err := s.sendUploadProgress(ctx, progressToken, params.Path, written, total); err != nil {
+				slog.Warn("failed to send progress notification", "path", params.Path, "error", err)
+			}
+		}
+	}
+
+	if err := s.client.UploadFileStream(ctx, params.Path, f, info.Size(), opts); err != nil {
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("✅ Uploaded file: %s (%d bytes)", params.Path, len(params.Content))), nil
+	return mcp.NewToolResultText(fmt.Sprintf("✅ Uploaded file: %s (%d bytes)", params.Path, info.Size())), nil
+}
+
+// sendUploadProgress emits a notifications/progress message carrying bytes
+// uploaded so far for path, for the given progress token. It's invoked
+// from UploadOptions.OnProgress, which is already throttled, so every call
+// here corresponds to one notification worth sending.
+func (s *FormationMCPServer) sendUploadProgress(ctx context.Context, token mcp.ProgressToken, path string, written, total int64) error {
+	message := fmt.Sprintf("uploading %s: %d", path, written)
+	if total > 0 {
+		message = fmt.Sprintf("uploading %s: %d/%d bytes", path, written, total)
+	}
+
+	params := map[string]interface{}{
+		"progressToken": token,
+		"progress":      written,
+		"message":       message,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+
+	return s.server.SendNotificationToClient(ctx, "notifications/progress", params)
 }
 
+// handleSetMetadata reconciles path's metadata per params.Strategy. remove,
+// strategic, and json-patch all compute the full next state in-process from
+// a SnapshotMetadata read and then write it back with replace=true, the
+// same way findTrashMarkers notes SetMetadata can only add or replace AVUs,
+// not remove them - so against the real Formation API, an attribute that's
+// supposed to disappear may in practice just stay at its last value.
 func (s *FormationMCPServer) handleSetMetadata(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		Path     string                 `json:"path"`
-		Metadata map[string]interface{} `json:"metadata"`
-		Replace  bool                   `json:"replace"`
+		Path       string                 `json:"path"`
+		Metadata   map[string]interface{} `json:"metadata"`
+		Attributes []string               `json:"attributes"`
+		Patch      []jsonPatchOp          `json:"patch"`
+		Replace    bool                   `json:"replace"`
+		Strategy   string                 `json:"strategy"`
 	}
 
 	if err := unmarshalParams(request, &params); err != nil {
 		return nil, err
 	}
 
-	slog.Info("setting metadata", "path", params.Path, "replace", params.Replace)
+	strategy := metadataStrategy(params.Strategy)
+	if strategy == "" {
+		strategy = metadataStrategyMerge
+		if params.Replace {
+			strategy = metadataStrategyReplace
+		}
+	}
 
-	if err := s.client.SetMetadata(ctx, params.Path, params.Metadata, params.Replace); err != nil {
+	slog.Info("setting metadata", "path", params.Path, "strategy", strategy)
+
+	before, err := s.client.SnapshotMetadata(ctx, params.Path)
+	if err != nil {
 		return nil, err
 	}
 
-	action := "added to"
-	if params.Replace {
+	var after map[string]interface{}
+	var action string
+
+	switch strategy {
+	case metadataStrategyMerge:
+		after = overlayMetadata(before, params.Metadata)
+		if err := s.client.SetMetadata(ctx, params.Path, params.Metadata, false); err != nil {
+			return nil, err
+		}
+		action = "added to"
+
+	case metadataStrategyReplace:
+		after = cloneMetadataMap(params.Metadata)
+		if err := s.client.SetMetadata(ctx, params.Path, params.Metadata, true); err != nil {
+			return nil, err
+		}
 		action = "replaced on"
+
+	case metadataStrategyRemove:
+		if len(params.Attributes) == 0 {
+			return nil, fmt.Errorf("attributes is required when strategy is %q", strategy)
+		}
+		after = cloneMetadataMap(before)
+		for _, attr := range params.Attributes {
+			delete(after, attr)
+		}
+		if err := s.client.SetMetadata(ctx, params.Path, after, true); err != nil {
+			return nil, err
+		}
+		action = "removed from"
+
+	case metadataStrategyStrategic:
+		after = mergeStrategic(before, params.Metadata)
+		if err := s.client.SetMetadata(ctx, params.Path, after, true); err != nil {
+			return nil, err
+		}
+		action = "strategically merged into"
+
+	case metadataStrategyJSONPatch:
+		if len(params.Patch) == 0 {
+			return nil, fmt.Errorf("patch is required when strategy is %q", strategy)
+		}
+		patched, err := applyJSONPatch(before, params.Patch)
+		if err != nil {
+			return nil, err
+		}
+		after = patched
+		if err := s.client.SetMetadata(ctx, params.Path, after, true); err != nil {
+			return nil, err
+		}
+		action = "patched on"
+
+	default:
+		return nil, fmt.Errorf("unknown strategy %q (want merge, replace, remove, strategic, or json-patch)", strategy)
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("✅ Metadata %s: %s", action, params.Path)), nil
+	diff := diffMetadataAttrs(before, after)
+	return mcp.NewToolResultText(fmt.Sprintf("✅ Metadata %s: %s\n\n%s", action, params.Path, formatMetadataDiff(diff))), nil
 }
 
 func (s *FormationMCPServer) handleDeleteData(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
-		Path    string `json:"path"`
-		Recurse bool   `json:"recurse"`
-		DryRun  bool   `json:"dry_run"`
+		Path               string `json:"path"`
+		Recurse            bool   `json:"recurse"`
+		VersionID          string `json:"version_id"`
+		Purge              bool   `json:"purge"`
+		DryRun             bool   `json:"dry_run"`
+		GracePeriodSeconds *int   `json:"grace_period_seconds"`
 	}
 
 	if err := unmarshalParams(request, &params); err != nil {
 		return nil, err
 	}
 
-	slog.Info("deleting data", "path", params.Path, "recurse", params.Recurse, "dry_run", params.DryRun)
-
-	if err := s.client.DeleteData(ctx, params.Path, params.Recurse, params.DryRun); err != nil {
-		return nil, err
+	gracePeriod := s.defaultDeleteGracePeriodSeconds
+	if params.GracePeriodSeconds != nil {
+		gracePeriod = *params.GracePeriodSeconds
 	}
 
+	slog.Info("deleting data", "path", params.Path, "recurse", params.Recurse, "purge", params.Purge, "dry_run", params.DryRun, "grace_period_seconds", gracePeriod)
+
 	if params.DryRun {
-		return mcp.NewToolResultText(fmt.Sprintf("✅ Dry run: Would delete %s", params.Path)), nil
+		if err := s.client.DeleteData(ctx, params.Path, params.Recurse, true); err != nil {
+			return nil, err
+		}
+		if params.Purge {
+			return mcp.NewToolResultText(fmt.Sprintf("✅ Dry run: Would permanently delete %s", params.Path)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("✅ Dry run: Would move %s to trash", params.Path)), nil
+	}
+
+	if gracePeriod >= 0 {
+		job := s.deleteJobs.enqueue(params.Path, params.Recurse, params.Purge, params.VersionID, gracePeriod)
+		return mcp.NewToolResultText(fmt.Sprintf("⏳ Scheduled delete job %s for %s, executing at %s. Use get_delete_job/cancel_delete_job with job_id=%q in the meantime.", job.ID, params.Path, job.ExecuteAt.Format(time.RFC3339), job.ID)), nil
+	}
+
+	if params.Purge {
+		if err := s.client.DeleteData(ctx, params.Path, params.Recurse, false); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("✅ Deleted: %s", params.Path)), nil
+	}
+
+	marker, err := s.trashPath(ctx, params.Path, params.VersionID)
+	if err != nil {
+		return nil, err
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("✅ Deleted: %s", params.Path)), nil
+	return mcp.NewToolResultText(fmt.Sprintf("🗑️  Moved to trash: %s (marker %s). Use restore_data to undo, or delete_data again with purge=true to permanently remove it.", params.Path, marker.MarkerID)), nil
 }
@@ -2,28 +2,101 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/cyverse-de/formation-mcp/internal/audit"
 	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/cyverse-de/formation-mcp/internal/logging"
 	"github.com/cyverse-de/formation-mcp/internal/workflows"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // mockWorkflows implements workflows for testing
 type mockWorkflows struct {
-	launchAndWaitFunc      func(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, maxWait time.Duration) (*workflows.LaunchResult, error)
-	getRunningAnalysesFunc func(ctx context.Context) ([]client.Analysis, error)
-	stopAnalysisFunc       func(ctx context.Context, analysisID string, saveOutputs bool) error
-	openInBrowserFunc      func(url string) error
+	launchAndWaitFunc        func(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, resourceRequests *client.ResourceRequests, costGate *workflows.CostGate, maxWait time.Duration) (*workflows.LaunchResult, error)
+	launchAndWaitStreamFunc  func(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, resourceRequests *client.ResourceRequests, costGate *workflows.CostGate, maxWait time.Duration) (<-chan workflows.WorkflowEvent, error)
+	launchAndStreamFunc      func(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, maxWait time.Duration, onUpdate func(workflows.LaunchProgress) error) (*workflows.LaunchResult, error)
+	costEstimateFunc         func(ctx context.Context, appID, systemID string, config client.LaunchConfig) (*client.CostEstimate, error)
+	getRunningAnalysesFunc   func(ctx context.Context) ([]client.Analysis, error)
+	stopAnalysisFunc         func(ctx context.Context, analysisID string, saveOutputs, snapshotBeforeExit bool) error
+	openInBrowserFunc        func(url string) error
 	browseDataWithFormatFunc func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, bool, error)
+	snapshotAnalysisFunc     func(ctx context.Context, analysisID, label string) (*workflows.Snapshot, error)
+	resumeFromSnapshotFunc   func(ctx context.Context, snapshotID string, config client.LaunchConfig) (*workflows.LaunchResult, error)
+	listSnapshotsFunc        func(ctx context.Context) ([]workflows.Snapshot, error)
+	launchBatchFunc          func(ctx context.Context, appID, systemID string, baseConfig client.LaunchConfig, items []workflows.BatchItem, maxParallel int) (*workflows.Batch, error)
+	getBatchStatusFunc       func(ctx context.Context, batchID string) (*workflows.Batch, error)
 }
 
-func (m *mockWorkflows) LaunchAndWait(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, maxWait time.Duration) (*workflows.LaunchResult, error) {
+func (m *mockWorkflows) LaunchAndWait(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, resourceRequests *client.ResourceRequests, costGate *workflows.CostGate, maxWait time.Duration) (*workflows.LaunchResult, error) {
 	if m.launchAndWaitFunc != nil {
-		return m.launchAndWaitFunc(ctx, appID, systemID, name, config, maxWait)
+		return m.launchAndWaitFunc(ctx, appID, systemID, name, config, resourceRequests, costGate, maxWait)
+	}
+	return &workflows.LaunchResult{}, nil
+}
+
+// LaunchAndWaitStream, absent an explicit launchAndWaitStreamFunc, drives
+// LaunchAndWait and replays its outcome as the one terminal WorkflowEvent
+// that would have produced it, so the many existing launchAndWaitFunc-based
+// test cases don't all need a parallel event-sequence rewritten by hand.
+func (m *mockWorkflows) LaunchAndWaitStream(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, resourceRequests *client.ResourceRequests, costGate *workflows.CostGate, maxWait time.Duration) (<-chan workflows.WorkflowEvent, error) {
+	if m.launchAndWaitStreamFunc != nil {
+		return m.launchAndWaitStreamFunc(ctx, appID, systemID, name, config, resourceRequests, costGate, maxWait)
+	}
+
+	result, err := m.LaunchAndWait(ctx, appID, systemID, name, config, resourceRequests, costGate, maxWait)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan workflows.WorkflowEvent, 1)
+	events <- workflowEventFromResult(result)
+	close(events)
+	return events, nil
+}
+
+// workflowEventFromResult reconstructs the single terminal WorkflowEvent
+// that would have produced result, for mockWorkflows's default
+// LaunchAndWaitStream behavior.
+func workflowEventFromResult(result *workflows.LaunchResult) workflows.WorkflowEvent {
+	switch {
+	case len(result.MissingParams) > 0:
+		return workflows.WorkflowEvent{Type: workflows.EventFailed, MissingParams: result.MissingParams}
+	case len(result.UnsupportedResources) > 0:
+		return workflows.WorkflowEvent{Type: workflows.EventFailed, UnsupportedResources: result.UnsupportedResources}
+	case result.CostExceeded != nil:
+		return workflows.WorkflowEvent{Type: workflows.EventFailed, CostExceeded: result.CostExceeded}
+	case result.URL != "":
+		return workflows.WorkflowEvent{Type: workflows.EventURLReady, AnalysisID: result.AnalysisID, Name: result.Name, Status: result.Status, IsInteractive: result.IsInteractive, URL: result.URL}
+	default:
+		return workflows.WorkflowEvent{Type: workflows.EventCompleted, AnalysisID: result.AnalysisID, Name: result.Name, Status: result.Status, IsInteractive: result.IsInteractive}
+	}
+}
+
+func (m *mockWorkflows) CostEstimate(ctx context.Context, appID, systemID string, config client.LaunchConfig) (*client.CostEstimate, error) {
+	if m.costEstimateFunc != nil {
+		return m.costEstimateFunc(ctx, appID, systemID, config)
+	}
+	return &client.CostEstimate{}, nil
+}
+
+func (m *mockWorkflows) LaunchAndStream(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, maxWait time.Duration, onUpdate func(workflows.LaunchProgress) error) (*workflows.LaunchResult, error) {
+	if m.launchAndStreamFunc != nil {
+		return m.launchAndStreamFunc(ctx, appID, systemID, name, config, maxWait, onUpdate)
 	}
 	return &workflows.LaunchResult{}, nil
 }
@@ -35,9 +108,9 @@ func (m *mockWorkflows) GetRunningAnalyses(ctx context.Context) ([]client.Analys
 	return []client.Analysis{}, nil
 }
 
-func (m *mockWorkflows) StopAnalysis(ctx context.Context, analysisID string, saveOutputs bool) error {
+func (m *mockWorkflows) StopAnalysis(ctx context.Context, analysisID string, saveOutputs, snapshotBeforeExit bool) error {
 	if m.stopAnalysisFunc != nil {
-		return m.stopAnalysisFunc(ctx, analysisID, saveOutputs)
+		return m.stopAnalysisFunc(ctx, analysisID, saveOutputs, snapshotBeforeExit)
 	}
 	return nil
 }
@@ -56,16 +129,66 @@ func (m *mockWorkflows) BrowseDataWithFormat(ctx context.Context, path string, o
 	return nil, false, nil
 }
 
+func (m *mockWorkflows) SnapshotAnalysis(ctx context.Context, analysisID, label string) (*workflows.Snapshot, error) {
+	if m.snapshotAnalysisFunc != nil {
+		return m.snapshotAnalysisFunc(ctx, analysisID, label)
+	}
+	return &workflows.Snapshot{}, nil
+}
+
+func (m *mockWorkflows) ResumeFromSnapshot(ctx context.Context, snapshotID string, config client.LaunchConfig) (*workflows.LaunchResult, error) {
+	if m.resumeFromSnapshotFunc != nil {
+		return m.resumeFromSnapshotFunc(ctx, snapshotID, config)
+	}
+	return &workflows.LaunchResult{}, nil
+}
+
+func (m *mockWorkflows) ListSnapshots(ctx context.Context) ([]workflows.Snapshot, error) {
+	if m.listSnapshotsFunc != nil {
+		return m.listSnapshotsFunc(ctx)
+	}
+	return []workflows.Snapshot{}, nil
+}
+
+func (m *mockWorkflows) LaunchBatch(ctx context.Context, appID, systemID string, baseConfig client.LaunchConfig, items []workflows.BatchItem, maxParallel int) (*workflows.Batch, error) {
+	if m.launchBatchFunc != nil {
+		return m.launchBatchFunc(ctx, appID, systemID, baseConfig, items, maxParallel)
+	}
+	return &workflows.Batch{}, nil
+}
+
+func (m *mockWorkflows) GetBatchStatus(ctx context.Context, batchID string) (*workflows.Batch, error) {
+	if m.getBatchStatusFunc != nil {
+		return m.getBatchStatusFunc(ctx, batchID)
+	}
+	return &workflows.Batch{}, nil
+}
+
 // mockClient implements FormationAPIClient for testing
 type mockClient struct {
 	listAppsFunc         func(ctx context.Context, name, integrator, description, jobType string, limit, offset int) ([]client.App, error)
 	getAppParametersFunc func(ctx context.Context, systemID, appID string) (*client.AppParameters, error)
 	getAnalysisStatusFunc func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error)
 	listAnalysesFunc     func(ctx context.Context, status string) ([]client.Analysis, error)
+	browseDataFunc       func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error)
 	createDirectoryFunc  func(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error)
 	uploadFileFunc       func(ctx context.Context, path, content string, metadata map[string]interface{}) error
 	setMetadataFunc      func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error
+	snapshotMetadataFunc func(ctx context.Context, path string) (map[string]interface{}, error)
+	searchMetadataFunc   func(ctx context.Context, query client.MetadataQuery) (*client.MetadataSearchResult, error)
 	deleteDataFunc       func(ctx context.Context, path string, recurse, dryRun bool) error
+	uploadFileStreamFunc   func(ctx context.Context, path string, r io.Reader, size int64, opts client.UploadOptions) error
+	uploadFileChunkFunc    func(ctx context.Context, uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*client.UploadChunkResult, error)
+	estimateCostFunc       func(ctx context.Context, systemID, appID string, config client.LaunchConfig) (*client.CostEstimate, error)
+	downloadFileStreamFunc func(ctx context.Context, path string, w io.Writer, onProgress func(n, total int64)) error
+	shutdownFunc           func(ctx context.Context) error
+	lastActivityFunc       func() time.Time
+	createAnalysisAlertFunc func(ctx context.Context, alert client.AnalysisAlert) (*client.AnalysisAlert, error)
+	listAnalysisAlertsFunc  func(ctx context.Context, analysisID string) ([]client.AnalysisAlert, error)
+	deleteAnalysisAlertFunc func(ctx context.Context, alertID string) error
+	downloadFileRangeFunc   func(ctx context.Context, path string, offset, length int64) (io.ReadCloser, *client.FileMetadata, error)
+	streamAnalysisLogsFunc  func(ctx context.Context, analysisID string, opts client.LogStreamOptions) (<-chan client.LogLine, error)
+	watchAnalysisFunc       func(ctx context.Context, analysisID string) (<-chan client.AnalysisEvent, error)
 }
 
 func (m *mockClient) Login(ctx context.Context) error { return nil }
@@ -88,6 +211,13 @@ func (m *mockClient) LaunchApp(ctx context.Context, systemID, appID string, subm
 	return &client.LaunchResponse{}, nil
 }
 
+func (m *mockClient) EstimateCost(ctx context.Context, systemID, appID string, config client.LaunchConfig) (*client.CostEstimate, error) {
+	if m.estimateCostFunc != nil {
+		return m.estimateCostFunc(ctx, systemID, appID, config)
+	}
+	return &client.CostEstimate{}, nil
+}
+
 func (m *mockClient) GetAnalysisStatus(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
 	if m.getAnalysisStatusFunc != nil {
 		return m.getAnalysisStatusFunc(ctx, analysisID)
@@ -107,6 +237,9 @@ func (m *mockClient) ControlAnalysis(ctx context.Context, analysisID, operation
 }
 
 func (m *mockClient) BrowseData(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+	if m.browseDataFunc != nil {
+		return m.browseDataFunc(ctx, path, offset, limit, includeMetadata)
+	}
 	return nil, nil
 }
 
@@ -131,6 +264,20 @@ func (m *mockClient) SetMetadata(ctx context.Context, path string, metadata map[
 	return nil
 }
 
+func (m *mockClient) SnapshotMetadata(ctx context.Context, path string) (map[string]interface{}, error) {
+	if m.snapshotMetadataFunc != nil {
+		return m.snapshotMetadataFunc(ctx, path)
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (m *mockClient) SearchMetadata(ctx context.Context, query client.MetadataQuery) (*client.MetadataSearchResult, error) {
+	if m.searchMetadataFunc != nil {
+		return m.searchMetadataFunc(ctx, query)
+	}
+	return &client.MetadataSearchResult{}, nil
+}
+
 func (m *mockClient) DeleteData(ctx context.Context, path string, recurse, dryRun bool) error {
 	if m.deleteDataFunc != nil {
 		return m.deleteDataFunc(ctx, path, recurse, dryRun)
@@ -138,6 +285,93 @@ func (m *mockClient) DeleteData(ctx context.Context, path string, recurse, dryRu
 	return nil
 }
 
+func (m *mockClient) Shutdown(ctx context.Context) error {
+	if m.shutdownFunc != nil {
+		return m.shutdownFunc(ctx)
+	}
+	return nil
+}
+
+func (m *mockClient) LastActivity() time.Time {
+	if m.lastActivityFunc != nil {
+		return m.lastActivityFunc()
+	}
+	return time.Time{}
+}
+
+func (m *mockClient) WatchAnalysis(ctx context.Context, analysisID string) (<-chan client.AnalysisEvent, error) {
+	if m.watchAnalysisFunc != nil {
+		return m.watchAnalysisFunc(ctx, analysisID)
+	}
+	ch := make(chan client.AnalysisEvent)
+	close(ch)
+	return ch, nil
+}
+
+func (m *mockClient) WatchAnalyses(ctx context.Context, filter string) (<-chan client.AnalysisEvent, error) {
+	ch := make(chan client.AnalysisEvent)
+	close(ch)
+	return ch, nil
+}
+
+func (m *mockClient) StreamAnalysisLogs(ctx context.Context, analysisID string, opts client.LogStreamOptions) (<-chan client.LogLine, error) {
+	if m.streamAnalysisLogsFunc != nil {
+		return m.streamAnalysisLogsFunc(ctx, analysisID, opts)
+	}
+	ch := make(chan client.LogLine)
+	close(ch)
+	return ch, nil
+}
+
+func (m *mockClient) UploadFileStream(ctx context.Context, path string, r io.Reader, size int64, opts client.UploadOptions) error {
+	if m.uploadFileStreamFunc != nil {
+		return m.uploadFileStreamFunc(ctx, path, r, size, opts)
+	}
+	return nil
+}
+
+func (m *mockClient) UploadFileChunk(ctx context.Context, uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*client.UploadChunkResult, error) {
+	if m.uploadFileChunkFunc != nil {
+		return m.uploadFileChunkFunc(ctx, uploadID, path, offset, chunk, isFinal, metadata)
+	}
+	return &client.UploadChunkResult{}, nil
+}
+
+func (m *mockClient) DownloadFileStream(ctx context.Context, path string, w io.Writer, onProgress func(n, total int64)) error {
+	if m.downloadFileStreamFunc != nil {
+		return m.downloadFileStreamFunc(ctx, path, w, onProgress)
+	}
+	return nil
+}
+
+func (m *mockClient) CreateAnalysisAlert(ctx context.Context, alert client.AnalysisAlert) (*client.AnalysisAlert, error) {
+	if m.createAnalysisAlertFunc != nil {
+		return m.createAnalysisAlertFunc(ctx, alert)
+	}
+	return &client.AnalysisAlert{}, nil
+}
+
+func (m *mockClient) ListAnalysisAlerts(ctx context.Context, analysisID string) ([]client.AnalysisAlert, error) {
+	if m.listAnalysisAlertsFunc != nil {
+		return m.listAnalysisAlertsFunc(ctx, analysisID)
+	}
+	return []client.AnalysisAlert{}, nil
+}
+
+func (m *mockClient) DeleteAnalysisAlert(ctx context.Context, alertID string) error {
+	if m.deleteAnalysisAlertFunc != nil {
+		return m.deleteAnalysisAlertFunc(ctx, alertID)
+	}
+	return nil
+}
+
+func (m *mockClient) DownloadFileRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, *client.FileMetadata, error) {
+	if m.downloadFileRangeFunc != nil {
+		return m.downloadFileRangeFunc(ctx, path, offset, length)
+	}
+	return io.NopCloser(strings.NewReader("")), &client.FileMetadata{}, nil
+}
+
 // TestHandleListApps tests the list_apps handler
 func TestHandleListApps(t *testing.T) {
 	mockApps := []client.App{
@@ -165,42 +399,104 @@ func TestHandleListApps(t *testing.T) {
 	mockWorkflowsImpl := &mockWorkflows{}
 	server := NewFormationMCPServer(mockWorkflowsImpl, mockClientImpl)
 
-	request := mcp.CallToolRequest{
-		Params: mcp.CallToolParams{
-			Name: "list_apps",
-			Arguments: map[string]interface{}{
-				"limit":  10,
-				"offset": 0,
+	t.Run("text format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "list_apps",
+				Arguments: map[string]interface{}{
+					"limit":  10,
+					"offset": 0,
+				},
 			},
-		},
-	}
+		}
 
-	result, err := server.handleListApps(context.Background(), request)
-	if err != nil {
-		t.Errorf("handleListApps() unexpected error = %v", err)
-	}
+		result, err := server.handleListApps(context.Background(), request)
+		if err != nil {
+			t.Errorf("handleListApps() unexpected error = %v", err)
+		}
 
-	if result == nil {
-		t.Fatal("handleListApps() returned nil result")
-	}
+		if result == nil {
+			t.Fatal("handleListApps() returned nil result")
+		}
 
-	// Verify result contains expected app information
-	if len(result.Content) == 0 {
-		t.Error("handleListApps() returned empty content")
-	}
+		// Verify result contains expected app information
+		if len(result.Content) == 0 {
+			t.Error("handleListApps() returned empty content")
+		}
 
-	textContent, ok := result.Content[0].(mcp.TextContent)
-	if !ok {
-		t.Fatal("handleListApps() result is not text content")
-	}
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			t.Fatal("handleListApps() result is not text content")
+		}
 
-	content := textContent.Text
-	if !strings.Contains(content, "Test App 1") {
-		t.Error("handleListApps() result doesn't contain app name")
-	}
-	if !strings.Contains(content, "app-1") {
-		t.Error("handleListApps() result doesn't contain app ID")
-	}
+		content := textContent.Text
+		if !strings.Contains(content, "Test App 1") {
+			t.Error("handleListApps() result doesn't contain app name")
+		}
+		if !strings.Contains(content, "app-1") {
+			t.Error("handleListApps() result doesn't contain app ID")
+		}
+	})
+
+	t.Run("json format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "list_apps",
+				Arguments: map[string]interface{}{
+					"response_format": "json",
+				},
+			},
+		}
+
+		result, err := server.handleListApps(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleListApps() unexpected error = %v", err)
+		}
+
+		resource, ok := result.Content[1].(mcp.EmbeddedResource)
+		if !ok {
+			t.Fatal("handleListApps() json format result is not an embedded resource")
+		}
+		textResource, ok := resource.Resource.(mcp.TextResourceContents)
+		if !ok {
+			t.Fatal("handleListApps() json resource is not text resource contents")
+		}
+		if textResource.MIMEType != "application/json" {
+			t.Errorf("MIMEType = %q, want application/json", textResource.MIMEType)
+		}
+
+		var apps []client.App
+		if err := json.Unmarshal([]byte(textResource.Text), &apps); err != nil {
+			t.Fatalf("failed to unmarshal json resource: %v", err)
+		}
+		if len(apps) != 2 || apps[0].ID != "app-1" {
+			t.Errorf("unmarshaled apps = %+v, want the two mock apps", apps)
+		}
+	})
+
+	t.Run("resource format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "list_apps",
+				Arguments: map[string]interface{}{
+					"response_format": "resource",
+				},
+			},
+		}
+
+		result, err := server.handleListApps(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleListApps() unexpected error = %v", err)
+		}
+
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			t.Fatal("handleListApps() resource format result is not text content")
+		}
+		if !strings.Contains(textContent.Text, "formation://apps") {
+			t.Errorf("handleListApps() resource result = %q, want it to reference the formation://apps URI", textContent.Text)
+		}
+	})
 }
 
 // TestHandleGetAppParameters tests the get_app_parameters handler
@@ -235,46 +531,114 @@ func TestHandleGetAppParameters(t *testing.T) {
 	mockWorkflowsImpl := &mockWorkflows{}
 	server := NewFormationMCPServer(mockWorkflowsImpl, mockClientImpl)
 
-	request := mcp.CallToolRequest{
-		Params: mcp.CallToolParams{
-			Name: "get_app_parameters",
-			Arguments: map[string]interface{}{
-				"app_id":    "test-app",
-				"system_id": "de",
+	t.Run("text format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "get_app_parameters",
+				Arguments: map[string]interface{}{
+					"app_id":    "test-app",
+					"system_id": "de",
+				},
 			},
-		},
-	}
+		}
 
-	result, err := server.handleGetAppParameters(context.Background(), request)
-	if err != nil {
-		t.Errorf("handleGetAppParameters() unexpected error = %v", err)
-	}
+		result, err := server.handleGetAppParameters(context.Background(), request)
+		if err != nil {
+			t.Errorf("handleGetAppParameters() unexpected error = %v", err)
+		}
 
-	if result == nil {
-		t.Fatal("handleGetAppParameters() returned nil result")
-	}
+		if result == nil {
+			t.Fatal("handleGetAppParameters() returned nil result")
+		}
 
-	textContent, ok := result.Content[0].(mcp.TextContent)
-	if !ok {
-		t.Fatal("handleGetAppParameters() result is not text content")
-	}
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			t.Fatal("handleGetAppParameters() result is not text content")
+		}
 
-	content := textContent.Text
-	if !strings.Contains(content, "Interactive") {
-		t.Error("handleGetAppParameters() result doesn't contain job type")
-	}
-	if !strings.Contains(content, "Input File") {
-		t.Error("handleGetAppParameters() result doesn't contain parameter label")
-	}
+		content := textContent.Text
+		if !strings.Contains(content, "Interactive") {
+			t.Error("handleGetAppParameters() result doesn't contain job type")
+		}
+		if !strings.Contains(content, "Input File") {
+			t.Error("handleGetAppParameters() result doesn't contain parameter label")
+		}
+	})
+
+	t.Run("json format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "get_app_parameters",
+				Arguments: map[string]interface{}{
+					"app_id":          "test-app",
+					"system_id":       "de",
+					"response_format": "json",
+				},
+			},
+		}
+
+		result, err := server.handleGetAppParameters(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleGetAppParameters() unexpected error = %v", err)
+		}
+
+		resource, ok := result.Content[1].(mcp.EmbeddedResource)
+		if !ok {
+			t.Fatal("handleGetAppParameters() json format result is not an embedded resource")
+		}
+		textResource, ok := resource.Resource.(mcp.TextResourceContents)
+		if !ok {
+			t.Fatal("handleGetAppParameters() json resource is not text resource contents")
+		}
+		if textResource.URI != "formation://apps/de/test-app" {
+			t.Errorf("resource URI = %q, want formation://apps/de/test-app", textResource.URI)
+		}
+
+		var appParams client.AppParameters
+		if err := json.Unmarshal([]byte(textResource.Text), &appParams); err != nil {
+			t.Fatalf("failed to unmarshal json resource: %v", err)
+		}
+		if appParams.OverallJobType != "Interactive" {
+			t.Errorf("unmarshaled job type = %q, want Interactive", appParams.OverallJobType)
+		}
+	})
+
+	t.Run("resource format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "get_app_parameters",
+				Arguments: map[string]interface{}{
+					"app_id":          "test-app",
+					"system_id":       "de",
+					"response_format": "resource",
+				},
+			},
+		}
+
+		result, err := server.handleGetAppParameters(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleGetAppParameters() unexpected error = %v", err)
+		}
+
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			t.Fatal("handleGetAppParameters() resource format result is not text content")
+		}
+		if !strings.Contains(textContent.Text, "formation://apps/de/test-app") {
+			t.Errorf("handleGetAppParameters() resource result = %q, want it to reference the app's URI", textContent.Text)
+		}
+	})
 }
 
 // TestHandleLaunchAppAndWait tests the launch_app_and_wait handler
 func TestHandleLaunchAppAndWait(t *testing.T) {
 	tests := []struct {
-		name              string
-		launchResult      *workflows.LaunchResult
-		expectSuccess     bool
-		expectMissingParams bool
+		name                       string
+		launchResult               *workflows.LaunchResult
+		expectSuccess              bool
+		expectMissingParams        bool
+		expectUnsupportedResources bool
+		expectCostExceeded         bool
 	}{
 		{
 			name: "successful launch",
@@ -294,12 +658,29 @@ func TestHandleLaunchAppAndWait(t *testing.T) {
 			},
 			expectMissingParams: true,
 		},
+		{
+			name: "unsupported resource request",
+			launchResult: &workflows.LaunchResult{
+				UnsupportedResources: []string{"gpu"},
+			},
+			expectUnsupportedResources: true,
+		},
+		{
+			name: "cost exceeded",
+			launchResult: &workflows.LaunchResult{
+				CostExceeded: &workflows.CostExceeded{
+					Estimate: client.CostEstimate{EstimatedCost: 10},
+					MaxCost:  5,
+				},
+			},
+			expectCostExceeded: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockWorkflowsImpl := &mockWorkflows{
-				launchAndWaitFunc: func(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, maxWait time.Duration) (*workflows.LaunchResult, error) {
+				launchAndWaitFunc: func(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, resourceRequests *client.ResourceRequests, costGate *workflows.CostGate, maxWait time.Duration) (*workflows.LaunchResult, error) {
 					return tt.launchResult, nil
 				},
 			}
@@ -349,10 +730,107 @@ func TestHandleLaunchAppAndWait(t *testing.T) {
 					t.Error("handleLaunchAppAndWait() result doesn't indicate missing params")
 				}
 			}
+
+			if tt.expectUnsupportedResources {
+				if !strings.Contains(content, "Unsupported Resource Requests") {
+					t.Error("handleLaunchAppAndWait() result doesn't indicate unsupported resources")
+				}
+			}
+
+			if tt.expectCostExceeded {
+				if !strings.Contains(content, "Estimated Cost Exceeds max_cost") {
+					t.Error("handleLaunchAppAndWait() result doesn't indicate cost exceeded")
+				}
+			}
 		})
 	}
 }
 
+// TestHandleLaunchAppAndStream tests the launch_app_and_stream handler's
+// progress-update plumbing and its propagation of cancellation errors.
+// LaunchAndStream's own polling and stop-on-cancel behavior is covered in
+// the workflows package; here we only verify the handler wires onUpdate and
+// the request context through correctly.
+func TestHandleLaunchAppAndStream(t *testing.T) {
+	t.Run("forwards progress updates to the caller's update callback", func(t *testing.T) {
+		mockWorkflowsImpl := &mockWorkflows{
+			launchAndStreamFunc: func(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, maxWait time.Duration, onUpdate func(workflows.LaunchProgress) error) (*workflows.LaunchResult, error) {
+				if err := onUpdate(workflows.LaunchProgress{AnalysisID: "analysis-456", Status: "Running"}); err != nil {
+					return nil, err
+				}
+				if err := onUpdate(workflows.LaunchProgress{AnalysisID: "analysis-456", Status: "Running", URLReady: true, URL: "https://test.cyverse.run"}); err != nil {
+					return nil, err
+				}
+				return &workflows.LaunchResult{AnalysisID: "analysis-456", Name: "test-analysis", Status: "Running", IsInteractive: true, URL: "https://test.cyverse.run"}, nil
+			},
+		}
+
+		server := NewFormationMCPServer(mockWorkflowsImpl, &mockClient{})
+
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "launch_app_and_stream",
+				Arguments: map[string]interface{}{
+					"app_id":    "test-app",
+					"system_id": "de",
+					"name":      "test-analysis",
+				},
+			},
+		}
+
+		// Without a progress token, the handler's onUpdate is a no-op, so
+		// drive the mock directly to confirm the callback never aborts the
+		// stream before the result is returned.
+		result, err := server.handleLaunchAppAndStream(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleLaunchAppAndStream() unexpected error = %v", err)
+		}
+		if result == nil {
+			t.Fatal("handleLaunchAppAndStream() returned nil result")
+		}
+
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			t.Fatal("handleLaunchAppAndStream() result is not text content")
+		}
+		if !strings.Contains(textContent.Text, "analysis-456") {
+			t.Error("handleLaunchAppAndStream() result doesn't contain analysis ID")
+		}
+		if !strings.Contains(textContent.Text, "https://test.cyverse.run") {
+			t.Error("handleLaunchAppAndStream() result doesn't contain the ready URL")
+		}
+	})
+
+	t.Run("propagates the workflow's cancellation error", func(t *testing.T) {
+		mockWorkflowsImpl := &mockWorkflows{
+			launchAndStreamFunc: func(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, maxWait time.Duration, onUpdate func(workflows.LaunchProgress) error) (*workflows.LaunchResult, error) {
+				return nil, ctx.Err()
+			},
+		}
+
+		server := NewFormationMCPServer(mockWorkflowsImpl, &mockClient{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "launch_app_and_stream",
+				Arguments: map[string]interface{}{
+					"app_id":    "test-app",
+					"system_id": "de",
+					"name":      "test-analysis",
+				},
+			},
+		}
+
+		_, err := server.handleLaunchAppAndStream(ctx, request)
+		if err != context.Canceled {
+			t.Errorf("handleLaunchAppAndStream() error = %v, want context.Canceled", err)
+		}
+	})
+}
+
 // TestHandleGetAnalysisStatus tests the get_analysis_status handler
 func TestHandleGetAnalysisStatus(t *testing.T) {
 	mockStatus := &client.AnalysisStatus{
@@ -370,36 +848,100 @@ func TestHandleGetAnalysisStatus(t *testing.T) {
 
 	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
 
-	request := mcp.CallToolRequest{
-		Params: mcp.CallToolParams{
-			Name: "get_analysis_status",
-			Arguments: map[string]interface{}{
-				"analysis_id": "analysis-123",
+	t.Run("text format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "get_analysis_status",
+				Arguments: map[string]interface{}{
+					"analysis_id": "analysis-123",
+				},
 			},
-		},
-	}
+		}
 
-	result, err := server.handleGetAnalysisStatus(context.Background(), request)
-	if err != nil {
-		t.Errorf("handleGetAnalysisStatus() unexpected error = %v", err)
-	}
+		result, err := server.handleGetAnalysisStatus(context.Background(), request)
+		if err != nil {
+			t.Errorf("handleGetAnalysisStatus() unexpected error = %v", err)
+		}
 
-	if result == nil {
-		t.Fatal("handleGetAnalysisStatus() returned nil result")
-	}
+		if result == nil {
+			t.Fatal("handleGetAnalysisStatus() returned nil result")
+		}
 
-	textContent, ok := result.Content[0].(mcp.TextContent)
-	if !ok {
-		t.Fatal("handleGetAnalysisStatus() result is not text content")
-	}
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			t.Fatal("handleGetAnalysisStatus() result is not text content")
+		}
 
-	content := textContent.Text
-	if !strings.Contains(content, "analysis-123") {
-		t.Error("handleGetAnalysisStatus() result doesn't contain analysis ID")
-	}
-	if !strings.Contains(content, "Running") {
-		t.Error("handleGetAnalysisStatus() result doesn't contain status")
-	}
+		content := textContent.Text
+		if !strings.Contains(content, "analysis-123") {
+			t.Error("handleGetAnalysisStatus() result doesn't contain analysis ID")
+		}
+		if !strings.Contains(content, "Running") {
+			t.Error("handleGetAnalysisStatus() result doesn't contain status")
+		}
+	})
+
+	t.Run("json format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "get_analysis_status",
+				Arguments: map[string]interface{}{
+					"analysis_id":     "analysis-123",
+					"response_format": "json",
+				},
+			},
+		}
+
+		result, err := server.handleGetAnalysisStatus(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleGetAnalysisStatus() unexpected error = %v", err)
+		}
+
+		resource, ok := result.Content[1].(mcp.EmbeddedResource)
+		if !ok {
+			t.Fatal("handleGetAnalysisStatus() json format result is not an embedded resource")
+		}
+		textResource, ok := resource.Resource.(mcp.TextResourceContents)
+		if !ok {
+			t.Fatal("handleGetAnalysisStatus() json resource is not text resource contents")
+		}
+		if textResource.URI != "formation://analyses/analysis-123" {
+			t.Errorf("resource URI = %q, want formation://analyses/analysis-123", textResource.URI)
+		}
+
+		var status client.AnalysisStatus
+		if err := json.Unmarshal([]byte(textResource.Text), &status); err != nil {
+			t.Fatalf("failed to unmarshal json resource: %v", err)
+		}
+		if status.AnalysisID != "analysis-123" {
+			t.Errorf("unmarshaled analysis id = %q, want analysis-123", status.AnalysisID)
+		}
+	})
+
+	t.Run("resource format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "get_analysis_status",
+				Arguments: map[string]interface{}{
+					"analysis_id":     "analysis-123",
+					"response_format": "resource",
+				},
+			},
+		}
+
+		result, err := server.handleGetAnalysisStatus(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleGetAnalysisStatus() unexpected error = %v", err)
+		}
+
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			t.Fatal("handleGetAnalysisStatus() resource format result is not text content")
+		}
+		if !strings.Contains(textContent.Text, "formation://analyses/analysis-123") {
+			t.Errorf("handleGetAnalysisStatus() resource result = %q, want it to reference the analysis's URI", textContent.Text)
+		}
+	})
 }
 
 // TestHandleListRunningAnalyses tests the list_running_analyses handler
@@ -427,36 +969,97 @@ func TestHandleListRunningAnalyses(t *testing.T) {
 
 	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
 
-	request := mcp.CallToolRequest{
-		Params: mcp.CallToolParams{
-			Name: "list_running_analyses",
-			Arguments: map[string]interface{}{
-				"status": "Running",
+	t.Run("text format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "list_running_analyses",
+				Arguments: map[string]interface{}{
+					"status": "Running",
+				},
 			},
-		},
-	}
+		}
 
-	result, err := server.handleListRunningAnalyses(context.Background(), request)
-	if err != nil {
-		t.Errorf("handleListRunningAnalyses() unexpected error = %v", err)
-	}
+		result, err := server.handleListRunningAnalyses(context.Background(), request)
+		if err != nil {
+			t.Errorf("handleListRunningAnalyses() unexpected error = %v", err)
+		}
 
-	if result == nil {
-		t.Fatal("handleListRunningAnalyses() returned nil result")
-	}
+		if result == nil {
+			t.Fatal("handleListRunningAnalyses() returned nil result")
+		}
 
-	textContent, ok := result.Content[0].(mcp.TextContent)
-	if !ok {
-		t.Fatal("handleListRunningAnalyses() result is not text content")
-	}
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			t.Fatal("handleListRunningAnalyses() result is not text content")
+		}
 
-	content := textContent.Text
-	if !strings.Contains(content, "analysis-1") {
-		t.Error("handleListRunningAnalyses() result doesn't contain first analysis")
-	}
-	if !strings.Contains(content, "analysis-2") {
-		t.Error("handleListRunningAnalyses() result doesn't contain second analysis")
-	}
+		content := textContent.Text
+		if !strings.Contains(content, "analysis-1") {
+			t.Error("handleListRunningAnalyses() result doesn't contain first analysis")
+		}
+		if !strings.Contains(content, "analysis-2") {
+			t.Error("handleListRunningAnalyses() result doesn't contain second analysis")
+		}
+	})
+
+	t.Run("json format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "list_running_analyses",
+				Arguments: map[string]interface{}{
+					"status":          "Running",
+					"response_format": "json",
+				},
+			},
+		}
+
+		result, err := server.handleListRunningAnalyses(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleListRunningAnalyses() unexpected error = %v", err)
+		}
+
+		resource, ok := result.Content[1].(mcp.EmbeddedResource)
+		if !ok {
+			t.Fatal("handleListRunningAnalyses() json format result is not an embedded resource")
+		}
+		textResource, ok := resource.Resource.(mcp.TextResourceContents)
+		if !ok {
+			t.Fatal("handleListRunningAnalyses() json resource is not text resource contents")
+		}
+
+		var analyses []client.Analysis
+		if err := json.Unmarshal([]byte(textResource.Text), &analyses); err != nil {
+			t.Fatalf("failed to unmarshal json resource: %v", err)
+		}
+		if len(analyses) != 2 || analyses[0].AnalysisID != "analysis-1" {
+			t.Errorf("unmarshaled analyses = %+v, want the two mock analyses", analyses)
+		}
+	})
+
+	t.Run("resource format", func(t *testing.T) {
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "list_running_analyses",
+				Arguments: map[string]interface{}{
+					"status":          "Running",
+					"response_format": "resource",
+				},
+			},
+		}
+
+		result, err := server.handleListRunningAnalyses(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleListRunningAnalyses() unexpected error = %v", err)
+		}
+
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			t.Fatal("handleListRunningAnalyses() resource format result is not text content")
+		}
+		if !strings.Contains(textContent.Text, "formation://analyses?status=Running") {
+			t.Errorf("handleListRunningAnalyses() resource result = %q, want it to reference the analyses URI", textContent.Text)
+		}
+	})
 }
 
 // TestHandleStopAnalysis tests the stop_analysis handler
@@ -465,7 +1068,7 @@ func TestHandleStopAnalysis(t *testing.T) {
 	var capturedSaveOutputs bool
 
 	mockWorkflowsImpl := &mockWorkflows{
-		stopAnalysisFunc: func(ctx context.Context, analysisID string, saveOutputs bool) error {
+		stopAnalysisFunc: func(ctx context.Context, analysisID string, saveOutputs, snapshotBeforeExit bool) error {
 			capturedAnalysisID = analysisID
 			capturedSaveOutputs = saveOutputs
 			return nil
@@ -511,6 +1114,360 @@ func TestHandleStopAnalysis(t *testing.T) {
 	}
 }
 
+// TestHandleSnapshotAnalysis tests the snapshot_analysis handler
+func TestHandleSnapshotAnalysis(t *testing.T) {
+	var capturedAnalysisID, capturedLabel string
+
+	mockWorkflowsImpl := &mockWorkflows{
+		snapshotAnalysisFunc: func(ctx context.Context, analysisID, label string) (*workflows.Snapshot, error) {
+			capturedAnalysisID = analysisID
+			capturedLabel = label
+			return &workflows.Snapshot{ID: "analysis-123-456", AnalysisID: analysisID, AppID: "app-1", Label: label}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(mockWorkflowsImpl, &mockClient{})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "snapshot_analysis",
+			Arguments: map[string]interface{}{
+				"analysis_id": "analysis-123",
+				"label":       "end of day",
+			},
+		},
+	}
+
+	result, err := server.handleSnapshotAnalysis(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleSnapshotAnalysis() unexpected error = %v", err)
+	}
+
+	if capturedAnalysisID != "analysis-123" {
+		t.Errorf("handleSnapshotAnalysis() called with analysis_id = %v, want analysis-123", capturedAnalysisID)
+	}
+	if capturedLabel != "end of day" {
+		t.Errorf("handleSnapshotAnalysis() called with label = %v, want %q", capturedLabel, "end of day")
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatal("handleSnapshotAnalysis() result is not text content")
+	}
+	if !strings.Contains(textContent.Text, "analysis-123-456") {
+		t.Errorf("handleSnapshotAnalysis() result = %q, want it to contain the snapshot ID", textContent.Text)
+	}
+}
+
+// TestHandleResumeFromSnapshot tests the resume_from_snapshot handler
+func TestHandleResumeFromSnapshot(t *testing.T) {
+	var capturedSnapshotID string
+	var capturedConfig client.LaunchConfig
+
+	mockWorkflowsImpl := &mockWorkflows{
+		resumeFromSnapshotFunc: func(ctx context.Context, snapshotID string, config client.LaunchConfig) (*workflows.LaunchResult, error) {
+			capturedSnapshotID = snapshotID
+			capturedConfig = config
+			return &workflows.LaunchResult{AnalysisID: "analysis-resumed"}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(mockWorkflowsImpl, &mockClient{})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "resume_from_snapshot",
+			Arguments: map[string]interface{}{
+				"snapshot_id": "analysis-123-456",
+				"config":      map[string]interface{}{"override": true},
+			},
+		},
+	}
+
+	result, err := server.handleResumeFromSnapshot(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleResumeFromSnapshot() unexpected error = %v", err)
+	}
+	if result == nil {
+		t.Fatal("handleResumeFromSnapshot() returned nil result")
+	}
+
+	if capturedSnapshotID != "analysis-123-456" {
+		t.Errorf("handleResumeFromSnapshot() called with snapshot_id = %v, want analysis-123-456", capturedSnapshotID)
+	}
+	if override, _ := capturedConfig["override"].(bool); !override {
+		t.Errorf("handleResumeFromSnapshot() called with config = %+v, want override = true", capturedConfig)
+	}
+}
+
+// TestHandleListSnapshots tests the list_snapshots handler
+func TestHandleListSnapshots(t *testing.T) {
+	mockWorkflowsImpl := &mockWorkflows{
+		listSnapshotsFunc: func(ctx context.Context) ([]workflows.Snapshot, error) {
+			return []workflows.Snapshot{
+				{ID: "analysis-1-123", AnalysisID: "analysis-1", AppID: "app-1", SystemID: "de"},
+			}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(mockWorkflowsImpl, &mockClient{})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "list_snapshots",
+			Arguments: map[string]interface{}{},
+		},
+	}
+
+	result, err := server.handleListSnapshots(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleListSnapshots() unexpected error = %v", err)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatal("handleListSnapshots() result is not text content")
+	}
+	if !strings.Contains(textContent.Text, "analysis-1-123") {
+		t.Errorf("handleListSnapshots() result = %q, want it to contain the snapshot ID", textContent.Text)
+	}
+}
+
+// TestHandleLaunchBatchInputsPath verifies that inputs_path is expanded to
+// one BatchItem per data object found under it, with input_param set to
+// each file's path.
+func TestHandleLaunchBatchInputsPath(t *testing.T) {
+	var capturedItems []workflows.BatchItem
+
+	mockClientImpl := &mockClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			return &client.DirectoryContents{
+				Path: path,
+				Type: "collection",
+				Contents: []client.DirectoryEntry{
+					{Name: "a.fastq", Type: "data_object"},
+					{Name: "subdir", Type: "collection"},
+					{Name: "b.fastq", Type: "data_object"},
+				},
+			}, nil
+		},
+	}
+	mockWorkflowsImpl := &mockWorkflows{
+		launchBatchFunc: func(ctx context.Context, appID, systemID string, baseConfig client.LaunchConfig, items []workflows.BatchItem, maxParallel int) (*workflows.Batch, error) {
+			capturedItems = items
+			return &workflows.Batch{ID: "batch-1", AppID: appID, SystemID: systemID}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(mockWorkflowsImpl, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "launch_batch",
+			Arguments: map[string]interface{}{
+				"app_id":      "app-1",
+				"inputs_path": "/iplant/home/me/reads",
+				"input_param": "input",
+			},
+		},
+	}
+
+	result, err := server.handleLaunchBatch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleLaunchBatch() unexpected error = %v", err)
+	}
+	if result == nil {
+		t.Fatal("handleLaunchBatch() returned nil result")
+	}
+
+	if len(capturedItems) != 2 {
+		t.Fatalf("expected 2 items (collections skipped), got %d: %+v", len(capturedItems), capturedItems)
+	}
+	if capturedItems[0].Config["input"] != "/iplant/home/me/reads/a.fastq" {
+		t.Errorf("item 0 config = %+v", capturedItems[0].Config)
+	}
+}
+
+// TestHandleLaunchBatchMatrix verifies that an inline matrix is passed
+// through as one BatchItem per entry.
+func TestHandleLaunchBatchMatrix(t *testing.T) {
+	var capturedItems []workflows.BatchItem
+
+	mockWorkflowsImpl := &mockWorkflows{
+		launchBatchFunc: func(ctx context.Context, appID, systemID string, baseConfig client.LaunchConfig, items []workflows.BatchItem, maxParallel int) (*workflows.Batch, error) {
+			capturedItems = items
+			return &workflows.Batch{ID: "batch-2", AppID: appID, SystemID: systemID}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(mockWorkflowsImpl, &mockClient{})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "launch_batch",
+			Arguments: map[string]interface{}{
+				"app_id": "app-1",
+				"matrix": []interface{}{
+					map[string]interface{}{"threshold": 1},
+					map[string]interface{}{"threshold": 2},
+				},
+			},
+		},
+	}
+
+	result, err := server.handleLaunchBatch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleLaunchBatch() unexpected error = %v", err)
+	}
+	if result == nil {
+		t.Fatal("handleLaunchBatch() returned nil result")
+	}
+	if len(capturedItems) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(capturedItems))
+	}
+}
+
+// TestHandleLaunchBatchRequiresInputs verifies that omitting both
+// inputs_path and matrix is rejected rather than submitting zero analyses.
+func TestHandleLaunchBatchRequiresInputs(t *testing.T) {
+	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "launch_batch",
+			Arguments: map[string]interface{}{"app_id": "app-1"},
+		},
+	}
+
+	if _, err := server.handleLaunchBatch(context.Background(), request); err == nil {
+		t.Error("handleLaunchBatch() expected an error when neither inputs_path nor matrix is set")
+	}
+}
+
+// TestHandleGetBatchStatus tests the get_batch_status handler
+func TestHandleGetBatchStatus(t *testing.T) {
+	mockWorkflowsImpl := &mockWorkflows{
+		getBatchStatusFunc: func(ctx context.Context, batchID string) (*workflows.Batch, error) {
+			return &workflows.Batch{
+				ID:    batchID,
+				AppID: "app-1",
+				Items: []workflows.BatchItemResult{
+					{Input: "/data/a.txt", AnalysisID: "analysis-a", Status: "Running"},
+				},
+			}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(mockWorkflowsImpl, &mockClient{})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "get_batch_status",
+			Arguments: map[string]interface{}{"batch_id": "batch-1"},
+		},
+	}
+
+	result, err := server.handleGetBatchStatus(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleGetBatchStatus() unexpected error = %v", err)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatal("handleGetBatchStatus() result is not text content")
+	}
+	if !strings.Contains(textContent.Text, "analysis-a") {
+		t.Errorf("handleGetBatchStatus() result = %q, want it to contain the analysis ID", textContent.Text)
+	}
+}
+
+func TestHandleListToolHistory(t *testing.T) {
+	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+
+	if _, err := server.auditLog.Record(context.Background(), audit.Record{Tool: "list_apps", ResultSummary: "2 apps"}); err != nil {
+		t.Fatalf("seeding audit record unexpected error = %v", err)
+	}
+	if _, err := server.auditLog.Record(context.Background(), audit.Record{Tool: "upload_file", Error: "boom"}); err != nil {
+		t.Fatalf("seeding audit record unexpected error = %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "list_tool_history",
+			Arguments: map[string]interface{}{"failed_only": true},
+		},
+	}
+
+	result, err := server.handleListToolHistory(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleListToolHistory() unexpected error = %v", err)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatal("handleListToolHistory() result is not text content")
+	}
+	if !strings.Contains(textContent.Text, "upload_file") || strings.Contains(textContent.Text, "list_apps") {
+		t.Errorf("handleListToolHistory() result = %q, want only the failed upload_file call", textContent.Text)
+	}
+}
+
+func TestHandleReplayToolCall(t *testing.T) {
+	mockClientImpl := &mockClient{
+		listAppsFunc: func(ctx context.Context, name, integrator, description, jobType string, limit, offset int) ([]client.App, error) {
+			return []client.App{{ID: "app-1", Name: name}}, nil
+		},
+	}
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	recorded, err := server.auditLog.Record(context.Background(), audit.Record{
+		ID:     "req-1",
+		Tool:   "list_apps",
+		Params: map[string]interface{}{"name": "Jupyter"},
+	})
+	if err != nil {
+		t.Fatalf("seeding audit record unexpected error = %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "replay_tool_call",
+			Arguments: map[string]interface{}{
+				"id":              recorded.ID,
+				"param_overrides": map[string]interface{}{"limit": 5},
+			},
+		},
+	}
+
+	result, err := server.handleReplayToolCall(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleReplayToolCall() unexpected error = %v", err)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatal("handleReplayToolCall() result is not text content")
+	}
+	if !strings.Contains(textContent.Text, "Jupyter") {
+		t.Errorf("handleReplayToolCall() result = %q, want it to contain Jupyter", textContent.Text)
+	}
+}
+
+func TestHandleReplayToolCallUnknownID(t *testing.T) {
+	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "replay_tool_call",
+			Arguments: map[string]interface{}{"id": "does-not-exist"},
+		},
+	}
+
+	if _, err := server.handleReplayToolCall(context.Background(), request); err == nil {
+		t.Fatal("handleReplayToolCall() expected an error for an unknown id")
+	}
+}
+
 // TestHandleOpenInBrowser tests the open_in_browser handler
 func TestHandleOpenInBrowser(t *testing.T) {
 	var capturedURL string
@@ -629,9 +1586,66 @@ func TestHandleBrowseData(t *testing.T) {
 					t.Error("handleBrowseData() file result doesn't contain content")
 				}
 			}
-		})
-	}
-}
+
+			t.Run("json format", func(t *testing.T) {
+				jsonRequest := mcp.CallToolRequest{
+					Params: mcp.CallToolParams{
+						Name: "browse_data",
+						Arguments: map[string]interface{}{
+							"path":            tt.path,
+							"response_format": "json",
+						},
+					},
+				}
+
+				result, err := server.handleBrowseData(context.Background(), jsonRequest)
+				if err != nil {
+					t.Fatalf("handleBrowseData() unexpected error = %v", err)
+				}
+
+				resource, ok := result.Content[1].(mcp.EmbeddedResource)
+				if !ok {
+					t.Fatal("handleBrowseData() json format result is not an embedded resource")
+				}
+				textResource, ok := resource.Resource.(mcp.TextResourceContents)
+				if !ok {
+					t.Fatal("handleBrowseData() json resource is not text resource contents")
+				}
+				if textResource.URI != dataResourceURI(tt.path) {
+					t.Errorf("resource URI = %q, want %q", textResource.URI, dataResourceURI(tt.path))
+				}
+				if !strings.Contains(textResource.Text, tt.path) {
+					t.Errorf("handleBrowseData() json resource doesn't contain path %v", tt.path)
+				}
+			})
+
+			t.Run("resource format", func(t *testing.T) {
+				resourceRequest := mcp.CallToolRequest{
+					Params: mcp.CallToolParams{
+						Name: "browse_data",
+						Arguments: map[string]interface{}{
+							"path":            tt.path,
+							"response_format": "resource",
+						},
+					},
+				}
+
+				result, err := server.handleBrowseData(context.Background(), resourceRequest)
+				if err != nil {
+					t.Fatalf("handleBrowseData() unexpected error = %v", err)
+				}
+
+				textContent, ok := result.Content[0].(mcp.TextContent)
+				if !ok {
+					t.Fatal("handleBrowseData() resource format result is not text content")
+				}
+				if !strings.Contains(textContent.Text, dataResourceURI(tt.path)) {
+					t.Errorf("handleBrowseData() resource result = %q, want it to reference %q", textContent.Text, dataResourceURI(tt.path))
+				}
+			})
+		})
+	}
+}
 
 // TestHandleCreateDirectory tests the create_directory handler
 func TestHandleCreateDirectory(t *testing.T) {
@@ -721,6 +1735,92 @@ func TestHandleUploadFile(t *testing.T) {
 	}
 }
 
+// TestHandleUploadFileBase64 tests that encoding: "base64" is decoded
+// before being handed to UploadFile.
+func TestHandleUploadFileBase64(t *testing.T) {
+	var capturedContent string
+
+	mockClientImpl := &mockClient{
+		uploadFileFunc: func(ctx context.Context, path, content string, metadata map[string]interface{}) error {
+			capturedContent = content
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "upload_file",
+			Arguments: map[string]interface{}{
+				"path":     "/cyverse/home/test/file.bin",
+				"content":  base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0x10}),
+				"encoding": "base64",
+			},
+		},
+	}
+
+	result, err := server.handleUploadFile(context.Background(), request)
+	if err != nil {
+		t.Errorf("handleUploadFile() unexpected error = %v", err)
+	}
+	if result == nil {
+		t.Fatal("handleUploadFile() returned nil result")
+	}
+
+	if capturedContent != string([]byte{0x00, 0xff, 0x10}) {
+		t.Errorf("handleUploadFile() called with content = %v, want decoded bytes", []byte(capturedContent))
+	}
+}
+
+// TestHandleUploadFileSourceURL tests that source_url is fetched and
+// streamed via UploadFileStream instead of UploadFile.
+func TestHandleUploadFileSourceURL(t *testing.T) {
+	body := []byte("remote file contents")
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer httpServer.Close()
+
+	var capturedPath string
+	var capturedSize int64
+
+	mockClientImpl := &mockClient{
+		uploadFileStreamFunc: func(ctx context.Context, path string, r io.Reader, size int64, opts client.UploadOptions) error {
+			capturedPath = path
+			capturedSize = size
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "upload_file",
+			Arguments: map[string]interface{}{
+				"path":       "/cyverse/home/test/remote.txt",
+				"source_url": httpServer.URL,
+			},
+		},
+	}
+
+	result, err := server.handleUploadFile(context.Background(), request)
+	if err != nil {
+		t.Errorf("handleUploadFile() unexpected error = %v", err)
+	}
+	if result == nil {
+		t.Fatal("handleUploadFile() returned nil result")
+	}
+
+	if capturedPath != "/cyverse/home/test/remote.txt" {
+		t.Errorf("handleUploadFile() called UploadFileStream with path = %v", capturedPath)
+	}
+	if capturedSize != int64(len(body)) {
+		t.Errorf("handleUploadFile() called UploadFileStream with size = %d, want %d", capturedSize, len(body))
+	}
+}
+
 // TestHandleSetMetadata tests the set_metadata handler
 func TestHandleSetMetadata(t *testing.T) {
 	mockClientImpl := &mockClient{
@@ -762,7 +1862,129 @@ func TestHandleSetMetadata(t *testing.T) {
 	}
 }
 
-// TestHandleDeleteData tests the delete_data handler
+// TestHandleSetMetadataStrategies covers the remove, strategic, and
+// json-patch strategies, which all compute their result against a
+// SnapshotMetadata read rather than just forwarding metadata straight
+// through to SetMetadata.
+func TestHandleSetMetadataStrategies(t *testing.T) {
+	t.Run("remove deletes only the named attributes", func(t *testing.T) {
+		var written map[string]interface{}
+		mockClientImpl := &mockClient{
+			snapshotMetadataFunc: func(ctx context.Context, path string) (map[string]interface{}, error) {
+				return map[string]interface{}{"keep": "yes", "drop": "no"}, nil
+			},
+			setMetadataFunc: func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
+				written = metadata
+				if !replace {
+					t.Error("setMetadataFunc replace = false, want true for strategy=remove")
+				}
+				return nil
+			},
+		}
+		server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+		result, err := server.handleSetMetadata(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "set_metadata",
+				Arguments: map[string]interface{}{
+					"path":       "/cyverse/home/test/file.txt",
+					"strategy":   "remove",
+					"attributes": []interface{}{"drop"},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("handleSetMetadata() unexpected error = %v", err)
+		}
+		if _, ok := written["drop"]; ok {
+			t.Errorf("handleSetMetadata() wrote %v, want drop removed", written)
+		}
+		if written["keep"] != "yes" {
+			t.Errorf("handleSetMetadata() wrote %v, want keep preserved", written)
+		}
+
+		text := result.Content[0].(mcp.TextContent).Text
+		if !strings.Contains(text, "removed: drop") {
+			t.Errorf("handleSetMetadata() result = %q, want a removed: drop diff line", text)
+		}
+		if !strings.Contains(text, "unchanged: keep") {
+			t.Errorf("handleSetMetadata() result = %q, want a unchanged: keep diff line", text)
+		}
+	})
+
+	t.Run("strategic unions array-valued attributes", func(t *testing.T) {
+		var written map[string]interface{}
+		mockClientImpl := &mockClient{
+			snapshotMetadataFunc: func(ctx context.Context, path string) (map[string]interface{}, error) {
+				return map[string]interface{}{"tags": []interface{}{"a", "b"}}, nil
+			},
+			setMetadataFunc: func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
+				written = metadata
+				return nil
+			},
+		}
+		server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+		if _, err := server.handleSetMetadata(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "set_metadata",
+				Arguments: map[string]interface{}{
+					"path":     "/cyverse/home/test/file.txt",
+					"strategy": "strategic",
+					"metadata": map[string]interface{}{"tags": []interface{}{"b", "c"}},
+				},
+			},
+		}); err != nil {
+			t.Fatalf("handleSetMetadata() unexpected error = %v", err)
+		}
+
+		tags, _ := written["tags"].([]interface{})
+		if len(tags) != 3 {
+			t.Errorf("handleSetMetadata() tags = %v, want a, b, c unioned", tags)
+		}
+	})
+
+	t.Run("json-patch applies add/replace/remove ops", func(t *testing.T) {
+		var written map[string]interface{}
+		mockClientImpl := &mockClient{
+			snapshotMetadataFunc: func(ctx context.Context, path string) (map[string]interface{}, error) {
+				return map[string]interface{}{"status": "draft", "obsolete": "yes"}, nil
+			},
+			setMetadataFunc: func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
+				written = metadata
+				return nil
+			},
+		}
+		server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+		if _, err := server.handleSetMetadata(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "set_metadata",
+				Arguments: map[string]interface{}{
+					"path":     "/cyverse/home/test/file.txt",
+					"strategy": "json-patch",
+					"patch": []interface{}{
+						map[string]interface{}{"op": "replace", "path": "/status", "value": "final"},
+						map[string]interface{}{"op": "remove", "path": "/obsolete"},
+						map[string]interface{}{"op": "add", "path": "/owner", "value": "alice"},
+					},
+				},
+			},
+		}); err != nil {
+			t.Fatalf("handleSetMetadata() unexpected error = %v", err)
+		}
+
+		if written["status"] != "final" || written["owner"] != "alice" {
+			t.Errorf("handleSetMetadata() wrote %v, want status=final and owner=alice", written)
+		}
+		if _, ok := written["obsolete"]; ok {
+			t.Errorf("handleSetMetadata() wrote %v, want obsolete removed", written)
+		}
+	})
+}
+
+// TestHandleDeleteData tests the delete_data handler's purge=true path,
+// which preserves the tool's original immediate-delete behavior.
 func TestHandleDeleteData(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -807,6 +2029,7 @@ func TestHandleDeleteData(t *testing.T) {
 						"path":    "/cyverse/home/test/file.txt",
 						"dry_run": tt.dryRun,
 						"recurse": tt.recurse,
+						"purge":   true,
 					},
 				},
 			}
@@ -844,182 +2067,2249 @@ func TestHandleDeleteData(t *testing.T) {
 	}
 }
 
-// TestUnmarshalParams tests parameter unmarshaling
-func TestUnmarshalParams(t *testing.T) {
-	type testParams struct {
-		Name  string `json:"name"`
-		Limit int    `json:"limit"`
+// TestHandleDeleteDataTrashesByDefault verifies that without purge=true,
+// delete_data tags the path with a trash marker instead of calling
+// client.DeleteData.
+func TestHandleDeleteDataTrashesByDefault(t *testing.T) {
+	var deleted bool
+	var taggedMetadata map[string]interface{}
+
+	mockClientImpl := &mockClient{
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			deleted = true
+			return nil
+		},
+		setMetadataFunc: func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
+			taggedMetadata = metadata
+			return nil
+		},
 	}
 
-	tests := []struct {
-		name      string
-		arguments map[string]interface{}
-		wantErr   bool
-	}{
-		{
-			name: "valid params",
-			arguments: map[string]interface{}{
-				"name":  "test",
-				"limit": 10,
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+	server.SetCurrentUser("alice")
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "delete_data",
+			Arguments: map[string]interface{}{
+				"path": "/cyverse/home/test/file.txt",
 			},
-			wantErr: false,
-		},
-		{
-			name: "empty params",
-			arguments: map[string]interface{}{},
-			wantErr: false,
 		},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			request := mcp.CallToolRequest{
-				Params: mcp.CallToolParams{
-					Arguments: tt.arguments,
-				},
-			}
+	result, err := server.handleDeleteData(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleDeleteData() unexpected error = %v", err)
+	}
+	if deleted {
+		t.Error("handleDeleteData() called client.DeleteData without purge=true")
+	}
+	if taggedMetadata[trashDeletedByAttr] != "alice" {
+		t.Errorf("handleDeleteData() trash marker deleted_by = %v, want alice", taggedMetadata[trashDeletedByAttr])
+	}
+	if taggedMetadata[trashMarkerIDAttr] == nil {
+		t.Error("handleDeleteData() trash marker has no marker_id")
+	}
 
-			var params testParams
-			err := unmarshalParams(request, &params)
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Moved to trash") {
+		t.Errorf("handleDeleteData() result = %q, want it to mention the trash move", text)
+	}
+}
 
-			if tt.wantErr && err == nil {
-				t.Error("unmarshalParams() expected error but got none")
+// TestTrashRestorePurgeRoundTrip exercises delete_data (trashing),
+// list_deleted, restore_data, and purge_deleted together against an
+// in-memory metadata store.
+func TestTrashRestorePurgeRoundTrip(t *testing.T) {
+	metadata := map[string]map[string]interface{}{}
+	var purged []string
+
+	mockClientImpl := &mockClient{
+		setMetadataFunc: func(ctx context.Context, path string, update map[string]interface{}, replace bool) error {
+			if metadata[path] == nil {
+				metadata[path] = map[string]interface{}{}
 			}
-			if !tt.wantErr && err != nil {
-				t.Errorf("unmarshalParams() unexpected error = %v", err)
+			for k, v := range update {
+				metadata[path][k] = v
 			}
-
-			if !tt.wantErr && tt.arguments["name"] != nil {
-				if params.Name != tt.arguments["name"].(string) {
-					t.Errorf("unmarshalParams() name = %v, want %v", params.Name, tt.arguments["name"])
+			return nil
+		},
+		searchMetadataFunc: func(ctx context.Context, query client.MetadataQuery) (*client.MetadataSearchResult, error) {
+			var matches []client.MetadataSearchMatch
+			for path, m := range metadata {
+				if _, ok := m[trashMarkerIDAttr]; ok {
+					matches = append(matches, client.MetadataSearchMatch{Path: path, Metadata: m})
 				}
 			}
-		})
+			return &client.MetadataSearchResult{Matches: matches, Total: len(matches)}, nil
+		},
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			purged = append(purged, path)
+			return nil
+		},
 	}
-}
 
-// TestNewFormationMCPServer tests server creation
-func TestNewFormationMCPServer(t *testing.T) {
-	mockWorkflowsImpl := &mockWorkflows{}
-	mockClientImpl := &mockClient{}
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
 
-	server := NewFormationMCPServer(mockWorkflowsImpl, mockClientImpl)
+	deleteResult, err := server.handleDeleteData(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "delete_data", Arguments: map[string]interface{}{"path": "/cyverse/home/test/a.txt"}},
+	})
+	if err != nil {
+		t.Fatalf("handleDeleteData() unexpected error = %v", err)
+	}
+	markerID := fmt.Sprintf("%v", metadata["/cyverse/home/test/a.txt"][trashMarkerIDAttr])
+	if !strings.Contains(deleteResult.Content[0].(mcp.TextContent).Text, markerID) {
+		t.Fatalf("handleDeleteData() result doesn't echo marker id %q", markerID)
+	}
 
-	if server == nil {
-		t.Fatal("NewFormationMCPServer() returned nil")
+	listResult, err := server.handleListDeleted(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "list_deleted", Arguments: map[string]interface{}{}},
+	})
+	if err != nil {
+		t.Fatalf("handleListDeleted() unexpected error = %v", err)
+	}
+	if !strings.Contains(listResult.Content[0].(mcp.TextContent).Text, "a.txt") {
+		t.Errorf("handleListDeleted() result = %q, want it to list a.txt", listResult.Content[0].(mcp.TextContent).Text)
 	}
 
-	if server.server == nil {
-		t.Error("NewFormationMCPServer() MCP server is nil")
+	if _, err := server.handleRestoreData(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "restore_data", Arguments: map[string]interface{}{"marker_id": markerID}},
+	}); err != nil {
+		t.Fatalf("handleRestoreData() unexpected error = %v", err)
 	}
 
-	if server.workflows == nil {
-		t.Error("NewFormationMCPServer() workflows is nil")
+	listResult, err = server.handleListDeleted(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "list_deleted", Arguments: map[string]interface{}{}},
+	})
+	if err != nil {
+		t.Fatalf("handleListDeleted() unexpected error = %v", err)
+	}
+	if strings.Contains(listResult.Content[0].(mcp.TextContent).Text, "a.txt") {
+		t.Errorf("handleListDeleted() result = %q, want a.txt removed after restore", listResult.Content[0].(mcp.TextContent).Text)
 	}
 
-	if server.client == nil {
-		t.Error("NewFormationMCPServer() client is nil")
+	// Trash a second path and purge it by marker_id, leaving the restored one alone.
+	if _, err := server.handleDeleteData(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "delete_data", Arguments: map[string]interface{}{"path": "/cyverse/home/test/b.txt"}},
+	}); err != nil {
+		t.Fatalf("handleDeleteData() unexpected error = %v", err)
+	}
+	bMarkerID := fmt.Sprintf("%v", metadata["/cyverse/home/test/b.txt"][trashMarkerIDAttr])
+
+	if _, err := server.handlePurgeDeleted(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "purge_deleted", Arguments: map[string]interface{}{"marker_id": bMarkerID}},
+	}); err != nil {
+		t.Fatalf("handlePurgeDeleted() unexpected error = %v", err)
+	}
+
+	if len(purged) != 1 || purged[0] != "/cyverse/home/test/b.txt" {
+		t.Errorf("handlePurgeDeleted() purged = %v, want only b.txt", purged)
 	}
 }
 
-// TestToolRegistration verifies all tools are registered
-func TestToolRegistration(t *testing.T) {
-	mockWorkflowsImpl := &mockWorkflows{}
-	mockClientImpl := &mockClient{}
+// TestHandleDeleteDataGracePeriodSchedulesJob verifies a positive
+// grace_period_seconds enqueues a delete job instead of trashing or
+// purging the path inline, and that get_delete_job/list_delete_jobs/
+// cancel_delete_job can see and cancel it before it fires.
+func TestHandleDeleteDataGracePeriodSchedulesJob(t *testing.T) {
+	var ran bool
 
-	server := NewFormationMCPServer(mockWorkflowsImpl, mockClientImpl)
+	mockClientImpl := &mockClient{
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			ran = true
+			return nil
+		},
+		setMetadataFunc: func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
+			ran = true
+			return nil
+		},
+	}
 
-	expectedTools := []string{
-		"list_apps",
-		"get_app_parameters",
-		"launch_app_and_wait",
-		"get_analysis_status",
-		"list_running_analyses",
-		"stop_analysis",
-		"open_in_browser",
-		"browse_data",
-		"create_directory",
-		"upload_file",
-		"set_metadata",
-		"delete_data",
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	deleteResult, err := server.handleDeleteData(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "delete_data",
+			Arguments: map[string]interface{}{
+				"path":                 "/cyverse/home/test/a.txt",
+				"grace_period_seconds": 60,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("handleDeleteData() unexpected error = %v", err)
+	}
+	if ran {
+		t.Error("handleDeleteData() executed the delete instead of scheduling a job")
 	}
 
-	// We can't directly access the tools from the MCP server,
-	// but we can verify the tool definitions are created correctly
-	for _, toolName := range expectedTools {
-		var tool mcp.Tool
-
-		switch toolName {
-		case "list_apps":
-			tool = server.listAppsTool()
-		case "get_app_parameters":
-			tool = server.getAppParametersTool()
-		case "launch_app_and_wait":
-			tool = server.launchAppAndWaitTool()
-		case "get_analysis_status":
-			tool = server.getAnalysisStatusTool()
-		case "list_running_analyses":
-			tool = server.listRunningAnalysesTool()
-		case "stop_analysis":
-			tool = server.stopAnalysisTool()
-		case "open_in_browser":
-			tool = server.openInBrowserTool()
-		case "browse_data":
-			tool = server.browseDataTool()
-		case "create_directory":
-			tool = server.createDirectoryTool()
-		case "upload_file":
-			tool = server.uploadFileTool()
-		case "set_metadata":
-			tool = server.setMetadataTool()
-		case "delete_data":
-			tool = server.deleteDataTool()
-		}
+	text := deleteResult.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Scheduled delete job") {
+		t.Errorf("handleDeleteData() result = %q, want it to mention a scheduled job", text)
+	}
 
-		if tool.Name != toolName {
-			t.Errorf("Tool %v not properly defined", toolName)
-		}
+	jobs := server.deleteJobs.list()
+	if len(jobs) != 1 {
+		t.Fatalf("deleteJobs.list() = %d jobs, want 1", len(jobs))
+	}
+	jobID := jobs[0].ID
 
-		if tool.Description == "" {
-			t.Errorf("Tool %v has no description", toolName)
-		}
+	getResult, err := server.handleGetDeleteJob(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "get_delete_job", Arguments: map[string]interface{}{"job_id": jobID}},
+	})
+	if err != nil {
+		t.Fatalf("handleGetDeleteJob() unexpected error = %v", err)
+	}
+	if !strings.Contains(getResult.Content[0].(mcp.TextContent).Text, "pending") {
+		t.Errorf("handleGetDeleteJob() result = %q, want pending status", getResult.Content[0].(mcp.TextContent).Text)
 	}
-}
 
-// TestToolSchemaValidation verifies tool input schemas
-func TestToolSchemaValidation(t *testing.T) {
-	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+	if _, err := server.handleCancelDeleteJob(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "cancel_delete_job", Arguments: map[string]interface{}{"job_id": jobID}},
+	}); err != nil {
+		t.Fatalf("handleCancelDeleteJob() unexpected error = %v", err)
+	}
 
-	// Test list_apps schema
-	listAppsTool := server.listAppsTool()
-	props := listAppsTool.InputSchema.Properties
-	if props == nil {
-		t.Error("list_apps has no properties defined")
+	job, ok := server.deleteJobs.get(jobID)
+	if !ok || job.Status != deleteJobCancelled {
+		t.Errorf("deleteJobs.get(%q) status = %v, want cancelled", jobID, job)
 	}
 
-	// Test launch_app_and_wait schema
-	launchTool := server.launchAppAndWaitTool()
-	if launchTool.InputSchema.Required == nil || len(launchTool.InputSchema.Required) == 0 {
-		t.Error("launch_app_and_wait has no required parameters")
+	if _, err := server.handleCancelDeleteJob(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "cancel_delete_job", Arguments: map[string]interface{}{"job_id": jobID}},
+	}); err == nil {
+		t.Error("handleCancelDeleteJob() expected an error cancelling an already-cancelled job")
 	}
+}
 
-	appIDFound := false
-	for _, req := range launchTool.InputSchema.Required {
-		if req == "app_id" {
-			appIDFound = true
-			break
+// TestDeleteJobManagerExecutesAfterGracePeriod verifies that a job with a
+// zero grace period still runs through the job manager (rather than
+// inline) and that its status reflects the run's outcome once it fires.
+func TestDeleteJobManagerExecutesAfterGracePeriod(t *testing.T) {
+	executed := make(chan struct{})
+
+	manager := newDeleteJobManager(func(ctx context.Context, job *deleteJob) error {
+		close(executed)
+		return nil
+	})
+
+	job := manager.enqueue("/cyverse/home/test/a.txt", false, false, "", 0)
+
+	select {
+	case <-executed:
+	case <-time.After(time.Second):
+		t.Fatal("deleteJobManager did not execute the job within the timeout")
+	}
+
+	// The status update happens just after run() returns, so poll briefly
+	// rather than asserting on it immediately after the close(executed).
+	for i := 0; i < 100; i++ {
+		if got, ok := manager.get(job.ID); ok && got.Status == deleteJobDone {
+			return
 		}
+		time.Sleep(time.Millisecond)
 	}
-	if !appIDFound {
-		t.Error("launch_app_and_wait doesn't require app_id parameter")
+	t.Fatal("deleteJobManager job never reached done status")
+}
+
+// TestHandleSearchMetadata tests the search_metadata handler
+func TestHandleSearchMetadata(t *testing.T) {
+	var capturedQuery client.MetadataQuery
+
+	mockClientImpl := &mockClient{
+		searchMetadataFunc: func(ctx context.Context, query client.MetadataQuery) (*client.MetadataSearchResult, error) {
+			capturedQuery = query
+			return &client.MetadataSearchResult{
+				Matches: []client.MetadataSearchMatch{
+					{Path: "/cyverse/home/test/a.fastq", Type: "data_object", Metadata: map[string]interface{}{"experiment": "RNA-seq"}},
+				},
+				Total: 1,
+			}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "search_metadata",
+			Arguments: map[string]interface{}{
+				"predicates": []interface{}{
+					map[string]interface{}{"attribute": "experiment", "operator": "=", "value": "RNA-seq"},
+				},
+				"path_prefix": "/cyverse/home/test",
+			},
+		},
+	}
+
+	result, err := server.handleSearchMetadata(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleSearchMetadata() unexpected error = %v", err)
+	}
+
+	if len(capturedQuery.Predicates) != 1 || capturedQuery.Predicates[0].Attribute != "experiment" {
+		t.Errorf("handleSearchMetadata() query predicates = %+v", capturedQuery.Predicates)
+	}
+	if capturedQuery.PathPrefix != "/cyverse/home/test" {
+		t.Errorf("handleSearchMetadata() query path_prefix = %v", capturedQuery.PathPrefix)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatal("handleSearchMetadata() result is not text content")
+	}
+	if !strings.Contains(textContent.Text, "a.fastq") {
+		t.Errorf("handleSearchMetadata() result = %q, want it to contain the matching path", textContent.Text)
 	}
 }
 
-// Helper to convert interface to JSON and back
-func mustMarshal(v interface{}) []byte {
-	b, err := json.Marshal(v)
+// TestHandleSearchMetadataRequiresPredicates verifies that an empty
+// predicates list is rejected rather than matching every path.
+func TestHandleSearchMetadataRequiresPredicates(t *testing.T) {
+	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "search_metadata",
+			Arguments: map[string]interface{}{},
+		},
+	}
+
+	if _, err := server.handleSearchMetadata(context.Background(), request); err == nil {
+		t.Error("handleSearchMetadata() expected an error when predicates is empty")
+	}
+}
+
+// TestParseSelector covers the selector grammar's clause kinds: equality,
+// negation, set membership, and existence.
+func TestParseSelector(t *testing.T) {
+	clauses, err := parseSelector("project=foo, stage in (draft, archived), !protected, owned")
 	if err != nil {
-		panic(err)
+		t.Fatalf("parseSelector() unexpected error = %v", err)
+	}
+	if len(clauses) != 4 {
+		t.Fatalf("parseSelector() = %+v, want 4 clauses", clauses)
+	}
+
+	if !reflect.DeepEqual(clauses[0], selectorClause{Attribute: "project", Op: "=", Values: []string{"foo"}}) {
+		t.Errorf("parseSelector() clause 0 = %+v", clauses[0])
+	}
+	if clauses[1].Attribute != "stage" || clauses[1].Op != "in" || len(clauses[1].Values) != 2 {
+		t.Errorf("parseSelector() clause 1 = %+v", clauses[1])
+	}
+	if !reflect.DeepEqual(clauses[2], selectorClause{Attribute: "protected", Op: "!exists"}) {
+		t.Errorf("parseSelector() clause 2 = %+v", clauses[2])
+	}
+	if !reflect.DeepEqual(clauses[3], selectorClause{Attribute: "owned", Op: "exists"}) {
+		t.Errorf("parseSelector() clause 3 = %+v", clauses[3])
+	}
+
+	if _, err := parseSelector(""); err == nil {
+		t.Error("parseSelector(\"\") expected an error for an empty selector")
+	}
+	if _, err := parseSelector("stage in draft"); err == nil {
+		t.Error("parseSelector() expected an error for a malformed \"in\" clause")
+	}
+}
+
+// TestHandleDeleteBySelectorDryRunThenConfirm verifies delete_by_selector
+// returns a candidate list and confirm_token on the first call, and only
+// deletes once that exact token is echoed back.
+func TestHandleDeleteBySelectorDryRunThenConfirm(t *testing.T) {
+	var deleted []string
+
+	mockClientImpl := &mockClient{
+		searchMetadataFunc: func(ctx context.Context, query client.MetadataQuery) (*client.MetadataSearchResult, error) {
+			return &client.MetadataSearchResult{
+				Matches: []client.MetadataSearchMatch{
+					{Path: "/cyverse/home/test/draft.txt", Type: "data_object", Metadata: map[string]interface{}{"project": "foo", "stage": "draft"}},
+					{Path: "/cyverse/home/test/protected.txt", Type: "data_object", Metadata: map[string]interface{}{"project": "foo", "stage": "draft", "protected": "true"}},
+				},
+			}, nil
+		},
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			deleted = append(deleted, path)
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "delete_by_selector",
+			Arguments: map[string]interface{}{
+				"selector": "project=foo,!protected",
+			},
+		},
+	}
+
+	result, err := server.handleDeleteBySelector(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleDeleteBySelector() unexpected error = %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("handleDeleteBySelector() deleted %v before a confirm_token was supplied", deleted)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if strings.Contains(text, "protected.txt") {
+		t.Errorf("handleDeleteBySelector() dry run = %q, want !protected to exclude protected.txt", text)
+	}
+	if !strings.Contains(text, "draft.txt") {
+		t.Errorf("handleDeleteBySelector() dry run = %q, want it to list draft.txt", text)
+	}
+
+	prefix := "confirm_token=\""
+	start := strings.Index(text, prefix)
+	if start == -1 {
+		t.Fatalf("handleDeleteBySelector() dry run = %q, want a confirm_token", text)
+	}
+	start += len(prefix)
+	token := text[start:strings.Index(text[start:], "\"")+start]
+
+	request.Params.Arguments.(map[string]interface{})["confirm_token"] = token
+	if _, err := server.handleDeleteBySelector(context.Background(), request); err != nil {
+		t.Fatalf("handleDeleteBySelector() confirmed call unexpected error = %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "/cyverse/home/test/draft.txt" {
+		t.Errorf("handleDeleteBySelector() deleted = %v, want only draft.txt", deleted)
+	}
+}
+
+// TestHandleBulkCreateDirectories tests bulk_create_directories across all
+// three modes, mixing a failing item in with succeeding ones.
+func TestHandleBulkCreateDirectories(t *testing.T) {
+	t.Run("best_effort applies every item despite a failure", func(t *testing.T) {
+		var created []string
+
+		mockClientImpl := &mockClient{
+			browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+				return nil, fmt.Errorf("not found")
+			},
+			createDirectoryFunc: func(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error) {
+				if path == "/cyverse/home/test/bad" {
+					return nil, errors.New("create failed")
+				}
+				created = append(created, path)
+				return &client.CreateDirectoryResponse{Path: path}, nil
+			},
+		}
+
+		server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "bulk_create_directories",
+				Arguments: map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"path": "/cyverse/home/test/ok1"},
+						map[string]interface{}{"path": "/cyverse/home/test/bad"},
+						map[string]interface{}{"path": "/cyverse/home/test/ok2"},
+					},
+					"mode": "best_effort",
+				},
+			},
+		}
+
+		result, err := server.handleBulkCreateDirectories(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleBulkCreateDirectories() unexpected error = %v", err)
+		}
+
+		if len(created) != 2 {
+			t.Errorf("handleBulkCreateDirectories() created %v, want 2 directories despite the failure", created)
+		}
+
+		text := result.Content[0].(mcp.TextContent).Text
+		if !strings.Contains(text, "2/3 succeeded") {
+			t.Errorf("handleBulkCreateDirectories() result = %q, want a 2/3 succeeded summary", text)
+		}
+	})
+
+	t.Run("stop_on_error skips items after the failure", func(t *testing.T) {
+		var applied []string
+
+		mockClientImpl := &mockClient{
+			browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+				return nil, fmt.Errorf("not found")
+			},
+			createDirectoryFunc: func(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error) {
+				applied = append(applied, path)
+				if path == "/cyverse/home/test/bad" {
+					return nil, errors.New("create failed")
+				}
+				return &client.CreateDirectoryResponse{Path: path}, nil
+			},
+		}
+
+		server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "bulk_create_directories",
+				Arguments: map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"path": "/cyverse/home/test/ok1"},
+						map[string]interface{}{"path": "/cyverse/home/test/bad"},
+						map[string]interface{}{"path": "/cyverse/home/test/ok2"},
+					},
+					"mode": "stop_on_error",
+				},
+			},
+		}
+
+		result, err := server.handleBulkCreateDirectories(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleBulkCreateDirectories() unexpected error = %v", err)
+		}
+
+		if len(applied) != 2 {
+			t.Errorf("handleBulkCreateDirectories() attempted %v, want exactly 2 attempts before stopping", applied)
+		}
+
+		text := result.Content[0].(mcp.TextContent).Text
+		if !strings.Contains(text, "skipped") {
+			t.Errorf("handleBulkCreateDirectories() result = %q, want the trailing item marked skipped", text)
+		}
+	})
+
+	t.Run("all_or_nothing rolls back already-created directories", func(t *testing.T) {
+		var deleted []string
+
+		mockClientImpl := &mockClient{
+			browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+				return nil, fmt.Errorf("not found")
+			},
+			createDirectoryFunc: func(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error) {
+				if path == "/cyverse/home/test/bad" {
+					return nil, errors.New("create failed")
+				}
+				return &client.CreateDirectoryResponse{Path: path}, nil
+			},
+			deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+				deleted = append(deleted, path)
+				return nil
+			},
+		}
+
+		server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "bulk_create_directories",
+				Arguments: map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"path": "/cyverse/home/test/ok1"},
+						map[string]interface{}{"path": "/cyverse/home/test/bad"},
+					},
+					"mode": "all_or_nothing",
+				},
+			},
+		}
+
+		result, err := server.handleBulkCreateDirectories(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleBulkCreateDirectories() unexpected error = %v", err)
+		}
+
+		if len(deleted) != 1 || deleted[0] != "/cyverse/home/test/ok1" {
+			t.Errorf("handleBulkCreateDirectories() rolled back %v, want [/cyverse/home/test/ok1]", deleted)
+		}
+
+		text := result.Content[0].(mcp.TextContent).Text
+		if !strings.Contains(text, "rolled back") {
+			t.Errorf("handleBulkCreateDirectories() result = %q, want a rolled back entry", text)
+		}
+	})
+
+	t.Run("all_or_nothing aborts before applying anything if preflight fails", func(t *testing.T) {
+		var applyCalled bool
+
+		mockClientImpl := &mockClient{
+			browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+				if path == "/cyverse/home/test/exists" {
+					return &client.DirectoryContents{Path: path}, nil
+				}
+				return nil, fmt.Errorf("not found")
+			},
+			createDirectoryFunc: func(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error) {
+				applyCalled = true
+				return &client.CreateDirectoryResponse{Path: path}, nil
+			},
+		}
+
+		server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "bulk_create_directories",
+				Arguments: map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"path": "/cyverse/home/test/exists"},
+						map[string]interface{}{"path": "/cyverse/home/test/new"},
+					},
+					"mode": "all_or_nothing",
+				},
+			},
+		}
+
+		result, err := server.handleBulkCreateDirectories(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleBulkCreateDirectories() unexpected error = %v", err)
+		}
+		if applyCalled {
+			t.Error("handleBulkCreateDirectories() called CreateDirectory despite a failed preflight")
+		}
+
+		text := result.Content[0].(mcp.TextContent).Text
+		if !strings.Contains(text, "preflight failed") {
+			t.Errorf("handleBulkCreateDirectories() result = %q, want a preflight failure explanation", text)
+		}
+	})
+}
+
+// TestHandleBulkUploadFiles tests bulk_upload_files' all_or_nothing
+// rollback, which restores metadata for files that already existed instead
+// of deleting them.
+func TestHandleBulkUploadFiles(t *testing.T) {
+	var restoredMetadata map[string]interface{}
+	var deletedPaths []string
+
+	mockClientImpl := &mockClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			if path == "/cyverse/home/test/existing.txt" {
+				return &client.FileContent{Path: path}, nil
+			}
+			return nil, fmt.Errorf("not found")
+		},
+		snapshotMetadataFunc: func(ctx context.Context, path string) (map[string]interface{}, error) {
+			return map[string]interface{}{"owner": "alice"}, nil
+		},
+		uploadFileFunc: func(ctx context.Context, path, content string, metadata map[string]interface{}) error {
+			if path == "/cyverse/home/test/bad.txt" {
+				return errors.New("upload failed")
+			}
+			return nil
+		},
+		setMetadataFunc: func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
+			restoredMetadata = metadata
+			return nil
+		},
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			deletedPaths = append(deletedPaths, path)
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "bulk_upload_files",
+			Arguments: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"path": "/cyverse/home/test/existing.txt", "content": "v2"},
+					map[string]interface{}{"path": "/cyverse/home/test/new.txt", "content": "v1"},
+					map[string]interface{}{"path": "/cyverse/home/test/bad.txt", "content": "boom"},
+				},
+				"mode": "all_or_nothing",
+			},
+		},
+	}
+
+	result, err := server.handleBulkUploadFiles(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleBulkUploadFiles() unexpected error = %v", err)
+	}
+
+	if restoredMetadata["owner"] != "alice" {
+		t.Errorf("handleBulkUploadFiles() restored metadata = %v, want the pre-overwrite snapshot", restoredMetadata)
+	}
+	if len(deletedPaths) != 1 || deletedPaths[0] != "/cyverse/home/test/new.txt" {
+		t.Errorf("handleBulkUploadFiles() deleted %v, want only the newly created file", deletedPaths)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "rolled back") {
+		t.Errorf("handleBulkUploadFiles() result = %q, want rolled back entries", text)
+	}
+}
+
+// TestHandleBulkSetMetadata tests bulk_set_metadata's all_or_nothing
+// rollback, which restores the snapshot taken before each overwrite.
+func TestHandleBulkSetMetadata(t *testing.T) {
+	var restored map[string]interface{}
+
+	mockClientImpl := &mockClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			return &client.FileContent{Path: path}, nil
+		},
+		snapshotMetadataFunc: func(ctx context.Context, path string) (map[string]interface{}, error) {
+			return map[string]interface{}{"status": "original"}, nil
+		},
+		setMetadataFunc: func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
+			if path == "/cyverse/home/test/bad.txt" {
+				return errors.New("set metadata failed")
+			}
+			restored = metadata
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "bulk_set_metadata",
+			Arguments: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"path": "/cyverse/home/test/ok.txt", "metadata": map[string]interface{}{"status": "new"}},
+					map[string]interface{}{"path": "/cyverse/home/test/bad.txt", "metadata": map[string]interface{}{"status": "new"}},
+				},
+				"mode": "all_or_nothing",
+			},
+		},
+	}
+
+	result, err := server.handleBulkSetMetadata(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleBulkSetMetadata() unexpected error = %v", err)
+	}
+
+	// The first call applies "new", the rollback then calls SetMetadata
+	// again to restore the snapshot - restored should end up holding that
+	// last, restorative call's metadata.
+	if restored["status"] != "original" {
+		t.Errorf("handleBulkSetMetadata() restored = %v, want the pre-overwrite snapshot", restored)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "rolled back") {
+		t.Errorf("handleBulkSetMetadata() result = %q, want rolled back entries", text)
+	}
+}
+
+// TestHandleBulkDeleteData tests bulk_delete_data: since deletes can't be
+// undone, an all_or_nothing batch only ever preflights before deleting.
+func TestHandleBulkDeleteData(t *testing.T) {
+	var deleted []string
+
+	mockClientImpl := &mockClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			if path == "/cyverse/home/test/missing.txt" {
+				return nil, fmt.Errorf("not found")
+			}
+			return &client.FileContent{Path: path}, nil
+		},
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			deleted = append(deleted, path)
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "bulk_delete_data",
+			Arguments: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"path": "/cyverse/home/test/a.txt"},
+					map[string]interface{}{"path": "/cyverse/home/test/missing.txt"},
+				},
+				"mode": "all_or_nothing",
+			},
+		},
+	}
+
+	result, err := server.handleBulkDeleteData(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleBulkDeleteData() unexpected error = %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("handleBulkDeleteData() deleted %v, want nothing deleted after a failed preflight", deleted)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "preflight failed") {
+		t.Errorf("handleBulkDeleteData() result = %q, want a preflight failure explanation", text)
+	}
+}
+
+// TestHandleBulkDeleteDataOrphanForcesNoRecurse verifies orphan propagation
+// deletes only the named path, ignoring a per-item recurse: true.
+func TestHandleBulkDeleteDataOrphanForcesNoRecurse(t *testing.T) {
+	var recursedWith []bool
+
+	mockClientImpl := &mockClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			return &client.FileContent{Path: path}, nil
+		},
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			recursedWith = append(recursedWith, recurse)
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "bulk_delete_data",
+			Arguments: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"path": "/cyverse/home/test/dir", "recurse": true},
+				},
+				"propagation": "orphan",
+			},
+		},
+	}
+
+	result, err := server.handleBulkDeleteData(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleBulkDeleteData() unexpected error = %v", err)
+	}
+	if len(recursedWith) != 1 || recursedWith[0] {
+		t.Errorf("handleBulkDeleteData() recurse = %v, want orphan propagation to force recurse=false", recursedWith)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "orphaned") {
+		t.Errorf("handleBulkDeleteData() result = %q, want an orphaned status", text)
+	}
+}
+
+// TestHandleBulkDeleteDataBackgroundReturnsSubmitted verifies background
+// propagation reports every item as submitted without waiting for the
+// underlying delete to actually run.
+func TestHandleBulkDeleteDataBackgroundReturnsSubmitted(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	mockClientImpl := &mockClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			return &client.FileContent{Path: path}, nil
+		},
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			close(started)
+			<-release
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "bulk_delete_data",
+			Arguments: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"path": "/cyverse/home/test/a.txt"},
+				},
+				"propagation": "background",
+			},
+		},
+	}
+
+	result, err := server.handleBulkDeleteData(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleBulkDeleteData() unexpected error = %v", err)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "submitted") {
+		t.Errorf("handleBulkDeleteData() result = %q, want a submitted status", text)
+	}
+
+	close(release)
+	<-started
+}
+
+// TestHandleBulkDeleteDataBestEffortConcurrent verifies best_effort deletes
+// run concurrently rather than one at a time, up to max_parallel.
+func TestHandleBulkDeleteDataBestEffortConcurrent(t *testing.T) {
+	const items = 4
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	release := make(chan struct{})
+
+	mockClientImpl := &mockClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			return &client.FileContent{Path: path}, nil
+		},
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			<-release
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	itemArgs := make([]interface{}, items)
+	for i := range itemArgs {
+		itemArgs[i] = map[string]interface{}{"path": fmt.Sprintf("/cyverse/home/test/%d.txt", i)}
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "bulk_delete_data",
+			Arguments: map[string]interface{}{
+				"items":        itemArgs,
+				"max_parallel": items,
+			},
+		},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		if _, err := server.handleBulkDeleteData(context.Background(), request); err != nil {
+			t.Errorf("handleBulkDeleteData() unexpected error = %v", err)
+		}
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for {
+		mu.Lock()
+		reached := maxInFlight == items
+		mu.Unlock()
+		if reached {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("handleBulkDeleteData() maxInFlight = %d, want %d concurrent deletes", maxInFlight, items)
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	close(release)
+	<-done
+}
+
+// TestHandleApplyDataPlanDryRun verifies dry_run resolves a glob set_metadata
+// op against the current tree and reports the matched paths without calling
+// SetMetadata.
+func TestHandleApplyDataPlanDryRun(t *testing.T) {
+	mockClientImpl := &mockClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			return &client.DirectoryContents{
+				Path: path,
+				Type: "collection",
+				Contents: []client.DirectoryEntry{
+					{Name: "run1.log", Type: "data_object"},
+					{Name: "run2.log", Type: "data_object"},
+					{Name: "notes.txt", Type: "data_object"},
+				},
+			}, nil
+		},
+		setMetadataFunc: func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
+			t.Errorf("setMetadataFunc called during dry_run for %v", path)
+			return nil
+		},
+	}
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "apply_data_plan",
+			Arguments: map[string]interface{}{
+				"ops": []interface{}{
+					map[string]interface{}{
+						"type":     "set_metadata",
+						"path":     "/cyverse/home/test/runs/*.log",
+						"metadata": map[string]interface{}{"status": "archived"},
+					},
+				},
+				"dry_run": true,
+			},
+		},
+	}
+
+	result, err := server.handleApplyDataPlan(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleApplyDataPlan() unexpected error = %v", err)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "run1.log") || !strings.Contains(text, "run2.log") {
+		t.Errorf("handleApplyDataPlan() dry run result = %q, want both matched paths resolved", text)
+	}
+	if strings.Contains(text, "notes.txt") {
+		t.Errorf("handleApplyDataPlan() dry run result = %q, want non-matching entries excluded", text)
+	}
+}
+
+// TestHandleApplyDataPlanStopOnErrorRollsBack verifies a stop_on_error plan
+// rolls back an already-applied create_directory once a later op fails.
+func TestHandleApplyDataPlanStopOnErrorRollsBack(t *testing.T) {
+	var created, rolledBackDeletes []string
+
+	mockClientImpl := &mockClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			return nil, fmt.Errorf("not found")
+		},
+		createDirectoryFunc: func(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error) {
+			created = append(created, path)
+			return &client.CreateDirectoryResponse{Path: path, Type: "collection"}, nil
+		},
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			if !recurse {
+				// This is the plan's own delete op, not the rollback of the
+				// create_directory op (which always passes recurse=true).
+				return errors.New("delete failed")
+			}
+			rolledBackDeletes = append(rolledBackDeletes, path)
+			return nil
+		},
+	}
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "apply_data_plan",
+			Arguments: map[string]interface{}{
+				"ops": []interface{}{
+					map[string]interface{}{"type": "create_directory", "path": "/cyverse/home/test/newdir"},
+					map[string]interface{}{"type": "delete", "path": "/cyverse/home/test/missing"},
+				},
+				"stop_on_error": true,
+			},
+		},
+	}
+
+	result, err := server.handleApplyDataPlan(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleApplyDataPlan() unexpected error = %v", err)
+	}
+	if len(created) != 1 || created[0] != "/cyverse/home/test/newdir" {
+		t.Errorf("handleApplyDataPlan() created = %v, want the first op applied before the failure", created)
+	}
+	if len(rolledBackDeletes) != 1 || rolledBackDeletes[0] != "/cyverse/home/test/newdir" {
+		t.Errorf("handleApplyDataPlan() rollback deletes = %v, want the created directory removed", rolledBackDeletes)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "rolled back") {
+		t.Errorf("handleApplyDataPlan() result = %q, want a rolled back entry", text)
+	}
+}
+
+// TestHandleWatchAnalysisReturnsOnTerminalStatus verifies watch_analysis
+// stops waiting as soon as a terminal-status event arrives, rather than
+// waiting out max_wait.
+func TestHandleWatchAnalysisReturnsOnTerminalStatus(t *testing.T) {
+	mockClientImpl := &mockClient{
+		watchAnalysisFunc: func(ctx context.Context, analysisID string) (<-chan client.AnalysisEvent, error) {
+			events := make(chan client.AnalysisEvent, 2)
+			events <- client.AnalysisEvent{AnalysisID: analysisID, Status: "Running"}
+			events <- client.AnalysisEvent{AnalysisID: analysisID, Status: "Completed"}
+			close(events)
+			return events, nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "watch_analysis",
+			Arguments: map[string]interface{}{
+				"analysis_id": "a1",
+				"max_wait":    5,
+			},
+		},
+	}
+
+	result, err := server.handleWatchAnalysis(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleWatchAnalysis() unexpected error = %v", err)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Completed") {
+		t.Errorf("handleWatchAnalysis() result = %q, want the terminal status reported", text)
+	}
+	if !strings.Contains(text, "Updates received: 2") {
+		t.Errorf("handleWatchAnalysis() result = %q, want both events counted", text)
+	}
+}
+
+// TestHandleTailAnalysisLogsNonFollow verifies tail_analysis_logs returns
+// the delivered backlog once the log channel closes.
+func TestHandleTailAnalysisLogsNonFollow(t *testing.T) {
+	mockClientImpl := &mockClient{
+		streamAnalysisLogsFunc: func(ctx context.Context, analysisID string, opts client.LogStreamOptions) (<-chan client.LogLine, error) {
+			lines := make(chan client.LogLine, 2)
+			lines <- client.LogLine{Line: "starting up"}
+			lines <- client.LogLine{Line: "listening on :8080"}
+			close(lines)
+			return lines, nil
+		},
+	}
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "tail_analysis_logs",
+			Arguments: map[string]interface{}{
+				"analysis_id": "a1",
+				"max_wait":    5,
+			},
+		},
+	}
+
+	result, err := server.handleTailAnalysisLogs(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleTailAnalysisLogs() unexpected error = %v", err)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "starting up") || !strings.Contains(text, "listening on :8080") {
+		t.Errorf("handleTailAnalysisLogs() result = %q, want both lines present", text)
+	}
+}
+
+// TestDryRunDispatchesToPlan verifies every PlannableTool's Apply stays
+// untouched while dry_run=true routes to Plan and never mutates anything.
+func TestDryRunDispatchesToPlan(t *testing.T) {
+	var applied bool
+
+	mockClientImpl := &mockClient{
+		createDirectoryFunc: func(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error) {
+			applied = true
+			return &client.CreateDirectoryResponse{Path: path, Type: "collection"}, nil
+		},
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			return nil, fmt.Errorf("not found")
+		},
+	}
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	handler := dispatchPlannable(createDirectoryPlan{s: server})
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "create_directory",
+			Arguments: map[string]interface{}{
+				"path":    "/cyverse/home/test/new-dir",
+				"dry_run": true,
+			},
+		},
+	}
+
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("dispatchPlannable() unexpected error = %v", err)
+	}
+	if applied {
+		t.Error("dry_run=true should not have invoked CreateDirectory")
+	}
+
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("dispatchPlannable() result is not text content: %+v", result.Content[0])
+	}
+	if !strings.Contains(text.Text, "Dry Run") {
+		t.Errorf("dispatchPlannable() text = %q, want a dry run indicator", text.Text)
+	}
+
+	request.Params.Arguments.(map[string]interface{})["dry_run"] = false
+	if _, err := handler(context.Background(), request); err != nil {
+		t.Fatalf("dispatchPlannable() apply path unexpected error = %v", err)
+	}
+	if !applied {
+		t.Error("dry_run=false should have invoked CreateDirectory")
+	}
+}
+
+// TestCreateDirectoryPlanFlagsExistingPath verifies create_directory's Plan
+// surfaces a pre-flight error when the target path already exists.
+func TestCreateDirectoryPlanFlagsExistingPath(t *testing.T) {
+	mockClientImpl := &mockClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			return &client.DirectoryContents{Path: path}, nil
+		},
+	}
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	plan, err := createDirectoryPlan{s: server}.Plan(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]interface{}{"path": "/cyverse/home/test/exists"}},
+	})
+	if err != nil {
+		t.Fatalf("Plan() unexpected error = %v", err)
+	}
+	if len(plan.Errors) == 0 || !strings.Contains(plan.Errors[0], "already exists") {
+		t.Errorf("Plan().Errors = %v, want an already-exists error", plan.Errors)
+	}
+}
+
+// TestUploadFilePlanEstimatesSize verifies upload_file's Plan reports the
+// content length without uploading anything.
+func TestUploadFilePlanEstimatesSize(t *testing.T) {
+	var uploaded bool
+	mockClientImpl := &mockClient{
+		uploadFileFunc: func(ctx context.Context, path, content string, metadata map[string]interface{}) error {
+			uploaded = true
+			return nil
+		},
+	}
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	plan, err := uploadFilePlan{s: server}.Plan(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]interface{}{
+			"path":    "/cyverse/home/test/file.txt",
+			"content": "hello world",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Plan() unexpected error = %v", err)
+	}
+	if uploaded {
+		t.Error("Plan() should not upload anything")
+	}
+	if plan.Estimate != "11 bytes" {
+		t.Errorf("Plan().Estimate = %q, want \"11 bytes\"", plan.Estimate)
+	}
+}
+
+// TestStopAnalysisPlanReportsMissingAnalysis verifies stop_analysis's Plan
+// surfaces a pre-flight error for an analysis ID that doesn't resolve.
+func TestStopAnalysisPlanReportsMissingAnalysis(t *testing.T) {
+	mockClientImpl := &mockClient{
+		getAnalysisStatusFunc: func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
+			return nil, fmt.Errorf("no such analysis")
+		},
+	}
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	plan, err := stopAnalysisPlan{s: server}.Plan(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]interface{}{"analysis_id": "analysis-404"}},
+	})
+	if err != nil {
+		t.Fatalf("Plan() unexpected error = %v", err)
+	}
+	if len(plan.Errors) == 0 || !strings.Contains(plan.Errors[0], "analysis not found") {
+		t.Errorf("Plan().Errors = %v, want an analysis-not-found error", plan.Errors)
+	}
+}
+
+// TestLaunchAppAndWaitPlanFlagsMissingParams verifies launch_app_and_wait's
+// Plan surfaces the same missing-required-parameter errors a real launch
+// would hit, without actually launching anything.
+func TestLaunchAppAndWaitPlanFlagsMissingParams(t *testing.T) {
+	mockClientImpl := &mockClient{
+		getAppParametersFunc: func(ctx context.Context, systemID, appID string) (*client.AppParameters, error) {
+			return &client.AppParameters{
+				OverallJobType: "Interactive",
+				Groups: []client.ParameterGroup{
+					{Parameters: []client.Parameter{{ID: "input", Name: "Input File", Required: true}}},
+				},
+			}, nil
+		},
+	}
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	plan, err := launchAppAndWaitPlan{s: server}.Plan(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]interface{}{"app_id": "app-1"}},
+	})
+	if err != nil {
+		t.Fatalf("Plan() unexpected error = %v", err)
+	}
+	if len(plan.Errors) == 0 || !strings.Contains(plan.Errors[0], "Input File") {
+		t.Errorf("Plan().Errors = %v, want a missing Input File error", plan.Errors)
+	}
+	if plan.Parameters["job_type"] != "Interactive" {
+		t.Errorf("Plan().Parameters = %v, want job_type=Interactive", plan.Parameters)
+	}
+}
+
+// TestUnmarshalParams tests parameter unmarshaling
+func TestUnmarshalParams(t *testing.T) {
+	type testParams struct {
+		Name  string `json:"name"`
+		Limit int    `json:"limit"`
+	}
+
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		wantErr   bool
+	}{
+		{
+			name: "valid params",
+			arguments: map[string]interface{}{
+				"name":  "test",
+				"limit": 10,
+			},
+			wantErr: false,
+		},
+		{
+			name: "empty params",
+			arguments: map[string]interface{}{},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Arguments: tt.arguments,
+				},
+			}
+
+			var params testParams
+			err := unmarshalParams(request, &params)
+
+			if tt.wantErr && err == nil {
+				t.Error("unmarshalParams() expected error but got none")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unmarshalParams() unexpected error = %v", err)
+			}
+
+			if !tt.wantErr && tt.arguments["name"] != nil {
+				if params.Name != tt.arguments["name"].(string) {
+					t.Errorf("unmarshalParams() name = %v, want %v", params.Name, tt.arguments["name"])
+				}
+			}
+		})
+	}
+}
+
+// TestNewFormationMCPServer tests server creation
+func TestNewFormationMCPServer(t *testing.T) {
+	mockWorkflowsImpl := &mockWorkflows{}
+	mockClientImpl := &mockClient{}
+
+	server := NewFormationMCPServer(mockWorkflowsImpl, mockClientImpl)
+
+	if server == nil {
+		t.Fatal("NewFormationMCPServer() returned nil")
+	}
+
+	if server.server == nil {
+		t.Error("NewFormationMCPServer() MCP server is nil")
+	}
+
+	if server.workflows == nil {
+		t.Error("NewFormationMCPServer() workflows is nil")
+	}
+
+	if server.client == nil {
+		t.Error("NewFormationMCPServer() client is nil")
+	}
+}
+
+// TestToolRegistration verifies every registered tool builds a well-formed
+// definition for a server instance.
+func TestToolRegistration(t *testing.T) {
+	mockWorkflowsImpl := &mockWorkflows{}
+	mockClientImpl := &mockClient{}
+
+	server := NewFormationMCPServer(mockWorkflowsImpl, mockClientImpl)
+
+	expectedTools := []string{
+		"list_apps",
+		"get_app_parameters",
+		"describe_app_parameters",
+		"list_app_resource_capabilities",
+		"estimate_cost",
+		"launch_app_and_wait",
+		"launch_app_and_stream",
+		"get_analysis_status",
+		"list_running_analyses",
+		"stop_analysis",
+		"open_in_browser",
+		"snapshot_analysis",
+		"resume_from_snapshot",
+		"list_snapshots",
+		"launch_batch",
+		"get_batch_status",
+		"list_tool_history",
+		"replay_tool_call",
+		"browse_data",
+		"create_directory",
+		"upload_file",
+		"upload_file_chunk",
+		"upload_file_from_path",
+		"read_file_range",
+		"set_metadata",
+		"delete_data",
+		"search_metadata",
+		"delete_by_selector",
+		"list_deleted",
+		"restore_data",
+		"purge_deleted",
+		"get_delete_job",
+		"list_delete_jobs",
+		"cancel_delete_job",
+		"create_analysis_alert",
+		"list_analysis_alerts",
+		"delete_analysis_alert",
+		"watch_analysis",
+		"tail_analysis_logs",
+		"apply_data_plan",
+		"bulk_create_directories",
+		"bulk_upload_files",
+		"bulk_set_metadata",
+		"bulk_delete_data",
+		"set_log_level",
+		"get_log_level",
+	}
+
+	names := List()
+	if len(names) != len(expectedTools) {
+		t.Fatalf("List() = %v tools, want %v", len(names), len(expectedTools))
+	}
+
+	for _, toolName := range expectedTools {
+		factory, ok := Lookup(toolName)
+		if !ok {
+			t.Errorf("tool %v not registered", toolName)
+			continue
+		}
+
+		tool, handler := factory(server)
+
+		if tool.Name != toolName {
+			t.Errorf("Tool %v not properly defined", toolName)
+		}
+		if tool.Description == "" {
+			t.Errorf("Tool %v has no description", toolName)
+		}
+		if handler == nil {
+			t.Errorf("Tool %v has no handler", toolName)
+		}
+	}
+}
+
+// TestRegistryRegisterLookupList verifies the plain Register/Lookup/List/
+// Remove mechanics independent of the built-in tool set.
+func TestRegistryRegisterLookupList(t *testing.T) {
+	const name = "test_only_tool_registry_roundtrip"
+
+	if _, ok := Lookup(name); ok {
+		t.Fatalf("%v should not be registered yet", name)
+	}
+
+	Register(name, func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+		return mcp.Tool{Name: name, Description: "test tool"},
+			func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("ok"), nil
+			}
+	})
+	defer Remove(name)
+
+	factory, ok := Lookup(name)
+	if !ok {
+		t.Fatalf("%v not found after Register", name)
+	}
+
+	found := false
+	for _, n := range List() {
+		if n == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("List() does not include %v", name)
+	}
+
+	tool, handler := factory(&FormationMCPServer{})
+	if tool.Name != name {
+		t.Errorf("factory returned tool named %v, want %v", tool.Name, name)
+	}
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handler() unexpected error = %v", err)
+	}
+	if result == nil {
+		t.Error("handler() returned nil result")
+	}
+}
+
+// TestRegisterDuplicatePanics verifies Register rejects a name collision,
+// matching Terraform's backend/init behavior for duplicate registrations.
+func TestRegisterDuplicatePanics(t *testing.T) {
+	const name = "test_only_tool_registry_duplicate"
+
+	factory := func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+		return mcp.Tool{Name: name}, nil
+	}
+
+	Register(name, factory)
+	defer Remove(name)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() did not panic on duplicate name")
+		}
+	}()
+	Register(name, factory)
+}
+
+// TestRegisterDeprecated verifies a deprecated alias delegates to the
+// replacement tool's handler while renaming itself and warning in its
+// description.
+func TestRegisterDeprecated(t *testing.T) {
+	const (
+		newName = "test_only_tool_registry_new"
+		oldName = "test_only_tool_registry_old"
+	)
+
+	Register(newName, func(s *FormationMCPServer) (mcp.Tool, ToolHandler) {
+		return mcp.Tool{Name: newName, Description: "does the thing"},
+			func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("from new"), nil
+			}
+	})
+	RegisterDeprecated(oldName, newName)
+	defer Remove(newName)
+	defer Remove(oldName)
+
+	factory, ok := Lookup(oldName)
+	if !ok {
+		t.Fatalf("%v not registered", oldName)
+	}
+
+	tool, handler := factory(&FormationMCPServer{})
+	if tool.Name != oldName {
+		t.Errorf("tool.Name = %v, want %v", tool.Name, oldName)
+	}
+	if !strings.Contains(tool.Description, newName) {
+		t.Errorf("tool.Description = %q, want it to mention %v", tool.Description, newName)
+	}
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handler() unexpected error = %v", err)
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok || text.Text != "from new" {
+		t.Errorf("handler() = %v, want delegation to the new tool's handler", result)
+	}
+}
+
+// TestRegisterDeprecatedUnknownTarget verifies the shim panics instead of
+// silently no-opping when its replacement was never registered.
+func TestRegisterDeprecatedUnknownTarget(t *testing.T) {
+	const oldName = "test_only_tool_registry_orphaned_shim"
+
+	RegisterDeprecated(oldName, "test_only_tool_registry_does_not_exist")
+	defer Remove(oldName)
+
+	factory, ok := Lookup(oldName)
+	if !ok {
+		t.Fatalf("%v not registered", oldName)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("factory() did not panic for an unregistered replacement")
+		}
+	}()
+	factory(&FormationMCPServer{})
+}
+
+// TestToolSchemaValidation verifies tool input schemas
+func TestToolSchemaValidation(t *testing.T) {
+	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+
+	// Test list_apps schema
+	listAppsTool := server.listAppsTool()
+	props := listAppsTool.InputSchema.Properties
+	if props == nil {
+		t.Error("list_apps has no properties defined")
+	}
+
+	// Test launch_app_and_wait schema
+	launchTool := server.launchAppAndWaitTool()
+	if launchTool.InputSchema.Required == nil || len(launchTool.InputSchema.Required) == 0 {
+		t.Error("launch_app_and_wait has no required parameters")
+	}
+
+	appIDFound := false
+	for _, req := range launchTool.InputSchema.Required {
+		if req == "app_id" {
+			appIDFound = true
+			break
+		}
+	}
+	if !appIDFound {
+		t.Error("launch_app_and_wait doesn't require app_id parameter")
+	}
+
+	// resource_requests is optional, not required, and must describe the
+	// gpu/qat/hugepages_2mi/sriov_nic device-plugin vocabulary.
+	for _, req := range launchTool.InputSchema.Required {
+		if req == "resource_requests" {
+			t.Error("launch_app_and_wait should not require resource_requests")
+		}
+	}
+
+	resourceRequests, ok := launchTool.InputSchema.Properties["resource_requests"].(map[string]interface{})
+	if !ok {
+		t.Fatal("launch_app_and_wait has no resource_requests property")
+	}
+	resourceProps, ok := resourceRequests["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("launch_app_and_wait's resource_requests has no nested properties")
+	}
+	for _, field := range []string{"gpu", "qat", "hugepages_2mi", "sriov_nic"} {
+		if _, ok := resourceProps[field]; !ok {
+			t.Errorf("launch_app_and_wait's resource_requests is missing %q", field)
+		}
+	}
+
+	// Test list_app_resource_capabilities schema
+	capabilitiesTool := server.listAppResourceCapabilitiesTool()
+	capAppIDFound := false
+	for _, req := range capabilitiesTool.InputSchema.Required {
+		if req == "app_id" {
+			capAppIDFound = true
+			break
+		}
+	}
+	if !capAppIDFound {
+		t.Error("list_app_resource_capabilities doesn't require app_id parameter")
+	}
+
+	// max_cost/confirm_over_cost are optional, not required
+	for _, req := range launchTool.InputSchema.Required {
+		if req == "max_cost" || req == "confirm_over_cost" {
+			t.Errorf("launch_app_and_wait should not require %q", req)
+		}
+	}
+	for _, field := range []string{"max_cost", "confirm_over_cost"} {
+		if _, ok := launchTool.InputSchema.Properties[field]; !ok {
+			t.Errorf("launch_app_and_wait is missing %q", field)
+		}
+	}
+
+	// Test estimate_cost schema
+	estimateCostTool := server.estimateCostTool()
+	estimateAppIDFound := false
+	for _, req := range estimateCostTool.InputSchema.Required {
+		if req == "app_id" {
+			estimateAppIDFound = true
+			break
+		}
+	}
+	if !estimateAppIDFound {
+		t.Error("estimate_cost doesn't require app_id parameter")
+	}
+}
+
+// Helper to convert interface to JSON and back
+func mustMarshal(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// TestHandleUploadFileChunk tests the upload_file_chunk handler decodes
+// base64 content and forwards it to the client.
+func TestHandleUploadFileChunk(t *testing.T) {
+	var capturedUploadID, capturedPath string
+	var capturedOffset int64
+	var capturedChunk []byte
+	var capturedIsFinal bool
+
+	mockClientImpl := &mockClient{
+		uploadFileChunkFunc: func(ctx context.Context, uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*client.UploadChunkResult, error) {
+			capturedUploadID = uploadID
+			capturedPath = path
+			capturedOffset = offset
+			capturedChunk = chunk
+			capturedIsFinal = isFinal
+			return &client.UploadChunkResult{UploadID: "session-1", Offset: offset + int64(len(chunk))}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "upload_file_chunk",
+			Arguments: map[string]interface{}{
+				"upload_id": "session-1",
+				"path":      "/cyverse/home/test/big.bin",
+				"offset":    float64(10),
+				"chunk":     base64.StdEncoding.EncodeToString([]byte("hello")),
+				"is_final":  false,
+			},
+		},
+	}
+
+	result, err := server.handleUploadFileChunk(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleUploadFileChunk() unexpected error = %v", err)
+	}
+	if result == nil {
+		t.Fatal("handleUploadFileChunk() returned nil result")
+	}
+
+	if capturedUploadID != "session-1" {
+		t.Errorf("upload_id = %q, want session-1", capturedUploadID)
+	}
+	if capturedPath != "/cyverse/home/test/big.bin" {
+		t.Errorf("path = %q, want /cyverse/home/test/big.bin", capturedPath)
+	}
+	if capturedOffset != 10 {
+		t.Errorf("offset = %d, want 10", capturedOffset)
+	}
+	if string(capturedChunk) != "hello" {
+		t.Errorf("chunk = %q, want %q", capturedChunk, "hello")
+	}
+	if capturedIsFinal {
+		t.Error("is_final = true, want false")
+	}
+}
+
+// TestHandleUploadFileChunkFinalizes verifies a final chunk produces a
+// completion message rather than a "send the next chunk" prompt.
+func TestHandleUploadFileChunkFinalizes(t *testing.T) {
+	mockClientImpl := &mockClient{
+		uploadFileChunkFunc: func(ctx context.Context, uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*client.UploadChunkResult, error) {
+			return &client.UploadChunkResult{UploadID: uploadID, Offset: offset + int64(len(chunk)), Complete: isFinal}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "upload_file_chunk",
+			Arguments: map[string]interface{}{
+				"upload_id": "session-1",
+				"path":      "/cyverse/home/test/big.bin",
+				"offset":    float64(10),
+				"chunk":     base64.StdEncoding.EncodeToString([]byte("hello")),
+				"is_final":  true,
+			},
+		},
+	}
+
+	result, err := server.handleUploadFileChunk(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleUploadFileChunk() unexpected error = %v", err)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Uploaded file") {
+		t.Errorf("handleUploadFileChunk() final chunk result = %q, want a completion message", text)
+	}
+}
+
+// TestHandleUploadFileChunkInvalidBase64 verifies malformed base64 is
+// rejected before reaching the client.
+func TestHandleUploadFileChunkInvalidBase64(t *testing.T) {
+	mockClientImpl := &mockClient{
+		uploadFileChunkFunc: func(ctx context.Context, uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*client.UploadChunkResult, error) {
+			t.Fatal("client should not be called with invalid base64")
+			return nil, nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "upload_file_chunk",
+			Arguments: map[string]interface{}{
+				"path":   "/cyverse/home/test/big.bin",
+				"offset": float64(0),
+				"chunk":  "not-valid-base64!!!",
+			},
+		},
+	}
+
+	if _, err := server.handleUploadFileChunk(context.Background(), request); err == nil {
+		t.Error("handleUploadFileChunk() expected error for invalid base64, got none")
+	}
+}
+
+// TestHandleUploadFileChunkPropagatesOffsetMismatch verifies the handler
+// surfaces a rejection when a second chunk resumes from the wrong offset,
+// using the real FormationClient so the session store is actually
+// exercised, not just mocked.
+func TestHandleUploadFileChunkPropagatesOffsetMismatch(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer httpServer.Close()
+
+	realClient := client.NewFormationClient(httpServer.URL, "test-token", "", "", client.DefaultRetryPolicy(), client.DefaultRateLimitPolicy())
+	server := NewFormationMCPServer(&mockWorkflows{}, realClient)
+
+	start := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "upload_file_chunk",
+			Arguments: map[string]interface{}{
+				"path":   "/cyverse/home/test/big.bin",
+				"offset": float64(0),
+				"chunk":  base64.StdEncoding.EncodeToString([]byte("hello")),
+			},
+		},
+	}
+
+	result, err := server.handleUploadFileChunk(context.Background(), start)
+	if err != nil {
+		t.Fatalf("first chunk unexpected error = %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	uploadID := text[strings.Index(text, "upload_id=`")+len("upload_id=`"):]
+	uploadID = uploadID[:strings.Index(uploadID, "`")]
+
+	mismatched := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "upload_file_chunk",
+			Arguments: map[string]interface{}{
+				"upload_id": uploadID,
+				"path":      "/cyverse/home/test/big.bin",
+				"offset":    float64(3), // wrong: should resume at 5
+				"chunk":     base64.StdEncoding.EncodeToString([]byte("world")),
+			},
+		},
+	}
+
+	if _, err := server.handleUploadFileChunk(context.Background(), mismatched); err == nil {
+		t.Fatal("expected an offset mismatch error, got none")
+	}
+}
+
+// TestHandleUploadFileFromPath tests the upload_file_from_path handler
+// streams a local file's contents and size to UploadFileStream.
+func TestHandleUploadFileFromPath(t *testing.T) {
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "input.bin")
+	content := []byte("the quick brown fox jumps over the lazy dog")
+	if err := os.WriteFile(localPath, content, 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var capturedPath string
+	var capturedSize int64
+	var capturedContent []byte
+
+	mockClientImpl := &mockClient{
+		uploadFileStreamFunc: func(ctx context.Context, path string, r io.Reader, size int64, opts client.UploadOptions) error {
+			capturedPath = path
+			capturedSize = size
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("failed to read streamed content: %v", err)
+			}
+			capturedContent = data
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "upload_file_from_path",
+			Arguments: map[string]interface{}{
+				"local_path": localPath,
+				"path":       "/cyverse/home/test/input.bin",
+			},
+		},
+	}
+
+	result, err := server.handleUploadFileFromPath(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleUploadFileFromPath() unexpected error = %v", err)
+	}
+	if result == nil {
+		t.Fatal("handleUploadFileFromPath() returned nil result")
+	}
+
+	if capturedPath != "/cyverse/home/test/input.bin" {
+		t.Errorf("path = %q, want /cyverse/home/test/input.bin", capturedPath)
+	}
+	if capturedSize != int64(len(content)) {
+		t.Errorf("size = %d, want %d", capturedSize, len(content))
+	}
+	if string(capturedContent) != string(content) {
+		t.Errorf("streamed content = %q, want %q", capturedContent, content)
+	}
+}
+
+// TestHandleUploadFileFromPathMissingFile verifies a missing local file
+// surfaces an error rather than calling UploadFileStream.
+func TestHandleUploadFileFromPathMissingFile(t *testing.T) {
+	mockClientImpl := &mockClient{
+		uploadFileStreamFunc: func(ctx context.Context, path string, r io.Reader, size int64, opts client.UploadOptions) error {
+			t.Fatal("UploadFileStream should not be called for a missing local file")
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "upload_file_from_path",
+			Arguments: map[string]interface{}{
+				"local_path": "/does/not/exist",
+				"path":       "/cyverse/home/test/input.bin",
+			},
+		},
+	}
+
+	if _, err := server.handleUploadFileFromPath(context.Background(), request); err == nil {
+		t.Error("handleUploadFileFromPath() expected error for missing local file, got none")
+	}
+}
+
+// TestWithRequestContextInjectsRequestIDAndLogger verifies registerTools'
+// withRequestContext wrapping gives every tool invocation its own request id
+// and a logger in context, so a single call's log lines - login, parameter
+// fetch, launch, poll - can be tied back together and to the tool that
+// triggered them.
+func TestWithRequestContextInjectsRequestIDAndLogger(t *testing.T) {
+	var gotRequestID string
+	var gotLogger logging.Logger
+
+	inner := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		gotRequestID, _ = client.RequestIDFromContext(ctx)
+		gotLogger = client.LoggerFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	}
+
+	wrapped := withRequestContext(logging.LogBackendSlog, "launch_app_and_wait", inner)
+
+	if _, err := wrapped(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("wrapped handler unexpected error = %v", err)
+	}
+
+	if gotRequestID == "" {
+		t.Error("withRequestContext() did not inject a request id")
+	}
+	if gotLogger == nil {
+		t.Error("withRequestContext() did not inject a logger")
+	}
+
+	var otherRequestID string
+	inner2 := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		otherRequestID, _ = client.RequestIDFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	}
+	wrapped2 := withRequestContext(logging.LogBackendSlog, "launch_app_and_wait", inner2)
+	if _, err := wrapped2(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("wrapped handler unexpected error = %v", err)
+	}
+	if otherRequestID == "" || otherRequestID == gotRequestID {
+		t.Errorf("withRequestContext() request ids = %q, %q, want distinct non-empty ids", gotRequestID, otherRequestID)
+	}
+}
+
+// TestHandleDescribeAppParameters tests the describe_app_parameters handler.
+func TestHandleDescribeAppParameters(t *testing.T) {
+	mockClientImpl := &mockClient{
+		getAppParametersFunc: func(ctx context.Context, systemID, appID string) (*client.AppParameters, error) {
+			return &client.AppParameters{
+				Groups: []client.ParameterGroup{
+					{
+						Parameters: []client.Parameter{
+							{ID: "name", Name: "Name", Required: true, Type: "string"},
+							{ID: "mode", Name: "Mode", Type: "enum", EnumValues: []string{"fast", "slow"}},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "describe_app_parameters",
+			Arguments: map[string]interface{}{"app_id": "test-app"},
+		},
+	}
+
+	result, err := server.handleDescribeAppParameters(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleDescribeAppParameters() unexpected error = %v", err)
+	}
+
+	resource, ok := result.Content[1].(mcp.EmbeddedResource)
+	if !ok {
+		t.Fatal("handleDescribeAppParameters() result is not an embedded resource")
+	}
+	textResource, ok := resource.Resource.(mcp.TextResourceContents)
+	if !ok {
+		t.Fatal("handleDescribeAppParameters() resource is not text")
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(textResource.Text), &schema); err != nil {
+		t.Fatalf("handleDescribeAppParameters() schema is not valid JSON: %v", err)
+	}
+
+	required, ok := schema["required"].([]interface{})
+	if !ok || len(required) != 1 || required[0] != "name" {
+		t.Errorf("schema required = %v, want [\"name\"]", schema["required"])
+	}
+
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("schema properties is not an object")
+	}
+	mode, ok := properties["mode"].(map[string]interface{})
+	if !ok || mode["type"] != "string" {
+		t.Fatalf("schema properties.mode = %v, want a string-typed property", properties["mode"])
+	}
+}
+
+// TestHandleCreateAnalysisAlert tests the create_analysis_alert handler.
+func TestHandleCreateAnalysisAlert(t *testing.T) {
+	var capturedAlert client.AnalysisAlert
+
+	mockClientImpl := &mockClient{
+		createAnalysisAlertFunc: func(ctx context.Context, alert client.AnalysisAlert) (*client.AnalysisAlert, error) {
+			capturedAlert = alert
+			alert.ID = "alert-1"
+			alert.Phase = client.AlertPhasePending
+			return &alert, nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "create_analysis_alert",
+			Arguments: map[string]interface{}{
+				"analysis_id":   "analysis-123",
+				"events":        []interface{}{"status_changed", "completed"},
+				"http_webhooks": []interface{}{map[string]interface{}{"url": "https://example.test/hook"}},
+				"secret":        "shh",
+			},
+		},
+	}
+
+	result, err := server.handleCreateAnalysisAlert(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleCreateAnalysisAlert() unexpected error = %v", err)
+	}
+
+	if capturedAlert.AnalysisID != "analysis-123" {
+		t.Errorf("captured alert AnalysisID = %q, want analysis-123", capturedAlert.AnalysisID)
+	}
+	if len(capturedAlert.HTTPWebhooks) != 1 || capturedAlert.HTTPWebhooks[0].URL != "https://example.test/hook" {
+		t.Errorf("captured alert HTTPWebhooks = %v, want one pointing at https://example.test/hook", capturedAlert.HTTPWebhooks)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatal("handleCreateAnalysisAlert() result is not text content")
+	}
+	if !strings.Contains(textContent.Text, "alert-1") {
+		t.Errorf("handleCreateAnalysisAlert() result = %q, want it to reference the new alert id", textContent.Text)
+	}
+}
+
+// TestHandleListAnalysisAlerts tests the list_analysis_alerts handler.
+func TestHandleListAnalysisAlerts(t *testing.T) {
+	mockClientImpl := &mockClient{
+		listAnalysisAlertsFunc: func(ctx context.Context, analysisID string) ([]client.AnalysisAlert, error) {
+			return []client.AnalysisAlert{
+				{ID: "alert-1", AnalysisID: analysisID, Phase: client.AlertPhaseActive},
+			}, nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "list_analysis_alerts",
+			Arguments: map[string]interface{}{"analysis_id": "analysis-123"},
+		},
+	}
+
+	result, err := server.handleListAnalysisAlerts(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleListAnalysisAlerts() unexpected error = %v", err)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatal("handleListAnalysisAlerts() result is not text content")
+	}
+	if !strings.Contains(textContent.Text, "alert-1") {
+		t.Errorf("handleListAnalysisAlerts() result = %q, want it to list alert-1", textContent.Text)
+	}
+}
+
+// TestHandleDeleteAnalysisAlert tests the delete_analysis_alert handler.
+func TestHandleDeleteAnalysisAlert(t *testing.T) {
+	var capturedAlertID string
+
+	mockClientImpl := &mockClient{
+		deleteAnalysisAlertFunc: func(ctx context.Context, alertID string) error {
+			capturedAlertID = alertID
+			return nil
+		},
+	}
+
+	server := NewFormationMCPServer(&mockWorkflows{}, mockClientImpl)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "delete_analysis_alert",
+			Arguments: map[string]interface{}{"alert_id": "alert-1"},
+		},
+	}
+
+	if _, err := server.handleDeleteAnalysisAlert(context.Background(), request); err != nil {
+		t.Fatalf("handleDeleteAnalysisAlert() unexpected error = %v", err)
+	}
+	if capturedAlertID != "alert-1" {
+		t.Errorf("captured alert id = %q, want alert-1", capturedAlertID)
+	}
+}
+
+func TestHandleSetLogLevelNotWiredUp(t *testing.T) {
+	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "set_log_level",
+			Arguments: map[string]interface{}{"level": "debug"},
+		},
+	}
+
+	if _, err := server.handleSetLogLevel(context.Background(), request); err == nil {
+		t.Fatal("handleSetLogLevel() expected error when log level controls are not wired up")
+	}
+}
+
+func TestHandleSetLogLevelProcessWide(t *testing.T) {
+	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+	baseLevel := &slog.LevelVar{}
+	baseLevel.Set(slog.LevelInfo)
+	server.SetLogLevelControls(baseLevel, logging.NewSubsystemLevels())
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "set_log_level",
+			Arguments: map[string]interface{}{"level": "debug"},
+		},
+	}
+
+	result, err := server.handleSetLogLevel(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleSetLogLevel() unexpected error = %v", err)
+	}
+	if baseLevel.Level() != slog.LevelDebug {
+		t.Errorf("baseLevel = %v, want debug", baseLevel.Level())
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatal("handleSetLogLevel() result is not text content")
+	}
+	if !strings.Contains(textContent.Text, "process-wide") {
+		t.Errorf("handleSetLogLevel() result = %q, want it to mention process-wide", textContent.Text)
+	}
+}
+
+func TestHandleSetLogLevelSubsystem(t *testing.T) {
+	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+	baseLevel := &slog.LevelVar{}
+	baseLevel.Set(slog.LevelInfo)
+	subsystemLevels := logging.NewSubsystemLevels()
+	server.SetLogLevelControls(baseLevel, subsystemLevels)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "set_log_level",
+			Arguments: map[string]interface{}{"level": "debug", "subsystem": "client"},
+		},
+	}
+
+	if _, err := server.handleSetLogLevel(context.Background(), request); err != nil {
+		t.Fatalf("handleSetLogLevel() unexpected error = %v", err)
+	}
+
+	if level, ok := subsystemLevels.Get("client"); !ok || level != slog.LevelDebug {
+		t.Errorf("subsystemLevels.Get(client) = %v, %v, want debug, true", level, ok)
+	}
+	if baseLevel.Level() != slog.LevelInfo {
+		t.Errorf("baseLevel = %v, want it unchanged at info", baseLevel.Level())
+	}
+}
+
+func TestHandleSetLogLevelInvalidLevel(t *testing.T) {
+	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+	server.SetLogLevelControls(&slog.LevelVar{}, logging.NewSubsystemLevels())
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "set_log_level",
+			Arguments: map[string]interface{}{"level": "verbose"},
+		},
+	}
+
+	if _, err := server.handleSetLogLevel(context.Background(), request); err == nil {
+		t.Fatal("handleSetLogLevel() expected error for unrecognized level")
+	}
+}
+
+func TestHandleGetLogLevel(t *testing.T) {
+	server := NewFormationMCPServer(&mockWorkflows{}, &mockClient{})
+	baseLevel := &slog.LevelVar{}
+	baseLevel.Set(slog.LevelWarn)
+	subsystemLevels := logging.NewSubsystemLevels()
+	subsystemLevels.Set("workflows", slog.LevelDebug)
+	server.SetLogLevelControls(baseLevel, subsystemLevels)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "get_log_level",
+		},
+	}
+
+	result, err := server.handleGetLogLevel(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleGetLogLevel() unexpected error = %v", err)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatal("handleGetLogLevel() result is not text content")
+	}
+
+	var report logLevelReport
+	if err := json.Unmarshal([]byte(textContent.Text), &report); err != nil {
+		t.Fatalf("unmarshaling handleGetLogLevel() result: %v", err)
+	}
+	if report.Level != "WARN" {
+		t.Errorf("report.Level = %q, want WARN", report.Level)
+	}
+	if report.Subsystems["workflows"] != "DEBUG" {
+		t.Errorf("report.Subsystems[workflows] = %q, want DEBUG", report.Subsystems["workflows"])
 	}
-	return b
 }
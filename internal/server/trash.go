@@ -0,0 +1,247 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Trash marker metadata attributes, written onto a path by trashPath and
+// read back by findTrashMarkers. Namespaced under "cyverse:trash:" so they
+// don't collide with a caller's own metadata.
+const (
+	trashMarkerIDAttr   = "cyverse:trash:marker_id"
+	trashDeletedAtAttr  = "cyverse:trash:deleted_at"
+	trashDeletedByAttr  = "cyverse:trash:deleted_by"
+	trashVersionIDAttr  = "cyverse:trash:version_id"
+	trashRestoredAtAttr = "cyverse:trash:restored_at"
+)
+
+// trashMarker is a delete_data trash marker, as recorded in a path's
+// metadata and reconstructed by findTrashMarkers.
+type trashMarker struct {
+	Path      string
+	MarkerID  string
+	DeletedAt string
+	DeletedBy string
+	VersionID string
+}
+
+// trashPath tags path as trashed by writing a marker to its metadata,
+// rather than calling client.DeleteData - delete_data's default, unless
+// purge is requested. The Formation client has no path rename/move, so
+// "moving to trash" is modeled as an in-place marker instead of an actual
+// sidecar collection move.
+func (s *FormationMCPServer) trashPath(ctx context.Context, path, versionID string) (trashMarker, error) {
+	var buf [10]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return trashMarker{}, fmt.Errorf("failed to generate trash marker id: %w", err)
+	}
+
+	marker := trashMarker{
+		Path:      path,
+		MarkerID:  "trash-" + hex.EncodeToString(buf[:]),
+		DeletedAt: time.Now().UTC().Format(time.RFC3339),
+		DeletedBy: s.currentUser,
+		VersionID: versionID,
+	}
+
+	metadata := map[string]interface{}{
+		trashMarkerIDAttr:  marker.MarkerID,
+		trashDeletedAtAttr: marker.DeletedAt,
+		trashDeletedByAttr: marker.DeletedBy,
+	}
+	if versionID != "" {
+		metadata[trashVersionIDAttr] = versionID
+	}
+
+	if err := s.client.SetMetadata(ctx, path, metadata, false); err != nil {
+		return trashMarker{}, err
+	}
+
+	slog.Info("moved data to trash", "path", path, "marker_id", marker.MarkerID)
+	return marker, nil
+}
+
+// findTrashMarkers returns every unrestored trash marker under pathPrefix
+// (every marker, if pathPrefix is empty). SetMetadata can only add or
+// replace AVUs, not remove them, so a restored marker's attributes are
+// still present - restore_data instead adds trashRestoredAtAttr, and this
+// filters those back out rather than ever truly deleting the marker.
+func (s *FormationMCPServer) findTrashMarkers(ctx context.Context, pathPrefix string) ([]trashMarker, error) {
+	result, err := s.client.SearchMetadata(ctx, client.MetadataQuery{
+		PathPrefix: pathPrefix,
+		Predicates: []client.MetadataPredicate{{Attribute: trashMarkerIDAttr, Operator: "like", Value: "%"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var markers []trashMarker
+	for _, match := range result.Matches {
+		if _, restored := match.Metadata[trashRestoredAtAttr]; restored {
+			continue
+		}
+		markers = append(markers, trashMarker{
+			Path:      match.Path,
+			MarkerID:  fmt.Sprintf("%v", match.Metadata[trashMarkerIDAttr]),
+			DeletedAt: fmt.Sprintf("%v", match.Metadata[trashDeletedAtAttr]),
+			DeletedBy: fmt.Sprintf("%v", match.Metadata[trashDeletedByAttr]),
+			VersionID: fmt.Sprintf("%v", match.Metadata[trashVersionIDAttr]),
+		})
+	}
+	return markers, nil
+}
+
+func (s *FormationMCPServer) listDeletedTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_deleted",
+		Description: "List paths delete_data has moved to the trash (without purge=true) that haven't been restored or purged yet",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"path_prefix": map[string]interface{}{
+					"type":        "string",
+					"description": "Restrict results to paths under this prefix",
+				},
+			},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleListDeleted(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		PathPrefix string `json:"path_prefix"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	markers, err := s.findTrashMarkers(ctx, params.PathPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## Trash (%d)\n\n", len(markers)))
+	for _, m := range markers {
+		builder.WriteString(fmt.Sprintf("- %s - marker `%s`, deleted %s by %s\n", m.Path, m.MarkerID, m.DeletedAt, m.DeletedBy))
+	}
+	return mcp.NewToolResultText(builder.String()), nil
+}
+
+func (s *FormationMCPServer) restoreDataTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "restore_data",
+		Description: "Reverse a delete_data trash marker by marker_id, removing it from list_deleted",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"marker_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The marker_id returned by delete_data",
+				},
+			},
+			Required: []string{"marker_id"},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handleRestoreData(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		MarkerID string `json:"marker_id"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+
+	markers, err := s.findTrashMarkers(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var found *trashMarker
+	for i := range markers {
+		if markers[i].MarkerID == params.MarkerID {
+			found = &markers[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no trash marker found with id %q", params.MarkerID)
+	}
+
+	if err := s.client.SetMetadata(ctx, found.Path, map[string]interface{}{trashRestoredAtAttr: time.Now().UTC().Format(time.RFC3339)}, false); err != nil {
+		return nil, err
+	}
+
+	slog.Info("restored data from trash", "path", found.Path, "marker_id", found.MarkerID)
+	return mcp.NewToolResultText(fmt.Sprintf("✅ Restored: %s (marker %s)", found.Path, found.MarkerID)), nil
+}
+
+func (s *FormationMCPServer) purgeDeletedTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "purge_deleted",
+		Description: "Permanently remove trashed paths: either one marker_id, or every unrestored marker older than older_than_seconds",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"marker_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Purge only the path tagged with this marker_id",
+				},
+				"older_than_seconds": map[string]interface{}{
+					"type":        "integer",
+					"description": "Purge every unrestored marker deleted more than this many seconds ago",
+				},
+			},
+		},
+	}
+}
+
+func (s *FormationMCPServer) handlePurgeDeleted(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var params struct {
+		MarkerID         string `json:"marker_id"`
+		OlderThanSeconds int    `json:"older_than_seconds"`
+	}
+	if err := unmarshalParams(request, &params); err != nil {
+		return nil, err
+	}
+	if params.MarkerID == "" && params.OlderThanSeconds <= 0 {
+		return nil, fmt.Errorf("purge_deleted requires either marker_id or a positive older_than_seconds")
+	}
+
+	markers, err := s.findTrashMarkers(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-time.Duration(params.OlderThanSeconds) * time.Second)
+
+	var purged []string
+	for _, m := range markers {
+		if params.MarkerID != "" {
+			if m.MarkerID != params.MarkerID {
+				continue
+			}
+		} else if deletedAt, err := time.Parse(time.RFC3339, m.DeletedAt); err != nil || deletedAt.After(cutoff) {
+			continue
+		}
+
+		if err := s.client.DeleteData(ctx, m.Path, true, false); err != nil {
+			slog.Warn("failed to purge trashed path", "path", m.Path, "marker_id", m.MarkerID, "error", err)
+			continue
+		}
+		purged = append(purged, m.Path)
+		slog.Info("purged trashed data", "path", m.Path, "marker_id", m.MarkerID)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("✅ Purged %d path(s):\n%s", len(purged), strings.Join(purged, "\n"))), nil
+}
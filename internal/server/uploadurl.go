@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// sourceURLHTTPClient fetches a source_url body for uploadFileFromURL. A
+// generous timeout covers large files without tying the tool call to the
+// request's own deadline (ctx still governs cancellation).
+var sourceURLHTTPClient = &http.Client{Timeout: 10 * time.Minute}
+
+// uploadFileFromURL fetches sourceURL and streams it into iRODS at path,
+// returning the number of bytes uploaded. UploadFileStream needs a known
+// size upfront, and an HTTP response's Content-Length isn't trustworthy
+// (missing, or lying, for chunked/compressed responses), so the body is
+// spooled to a temp file first to learn its real size - the bytes still
+// never round-trip through the MCP caller, they just take a brief detour
+// through local disk instead of content.
+func (s *FormationMCPServer) uploadFileFromURL(ctx context.Context, path, sourceURL string, metadata map[string]interface{}) (int64, error) {
+	parsed, err := url.Parse(sourceURL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid source_url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return 0, fmt.Errorf("unsupported source_url scheme %q: want http or https", parsed.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("building source_url request: %w", err)
+	}
+
+	resp, err := sourceURLHTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("fetching source_url: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("fetching source_url: unexpected status %s", resp.Status)
+	}
+
+	tmp, err := os.CreateTemp("", "formation-mcp-upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("creating temp file for source_url: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("downloading source_url: %w", err)
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("rewinding source_url download: %w", err)
+	}
+
+	if err := s.client.UploadFileStream(ctx, path, tmp, size, client.UploadOptions{Metadata: metadata}); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
@@ -0,0 +1,265 @@
+package workflows
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// watcherTick is the slowest AnalysisWatcher's single background goroutine
+// ever wakes up to check whether any watched analysis is due for a poll.
+// It bounds how quickly a newly adjusted interval (see nextPollInterval)
+// takes effect, not how often Formation is actually called - see
+// tickInterval, which tightens it up for a watcher configured with a
+// faster basePollInterval than this.
+const watcherTick = 100 * time.Millisecond
+
+// tickInterval returns how often run's background goroutine should wake
+// up for a watcher configured with basePollInterval: never slower than
+// watcherTick, and never slower than basePollInterval itself, so a watch
+// whose interval is shorter than watcherTick (as this package's own tests
+// configure, down to 10ms) still gets checked before its nextPoll arrives
+// instead of waiting for the next fixed tick boundary.
+func tickInterval(basePollInterval time.Duration) time.Duration {
+	if basePollInterval > 0 && basePollInterval < watcherTick {
+		return basePollInterval
+	}
+	return watcherTick
+}
+
+// analysisTerminalStatuses mirrors client's own terminalAnalysisStatuses
+// (unexported there, so not reusable directly): once an analysis reaches
+// one of these, AnalysisWatcher stops polling it.
+var analysisTerminalStatuses = map[string]bool{
+	"Completed": true,
+	"Failed":    true,
+	"Canceled":  true,
+}
+
+// analysisWatch is one analysis AnalysisWatcher is polling on behalf of one
+// or more subscribers.
+type analysisWatch struct {
+	interval    time.Duration
+	nextPoll    time.Time
+	subscribers map[int]chan client.AnalysisStatus
+}
+
+// AnalysisWatcher coalesces status polling for interactive analyses: many
+// callers watching the same analysisID (e.g. several concurrent
+// LaunchAndWait calls, or a dashboard and a CLI both watching the same
+// analysis) share a single poll loop and a single GetAnalysisStatus call
+// per tick, rather than each running their own ticker against Formation.
+// Polling backs off while an analysis is Submitted/Queued, tightens up
+// while it's Running without a ready URL, and stops once the analysis
+// reaches a terminal status or its URL becomes ready.
+type AnalysisWatcher struct {
+	client           client.FormationAPIClient
+	basePollInterval time.Duration
+
+	mu        sync.Mutex
+	watches   map[string]*analysisWatch
+	nextSubID int
+
+	callsMade  atomic.Int64
+	callsSaved atomic.Int64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewAnalysisWatcher creates an AnalysisWatcher and starts its background
+// poll loop. basePollInterval is the interval used for a Running analysis
+// with no ready URL yet; Submitted/Queued analyses are polled less often,
+// scaled off the same value (see nextPollInterval).
+func NewAnalysisWatcher(c client.FormationAPIClient, basePollInterval time.Duration) *AnalysisWatcher {
+	w := &AnalysisWatcher{
+		client:           c,
+		basePollInterval: basePollInterval,
+		watches:          make(map[string]*analysisWatch),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Watch subscribes to status updates for analysisID. If another subscriber
+// is already watching the same analysisID, this shares its poll loop
+// instead of starting a new one. The returned channel is buffered and only
+// ever holds the most recent status - a slow subscriber sees the latest
+// state, not a backlog of every intermediate one - and is closed when ctx
+// is done or the returned unsubscribe func is called, whichever comes
+// first. unsubscribe is safe to call more than once.
+func (w *AnalysisWatcher) Watch(ctx context.Context, analysisID string) (<-chan client.AnalysisStatus, func() error) {
+	w.mu.Lock()
+	aw, ok := w.watches[analysisID]
+	if !ok {
+		aw = &analysisWatch{interval: w.basePollInterval, nextPoll: time.Now(), subscribers: make(map[int]chan client.AnalysisStatus)}
+		w.watches[analysisID] = aw
+	}
+	id := w.nextSubID
+	w.nextSubID++
+	ch := make(chan client.AnalysisStatus, 1)
+	aw.subscribers[id] = ch
+	w.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() error {
+		once.Do(func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			if aw, ok := w.watches[analysisID]; ok {
+				delete(aw.subscribers, id)
+				if len(aw.subscribers) == 0 {
+					delete(w.watches, analysisID)
+				}
+			}
+			close(ch)
+		})
+		return nil
+	}
+
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+
+	return ch, unsubscribe
+}
+
+// Metrics reports callsMade (actual GetAnalysisStatus calls issued) and
+// callsSaved (the additional calls that would have been made had each
+// subscriber polled independently instead of sharing these), so an
+// operator can see the benefit of coalescing.
+func (w *AnalysisWatcher) Metrics() (callsMade, callsSaved int64) {
+	return w.callsMade.Load(), w.callsSaved.Load()
+}
+
+// Stop ends the background poll loop. Subscribers already watching keep
+// their channels open but stop receiving further updates; it's meant for
+// process shutdown, not per-watch cleanup (use the unsubscribe func
+// Watch returns for that).
+func (w *AnalysisWatcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+func (w *AnalysisWatcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(tickInterval(w.basePollInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.pollDue()
+		}
+	}
+}
+
+// pollDue polls every watched analysis whose nextPoll has arrived.
+func (w *AnalysisWatcher) pollDue() {
+	now := time.Now()
+
+	w.mu.Lock()
+	var due []string
+	for id, aw := range w.watches {
+		if !now.Before(aw.nextPoll) {
+			due = append(due, id)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, id := range due {
+		w.pollOne(id)
+	}
+}
+
+// pollOne makes one GetAnalysisStatus call for analysisID and fans the
+// result out to its current subscribers, adjusting the next poll time and
+// removing the watch entirely once the analysis is done (terminal status
+// or a ready URL) so a client that never unsubscribes doesn't leak a
+// permanently-due watch.
+func (w *AnalysisWatcher) pollOne(analysisID string) {
+	status, err := w.client.GetAnalysisStatus(context.Background(), analysisID)
+	w.callsMade.Add(1)
+
+	w.mu.Lock()
+	aw, ok := w.watches[analysisID]
+	if !ok {
+		w.mu.Unlock()
+		return
+	}
+	if n := len(aw.subscribers); n > 1 {
+		w.callsSaved.Add(int64(n - 1))
+	}
+
+	if err != nil {
+		aw.nextPoll = time.Now().Add(aw.interval)
+		subscribers := aw.subscribers
+		w.mu.Unlock()
+		slog.Warn("analysis watcher poll failed", "analysis_id", analysisID, "error", err)
+		_ = subscribers // subscribers still get the next, hopefully successful, poll
+		return
+	}
+
+	aw.interval = nextPollInterval(w.basePollInterval, *status)
+	aw.nextPoll = time.Now().Add(aw.interval)
+
+	done := analysisTerminalStatuses[status.Status] || (status.URLReady && status.URL != "")
+	subscribers := make([]chan client.AnalysisStatus, 0, len(aw.subscribers))
+	for _, ch := range aw.subscribers {
+		subscribers = append(subscribers, ch)
+	}
+	if done {
+		delete(w.watches, analysisID)
+	}
+	w.mu.Unlock()
+
+	for _, ch := range subscribers {
+		sendLatestAnalysisStatus(ch, *status)
+	}
+}
+
+// nextPollInterval applies chunk5-5's adaptive backoff: Submitted/Queued
+// analyses are polled at 4x base (they're typically waiting on a scheduler
+// and unlikely to change between ticks), a Running analysis without a
+// ready URL yet is polled at half base (the state callers care most about
+// is imminent), and anything else uses base.
+func nextPollInterval(base time.Duration, status client.AnalysisStatus) time.Duration {
+	switch {
+	case status.Status == "Submitted" || status.Status == "Queued":
+		return 4 * base
+	case status.Status == "Running" && !status.URLReady:
+		return base / 2
+	default:
+		return base
+	}
+}
+
+// sendLatestAnalysisStatus delivers status on ch without blocking,
+// replacing whatever stale status is already buffered there if the
+// subscriber hasn't read it yet, so a slow consumer always sees the most
+// recent state rather than an ever-growing backlog.
+func sendLatestAnalysisStatus(ch chan client.AnalysisStatus, status client.AnalysisStatus) {
+	for {
+		select {
+		case ch <- status:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
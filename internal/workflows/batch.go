@@ -0,0 +1,168 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// BatchItem is one analysis to submit within a LaunchBatch call. Config is
+// merged onto the batch's base config (BatchItem keys win on overlap);
+// Input labels the item in the returned table, typically the iRODS path
+// it was fanned out from or a short description of its matrix overrides.
+type BatchItem struct {
+	Input  string
+	Config client.LaunchConfig
+}
+
+// BatchItemResult is the outcome of submitting one BatchItem. AnalysisID
+// and Status are set once LaunchApp accepts it; Err is set instead if
+// submission failed, the same either/or shape ItemResult uses for bulk
+// operations.
+type BatchItemResult struct {
+	Input      string
+	AnalysisID string
+	Status     string
+	Err        error
+}
+
+// Batch is the record LaunchBatch creates and GetBatchStatus refreshes:
+// one BatchItemResult per input, always in the original input order.
+type Batch struct {
+	ID       string
+	AppID    string
+	SystemID string
+	Items    []BatchItemResult
+}
+
+// LaunchBatch submits one analysis per item in items against appID/
+// systemID, merging each item's Config onto baseConfig, with up to
+// maxParallel submissions in flight at once (zero uses the same
+// min(8, GOMAXPROCS) default as bulk operations). Unlike LaunchAndWait, it
+// does not wait for any analysis to become ready, estimate cost, or run
+// LaunchPolicy checks - it's for fire-and-forget fan-out over many inputs
+// (e.g. one VICE app per FASTQ under an iRODS directory), with
+// GetBatchStatus polling what LaunchApp reported for each item afterward.
+func (w *FormationWorkflows) LaunchBatch(ctx context.Context, appID, systemID string, baseConfig client.LaunchConfig, items []BatchItem, maxParallel int) (*Batch, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("launch_batch requires at least one input")
+	}
+
+	concurrency := maxParallel
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency()
+	}
+	if concurrency > len(items) {
+		concurrency = len(items)
+	}
+
+	batch := &Batch{
+		ID:       fmt.Sprintf("batch-%d", time.Now().UnixNano()),
+		AppID:    appID,
+		SystemID: systemID,
+		Items:    make([]BatchItemResult, len(items)),
+	}
+
+	indices := make(chan int)
+	go func() {
+		defer close(indices)
+		for i := range items {
+			indices <- i
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for n := 0; n < concurrency; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				batch.Items[i] = w.launchBatchItem(ctx, batch.ID, appID, systemID, baseConfig, items[i], i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	w.recordBatch(batch)
+
+	subsystemLogger(ctx).Info("launched batch", "batch_id", batch.ID, "app_id", appID, "system_id", systemID, "items", len(items))
+
+	return batch, nil
+}
+
+// launchBatchItem merges item.Config onto baseConfig and submits it as
+// analysis index of batch batchID, recording the launch (so it can later
+// be snapshotted) on success.
+func (w *FormationWorkflows) launchBatchItem(ctx context.Context, batchID, appID, systemID string, baseConfig client.LaunchConfig, item BatchItem, index int) BatchItemResult {
+	config := make(client.LaunchConfig, len(baseConfig)+len(item.Config))
+	for k, v := range baseConfig {
+		config[k] = v
+	}
+	for k, v := range item.Config {
+		config[k] = v
+	}
+
+	result := BatchItemResult{Input: item.Input}
+
+	resp, err := w.client.LaunchApp(ctx, systemID, appID, client.LaunchSubmission{
+		Name:   fmt.Sprintf("%s-%d", batchID, index),
+		Config: config,
+	})
+	if err != nil {
+		result.Err = fmt.Errorf("item %d (%s): %w", index, item.Input, err)
+		return result
+	}
+
+	w.recordLaunch(resp.AnalysisID, appID, systemID, config)
+	result.AnalysisID = resp.AnalysisID
+	result.Status = resp.Status
+	return result
+}
+
+// recordBatch stores batch for later GetBatchStatus calls.
+func (w *FormationWorkflows) recordBatch(batch *Batch) {
+	w.batchesMu.Lock()
+	defer w.batchesMu.Unlock()
+	w.batches[batch.ID] = batch
+}
+
+// batchFor returns the recorded Batch for batchID, if LaunchBatch produced
+// one.
+func (w *FormationWorkflows) batchFor(batchID string) (*Batch, bool) {
+	w.batchesMu.Lock()
+	defer w.batchesMu.Unlock()
+	batch, ok := w.batches[batchID]
+	return batch, ok
+}
+
+// GetBatchStatus returns the Batch a prior LaunchBatch call recorded as
+// batchID, refreshing each successfully-submitted item's Status from
+// GetAnalysisStatus so a caller polling get_batch_status sees live
+// progress instead of the submission-time snapshot. A status lookup that
+// fails for one item leaves its last known Status in place rather than
+// failing the whole poll.
+func (w *FormationWorkflows) GetBatchStatus(ctx context.Context, batchID string) (*Batch, error) {
+	batch, ok := w.batchFor(batchID)
+	if !ok {
+		return nil, fmt.Errorf("no batch recorded with id %s", batchID)
+	}
+
+	items := make([]BatchItemResult, len(batch.Items))
+	copy(items, batch.Items)
+
+	for i, item := range items {
+		if item.Err != nil || item.AnalysisID == "" {
+			continue
+		}
+		status, err := w.client.GetAnalysisStatus(ctx, item.AnalysisID)
+		if err != nil {
+			continue
+		}
+		items[i].Status = status.Status
+	}
+
+	return &Batch{ID: batch.ID, AppID: batch.AppID, SystemID: batch.SystemID, Items: items}, nil
+}
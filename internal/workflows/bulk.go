@@ -0,0 +1,235 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// defaultBulkConcurrency is the worker pool size a BulkExecutor falls back
+// to when BulkOptions.Concurrency is left at zero: GOMAXPROCS capped at 8,
+// since past that many concurrent requests Formation itself is the
+// bottleneck, not the client's own CPU budget.
+func defaultBulkConcurrency() int {
+	if n := runtime.GOMAXPROCS(0); n < 8 {
+		return n
+	}
+	return 8
+}
+
+// UploadItem is one file to upload in a BulkUpload call.
+type UploadItem struct {
+	Path     string
+	Content  string
+	Metadata map[string]interface{}
+}
+
+// MetadataItem is one path to apply an AVU to in a BulkSetMetadata call.
+type MetadataItem struct {
+	Path     string
+	Metadata map[string]interface{}
+	Replace  bool
+}
+
+// DeleteItem is one path to remove in a BulkDelete call.
+type DeleteItem struct {
+	Path    string
+	Recurse bool
+	DryRun  bool
+}
+
+// BulkOptions configures a bulk operation's concurrency and failure
+// handling.
+type BulkOptions struct {
+	// Concurrency is the worker pool size. Zero uses
+	// min(8, GOMAXPROCS).
+	Concurrency int
+
+	// StopOnFirstError cancels outstanding workers and stops dispatching
+	// new items as soon as one item fails, instead of the default
+	// best-effort behavior of running every item and reporting failures
+	// alongside successes.
+	StopOnFirstError bool
+
+	// Progress, if non-nil, receives a BulkProgress update after every
+	// item completes. Sends never block a worker - an update is dropped
+	// rather than stalling on a slow reader - so callers should give it
+	// enough buffer for how often they read from it.
+	Progress chan<- BulkProgress
+}
+
+// BulkProgress is one update on a bulk operation's progress, suitable for
+// rendering a progress bar.
+type BulkProgress struct {
+	Completed int
+	Failed    int
+	Total     int
+}
+
+// ItemResult is the outcome of one item in a bulk operation. Index matches
+// its position in the slice passed to BulkUpload/BulkSetMetadata/
+// BulkDelete, so a caller can map a failure back to its input regardless
+// of the order items actually completed in.
+type ItemResult struct {
+	Index int
+	Err   error
+}
+
+// BulkResult is the outcome of a bulk operation: one ItemResult per input
+// item, always in the original input order.
+type BulkResult struct {
+	Results []ItemResult
+}
+
+// Failed returns the ItemResults with a non-nil Err.
+func (r BulkResult) Failed() []ItemResult {
+	var failed []ItemResult
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// BulkExecutor runs bulk upload/metadata/delete operations against a
+// FormationAPIClient with bounded worker-pool concurrency, so a caller
+// with hundreds of items doesn't either serialize them one HTTP
+// round-trip at a time or fire them all off at once.
+type BulkExecutor struct {
+	client client.FormationAPIClient
+}
+
+// NewBulkExecutor creates a BulkExecutor backed by c.
+func NewBulkExecutor(c client.FormationAPIClient) *BulkExecutor {
+	return &BulkExecutor{client: c}
+}
+
+// BulkUpload uploads every item in items, bounded by opts.Concurrency.
+func (b *BulkExecutor) BulkUpload(ctx context.Context, items []UploadItem, opts BulkOptions) (BulkResult, error) {
+	return runBulk(ctx, len(items), opts, func(ctx context.Context, i int) error {
+		item := items[i]
+		return b.client.UploadFile(ctx, item.Path, item.Content, item.Metadata)
+	})
+}
+
+// BulkSetMetadata applies metadata to every item in items, bounded by
+// opts.Concurrency.
+func (b *BulkExecutor) BulkSetMetadata(ctx context.Context, items []MetadataItem, opts BulkOptions) (BulkResult, error) {
+	return runBulk(ctx, len(items), opts, func(ctx context.Context, i int) error {
+		item := items[i]
+		return b.client.SetMetadata(ctx, item.Path, item.Metadata, item.Replace)
+	})
+}
+
+// BulkDelete deletes every item in items, bounded by opts.Concurrency.
+func (b *BulkExecutor) BulkDelete(ctx context.Context, items []DeleteItem, opts BulkOptions) (BulkResult, error) {
+	return runBulk(ctx, len(items), opts, func(ctx context.Context, i int) error {
+		item := items[i]
+		return b.client.DeleteData(ctx, item.Path, item.Recurse, item.DryRun)
+	})
+}
+
+// runBulk is the shared worker pool behind BulkUpload/BulkSetMetadata/
+// BulkDelete: it runs work(i) for every index in [0, total) across
+// opts.Concurrency workers (default min(8, GOMAXPROCS)), preserving each
+// item's original index in the returned BulkResult regardless of
+// completion order. Each work call already went through the client's own
+// RetryPolicy before returning, so a transiently flaky item has already
+// had its retries by the time it's counted as a failure here - a single
+// bad item doesn't abort the rest of the batch unless StopOnFirstError is
+// set, in which case outstanding workers are cancelled and any item that
+// never got to run is recorded as failed with ctx.Err() rather than
+// silently missing from the result.
+func runBulk(ctx context.Context, total int, opts BulkOptions, work func(ctx context.Context, i int) error) (BulkResult, error) {
+	result := BulkResult{Results: make([]ItemResult, total)}
+	for i := range result.Results {
+		result.Results[i].Index = i
+	}
+	if total == 0 {
+		return result, nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency()
+	}
+	if concurrency > total {
+		concurrency = total
+	}
+
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indices := make(chan int)
+	go func() {
+		defer close(indices)
+		for i := 0; i < total; i++ {
+			select {
+			case indices <- i:
+			case <-workCtx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		mu        sync.Mutex
+		completed int
+		failed    int
+		firstErr  error
+		attempted = make([]bool, total)
+	)
+
+	var wg sync.WaitGroup
+	for n := 0; n < concurrency; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				err := work(workCtx, i)
+
+				mu.Lock()
+				attempted[i] = true
+				result.Results[i].Err = err
+				completed++
+				if err != nil {
+					failed++
+					if firstErr == nil {
+						firstErr = fmt.Errorf("item %d: %w", i, err)
+					}
+					if opts.StopOnFirstError {
+						cancel()
+					}
+				}
+				progress := BulkProgress{Completed: completed, Failed: failed, Total: total}
+				mu.Unlock()
+
+				if opts.Progress != nil {
+					select {
+					case opts.Progress <- progress:
+					default:
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Any item never dispatched (StopOnFirstError cancelled the producer
+	// before it got to it) is recorded as failed rather than left looking
+	// like an untouched success.
+	for i, ok := range attempted {
+		if !ok {
+			result.Results[i].Err = workCtx.Err()
+		}
+	}
+
+	if opts.StopOnFirstError && firstErr != nil {
+		return result, firstErr
+	}
+	return result, nil
+}
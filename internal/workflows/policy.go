@@ -0,0 +1,200 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// PolicySeverity distinguishes a LaunchPolicy violation that should merely
+// be surfaced to the caller (PolicyAdvisory) from one that should stop the
+// launch outright (PolicyDeny).
+type PolicySeverity string
+
+const (
+	PolicyAdvisory PolicySeverity = "advisory"
+	PolicyDeny     PolicySeverity = "deny"
+)
+
+// PolicyResult is the outcome of evaluating a single LaunchPolicy. A zero
+// value (Violated false) means the policy raised no objection.
+type PolicyResult struct {
+	Policy   string
+	Violated bool
+	Severity PolicySeverity
+	Message  string
+}
+
+// LaunchPolicy is a pre-launch check LaunchAndWaitStream (and so
+// LaunchAndWait and ResumeFromSnapshot) runs against every app launch,
+// after checkMissingParams and the cost gate but before the app is
+// actually submitted via LaunchApp. LaunchAndStream and LaunchBatch don't
+// run it. Implementations should be cheap
+// to construct and safe for concurrent use, since the same LaunchPolicy is
+// reused across launches.
+type LaunchPolicy interface {
+	Evaluate(ctx context.Context, app *client.App, params *client.AppParameters, config client.LaunchConfig) PolicyResult
+}
+
+// RegisterPolicy adds policy to the set LaunchAndWaitStream evaluates
+// before each launch. The
+// order policies are registered in is the order they're evaluated in, and
+// all of them run even once one denies the launch, so a caller always sees
+// every violation rather than just the first.
+func (w *FormationWorkflows) RegisterPolicy(policy LaunchPolicy) {
+	w.policies = append(w.policies, policy)
+}
+
+// lookupApp finds the App for appID/systemID among the apps ListApps
+// returns, for policies that need details (like IntegratorUsername)
+// GetAppParameters doesn't carry. FormationAPIClient has no get-by-ID
+// lookup, so this scans the unfiltered catalog; if appID isn't found there
+// (e.g. it's been removed from the catalog since it was launched), a
+// minimal App with just ID/SystemID is returned so ID- and SystemID-based
+// policies still work.
+func (w *FormationWorkflows) lookupApp(ctx context.Context, systemID, appID string) (*client.App, error) {
+	apps, err := w.client.ListApps(ctx, "", "", "", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, app := range apps {
+		if app.ID == appID && app.SystemID == systemID {
+			return &app, nil
+		}
+	}
+
+	return &client.App{ID: appID, SystemID: systemID}, nil
+}
+
+// MaxConcurrentAnalysesPerUser denies a launch once the caller already has
+// Max or more analyses in a non-terminal state. It relies on ListAnalyses
+// rather than any per-user accounting of its own, so it's only as accurate
+// as what the Formation API reports for the caller's credentials.
+type MaxConcurrentAnalysesPerUser struct {
+	Client client.FormationAPIClient
+	Max    int
+}
+
+func (p MaxConcurrentAnalysesPerUser) Evaluate(ctx context.Context, app *client.App, params *client.AppParameters, config client.LaunchConfig) PolicyResult {
+	running, err := p.Client.ListAnalyses(ctx, "Running")
+	if err != nil {
+		return PolicyResult{Policy: "MaxConcurrentAnalysesPerUser", Violated: true, Severity: PolicyDeny, Message: fmt.Sprintf("could not count running analyses: %v", err)}
+	}
+
+	if len(running) >= p.Max {
+		return PolicyResult{
+			Policy:   "MaxConcurrentAnalysesPerUser",
+			Violated: true,
+			Severity: PolicyDeny,
+			Message:  fmt.Sprintf("already have %d running analyses, at or above the limit of %d", len(running), p.Max),
+		}
+	}
+
+	return PolicyResult{Policy: "MaxConcurrentAnalysesPerUser"}
+}
+
+// AllowedSystemIDs denies launching an app on any system ID other than one
+// of SystemIDs.
+type AllowedSystemIDs struct {
+	SystemIDs []string
+}
+
+func (p AllowedSystemIDs) Evaluate(ctx context.Context, app *client.App, params *client.AppParameters, config client.LaunchConfig) PolicyResult {
+	for _, id := range p.SystemIDs {
+		if app.SystemID == id {
+			return PolicyResult{Policy: "AllowedSystemIDs"}
+		}
+	}
+
+	return PolicyResult{
+		Policy:   "AllowedSystemIDs",
+		Violated: true,
+		Severity: PolicyDeny,
+		Message:  fmt.Sprintf("system %q is not in the allowed list", app.SystemID),
+	}
+}
+
+// irodsInputParamTypes lists the Parameter.Type values RequiredMetadataOnInputs
+// treats as referencing an iRODS path, rather than a literal value.
+var irodsInputParamTypes = map[string]bool{
+	"FileInput":         true,
+	"FolderInput":       true,
+	"MultiFileSelector": true,
+}
+
+// RequiredMetadataOnInputs denies a launch if any file input in config
+// points to an iRODS path missing one of RequiredAVUs, checked via
+// BrowseData. Folder inputs are skipped: BrowseData's DirectoryContents
+// doesn't carry metadata the way FileContent does, so there's nothing to
+// check them against.
+type RequiredMetadataOnInputs struct {
+	Client       client.FormationAPIClient
+	RequiredAVUs []string
+}
+
+func (p RequiredMetadataOnInputs) Evaluate(ctx context.Context, app *client.App, params *client.AppParameters, config client.LaunchConfig) PolicyResult {
+	for _, group := range params.Groups {
+		for _, param := range group.Parameters {
+			if !irodsInputParamTypes[param.Type] {
+				continue
+			}
+
+			value, ok := config[param.ID]
+			if !ok {
+				continue
+			}
+			path, ok := value.(string)
+			if !ok || path == "" {
+				continue
+			}
+
+			result, err := p.Client.BrowseData(ctx, path, 0, 0, true)
+			if err != nil {
+				return PolicyResult{Policy: "RequiredMetadataOnInputs", Violated: true, Severity: PolicyDeny, Message: fmt.Sprintf("could not verify metadata on %s: %v", path, err)}
+			}
+
+			file, ok := result.(*client.FileContent)
+			if !ok {
+				continue
+			}
+
+			for _, attr := range p.RequiredAVUs {
+				if _, ok := file.Metadata[attr]; !ok {
+					return PolicyResult{
+						Policy:   "RequiredMetadataOnInputs",
+						Violated: true,
+						Severity: PolicyDeny,
+						Message:  fmt.Sprintf("%s is missing required metadata attribute %q", path, attr),
+					}
+				}
+			}
+		}
+	}
+
+	return PolicyResult{Policy: "RequiredMetadataOnInputs"}
+}
+
+// BlockedIntegrators flags a launch as an advisory (not a hard deny) when
+// the app comes from one of Usernames, so callers can warn about
+// unsupported or deprecated integrators without blocking the launch
+// outright.
+type BlockedIntegrators struct {
+	Usernames []string
+}
+
+func (p BlockedIntegrators) Evaluate(ctx context.Context, app *client.App, params *client.AppParameters, config client.LaunchConfig) PolicyResult {
+	for _, u := range p.Usernames {
+		if app.IntegratorUsername == u {
+			return PolicyResult{
+				Policy:   "BlockedIntegrators",
+				Violated: true,
+				Severity: PolicyAdvisory,
+				Message:  fmt.Sprintf("app is integrated by %q, which is on the advisory block list", u),
+			}
+		}
+	}
+
+	return PolicyResult{Policy: "BlockedIntegrators"}
+}
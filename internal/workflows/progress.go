@@ -0,0 +1,137 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// progressBarWidth is how many characters wide the rendered terminal
+// progress bar is, not counting the surrounding brackets and stats.
+const progressBarWidth = 30
+
+// isTerminal reports whether f is attached to an interactive terminal
+// rather than a redirected file or pipe, so UploadFileWithProgress can
+// decide between a redrawing progress bar and periodic log lines.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// transferProgress renders the onProgress callbacks a client upload or
+// download reports as either a live, redrawing terminal progress bar
+// (bytes transferred, transfer rate, ETA) when out is a terminal, or
+// periodic slog.Info lines otherwise, so a redirected or piped CLI run
+// still gets progress in its log output instead of a screen full of
+// carriage returns.
+type transferProgress struct {
+	op    string
+	path  string
+	out   *os.File
+	start time.Time
+
+	lastLineLen int
+}
+
+// newTransferProgress returns a transferProgress for a transfer of op
+// (e.g. "upload") on path, reporting to out.
+func newTransferProgress(op, path string, out *os.File) *transferProgress {
+	return &transferProgress{op: op, path: path, out: out, start: time.Now()}
+}
+
+// report is a client.UploadOptions.OnProgress (or DownloadFileStream
+// onProgress) callback: n is bytes transferred so far, total is the
+// overall size, or 0 if unknown.
+func (p *transferProgress) report(n, total int64) {
+	if isTerminal(p.out) {
+		p.renderBar(n, total)
+		return
+	}
+	p.logLine(n, total)
+}
+
+func (p *transferProgress) renderBar(n, total int64) {
+	elapsed := time.Since(p.start).Seconds()
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(n) / elapsed
+	}
+
+	filled := 0
+	pct := "?"
+	eta := "?"
+	if total > 0 {
+		frac := float64(n) / float64(total)
+		filled = int(frac * progressBarWidth)
+		if filled > progressBarWidth {
+			filled = progressBarWidth
+		}
+		pct = fmt.Sprintf("%d%%", int(frac*100))
+		if rate > 0 {
+			eta = formatDuration(time.Duration(float64(total-n)/rate) * time.Second)
+		}
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+
+	line := fmt.Sprintf("\r%s %s [%s] %s %s/s ETA %s", p.op, p.path, bar, pct, formatBytes(int64(rate)), eta)
+	if pad := p.lastLineLen - len(line); pad > 0 {
+		line += strings.Repeat(" ", pad)
+	}
+	p.lastLineLen = len(line)
+
+	fmt.Fprint(p.out, line)
+	if total > 0 && n >= total {
+		fmt.Fprintln(p.out)
+	}
+}
+
+func (p *transferProgress) logLine(n, total int64) {
+	slog.Info("transfer progress", "op", p.op, "path", p.path, "bytes", n, "total", total)
+}
+
+// formatBytes renders n bytes as a human-readable size (e.g. "4.2MiB").
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatDuration renders d rounded to the second, e.g. "1h2m3s".
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+// UploadFileWithProgress uploads size bytes read from r to path the same
+// way the client's UploadFileStream does, but reports progress as a live
+// bar on stdout when it's a terminal, or as periodic slog.Info lines
+// otherwise - the CLI-facing counterpart plain UploadFileStream callers
+// (like the MCP tool handlers) don't need. Any OnProgress already set on
+// opts is still called alongside the rendered progress.
+func (w *FormationWorkflows) UploadFileWithProgress(ctx context.Context, path string, r io.Reader, size int64, opts client.UploadOptions) error {
+	progress := newTransferProgress("upload", path, os.Stdout)
+	next := opts.OnProgress
+	opts.OnProgress = func(n, total int64) {
+		progress.report(n, total)
+		if next != nil {
+			next(n, total)
+		}
+	}
+
+	return w.client.UploadFileStream(ctx, path, r, size, opts)
+}
@@ -0,0 +1,17 @@
+package workflows
+
+import (
+	"context"
+
+	"github.com/cyverse-de/formation-mcp/internal/client"
+)
+
+// UploadLargeFile uploads the local file at localPath to remotePath via
+// client.UploadLocalFile, persisting resumable progress under
+// opts.StateDir so a crashed or interrupted transfer can be continued
+// later with client.ResumeUpload rather than restarting from scratch -
+// the CLI-facing entry point for multi-GB datasets that UploadFileStream
+// alone isn't convenient to drive from a local file path.
+func (w *FormationWorkflows) UploadLargeFile(ctx context.Context, localPath, remotePath string, opts client.ResumableUploadOptions) error {
+	return client.UploadLocalFile(ctx, w.client, localPath, remotePath, opts)
+}
@@ -3,29 +3,181 @@ package workflows
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os/exec"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/cyverse-de/formation-mcp/internal/client"
+	"github.com/cyverse-de/formation-mcp/internal/logging"
 )
 
+// subsystemLogger returns ctx's logger (see client.LoggerFromContext),
+// re-tagged logging.SubsystemKey="workflows" so a SubsystemLevelHandler
+// can tune this package's verbosity independently of the server/client
+// packages, even though all three log through the same per-request logger
+// chain (see withRequestContext in internal/server).
+func subsystemLogger(ctx context.Context) logging.Logger {
+	return client.LoggerFromContext(ctx).With(logging.SubsystemKey, "workflows")
+}
+
+// Workflows is the set of workflow operations the MCP server depends on,
+// so it can be driven by a mock in tests instead of a real FormationWorkflows.
+type Workflows interface {
+	LaunchAndWait(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, resourceRequests *client.ResourceRequests, costGate *CostGate, maxWait time.Duration) (*LaunchResult, error)
+	LaunchAndWaitStream(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, resourceRequests *client.ResourceRequests, costGate *CostGate, maxWait time.Duration) (<-chan WorkflowEvent, error)
+	CostEstimate(ctx context.Context, appID, systemID string, config client.LaunchConfig) (*client.CostEstimate, error)
+	LaunchAndStream(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, maxWait time.Duration, onUpdate func(LaunchProgress) error) (*LaunchResult, error)
+	GetRunningAnalyses(ctx context.Context) ([]client.Analysis, error)
+	StopAnalysis(ctx context.Context, analysisID string, saveOutputs, snapshotBeforeExit bool) error
+	OpenInBrowser(url string) error
+	BrowseDataWithFormat(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, bool, error)
+	SnapshotAnalysis(ctx context.Context, analysisID, label string) (*Snapshot, error)
+	ResumeFromSnapshot(ctx context.Context, snapshotID string, config client.LaunchConfig) (*LaunchResult, error)
+	ListSnapshots(ctx context.Context) ([]Snapshot, error)
+	LaunchBatch(ctx context.Context, appID, systemID string, baseConfig client.LaunchConfig, items []BatchItem, maxParallel int) (*Batch, error)
+	GetBatchStatus(ctx context.Context, batchID string) (*Batch, error)
+}
+
+// BrowserOpener opens a URL in the user's default browser, so tests can
+// substitute a fake instead of exercising the OS.
+type BrowserOpener interface {
+	Open(url string) error
+}
+
+// SystemBrowserOpener opens URLs with the OS's default handler.
+type SystemBrowserOpener struct{}
+
+// Open opens url with the OS's default handler (open, xdg-open, or
+// cmd /c start).
+func (SystemBrowserOpener) Open(url string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "linux":
+		cmd = exec.Command("xdg-open", url)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", url)
+	default:
+		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+
+	return cmd.Start()
+}
+
 // FormationWorkflows provides high-level workflow operations.
 type FormationWorkflows struct {
-	client       *client.FormationClient
-	pollInterval time.Duration
+	client        client.FormationAPIClient
+	browserOpener BrowserOpener
+	pollInterval  time.Duration
+
+	// watcher coalesces GetAnalysisStatus polling across every concurrent
+	// LaunchAndWaitStream call (and any other caller that watches the same
+	// analysis), so ten callers watching one analysis produce one poll loop
+	// rather than ten. See AnalysisWatcher.
+	watcher *AnalysisWatcher
+
+	// policies is the set of LaunchPolicy checks LaunchAndWaitStream runs
+	// before each launch it submits.
+	// See RegisterPolicy.
+	policies []LaunchPolicy
+
+	// launchesMu guards launches.
+	launchesMu sync.Mutex
+
+	// launches records the appID/systemID/config a launch was submitted
+	// with, keyed by analysis ID, so SnapshotAnalysis can recover them for
+	// an analysis this FormationWorkflows itself launched. It's in-memory
+	// only and doesn't survive a restart - snapshotting an analysis
+	// launched by a previous process isn't supported.
+	launches map[string]launchRecord
+
+	// batchesMu guards batches.
+	batchesMu sync.Mutex
+
+	// batches records the Batch produced by each LaunchBatch call, keyed by
+	// batch ID, so GetBatchStatus can refresh and return it later. In-memory
+	// only, like launches - a batch doesn't survive a restart.
+	batches map[string]*Batch
+
+	// shutdown is closed by Shutdown, so a LaunchAndWaitStream poll loop
+	// already running on some other ctx still exits as soon as shutdown
+	// begins, rather than only at its own ctx's cancellation or maxWait.
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
 }
 
+// launchRecord is what SnapshotAnalysis needs to recreate a launch: the app
+// and system it was submitted against, and the config it was submitted
+// with.
+type launchRecord struct {
+	AppID    string
+	SystemID string
+	Config   client.LaunchConfig
+}
+
+// Compile-time check to ensure FormationWorkflows implements Workflows.
+var _ Workflows = (*FormationWorkflows)(nil)
+
 // NewFormationWorkflows creates a new workflows instance.
-func NewFormationWorkflows(c *client.FormationClient, pollInterval time.Duration) *FormationWorkflows {
+func NewFormationWorkflows(c client.FormationAPIClient, browserOpener BrowserOpener, pollInterval time.Duration) *FormationWorkflows {
 	return &FormationWorkflows{
-		client:       c,
-		pollInterval: pollInterval,
+		client:        c,
+		browserOpener: browserOpener,
+		pollInterval:  pollInterval,
+		watcher:       NewAnalysisWatcher(c, pollInterval),
+		launches:      make(map[string]launchRecord),
+		batches:       make(map[string]*Batch),
+		shutdown:      make(chan struct{}),
 	}
 }
 
+// Shutdown begins draining FormationWorkflows: any LaunchAndWaitStream poll
+// loop still running exits on its next tick rather than waiting out its own
+// ctx or maxWait, and the underlying client stops accepting new requests
+// once its own in-flight ones finish or ctx expires (see
+// client.FormationAPIClient.Shutdown). Safe to call more than once.
+func (w *FormationWorkflows) Shutdown(ctx context.Context) error {
+	w.shutdownOnce.Do(func() { close(w.shutdown) })
+	w.watcher.Stop()
+	return w.client.Shutdown(ctx)
+}
+
+// LastActivity returns the underlying client's most recent request start or
+// completion time, for an outer idle-timeout loop to compare against.
+func (w *FormationWorkflows) LastActivity() time.Time {
+	return w.client.LastActivity()
+}
+
+// Bulk returns a BulkExecutor backed by this FormationWorkflows' client,
+// for running BulkUpload/BulkSetMetadata/BulkDelete against the same
+// Formation deployment.
+func (w *FormationWorkflows) Bulk() *BulkExecutor {
+	return NewBulkExecutor(w.client)
+}
+
+// recordLaunch remembers appID/systemID/config for analysisID, so a later
+// SnapshotAnalysis call can recover them.
+func (w *FormationWorkflows) recordLaunch(analysisID, appID, systemID string, config client.LaunchConfig) {
+	w.launchesMu.Lock()
+	defer w.launchesMu.Unlock()
+	w.launches[analysisID] = launchRecord{AppID: appID, SystemID: systemID, Config: config}
+}
+
+// launchRecordFor returns the recorded appID/systemID/config for
+// analysisID, if this FormationWorkflows launched it.
+func (w *FormationWorkflows) launchRecordFor(analysisID string) (launchRecord, bool) {
+	w.launchesMu.Lock()
+	defer w.launchesMu.Unlock()
+	record, ok := w.launches[analysisID]
+	return record, ok
+}
+
 // LaunchResult represents the result of launching an app.
 type LaunchResult struct {
 	AnalysisID     string
@@ -34,18 +186,625 @@ type LaunchResult struct {
 	URL            string
 	MissingParams  []string
 	IsInteractive  bool
+
+	// UnsupportedResources lists the resource_requests classes the app
+	// does not declare in ResourceCapabilities. A non-empty
+	// UnsupportedResources means the launch was rejected before
+	// submission, the same way a non-empty MissingParams is.
+	UnsupportedResources []string
+
+	// CostExceeded is set when a CostGate rejected the launch because its
+	// estimated cost was over MaxCost. A non-nil CostExceeded means the
+	// launch was rejected before submission, the same way a non-empty
+	// MissingParams is.
+	CostExceeded *CostExceeded
+
+	// PolicyViolations lists every registered LaunchPolicy that objected to
+	// this launch. A violation with Severity PolicyDeny means the launch
+	// was rejected before submission, as a non-empty MissingParams is, but
+	// LaunchAndWait also returns a non-nil error for it; PolicyAdvisory
+	// violations are informational only and don't prevent the launch.
+	PolicyViolations []PolicyResult
+}
+
+// CostGate optionally caps what LaunchAndWait is willing to submit: if the
+// app's estimated cost exceeds MaxCost, the launch is rejected with a
+// CostExceeded result instead of being submitted, unless ConfirmOverCost
+// explicitly waives the cap.
+type CostGate struct {
+	MaxCost         float64
+	ConfirmOverCost bool
+}
+
+// CostExceeded reports why a CostGate rejected a launch.
+type CostExceeded struct {
+	Estimate client.CostEstimate
+	MaxCost  float64
+}
+
+// WorkflowEventType identifies the kind of lifecycle update carried by a
+// WorkflowEvent.
+type WorkflowEventType string
+
+const (
+	EventSubmitted WorkflowEventType = "Submitted"
+	EventQueued    WorkflowEventType = "Queued"
+	EventRunning   WorkflowEventType = "Running"
+	EventURLReady  WorkflowEventType = "URLReady"
+	EventCompleted WorkflowEventType = "Completed"
+	EventFailed    WorkflowEventType = "Failed"
+	EventTimedOut  WorkflowEventType = "TimedOut"
+
+	// EventHeartbeat is emitted periodically while waiting on an interactive
+	// app whose status hasn't changed since the last event, so a caller
+	// streaming this to a user can show it's still alive rather than going
+	// quiet for minutes at a time.
+	EventHeartbeat WorkflowEventType = "Heartbeat"
+)
+
+// WorkflowEvent is a single lifecycle update emitted by LaunchAndWaitStream
+// as it drives an app from submission through to a terminal state. Only the
+// fields relevant to Type are populated; for example MissingParams is only
+// set on the Failed event reporting a pre-submission validation rejection.
+type WorkflowEvent struct {
+	Type          WorkflowEventType
+	AnalysisID    string
+	Name          string
+	Status        string
+	Elapsed       time.Duration
+	IsInteractive bool
+	URL           string
+
+	// MissingParams, UnsupportedResources, and CostExceeded are set on a
+	// Failed event emitted before the app was ever submitted, reporting why
+	// LaunchAndWaitStream refused to launch it at all.
+	MissingParams        []string
+	UnsupportedResources []string
+	CostExceeded         *CostExceeded
+
+	// PolicyViolations is set on a Failed event rejecting a launch that a
+	// registered LaunchPolicy denied, and on the Submitted event to carry
+	// forward any advisory violations that didn't block the launch.
+	PolicyViolations []PolicyResult
+
+	// Err is set on a Failed event caused by an upstream error (a failed
+	// API call, or ctx being cancelled), as opposed to the pre-submission
+	// rejections above or the app itself reaching a Failed/Canceled status.
+	Err error
 }
 
-// LaunchAndWait launches an app and waits for it to be ready (if interactive).
-// For batch jobs, it returns immediately after launch.
-func (w *FormationWorkflows) LaunchAndWait(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, maxWait time.Duration) (*LaunchResult, error) {
-	// Get app parameters to determine if required params are missing
+// LaunchAndWait launches an app and waits for it to be ready (if
+// interactive), returning only the terminal outcome. It's a thin wrapper
+// around LaunchAndWaitStream for callers that don't need incremental
+// progress.
+func (w *FormationWorkflows) LaunchAndWait(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, resourceRequests *client.ResourceRequests, costGate *CostGate, maxWait time.Duration) (*LaunchResult, error) {
+	events, err := w.LaunchAndWaitStream(ctx, appID, systemID, name, config, resourceRequests, costGate, maxWait)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LaunchResult{}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return result, nil
+			}
+			if terminal, err := ApplyWorkflowEvent(result, event, maxWait); terminal {
+				return result, err
+			}
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+}
+
+// ApplyWorkflowEvent folds event into result and reports whether it's a
+// terminal event, along with the error LaunchAndWait should return for it
+// (nil for a successful outcome or for a pre-submission rejection over
+// missing params, unsupported resources, or cost; non-nil for a launch a
+// LaunchPolicy denied, an upstream error, or a failed analysis). It's exported
+// so the MCP server can drain a LaunchAndWaitStream channel itself (to emit
+// progress notifications alongside each event) while still building the
+// same LaunchResult LaunchAndWait would have returned.
+func ApplyWorkflowEvent(result *LaunchResult, event WorkflowEvent, maxWait time.Duration) (terminal bool, err error) {
+	switch event.Type {
+	case EventFailed:
+		result.AnalysisID = event.AnalysisID
+		result.Name = event.Name
+		result.Status = event.Status
+		switch {
+		case len(event.MissingParams) > 0:
+			result.MissingParams = event.MissingParams
+			return true, nil
+		case len(event.UnsupportedResources) > 0:
+			result.UnsupportedResources = event.UnsupportedResources
+			return true, nil
+		case event.CostExceeded != nil:
+			result.CostExceeded = event.CostExceeded
+			return true, nil
+		case len(event.PolicyViolations) > 0:
+			result.PolicyViolations = event.PolicyViolations
+			return true, fmt.Errorf("launch denied by policy")
+		case event.Err != nil:
+			return true, event.Err
+		default:
+			return true, fmt.Errorf("analysis failed with status: %s", event.Status)
+		}
+	case EventTimedOut:
+		result.AnalysisID = event.AnalysisID
+		result.Name = event.Name
+		result.Status = event.Status
+		return true, fmt.Errorf("timeout waiting for app to be ready after %v", maxWait)
+	case EventCompleted:
+		result.AnalysisID = event.AnalysisID
+		result.Name = event.Name
+		result.Status = event.Status
+		result.IsInteractive = event.IsInteractive
+		return true, nil
+	case EventURLReady:
+		result.AnalysisID = event.AnalysisID
+		result.Name = event.Name
+		result.Status = event.Status
+		result.IsInteractive = event.IsInteractive
+		result.URL = event.URL
+		return true, nil
+	default: // Submitted, Queued, Running, Heartbeat
+		result.AnalysisID = event.AnalysisID
+		result.Name = event.Name
+		result.Status = event.Status
+		result.IsInteractive = event.IsInteractive
+		if event.PolicyViolations != nil {
+			result.PolicyViolations = event.PolicyViolations
+		}
+		return false, nil
+	}
+}
+
+// LaunchAndWaitStream launches an app the same way LaunchAndWait does, but
+// reports its progress as a sequence of WorkflowEvents on the returned
+// channel instead of blocking until a terminal outcome: Submitted, then
+// (for interactive apps) Queued/Running as the status changes, periodic
+// Heartbeat events while it doesn't, and finally one of URLReady,
+// Completed, Failed, or TimedOut. The channel is closed after the terminal
+// event. The returned error is non-nil only if the app's parameters
+// couldn't even be fetched; failures after that point are reported as a
+// Failed event instead, mirroring how watch's channel reports mid-stream
+// errors rather than returning them.
+//
+// After checkMissingParams and the cost gate, every registered LaunchPolicy
+// is evaluated (see RegisterPolicy). A PolicyDeny violation rejects the
+// launch the same way missing params or an exceeded cost gate do; advisory
+// violations are carried forward on the Submitted event instead.
+func (w *FormationWorkflows) LaunchAndWaitStream(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, resourceRequests *client.ResourceRequests, costGate *CostGate, maxWait time.Duration) (<-chan WorkflowEvent, error) {
+	params, err := w.client.GetAppParameters(ctx, systemID, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app parameters: %w", err)
+	}
+
+	events := make(chan WorkflowEvent)
+
+	go func() {
+		defer close(events)
+
+		missingParams := w.checkMissingParams(params, config)
+		if len(missingParams) > 0 {
+			sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventFailed, MissingParams: missingParams})
+			return
+		}
+
+		unsupported := unsupportedResources(params, resourceRequests)
+		if len(unsupported) > 0 {
+			sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventFailed, UnsupportedResources: unsupported})
+			return
+		}
+
+		if costGate != nil && !costGate.ConfirmOverCost {
+			estimate, err := w.client.EstimateCost(ctx, systemID, appID, config)
+			if err != nil {
+				sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventFailed, Err: fmt.Errorf("failed to estimate cost: %w", err)})
+				return
+			}
+			if estimate.EstimatedCost > costGate.MaxCost {
+				sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventFailed, CostExceeded: &CostExceeded{Estimate: *estimate, MaxCost: costGate.MaxCost}})
+				return
+			}
+		}
+
+		var policyViolations []PolicyResult
+		if len(w.policies) > 0 {
+			app, err := w.lookupApp(ctx, systemID, appID)
+			if err != nil {
+				sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventFailed, Err: fmt.Errorf("failed to look up app for policy evaluation: %w", err)})
+				return
+			}
+
+			var denied bool
+			for _, policy := range w.policies {
+				result := policy.Evaluate(ctx, app, params, config)
+				if !result.Violated {
+					continue
+				}
+				policyViolations = append(policyViolations, result)
+				if result.Severity == PolicyDeny {
+					denied = true
+				}
+			}
+
+			if denied {
+				sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventFailed, PolicyViolations: policyViolations})
+				return
+			}
+		}
+
+		isInteractive := w.isInteractiveJobType(params.OverallJobType)
+		logger := subsystemLogger(ctx)
+
+		logger.Info("launching app", "app_id", appID, "system_id", systemID, "job_type", params.OverallJobType, "interactive", isInteractive)
+
+		submission := client.LaunchSubmission{
+			Name:         name,
+			Config:       config,
+			Requirements: resourceRequirements(resourceRequests),
+		}
+
+		launchResp, err := w.client.LaunchApp(ctx, systemID, appID, submission)
+		if err != nil {
+			sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventFailed, Err: fmt.Errorf("failed to launch app: %w", err)})
+			return
+		}
+		w.recordLaunch(launchResp.AnalysisID, appID, systemID, config)
+
+		start := time.Now()
+		if !sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventSubmitted, AnalysisID: launchResp.AnalysisID, Name: launchResp.Name, Status: launchResp.Status, IsInteractive: isInteractive, PolicyViolations: policyViolations}) {
+			return
+		}
+
+		if !isInteractive {
+			logger.Info("batch job launched", "analysis_id", launchResp.AnalysisID)
+			sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventCompleted, AnalysisID: launchResp.AnalysisID, Name: launchResp.Name, Status: launchResp.Status, Elapsed: time.Since(start)})
+			return
+		}
+
+		logger.Info("waiting for interactive app to be ready", "analysis_id", launchResp.AnalysisID, "max_wait", maxWait)
+
+		deadline := time.NewTimer(maxWait)
+		defer deadline.Stop()
+
+		statusCh, unsubscribe := w.watcher.Watch(ctx, launchResp.AnalysisID)
+		defer unsubscribe()
+
+		lastStatus := launchResp.Status
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.shutdown:
+				return
+			case <-deadline.C:
+				sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventTimedOut, AnalysisID: launchResp.AnalysisID, Name: launchResp.Name, Status: lastStatus, Elapsed: time.Since(start), IsInteractive: true})
+				return
+			case status, ok := <-statusCh:
+				if !ok {
+					return
+				}
+
+				logger.Debug("analysis status", "analysis_id", launchResp.AnalysisID, "status", status.Status, "url_ready", status.URLReady)
+
+				if status.URLReady && status.URL != "" {
+					logger.Info("interactive app ready", "analysis_id", launchResp.AnalysisID, "url", status.URL)
+					sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventURLReady, AnalysisID: launchResp.AnalysisID, Name: launchResp.Name, Status: status.Status, Elapsed: time.Since(start), IsInteractive: true, URL: status.URL})
+					return
+				}
+
+				if status.Status == "Failed" || status.Status == "Canceled" {
+					sendWorkflowEvent(ctx, events, WorkflowEvent{Type: EventFailed, AnalysisID: launchResp.AnalysisID, Name: launchResp.Name, Status: status.Status, Elapsed: time.Since(start), IsInteractive: true})
+					return
+				}
+
+				event := WorkflowEvent{Type: EventHeartbeat, AnalysisID: launchResp.AnalysisID, Name: launchResp.Name, Status: status.Status, Elapsed: time.Since(start), IsInteractive: true}
+				if status.Status != lastStatus {
+					event.Type = workflowEventTypeForStatus(status.Status)
+				}
+				lastStatus = status.Status
+
+				if !sendWorkflowEvent(ctx, events, event) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// sendWorkflowEvent delivers event on events, reporting whether it was sent
+// before ctx was cancelled - mirroring the same select-on-ctx-or-send
+// pattern watch's stream loop uses to avoid blocking forever on a consumer
+// that's gone away.
+func sendWorkflowEvent(ctx context.Context, events chan<- WorkflowEvent, event WorkflowEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// workflowEventTypeForStatus maps an analysis status string to the
+// WorkflowEvent type it should be reported as the moment the status
+// changes to it, defaulting to Running for any status this package doesn't
+// specifically recognize.
+func workflowEventTypeForStatus(status string) WorkflowEventType {
+	switch status {
+	case "Submitted":
+		return EventSubmitted
+	case "Queued":
+		return EventQueued
+	default:
+		return EventRunning
+	}
+}
+
+// CostEstimate estimates the resource envelope and cost of launching appID
+// with config, before submitting it, so a caller can reason about spend
+// ahead of a potentially long-running interactive session.
+func (w *FormationWorkflows) CostEstimate(ctx context.Context, appID, systemID string, config client.LaunchConfig) (*client.CostEstimate, error) {
+	return w.client.EstimateCost(ctx, systemID, appID, config)
+}
+
+// GetRunningAnalyses retrieves all running analyses.
+func (w *FormationWorkflows) GetRunningAnalyses(ctx context.Context) ([]client.Analysis, error) {
+	return w.client.ListAnalyses(ctx, "Running")
+}
+
+// StopAnalysis stops a running analysis.
+// StopAnalysis stops a running analysis. If snapshotBeforeExit is true, it
+// first snapshots the analysis (see SnapshotAnalysis) so it can be resumed
+// later via ResumeFromSnapshot; the stop still proceeds even if the
+// snapshot fails, but the error is returned once the analysis has stopped.
+func (w *FormationWorkflows) StopAnalysis(ctx context.Context, analysisID string, saveOutputs, snapshotBeforeExit bool) error {
+	var snapshotErr error
+	if snapshotBeforeExit {
+		if _, err := w.SnapshotAnalysis(ctx, analysisID, "stop-analysis auto-snapshot"); err != nil {
+			snapshotErr = fmt.Errorf("failed to snapshot analysis before stopping it: %w", err)
+		}
+	}
+
+	operation := "exit"
+	if saveOutputs {
+		operation = "save_and_exit"
+	}
+
+	if err := w.client.ControlAnalysis(ctx, analysisID, operation, saveOutputs); err != nil {
+		return err
+	}
+
+	return snapshotErr
+}
+
+// snapshotsCollectionPath is the collection SnapshotAnalysis writes
+// checkpoints under. Formation API paths are scoped to the authenticated
+// user, so a single well-known relative path is enough - there's no
+// cross-user collision to avoid.
+const snapshotsCollectionPath = ".snapshots"
+
+// snapshotAttr is the AVU attribute SnapshotAnalysis tags every checkpoint
+// directory with, so ListSnapshots can find them via BrowseData without a
+// dedicated listing API.
+const snapshotAttr = "formation-mcp-snapshot"
+
+// SnapshotResumeParam is the LaunchConfig key ResumeFromSnapshot
+// pre-populates with the snapshot's checkpoint path, for apps wired to
+// pick up a prior session's state from it.
+const SnapshotResumeParam = "formation_mcp_snapshot_path"
+
+// Snapshot is a saved checkpoint of an interactive analysis's launch
+// configuration, persisted to iRODS so ResumeFromSnapshot can relaunch it
+// later - typically used to shut down a VICE session at end-of-day and
+// pick it back up tomorrow.
+type Snapshot struct {
+	ID         string
+	AnalysisID string
+	AppID      string
+	SystemID   string
+	Label      string
+	Path       string
+	Config     client.LaunchConfig
+	CreatedAt  string
+}
+
+// SnapshotAnalysis checkpoints analysisID - which must have been launched
+// by this FormationWorkflows, so its app/system/config are known - by
+// persisting its launch configuration to a new directory under
+// .snapshots/, tagged with AVU metadata recording the app, system, and
+// creation time. ResumeFromSnapshot reverses this to relaunch the app.
+func (w *FormationWorkflows) SnapshotAnalysis(ctx context.Context, analysisID, label string) (*Snapshot, error) {
+	record, ok := w.launchRecordFor(analysisID)
+	if !ok {
+		return nil, fmt.Errorf("no launch recorded for analysis %s, can't snapshot it", analysisID)
+	}
+
+	configJSON, err := json.Marshal(record.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot config: %w", err)
+	}
+
+	createdAt := time.Now().UTC().Format(time.RFC3339)
+	snapshotID := fmt.Sprintf("%s-%d", analysisID, time.Now().UnixNano())
+	dirPath := fmt.Sprintf("%s/%s", snapshotsCollectionPath, snapshotID)
+	filePath := fmt.Sprintf("%s/snapshot.json", dirPath)
+
+	metadata := map[string]interface{}{
+		snapshotAttr:  "true",
+		"analysis_id": analysisID,
+		"app_id":      record.AppID,
+		"system_id":   record.SystemID,
+		"created_at":  createdAt,
+	}
+	if label != "" {
+		metadata["label"] = label
+	}
+
+	if _, err := w.client.CreateDirectory(ctx, dirPath, metadata); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	if err := w.client.UploadFile(ctx, filePath, string(configJSON), metadata); err != nil {
+		return nil, fmt.Errorf("failed to persist snapshot: %w", err)
+	}
+
+	subsystemLogger(ctx).Info("snapshotted analysis", "analysis_id", analysisID, "snapshot_id", snapshotID)
+
+	return &Snapshot{
+		ID:         snapshotID,
+		AnalysisID: analysisID,
+		AppID:      record.AppID,
+		SystemID:   record.SystemID,
+		Label:      label,
+		Path:       dirPath,
+		Config:     record.Config,
+		CreatedAt:  createdAt,
+	}, nil
+}
+
+// ResumeFromSnapshot relaunches the app a prior SnapshotAnalysis call
+// checkpointed as snapshotID, merging its saved config with config (config
+// takes precedence on overlapping keys) and pre-populating
+// SnapshotResumeParam with the checkpoint path so an app wired for it can
+// pick up where the snapshot left off. It waits for readiness the same way
+// LaunchAndWait does.
+func (w *FormationWorkflows) ResumeFromSnapshot(ctx context.Context, snapshotID string, config client.LaunchConfig) (*LaunchResult, error) {
+	filePath := fmt.Sprintf("%s/%s/snapshot.json", snapshotsCollectionPath, snapshotID)
+
+	data, err := w.client.BrowseData(ctx, filePath, 0, 0, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", snapshotID, err)
+	}
+	file, ok := data.(*client.FileContent)
+	if !ok {
+		return nil, fmt.Errorf("snapshot %s has no readable content", snapshotID)
+	}
+
+	appID, _ := file.Metadata["app_id"].(string)
+	systemID, _ := file.Metadata["system_id"].(string)
+	if appID == "" || systemID == "" {
+		return nil, fmt.Errorf("snapshot %s is missing app/system metadata", snapshotID)
+	}
+
+	var savedConfig client.LaunchConfig
+	if err := json.Unmarshal([]byte(file.Content), &savedConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot config: %w", err)
+	}
+
+	merged := make(client.LaunchConfig, len(savedConfig)+len(config)+1)
+	for k, v := range savedConfig {
+		merged[k] = v
+	}
+	merged[SnapshotResumeParam] = fmt.Sprintf("%s/%s", snapshotsCollectionPath, snapshotID)
+	for k, v := range config {
+		merged[k] = v
+	}
+
+	subsystemLogger(ctx).Info("resuming analysis from snapshot", "snapshot_id", snapshotID, "app_id", appID, "system_id", systemID)
+
+	return w.LaunchAndWait(ctx, appID, systemID, fmt.Sprintf("resume-%s", snapshotID), merged, nil, nil, w.pollInterval*maxResumeWaitPolls)
+}
+
+// maxResumeWaitPolls bounds how long ResumeFromSnapshot waits for the
+// resumed app to become ready, expressed as a multiple of pollInterval so
+// it scales the same way tests that use a shortened pollInterval do.
+const maxResumeWaitPolls = 60
+
+// ListSnapshots lists every checkpoint SnapshotAnalysis has persisted, by
+// browsing the .snapshots collection and filtering its entries down to the
+// ones carrying snapshotAttr - BrowseData has no query-by-metadata mode of
+// its own, so filtering happens here instead.
+func (w *FormationWorkflows) ListSnapshots(ctx context.Context) ([]Snapshot, error) {
+	data, err := w.client.BrowseData(ctx, snapshotsCollectionPath, 0, 0, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	dir, ok := data.(*client.DirectoryContents)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a directory", snapshotsCollectionPath)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range dir.Contents {
+		if entry.Type != "collection" {
+			continue
+		}
+
+		filePath := fmt.Sprintf("%s/%s/snapshot.json", snapshotsCollectionPath, entry.Name)
+		data, err := w.client.BrowseData(ctx, filePath, 0, 0, true)
+		if err != nil {
+			subsystemLogger(ctx).Warn("failed to read snapshot while listing", "snapshot_id", entry.Name, "error", err)
+			continue
+		}
+		file, ok := data.(*client.FileContent)
+		if !ok || file.Metadata[snapshotAttr] != "true" {
+			continue
+		}
+
+		var config client.LaunchConfig
+		if err := json.Unmarshal([]byte(file.Content), &config); err != nil {
+			subsystemLogger(ctx).Warn("failed to parse snapshot config while listing", "snapshot_id", entry.Name, "error", err)
+			continue
+		}
+
+		analysisID, _ := file.Metadata["analysis_id"].(string)
+		appID, _ := file.Metadata["app_id"].(string)
+		systemID, _ := file.Metadata["system_id"].(string)
+		label, _ := file.Metadata["label"].(string)
+		createdAt, _ := file.Metadata["created_at"].(string)
+
+		snapshots = append(snapshots, Snapshot{
+			ID:         entry.Name,
+			AnalysisID: analysisID,
+			AppID:      appID,
+			SystemID:   systemID,
+			Label:      label,
+			Path:       fmt.Sprintf("%s/%s", snapshotsCollectionPath, entry.Name),
+			Config:     config,
+			CreatedAt:  createdAt,
+		})
+	}
+
+	return snapshots, nil
+}
+
+// OpenInBrowser opens a URL in the default browser.
+func (w *FormationWorkflows) OpenInBrowser(url string) error {
+	slog.Info("opening url in browser", "url", url)
+	return w.browserOpener.Open(url)
+}
+
+// LaunchProgress is one intermediate update delivered by LaunchAndStream's
+// onUpdate callback while the launched app is coming up or running.
+type LaunchProgress struct {
+	AnalysisID string
+	Status     string
+	Elapsed    time.Duration
+	URLReady   bool
+	URL        string
+}
+
+// LaunchAndStream is LaunchAndWait with progress reporting: it launches an
+// app the same way, but instead of blocking silently, it invokes onUpdate
+// with the analysis's status every pollInterval (and once immediately
+// after launch) so a caller can forward progress to its own client. If
+// onUpdate returns an error, streaming stops and that error is returned.
+// If ctx is cancelled while waiting for an interactive app to become
+// ready, the analysis is stopped (without saving outputs) before ctx.Err()
+// is returned.
+func (w *FormationWorkflows) LaunchAndStream(ctx context.Context, appID, systemID, name string, config client.LaunchConfig, maxWait time.Duration, onUpdate func(LaunchProgress) error) (*LaunchResult, error) {
 	params, err := w.client.GetAppParameters(ctx, systemID, appID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get app parameters: %w", err)
 	}
 
-	// Check for missing required parameters
 	missingParams := w.checkMissingParams(params, config)
 	if len(missingParams) > 0 {
 		return &LaunchResult{
@@ -53,22 +812,16 @@ func (w *FormationWorkflows) LaunchAndWait(ctx context.Context, appID, systemID,
 		}, nil
 	}
 
-	// Determine if this is an interactive app
 	isInteractive := w.isInteractiveJobType(params.OverallJobType)
+	logger := subsystemLogger(ctx)
 
-	slog.Info("launching app", "app_id", appID, "system_id", systemID, "job_type", params.OverallJobType, "interactive", isInteractive)
+	logger.Info("launching app", "app_id", appID, "system_id", systemID, "job_type", params.OverallJobType, "interactive", isInteractive)
 
-	// Build submission with proper defaults
-	// Formation API will auto-generate name and output_dir if not provided
 	submission := client.LaunchSubmission{
 		Name:   name,
 		Config: config,
-		// Email will be resolved from JWT token by Formation API
-		// Debug defaults to false in Formation API
-		// Notify defaults to true in Formation API
 	}
 
-	// Launch the app
 	launchResp, err := w.client.LaunchApp(ctx, systemID, appID, submission)
 	if err != nil {
 		return nil, fmt.Errorf("failed to launch app: %w", err)
@@ -81,14 +834,17 @@ func (w *FormationWorkflows) LaunchAndWait(ctx context.Context, appID, systemID,
 		IsInteractive: isInteractive,
 	}
 
-	// For batch jobs, return immediately
+	start := time.Now()
+	if err := onUpdate(LaunchProgress{AnalysisID: result.AnalysisID, Status: result.Status}); err != nil {
+		return result, err
+	}
+
 	if !isInteractive {
-		slog.Info("batch job launched", "analysis_id", result.AnalysisID)
+		logger.Info("batch job launched", "analysis_id", result.AnalysisID)
 		return result, nil
 	}
 
-	// For interactive apps, poll until URL is ready or timeout
-	slog.Info("waiting for interactive app to be ready", "analysis_id", result.AnalysisID, "max_wait", maxWait)
+	logger.Info("streaming progress for interactive app", "analysis_id", result.AnalysisID, "max_wait", maxWait)
 
 	deadline := time.Now().Add(maxWait)
 	ticker := time.NewTicker(w.pollInterval)
@@ -97,6 +853,10 @@ func (w *FormationWorkflows) LaunchAndWait(ctx context.Context, appID, systemID,
 	for {
 		select {
 		case <-ctx.Done():
+			logger.Info("launch stream cancelled, stopping analysis", "analysis_id", result.AnalysisID)
+			if stopErr := w.client.ControlAnalysis(context.Background(), result.AnalysisID, "exit", false); stopErr != nil {
+				logger.Warn("failed to stop analysis after cancellation", "analysis_id", result.AnalysisID, "error", stopErr)
+			}
 			return result, ctx.Err()
 		case <-ticker.C:
 			if time.Now().After(deadline) {
@@ -105,17 +865,25 @@ func (w *FormationWorkflows) LaunchAndWait(ctx context.Context, appID, systemID,
 
 			status, err := w.client.GetAnalysisStatus(ctx, result.AnalysisID)
 			if err != nil {
-				slog.Warn("failed to get analysis status", "analysis_id", result.AnalysisID, "error", err)
+				logger.Warn("failed to get analysis status", "analysis_id", result.AnalysisID, "error", err)
 				continue
 			}
 
 			result.Status = status.Status
 			result.URL = status.URL
 
-			slog.Debug("analysis status", "analysis_id", result.AnalysisID, "status", status.Status, "url_ready", status.URLReady)
+			if err := onUpdate(LaunchProgress{
+				AnalysisID: result.AnalysisID,
+				Status:     status.Status,
+				Elapsed:    time.Since(start),
+				URLReady:   status.URLReady,
+				URL:        status.URL,
+			}); err != nil {
+				return result, err
+			}
 
 			if status.URLReady && status.URL != "" {
-				slog.Info("interactive app ready", "analysis_id", result.AnalysisID, "url", status.URL)
+				logger.Info("interactive app ready", "analysis_id", result.AnalysisID, "url", status.URL)
 				return result, nil
 			}
 
@@ -127,38 +895,69 @@ func (w *FormationWorkflows) LaunchAndWait(ctx context.Context, appID, systemID,
 	}
 }
 
-// GetRunningAnalyses retrieves all running analyses.
-func (w *FormationWorkflows) GetRunningAnalyses(ctx context.Context) ([]client.Analysis, error) {
-	return w.client.ListAnalyses(ctx, "Running")
-}
+// unsupportedResources reports which classes of resourceRequests the app at
+// params does not declare in its ResourceCapabilities. A nil or zero-value
+// resourceRequests always returns no unsupported classes.
+func unsupportedResources(params *client.AppParameters, resourceRequests *client.ResourceRequests) []string {
+	if resourceRequests == nil {
+		return nil
+	}
 
-// StopAnalysis stops a running analysis.
-func (w *FormationWorkflows) StopAnalysis(ctx context.Context, analysisID string, saveOutputs bool) error {
-	operation := "exit"
-	if saveOutputs {
-		operation = "save_and_exit"
+	capable := make(map[string]bool, len(params.ResourceCapabilities))
+	for _, class := range params.ResourceCapabilities {
+		capable[class] = true
 	}
 
-	return w.client.ControlAnalysis(ctx, analysisID, operation, saveOutputs)
+	var unsupported []string
+	if resourceRequests.GPU != nil && resourceRequests.GPU.Count > 0 && !capable["gpu"] {
+		unsupported = append(unsupported, "gpu")
+	}
+	if resourceRequests.QAT > 0 && !capable["qat"] {
+		unsupported = append(unsupported, "qat")
+	}
+	if resourceRequests.Hugepages2Mi > 0 && !capable["hugepages_2mi"] {
+		unsupported = append(unsupported, "hugepages_2mi")
+	}
+	if resourceRequests.SRIOVNIC > 0 && !capable["sriov_nic"] {
+		unsupported = append(unsupported, "sriov_nic")
+	}
+	return unsupported
 }
 
-// OpenInBrowser opens a URL in the default browser.
-func (w *FormationWorkflows) OpenInBrowser(url string) error {
-	var cmd *exec.Cmd
+// resourceRequirements translates resourceRequests into the analysis
+// submission's requirement block, keyed by the Kubernetes device-plugin
+// resource names the DE scheduler matches pods against (e.g.
+// "nvidia.com/gpu"). Returns nil if resourceRequests is nil or empty, so
+// LaunchSubmission.Requirements is omitted rather than submitted as an
+// empty object.
+func resourceRequirements(resourceRequests *client.ResourceRequests) map[string]interface{} {
+	if resourceRequests == nil {
+		return nil
+	}
 
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", url)
-	case "linux":
-		cmd = exec.Command("xdg-open", url)
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", url)
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	requirements := make(map[string]interface{})
+
+	if resourceRequests.GPU != nil && resourceRequests.GPU.Count > 0 {
+		vendor := resourceRequests.GPU.Vendor
+		if vendor == "" {
+			vendor = "nvidia"
+		}
+		requirements[fmt.Sprintf("%s.com/gpu", vendor)] = resourceRequests.GPU.Count
+	}
+	if resourceRequests.QAT > 0 {
+		requirements["qat.intel.com/generic"] = resourceRequests.QAT
+	}
+	if resourceRequests.Hugepages2Mi > 0 {
+		requirements["hugepages-2Mi"] = resourceRequests.Hugepages2Mi
+	}
+	if resourceRequests.SRIOVNIC > 0 {
+		requirements["intel.com/sriov"] = resourceRequests.SRIOVNIC
 	}
 
-	slog.Info("opening url in browser", "url", url)
-	return cmd.Start()
+	if len(requirements) == 0 {
+		return nil
+	}
+	return requirements
 }
 
 // checkMissingParams checks if any required parameters are missing from the config.
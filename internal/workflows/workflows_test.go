@@ -3,6 +3,11 @@ package workflows
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,18 +16,28 @@ import (
 
 // mockFormationClient implements FormationAPIClient for testing
 type mockFormationClient struct {
-	loginFunc             func(ctx context.Context) error
-	listAppsFunc          func(ctx context.Context, name, integrator, description, jobType string, limit, offset int) ([]client.App, error)
-	getAppParametersFunc  func(ctx context.Context, systemID, appID string) (*client.AppParameters, error)
-	launchAppFunc         func(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error)
-	getAnalysisStatusFunc func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error)
-	listAnalysesFunc      func(ctx context.Context, status string) ([]client.Analysis, error)
-	controlAnalysisFunc   func(ctx context.Context, analysisID, operation string, saveOutputs bool) error
-	browseDataFunc        func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error)
-	createDirectoryFunc   func(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error)
-	uploadFileFunc        func(ctx context.Context, path, content string, metadata map[string]interface{}) error
-	setMetadataFunc       func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error
-	deleteDataFunc        func(ctx context.Context, path string, recurse, dryRun bool) error
+	loginFunc              func(ctx context.Context) error
+	listAppsFunc           func(ctx context.Context, name, integrator, description, jobType string, limit, offset int) ([]client.App, error)
+	getAppParametersFunc   func(ctx context.Context, systemID, appID string) (*client.AppParameters, error)
+	launchAppFunc          func(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error)
+	estimateCostFunc       func(ctx context.Context, systemID, appID string, config client.LaunchConfig) (*client.CostEstimate, error)
+	getAnalysisStatusFunc  func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error)
+	listAnalysesFunc       func(ctx context.Context, status string) ([]client.Analysis, error)
+	watchAnalysisFunc      func(ctx context.Context, analysisID string) (<-chan client.AnalysisEvent, error)
+	watchAnalysesFunc      func(ctx context.Context, filter string) (<-chan client.AnalysisEvent, error)
+	controlAnalysisFunc    func(ctx context.Context, analysisID, operation string, saveOutputs bool) error
+	browseDataFunc         func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error)
+	createDirectoryFunc    func(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error)
+	uploadFileFunc         func(ctx context.Context, path, content string, metadata map[string]interface{}) error
+	uploadFileStreamFunc   func(ctx context.Context, path string, r io.Reader, size int64, opts client.UploadOptions) error
+	downloadFileStreamFunc func(ctx context.Context, path string, w io.Writer, onProgress func(n, total int64)) error
+	uploadFileChunkFunc    func(ctx context.Context, uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*client.UploadChunkResult, error)
+	setMetadataFunc        func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error
+	snapshotMetadataFunc   func(ctx context.Context, path string) (map[string]interface{}, error)
+	searchMetadataFunc     func(ctx context.Context, query client.MetadataQuery) (*client.MetadataSearchResult, error)
+	deleteDataFunc         func(ctx context.Context, path string, recurse, dryRun bool) error
+	shutdownFunc           func(ctx context.Context) error
+	lastActivityFunc       func() time.Time
 }
 
 func (m *mockFormationClient) Login(ctx context.Context) error {
@@ -53,6 +68,13 @@ func (m *mockFormationClient) LaunchApp(ctx context.Context, systemID, appID str
 	return &client.LaunchResponse{}, nil
 }
 
+func (m *mockFormationClient) EstimateCost(ctx context.Context, systemID, appID string, config client.LaunchConfig) (*client.CostEstimate, error) {
+	if m.estimateCostFunc != nil {
+		return m.estimateCostFunc(ctx, systemID, appID, config)
+	}
+	return &client.CostEstimate{}, nil
+}
+
 func (m *mockFormationClient) GetAnalysisStatus(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
 	if m.getAnalysisStatusFunc != nil {
 		return m.getAnalysisStatusFunc(ctx, analysisID)
@@ -67,6 +89,26 @@ func (m *mockFormationClient) ListAnalyses(ctx context.Context, status string) (
 	return []client.Analysis{}, nil
 }
 
+func (m *mockFormationClient) WatchAnalysis(ctx context.Context, analysisID string) (<-chan client.AnalysisEvent, error) {
+	if m.watchAnalysisFunc != nil {
+		return m.watchAnalysisFunc(ctx, analysisID)
+	}
+	return nil, nil
+}
+
+func (m *mockFormationClient) WatchAnalyses(ctx context.Context, filter string) (<-chan client.AnalysisEvent, error) {
+	if m.watchAnalysesFunc != nil {
+		return m.watchAnalysesFunc(ctx, filter)
+	}
+	return nil, nil
+}
+
+func (m *mockFormationClient) StreamAnalysisLogs(ctx context.Context, analysisID string, opts client.LogStreamOptions) (<-chan client.LogLine, error) {
+	ch := make(chan client.LogLine)
+	close(ch)
+	return ch, nil
+}
+
 func (m *mockFormationClient) ControlAnalysis(ctx context.Context, analysisID, operation string, saveOutputs bool) error {
 	if m.controlAnalysisFunc != nil {
 		return m.controlAnalysisFunc(ctx, analysisID, operation, saveOutputs)
@@ -95,6 +137,27 @@ func (m *mockFormationClient) UploadFile(ctx context.Context, path, content stri
 	return nil
 }
 
+func (m *mockFormationClient) UploadFileStream(ctx context.Context, path string, r io.Reader, size int64, opts client.UploadOptions) error {
+	if m.uploadFileStreamFunc != nil {
+		return m.uploadFileStreamFunc(ctx, path, r, size, opts)
+	}
+	return nil
+}
+
+func (m *mockFormationClient) DownloadFileStream(ctx context.Context, path string, w io.Writer, onProgress func(n, total int64)) error {
+	if m.downloadFileStreamFunc != nil {
+		return m.downloadFileStreamFunc(ctx, path, w, onProgress)
+	}
+	return nil
+}
+
+func (m *mockFormationClient) UploadFileChunk(ctx context.Context, uploadID, path string, offset int64, chunk []byte, isFinal bool, metadata map[string]interface{}) (*client.UploadChunkResult, error) {
+	if m.uploadFileChunkFunc != nil {
+		return m.uploadFileChunkFunc(ctx, uploadID, path, offset, chunk, isFinal, metadata)
+	}
+	return &client.UploadChunkResult{}, nil
+}
+
 func (m *mockFormationClient) SetMetadata(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
 	if m.setMetadataFunc != nil {
 		return m.setMetadataFunc(ctx, path, metadata, replace)
@@ -102,6 +165,20 @@ func (m *mockFormationClient) SetMetadata(ctx context.Context, path string, meta
 	return nil
 }
 
+func (m *mockFormationClient) SnapshotMetadata(ctx context.Context, path string) (map[string]interface{}, error) {
+	if m.snapshotMetadataFunc != nil {
+		return m.snapshotMetadataFunc(ctx, path)
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (m *mockFormationClient) SearchMetadata(ctx context.Context, query client.MetadataQuery) (*client.MetadataSearchResult, error) {
+	if m.searchMetadataFunc != nil {
+		return m.searchMetadataFunc(ctx, query)
+	}
+	return &client.MetadataSearchResult{}, nil
+}
+
 func (m *mockFormationClient) DeleteData(ctx context.Context, path string, recurse, dryRun bool) error {
 	if m.deleteDataFunc != nil {
 		return m.deleteDataFunc(ctx, path, recurse, dryRun)
@@ -109,6 +186,36 @@ func (m *mockFormationClient) DeleteData(ctx context.Context, path string, recur
 	return nil
 }
 
+func (m *mockFormationClient) CreateAnalysisAlert(ctx context.Context, alert client.AnalysisAlert) (*client.AnalysisAlert, error) {
+	return &client.AnalysisAlert{}, nil
+}
+
+func (m *mockFormationClient) ListAnalysisAlerts(ctx context.Context, analysisID string) ([]client.AnalysisAlert, error) {
+	return []client.AnalysisAlert{}, nil
+}
+
+func (m *mockFormationClient) DeleteAnalysisAlert(ctx context.Context, alertID string) error {
+	return nil
+}
+
+func (m *mockFormationClient) DownloadFileRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, *client.FileMetadata, error) {
+	return io.NopCloser(strings.NewReader("")), &client.FileMetadata{}, nil
+}
+
+func (m *mockFormationClient) Shutdown(ctx context.Context) error {
+	if m.shutdownFunc != nil {
+		return m.shutdownFunc(ctx)
+	}
+	return nil
+}
+
+func (m *mockFormationClient) LastActivity() time.Time {
+	if m.lastActivityFunc != nil {
+		return m.lastActivityFunc()
+	}
+	return time.Time{}
+}
+
 // mockBrowserOpener implements BrowserOpener for testing
 type mockBrowserOpener struct {
 	openFunc func(url string) error
@@ -126,17 +233,22 @@ func (m *mockBrowserOpener) Open(url string) error {
 // TestLaunchAndWait tests the LaunchAndWait workflow
 func TestLaunchAndWait(t *testing.T) {
 	tests := []struct {
-		name            string
-		appID           string
-		systemID        string
-		analysisName    string
-		config          client.LaunchConfig
-		params          *client.AppParameters
-		launchResp      *client.LaunchResponse
-		statusSequence  []*client.AnalysisStatus
-		wantErr         bool
-		wantMissingParams bool
-		errContains     string
+		name                     string
+		appID                    string
+		systemID                 string
+		analysisName             string
+		config                   client.LaunchConfig
+		resourceRequests         *client.ResourceRequests
+		costGate                 *CostGate
+		costEstimate             *client.CostEstimate
+		params                   *client.AppParameters
+		launchResp               *client.LaunchResponse
+		statusSequence           []*client.AnalysisStatus
+		wantErr                  bool
+		wantMissingParams        bool
+		wantUnsupportedResources bool
+		wantCostExceeded         bool
+		errContains              string
 	}{
 		{
 			name:         "successful batch job launch",
@@ -185,6 +297,36 @@ func TestLaunchAndWait(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:             "unsupported resource request",
+			appID:            "no-gpu-app",
+			systemID:         "de",
+			analysisName:     "test-unsupported-resource",
+			config:           client.LaunchConfig{},
+			resourceRequests: &client.ResourceRequests{GPU: &client.GPURequest{Count: 1}},
+			params: &client.AppParameters{
+				OverallJobType:       "DE",
+				Groups:               []client.ParameterGroup{},
+				ResourceCapabilities: []string{"qat"},
+			},
+			wantUnsupportedResources: true,
+			wantErr:                  false,
+		},
+		{
+			name:         "cost gate rejects over-cost launch",
+			appID:        "expensive-app",
+			systemID:     "de",
+			analysisName: "test-cost-gate",
+			config:       client.LaunchConfig{},
+			costGate:     &CostGate{MaxCost: 5},
+			costEstimate: &client.CostEstimate{EstimatedCost: 10},
+			params: &client.AppParameters{
+				OverallJobType: "DE",
+				Groups:         []client.ParameterGroup{},
+			},
+			wantCostExceeded: true,
+			wantErr:          false,
+		},
 		{
 			name:         "missing required parameters",
 			appID:        "app-with-params",
@@ -288,6 +430,12 @@ func TestLaunchAndWait(t *testing.T) {
 					}
 					return &client.AnalysisStatus{}, nil
 				},
+				estimateCostFunc: func(ctx context.Context, systemID, appID string, config client.LaunchConfig) (*client.CostEstimate, error) {
+					if tt.costEstimate == nil {
+						return &client.CostEstimate{}, nil
+					}
+					return tt.costEstimate, nil
+				},
 			}
 
 			mockBrowser := &mockBrowserOpener{}
@@ -304,6 +452,8 @@ func TestLaunchAndWait(t *testing.T) {
 				tt.systemID,
 				tt.analysisName,
 				tt.config,
+				tt.resourceRequests,
+				tt.costGate,
 				maxWait,
 			)
 
@@ -322,6 +472,14 @@ func TestLaunchAndWait(t *testing.T) {
 					if len(result.MissingParams) == 0 {
 						t.Errorf("LaunchAndWait() expected missing params but got none")
 					}
+				} else if tt.wantUnsupportedResources {
+					if len(result.UnsupportedResources) == 0 {
+						t.Errorf("LaunchAndWait() expected unsupported resources but got none")
+					}
+				} else if tt.wantCostExceeded {
+					if result.CostExceeded == nil {
+						t.Errorf("LaunchAndWait() expected cost exceeded but got none")
+					}
 				} else {
 					if result == nil {
 						t.Errorf("LaunchAndWait() returned nil result")
@@ -336,6 +494,412 @@ func TestLaunchAndWait(t *testing.T) {
 	}
 }
 
+// TestLaunchAndWaitStreamEventOrdering asserts the sequence of WorkflowEvent
+// types LaunchAndWaitStream emits for an interactive launch, which
+// LaunchAndWait's final-outcome-only assertions in TestLaunchAndWait can't
+// check.
+func TestLaunchAndWaitStreamEventOrdering(t *testing.T) {
+	statusIndex := 0
+	statusSequence := []*client.AnalysisStatus{
+		{AnalysisID: "analysis-456", Status: "Running", URLReady: false},
+		{AnalysisID: "analysis-456", Status: "Running", URLReady: false},
+		{AnalysisID: "analysis-456", Status: "Running", URLReady: true, URL: "https://test.cyverse.run"},
+	}
+
+	mockClient := &mockFormationClient{
+		getAppParametersFunc: func(ctx context.Context, systemID, appID string) (*client.AppParameters, error) {
+			return &client.AppParameters{OverallJobType: "Interactive", Groups: []client.ParameterGroup{}}, nil
+		},
+		launchAppFunc: func(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error) {
+			return &client.LaunchResponse{AnalysisID: "analysis-456", Name: "test", Status: "Submitted"}, nil
+		},
+		getAnalysisStatusFunc: func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
+			if statusIndex < len(statusSequence) {
+				status := statusSequence[statusIndex]
+				statusIndex++
+				return status, nil
+			}
+			return statusSequence[len(statusSequence)-1], nil
+		},
+	}
+
+	w := NewFormationWorkflows(mockClient, &mockBrowserOpener{}, 10*time.Millisecond)
+
+	events, err := w.LaunchAndWaitStream(context.Background(), "interactive-app", "de", "test", client.LaunchConfig{}, nil, nil, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("LaunchAndWaitStream() unexpected error = %v", err)
+	}
+
+	var types []WorkflowEventType
+	for event := range events {
+		types = append(types, event.Type)
+	}
+
+	want := []WorkflowEventType{EventSubmitted, EventRunning, EventHeartbeat, EventURLReady}
+	if len(types) != len(want) {
+		t.Fatalf("event types = %v, want %v", types, want)
+	}
+	for i, wantType := range want {
+		if types[i] != wantType {
+			t.Errorf("event[%d].Type = %v, want %v", i, types[i], wantType)
+		}
+	}
+}
+
+// TestShutdownStopsPollLoop verifies that calling Shutdown on a
+// FormationWorkflows mid-poll makes an in-progress LaunchAndWaitStream exit
+// on its next tick instead of waiting out maxWait, and that it also calls
+// through to the underlying client's Shutdown.
+func TestShutdownStopsPollLoop(t *testing.T) {
+	var clientShutdownCalled bool
+	mockClient := &mockFormationClient{
+		getAppParametersFunc: func(ctx context.Context, systemID, appID string) (*client.AppParameters, error) {
+			return &client.AppParameters{OverallJobType: "Interactive", Groups: []client.ParameterGroup{}}, nil
+		},
+		launchAppFunc: func(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error) {
+			return &client.LaunchResponse{AnalysisID: "analysis-789", Name: "test", Status: "Submitted"}, nil
+		},
+		getAnalysisStatusFunc: func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
+			return &client.AnalysisStatus{AnalysisID: analysisID, Status: "Running", URLReady: false}, nil
+		},
+		shutdownFunc: func(ctx context.Context) error {
+			clientShutdownCalled = true
+			return nil
+		},
+	}
+
+	w := NewFormationWorkflows(mockClient, &mockBrowserOpener{}, 10*time.Millisecond)
+
+	events, err := w.LaunchAndWaitStream(context.Background(), "interactive-app", "de", "test", client.LaunchConfig{}, nil, nil, time.Hour)
+	if err != nil {
+		t.Fatalf("LaunchAndWaitStream() unexpected error = %v", err)
+	}
+
+	// Drain the initial EventSubmitted before shutting down, so the poll
+	// loop is the thing Shutdown has to interrupt rather than the launch
+	// itself.
+	<-events
+
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() unexpected error = %v", err)
+	}
+	if !clientShutdownCalled {
+		t.Error("expected Shutdown() to call through to the underlying client's Shutdown")
+	}
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			// Heartbeats may still arrive between the drained event and
+			// the shutdown taking effect; keep draining until closed.
+			for range events {
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the poll loop's events channel to close shortly after Shutdown()")
+	}
+}
+
+// fakePolicy is a LaunchPolicy controlled directly by a test, for cases
+// that don't need one of the built-in policies' own lookup logic.
+type fakePolicy struct {
+	result PolicyResult
+}
+
+func (p fakePolicy) Evaluate(ctx context.Context, app *client.App, params *client.AppParameters, config client.LaunchConfig) PolicyResult {
+	return p.result
+}
+
+// TestLaunchAndWaitPolicies covers RegisterPolicy's effect on LaunchAndWait:
+// a PolicyDeny violation rejects the launch before submission, an advisory
+// violation is carried forward but doesn't block it, and no registered
+// policies leaves LaunchAndWait's behavior unchanged from before this
+// feature existed.
+func TestLaunchAndWaitPolicies(t *testing.T) {
+	params := &client.AppParameters{OverallJobType: "Batch", Groups: []client.ParameterGroup{}}
+
+	newWorkflows := func() (*FormationWorkflows, *mockFormationClient) {
+		mockClient := &mockFormationClient{
+			getAppParametersFunc: func(ctx context.Context, systemID, appID string) (*client.AppParameters, error) {
+				return params, nil
+			},
+			launchAppFunc: func(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error) {
+				return &client.LaunchResponse{AnalysisID: "analysis-policy", Name: "test", Status: "Submitted"}, nil
+			},
+			listAppsFunc: func(ctx context.Context, name, integrator, description, jobType string, limit, offset int) ([]client.App, error) {
+				return []client.App{{ID: "app-1", SystemID: "de"}}, nil
+			},
+		}
+		return NewFormationWorkflows(mockClient, &mockBrowserOpener{}, 10*time.Millisecond), mockClient
+	}
+
+	t.Run("deny violation rejects the launch", func(t *testing.T) {
+		w, _ := newWorkflows()
+		w.RegisterPolicy(fakePolicy{result: PolicyResult{Policy: "test-deny", Violated: true, Severity: PolicyDeny, Message: "nope"}})
+
+		result, err := w.LaunchAndWait(context.Background(), "app-1", "de", "test", client.LaunchConfig{}, nil, nil, 50*time.Millisecond)
+		if err == nil {
+			t.Fatal("LaunchAndWait() expected error from denied policy, got none")
+		}
+		if len(result.PolicyViolations) != 1 || result.PolicyViolations[0].Policy != "test-deny" {
+			t.Errorf("LaunchAndWait() PolicyViolations = %v, want one violation from test-deny", result.PolicyViolations)
+		}
+	})
+
+	t.Run("advisory violation doesn't block the launch", func(t *testing.T) {
+		w, _ := newWorkflows()
+		w.RegisterPolicy(fakePolicy{result: PolicyResult{Policy: "test-advisory", Violated: true, Severity: PolicyAdvisory, Message: "fyi"}})
+
+		result, err := w.LaunchAndWait(context.Background(), "app-1", "de", "test", client.LaunchConfig{}, nil, nil, 50*time.Millisecond)
+		if err != nil {
+			t.Fatalf("LaunchAndWait() unexpected error = %v", err)
+		}
+		if result.AnalysisID != "analysis-policy" {
+			t.Errorf("LaunchAndWait() analysis ID = %v, want analysis-policy", result.AnalysisID)
+		}
+		if len(result.PolicyViolations) != 1 || result.PolicyViolations[0].Policy != "test-advisory" {
+			t.Errorf("LaunchAndWait() PolicyViolations = %v, want one advisory from test-advisory", result.PolicyViolations)
+		}
+	})
+
+	t.Run("no registered policies leaves the launch unaffected", func(t *testing.T) {
+		w, _ := newWorkflows()
+
+		result, err := w.LaunchAndWait(context.Background(), "app-1", "de", "test", client.LaunchConfig{}, nil, nil, 50*time.Millisecond)
+		if err != nil {
+			t.Fatalf("LaunchAndWait() unexpected error = %v", err)
+		}
+		if len(result.PolicyViolations) != 0 {
+			t.Errorf("LaunchAndWait() PolicyViolations = %v, want none", result.PolicyViolations)
+		}
+	})
+}
+
+// TestMaxConcurrentAnalysesPerUser covers the built-in policy that denies a
+// launch once the caller already has Max or more running analyses.
+func TestMaxConcurrentAnalysesPerUser(t *testing.T) {
+	mockClient := &mockFormationClient{
+		listAnalysesFunc: func(ctx context.Context, status string) ([]client.Analysis, error) {
+			return []client.Analysis{{AnalysisID: "a1"}, {AnalysisID: "a2"}}, nil
+		},
+	}
+
+	policy := MaxConcurrentAnalysesPerUser{Client: mockClient, Max: 2}
+	result := policy.Evaluate(context.Background(), &client.App{}, &client.AppParameters{}, client.LaunchConfig{})
+	if !result.Violated || result.Severity != PolicyDeny {
+		t.Errorf("Evaluate() = %+v, want a deny violation at the limit", result)
+	}
+
+	policy.Max = 3
+	result = policy.Evaluate(context.Background(), &client.App{}, &client.AppParameters{}, client.LaunchConfig{})
+	if result.Violated {
+		t.Errorf("Evaluate() = %+v, want no violation under the limit", result)
+	}
+}
+
+// TestAllowedSystemIDs covers the built-in policy that denies launching on
+// any system ID outside an allowlist.
+func TestAllowedSystemIDs(t *testing.T) {
+	policy := AllowedSystemIDs{SystemIDs: []string{"de"}}
+
+	if result := policy.Evaluate(context.Background(), &client.App{SystemID: "de"}, &client.AppParameters{}, client.LaunchConfig{}); result.Violated {
+		t.Errorf("Evaluate() = %+v, want no violation for an allowed system ID", result)
+	}
+	if result := policy.Evaluate(context.Background(), &client.App{SystemID: "other"}, &client.AppParameters{}, client.LaunchConfig{}); !result.Violated || result.Severity != PolicyDeny {
+		t.Errorf("Evaluate() = %+v, want a deny violation for a disallowed system ID", result)
+	}
+}
+
+// TestRequiredMetadataOnInputs covers the built-in policy that denies a
+// launch if a file input path is missing a required AVU.
+func TestRequiredMetadataOnInputs(t *testing.T) {
+	params := &client.AppParameters{
+		Groups: []client.ParameterGroup{
+			{Parameters: []client.Parameter{{ID: "input", Type: "FileInput"}}},
+		},
+	}
+
+	t.Run("missing attribute denies the launch", func(t *testing.T) {
+		mockClient := &mockFormationClient{
+			browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+				return &client.FileContent{Path: path, Metadata: map[string]interface{}{}}, nil
+			},
+		}
+		policy := RequiredMetadataOnInputs{Client: mockClient, RequiredAVUs: []string{"project"}}
+
+		result := policy.Evaluate(context.Background(), &client.App{}, params, client.LaunchConfig{"input": "/iplant/home/user/data.csv"})
+		if !result.Violated || result.Severity != PolicyDeny {
+			t.Errorf("Evaluate() = %+v, want a deny violation for the missing attribute", result)
+		}
+	})
+
+	t.Run("present attribute passes", func(t *testing.T) {
+		mockClient := &mockFormationClient{
+			browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+				return &client.FileContent{Path: path, Metadata: map[string]interface{}{"project": "plant-genomics"}}, nil
+			},
+		}
+		policy := RequiredMetadataOnInputs{Client: mockClient, RequiredAVUs: []string{"project"}}
+
+		result := policy.Evaluate(context.Background(), &client.App{}, params, client.LaunchConfig{"input": "/iplant/home/user/data.csv"})
+		if result.Violated {
+			t.Errorf("Evaluate() = %+v, want no violation when the attribute is present", result)
+		}
+	})
+}
+
+// TestBlockedIntegrators covers the built-in policy that flags, but doesn't
+// block, launches of apps from a blocklisted integrator.
+func TestBlockedIntegrators(t *testing.T) {
+	policy := BlockedIntegrators{Usernames: []string{"deprecated_integrator"}}
+
+	result := policy.Evaluate(context.Background(), &client.App{IntegratorUsername: "deprecated_integrator"}, &client.AppParameters{}, client.LaunchConfig{})
+	if !result.Violated || result.Severity != PolicyAdvisory {
+		t.Errorf("Evaluate() = %+v, want an advisory violation for a blocked integrator", result)
+	}
+
+	result = policy.Evaluate(context.Background(), &client.App{IntegratorUsername: "someone_else"}, &client.AppParameters{}, client.LaunchConfig{})
+	if result.Violated {
+		t.Errorf("Evaluate() = %+v, want no violation for a non-blocked integrator", result)
+	}
+}
+
+// TestLaunchAndStream tests the LaunchAndStream workflow's progress callback
+// and cancellation handling.
+func TestLaunchAndStream(t *testing.T) {
+	t.Run("reports intermediate and URL-ready updates", func(t *testing.T) {
+		statusIndex := 0
+		statusSequence := []*client.AnalysisStatus{
+			{AnalysisID: "analysis-456", Status: "Running", URLReady: false},
+			{AnalysisID: "analysis-456", Status: "Running", URLReady: true, URL: "https://test.cyverse.run"},
+		}
+
+		mockClient := &mockFormationClient{
+			getAppParametersFunc: func(ctx context.Context, systemID, appID string) (*client.AppParameters, error) {
+				return &client.AppParameters{OverallJobType: "Interactive", Groups: []client.ParameterGroup{}}, nil
+			},
+			launchAppFunc: func(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error) {
+				return &client.LaunchResponse{AnalysisID: "analysis-456", Name: "test", Status: "Submitted"}, nil
+			},
+			getAnalysisStatusFunc: func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
+				if statusIndex < len(statusSequence) {
+					status := statusSequence[statusIndex]
+					statusIndex++
+					return status, nil
+				}
+				return statusSequence[len(statusSequence)-1], nil
+			},
+		}
+
+		workflows := NewFormationWorkflows(mockClient, &mockBrowserOpener{}, 10*time.Millisecond)
+
+		var updates []LaunchProgress
+		result, err := workflows.LaunchAndStream(context.Background(), "interactive-app", "de", "test", client.LaunchConfig{}, 200*time.Millisecond, func(p LaunchProgress) error {
+			updates = append(updates, p)
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("LaunchAndStream() unexpected error = %v", err)
+		}
+		if result.AnalysisID != "analysis-456" {
+			t.Errorf("LaunchAndStream() analysis ID = %v, want analysis-456", result.AnalysisID)
+		}
+		if len(updates) < 2 {
+			t.Fatalf("LaunchAndStream() got %d updates, want at least 2 (an intermediate update and a URL-ready one)", len(updates))
+		}
+
+		sawURLReady := false
+		for _, u := range updates {
+			if u.URLReady && u.URL == "https://test.cyverse.run" {
+				sawURLReady = true
+			}
+		}
+		if !sawURLReady {
+			t.Errorf("LaunchAndStream() never reported a URL-ready update, got %+v", updates)
+		}
+	})
+
+	t.Run("cancellation stops the analysis without saving", func(t *testing.T) {
+		var calledAnalysisID, calledOperation string
+		var calledSaveOutputs bool
+		stopped := make(chan struct{})
+
+		mockClient := &mockFormationClient{
+			getAppParametersFunc: func(ctx context.Context, systemID, appID string) (*client.AppParameters, error) {
+				return &client.AppParameters{OverallJobType: "Interactive", Groups: []client.ParameterGroup{}}, nil
+			},
+			launchAppFunc: func(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error) {
+				return &client.LaunchResponse{AnalysisID: "analysis-cancel", Name: "test", Status: "Submitted"}, nil
+			},
+			getAnalysisStatusFunc: func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
+				return &client.AnalysisStatus{AnalysisID: analysisID, Status: "Running", URLReady: false}, nil
+			},
+			controlAnalysisFunc: func(ctx context.Context, analysisID, operation string, saveOutputs bool) error {
+				calledAnalysisID = analysisID
+				calledOperation = operation
+				calledSaveOutputs = saveOutputs
+				close(stopped)
+				return nil
+			},
+		}
+
+		workflows := NewFormationWorkflows(mockClient, &mockBrowserOpener{}, 10*time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+
+		_, err := workflows.LaunchAndStream(ctx, "interactive-app", "de", "test", client.LaunchConfig{}, time.Second, func(p LaunchProgress) error {
+			return nil
+		})
+
+		if err != context.Canceled {
+			t.Errorf("LaunchAndStream() error = %v, want context.Canceled", err)
+		}
+
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("LaunchAndStream() did not call ControlAnalysis after cancellation")
+		}
+
+		if calledAnalysisID != "analysis-cancel" {
+			t.Errorf("ControlAnalysis() called with analysis ID %v, want analysis-cancel", calledAnalysisID)
+		}
+		if calledOperation != "exit" {
+			t.Errorf("ControlAnalysis() called with operation %v, want exit", calledOperation)
+		}
+		if calledSaveOutputs {
+			t.Errorf("ControlAnalysis() called with saveOutputs=true, want false")
+		}
+	})
+
+	t.Run("onUpdate error aborts streaming", func(t *testing.T) {
+		mockClient := &mockFormationClient{
+			getAppParametersFunc: func(ctx context.Context, systemID, appID string) (*client.AppParameters, error) {
+				return &client.AppParameters{OverallJobType: "DE", Groups: []client.ParameterGroup{}}, nil
+			},
+			launchAppFunc: func(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error) {
+				return &client.LaunchResponse{AnalysisID: "analysis-batch", Name: "test", Status: "Submitted"}, nil
+			},
+		}
+
+		workflows := NewFormationWorkflows(mockClient, &mockBrowserOpener{}, 10*time.Millisecond)
+
+		wantErr := errors.New("sink closed")
+		_, err := workflows.LaunchAndStream(context.Background(), "batch-app", "de", "test", client.LaunchConfig{}, time.Second, func(p LaunchProgress) error {
+			return wantErr
+		})
+
+		if err != wantErr {
+			t.Errorf("LaunchAndStream() error = %v, want %v", err, wantErr)
+		}
+	})
+}
+
 // TestIsInteractiveJobType tests job type detection
 func TestIsInteractiveJobType(t *testing.T) {
 	tests := []struct {
@@ -462,7 +1026,7 @@ func TestStopAnalysis(t *testing.T) {
 			}
 
 			workflows := NewFormationWorkflows(mockClient, &mockBrowserOpener{}, 5*time.Second)
-			err := workflows.StopAnalysis(context.Background(), tt.analysisID, tt.saveOutputs)
+			err := workflows.StopAnalysis(context.Background(), tt.analysisID, tt.saveOutputs, false)
 
 			if tt.wantErr && err == nil {
 				t.Errorf("StopAnalysis() expected error but got none")
@@ -482,6 +1046,129 @@ func TestStopAnalysis(t *testing.T) {
 	}
 }
 
+// TestSnapshotAndResume covers SnapshotAnalysis and ResumeFromSnapshot
+// together, since ResumeFromSnapshot reads back exactly what
+// SnapshotAnalysis persisted.
+func TestSnapshotAndResume(t *testing.T) {
+	var createdDirs []string
+	var createdMetadata map[string]interface{}
+	var uploadedPath, uploadedContent string
+
+	mockClient := &mockFormationClient{
+		getAppParametersFunc: func(ctx context.Context, systemID, appID string) (*client.AppParameters, error) {
+			return &client.AppParameters{OverallJobType: "Batch", Groups: []client.ParameterGroup{}}, nil
+		},
+		launchAppFunc: func(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error) {
+			return &client.LaunchResponse{AnalysisID: "analysis-snap", Name: "test", Status: "Submitted"}, nil
+		},
+		createDirectoryFunc: func(ctx context.Context, path string, metadata map[string]interface{}) (*client.CreateDirectoryResponse, error) {
+			createdDirs = append(createdDirs, path)
+			createdMetadata = metadata
+			return &client.CreateDirectoryResponse{Path: path, Type: "collection"}, nil
+		},
+		uploadFileFunc: func(ctx context.Context, path, content string, metadata map[string]interface{}) error {
+			uploadedPath = path
+			uploadedContent = content
+			return nil
+		},
+	}
+
+	w := NewFormationWorkflows(mockClient, &mockBrowserOpener{}, 10*time.Millisecond)
+
+	result, err := w.LaunchAndWait(context.Background(), "app-1", "de", "test", client.LaunchConfig{"input": "/data/file.txt"}, nil, nil, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("LaunchAndWait() unexpected error = %v", err)
+	}
+
+	snapshot, err := w.SnapshotAnalysis(context.Background(), result.AnalysisID, "end of day")
+	if err != nil {
+		t.Fatalf("SnapshotAnalysis() unexpected error = %v", err)
+	}
+	if len(createdDirs) != 1 || createdDirs[0] != snapshot.Path {
+		t.Errorf("CreateDirectory called with %v, want [%s]", createdDirs, snapshot.Path)
+	}
+	if createdMetadata["app_id"] != "app-1" || createdMetadata["system_id"] != "de" {
+		t.Errorf("snapshot metadata = %v, missing app/system id", createdMetadata)
+	}
+	if uploadedPath != snapshot.Path+"/snapshot.json" {
+		t.Errorf("UploadFile path = %v, want %s/snapshot.json", uploadedPath, snapshot.Path)
+	}
+
+	if _, err := w.SnapshotAnalysis(context.Background(), "never-launched", ""); err == nil {
+		t.Error("SnapshotAnalysis() expected error for an analysis this instance never launched")
+	}
+
+	mockClient.browseDataFunc = func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+		if path != snapshot.Path+"/snapshot.json" {
+			return nil, fmt.Errorf("unexpected path %s", path)
+		}
+		return &client.FileContent{
+			Path:    path,
+			Content: uploadedContent,
+			Metadata: map[string]interface{}{
+				"app_id":    "app-1",
+				"system_id": "de",
+			},
+		}, nil
+	}
+
+	resumed, err := w.ResumeFromSnapshot(context.Background(), snapshot.ID, client.LaunchConfig{"override": true})
+	if err != nil {
+		t.Fatalf("ResumeFromSnapshot() unexpected error = %v", err)
+	}
+	if resumed.AnalysisID != "analysis-snap" {
+		t.Errorf("ResumeFromSnapshot() analysis ID = %v, want analysis-snap", resumed.AnalysisID)
+	}
+}
+
+// TestListSnapshots covers ListSnapshots filtering BrowseData's listing of
+// .snapshots down to the entries carrying the snapshot AVU.
+func TestListSnapshots(t *testing.T) {
+	configJSON := `{"input":"/data/file.txt"}`
+
+	mockClient := &mockFormationClient{
+		browseDataFunc: func(ctx context.Context, path string, offset, limit int, includeMetadata bool) (interface{}, error) {
+			switch path {
+			case ".snapshots":
+				return &client.DirectoryContents{
+					Path: path,
+					Type: "collection",
+					Contents: []client.DirectoryEntry{
+						{Name: "analysis-1-123", Type: "collection"},
+						{Name: "notes.txt", Type: "data_object"},
+					},
+				}, nil
+			case ".snapshots/analysis-1-123/snapshot.json":
+				return &client.FileContent{
+					Path:    path,
+					Content: configJSON,
+					Metadata: map[string]interface{}{
+						"formation-mcp-snapshot": "true",
+						"analysis_id":            "analysis-1",
+						"app_id":                 "app-1",
+						"system_id":              "de",
+						"created_at":             "2026-07-26T00:00:00Z",
+					},
+				}, nil
+			}
+			return nil, fmt.Errorf("unexpected path %s", path)
+		},
+	}
+
+	w := NewFormationWorkflows(mockClient, &mockBrowserOpener{}, 10*time.Millisecond)
+
+	snapshots, err := w.ListSnapshots(context.Background())
+	if err != nil {
+		t.Fatalf("ListSnapshots() unexpected error = %v", err)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("ListSnapshots() returned %d snapshots, want 1 (the non-collection entry should be skipped)", len(snapshots))
+	}
+	if snapshots[0].AnalysisID != "analysis-1" || snapshots[0].AppID != "app-1" {
+		t.Errorf("ListSnapshots()[0] = %+v, want analysis-1/app-1", snapshots[0])
+	}
+}
+
 // TestOpenInBrowser tests browser opening
 func TestOpenInBrowser(t *testing.T) {
 	tests := []struct {
@@ -644,3 +1331,316 @@ func stringContains(s, substr string) bool {
 	}
 	return false
 }
+
+// TestAnalysisWatcherCoalescesPolling verifies that two subscribers
+// watching the same analysisID share a single poll loop: both receive
+// status updates, but GetAnalysisStatus is called once per tick, not once
+// per subscriber.
+func TestAnalysisWatcherCoalescesPolling(t *testing.T) {
+	var calls atomic.Int64
+	mockClient := &mockFormationClient{
+		getAnalysisStatusFunc: func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
+			calls.Add(1)
+			return &client.AnalysisStatus{AnalysisID: analysisID, Status: "Running", URLReady: true, URL: "https://example.org"}, nil
+		},
+	}
+
+	w := NewAnalysisWatcher(mockClient, 20*time.Millisecond)
+	defer w.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch1, unsubscribe1 := w.Watch(ctx, "analysis-1")
+	defer unsubscribe1()
+	ch2, unsubscribe2 := w.Watch(ctx, "analysis-1")
+	defer unsubscribe2()
+
+	var status1, status2 client.AnalysisStatus
+	select {
+	case status1 = <-ch1:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for status on first subscriber")
+	}
+	select {
+	case status2 = <-ch2:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for status on second subscriber")
+	}
+
+	if status1.AnalysisID != "analysis-1" || status2.AnalysisID != "analysis-1" {
+		t.Errorf("expected both subscribers to receive analysis-1's status, got %+v and %+v", status1, status2)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("expected exactly 1 GetAnalysisStatus call to be coalesced across 2 subscribers, got %d", calls.Load())
+	}
+
+	callsMade, callsSaved := w.Metrics()
+	if callsMade != 1 {
+		t.Errorf("Metrics() callsMade = %d, want 1", callsMade)
+	}
+	if callsSaved != 1 {
+		t.Errorf("Metrics() callsSaved = %d, want 1 (second subscriber's call was saved)", callsSaved)
+	}
+}
+
+// TestAnalysisWatcherStopsOnTerminalStatus verifies that once an analysis
+// reaches a terminal status, the watcher stops polling it rather than
+// continuing to poll forever.
+func TestAnalysisWatcherStopsOnTerminalStatus(t *testing.T) {
+	var calls atomic.Int64
+	mockClient := &mockFormationClient{
+		getAnalysisStatusFunc: func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
+			calls.Add(1)
+			return &client.AnalysisStatus{AnalysisID: analysisID, Status: "Completed"}, nil
+		},
+	}
+
+	w := NewAnalysisWatcher(mockClient, 10*time.Millisecond)
+	defer w.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, unsubscribe := w.Watch(ctx, "analysis-2")
+	defer unsubscribe()
+
+	select {
+	case status := <-ch:
+		if status.Status != "Completed" {
+			t.Fatalf("expected Completed status, got %q", status.Status)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for status")
+	}
+
+	// Give the watcher a chance to poll again if it (incorrectly) kept
+	// polling a terminal analysis.
+	time.Sleep(50 * time.Millisecond)
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected polling to stop after a terminal status, got %d calls", got)
+	}
+}
+
+// TestAnalysisWatcherUnsubscribeStopsDelivery verifies that calling the
+// unsubscribe func returned by Watch closes the subscriber's channel.
+func TestAnalysisWatcherUnsubscribeStopsDelivery(t *testing.T) {
+	mockClient := &mockFormationClient{
+		getAnalysisStatusFunc: func(ctx context.Context, analysisID string) (*client.AnalysisStatus, error) {
+			return &client.AnalysisStatus{AnalysisID: analysisID, Status: "Running"}, nil
+		},
+	}
+
+	w := NewAnalysisWatcher(mockClient, 10*time.Millisecond)
+	defer w.Stop()
+
+	ch, unsubscribe := w.Watch(context.Background(), "analysis-3")
+	if err := unsubscribe(); err != nil {
+		t.Fatalf("unsubscribe() unexpected error = %v", err)
+	}
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("expected channel to be closed after unsubscribe, got a value instead")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for channel to close after unsubscribe")
+	}
+
+	// Calling unsubscribe again must not panic or block.
+	if err := unsubscribe(); err != nil {
+		t.Fatalf("second unsubscribe() unexpected error = %v", err)
+	}
+}
+
+// TestBulkUploadBestEffort verifies that BulkUpload runs every item, keeps
+// each ItemResult at its original index, and doesn't stop after a single
+// item fails when StopOnFirstError isn't set.
+func TestBulkUploadBestEffort(t *testing.T) {
+	mockClient := &mockFormationClient{
+		uploadFileFunc: func(ctx context.Context, path, content string, metadata map[string]interface{}) error {
+			if path == "/fail" {
+				return errors.New("upload failed")
+			}
+			return nil
+		},
+	}
+
+	items := []UploadItem{
+		{Path: "/ok-1"},
+		{Path: "/fail"},
+		{Path: "/ok-2"},
+	}
+
+	result, err := NewBulkExecutor(mockClient).BulkUpload(context.Background(), items, BulkOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("BulkUpload() unexpected error = %v", err)
+	}
+	if len(result.Results) != len(items) {
+		t.Fatalf("expected %d results, got %d", len(items), len(result.Results))
+	}
+	for i, res := range result.Results {
+		if res.Index != i {
+			t.Errorf("result %d has Index %d, want %d", i, res.Index, i)
+		}
+	}
+	if result.Results[0].Err != nil || result.Results[2].Err != nil {
+		t.Errorf("expected items 0 and 2 to succeed, got %v and %v", result.Results[0].Err, result.Results[2].Err)
+	}
+	if result.Results[1].Err == nil {
+		t.Error("expected item 1 to fail")
+	}
+	if failed := result.Failed(); len(failed) != 1 || failed[0].Index != 1 {
+		t.Errorf("Failed() = %+v, want exactly index 1", failed)
+	}
+}
+
+// TestBulkDeleteStopOnFirstError verifies that, with StopOnFirstError set,
+// BulkDelete returns an error once one item fails and records that item
+// as failed.
+func TestBulkDeleteStopOnFirstError(t *testing.T) {
+	block := make(chan struct{})
+	mockClient := &mockFormationClient{
+		deleteDataFunc: func(ctx context.Context, path string, recurse, dryRun bool) error {
+			if path == "/fail" {
+				return errors.New("delete failed")
+			}
+			// Every non-failing item blocks until the failing one has had
+			// a chance to trigger cancellation, so the test deterministically
+			// exercises the StopOnFirstError path instead of racing it.
+			select {
+			case <-block:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+
+	items := []DeleteItem{
+		{Path: "/fail"},
+		{Path: "/blocked-1"},
+		{Path: "/blocked-2"},
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(block)
+	}()
+
+	result, err := NewBulkExecutor(mockClient).BulkDelete(context.Background(), items, BulkOptions{Concurrency: 3, StopOnFirstError: true})
+	if err == nil {
+		t.Fatal("expected BulkDelete() to return an error when StopOnFirstError is set and an item fails")
+	}
+	if result.Results[0].Err == nil {
+		t.Error("expected the failing item to be recorded as failed")
+	}
+}
+
+// TestBulkSetMetadataProgress verifies that BulkSetMetadata reports a
+// progress update per completed item on opts.Progress.
+func TestBulkSetMetadataProgress(t *testing.T) {
+	mockClient := &mockFormationClient{
+		setMetadataFunc: func(ctx context.Context, path string, metadata map[string]interface{}, replace bool) error {
+			return nil
+		},
+	}
+
+	items := []MetadataItem{{Path: "/a"}, {Path: "/b"}, {Path: "/c"}}
+	progress := make(chan BulkProgress, len(items))
+
+	result, err := NewBulkExecutor(mockClient).BulkSetMetadata(context.Background(), items, BulkOptions{Concurrency: 1, Progress: progress})
+	if err != nil {
+		t.Fatalf("BulkSetMetadata() unexpected error = %v", err)
+	}
+	close(progress)
+
+	var last BulkProgress
+	count := 0
+	for p := range progress {
+		count++
+		last = p
+	}
+	if count != len(items) {
+		t.Errorf("expected %d progress updates, got %d", len(items), count)
+	}
+	if last.Completed != len(items) || last.Total != len(items) || last.Failed != 0 {
+		t.Errorf("final progress = %+v, want Completed/Total %d and Failed 0", last, len(items))
+	}
+	for _, res := range result.Results {
+		if res.Err != nil {
+			t.Errorf("unexpected error for item %d: %v", res.Index, res.Err)
+		}
+	}
+}
+
+// TestLaunchBatch verifies that LaunchBatch merges each item's config onto
+// the base config, submits every item, and that a failing item doesn't
+// prevent the rest from being reported.
+func TestLaunchBatch(t *testing.T) {
+	var mu sync.Mutex
+	submitted := make(map[string]client.LaunchConfig)
+
+	mockClient := &mockFormationClient{
+		launchAppFunc: func(ctx context.Context, systemID, appID string, submission client.LaunchSubmission) (*client.LaunchResponse, error) {
+			mu.Lock()
+			submitted[submission.Name] = submission.Config
+			mu.Unlock()
+
+			if submission.Config["input"] == "/data/bad.txt" {
+				return nil, errors.New("launch failed")
+			}
+			return &client.LaunchResponse{AnalysisID: "analysis-" + submission.Name, Status: "Submitted"}, nil
+		},
+	}
+
+	w := NewFormationWorkflows(mockClient, &mockBrowserOpener{}, 10*time.Millisecond)
+
+	items := []BatchItem{
+		{Input: "/data/a.txt", Config: client.LaunchConfig{"input": "/data/a.txt"}},
+		{Input: "/data/bad.txt", Config: client.LaunchConfig{"input": "/data/bad.txt"}},
+		{Input: "/data/c.txt", Config: client.LaunchConfig{"input": "/data/c.txt"}},
+	}
+
+	batch, err := w.LaunchBatch(context.Background(), "app-1", "de", client.LaunchConfig{"shared": "value"}, items, 2)
+	if err != nil {
+		t.Fatalf("LaunchBatch() unexpected error = %v", err)
+	}
+	if batch.ID == "" {
+		t.Error("LaunchBatch() returned an empty batch ID")
+	}
+	if len(batch.Items) != len(items) {
+		t.Fatalf("LaunchBatch() returned %d items, want %d", len(batch.Items), len(items))
+	}
+
+	if batch.Items[0].Err != nil || batch.Items[0].AnalysisID == "" {
+		t.Errorf("item 0 = %+v, want a successful submission", batch.Items[0])
+	}
+	if batch.Items[1].Err == nil {
+		t.Error("item 1 expected to fail")
+	}
+	if batch.Items[2].Err != nil || batch.Items[2].AnalysisID == "" {
+		t.Errorf("item 2 = %+v, want a successful submission", batch.Items[2])
+	}
+
+	for name, config := range submitted {
+		if config["shared"] != "value" {
+			t.Errorf("submission %s missing merged base config: %+v", name, config)
+		}
+	}
+
+	status, err := w.GetBatchStatus(context.Background(), batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatchStatus() unexpected error = %v", err)
+	}
+	if len(status.Items) != len(items) {
+		t.Errorf("GetBatchStatus() returned %d items, want %d", len(status.Items), len(items))
+	}
+
+	if _, err := w.GetBatchStatus(context.Background(), "no-such-batch"); err == nil {
+		t.Error("GetBatchStatus() expected an error for an unknown batch ID")
+	}
+}